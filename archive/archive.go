@@ -0,0 +1,472 @@
+// The archive package is used to store API dumps and related files of many
+// versions.
+//
+// An Archive organizes files by version. File content is stored by its
+// SHA-256 hash, and each version refers to the content of its files. Many
+// builds ship identical API dumps, and such content is stored only once.
+//
+// The underlying storage is abstracted by a Store. The Dir type stores an
+// archive on the local file system, while the s3 subpackage stores an archive
+// in S3-compatible object storage.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/mmap"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// versionsPrefix is the prefix of keys containing versions.
+	versionsPrefix = "versions/"
+	// blobsPrefix is the prefix of keys containing file content.
+	blobsPrefix = "blobs/"
+	// metaFile is the name of the file containing the metadata of a
+	// version.
+	metaFile = "meta.json"
+)
+
+// Meta describes a version stored in an archive.
+type Meta struct {
+	// Version is the version described by the metadata.
+	Version fetch.Version
+	// Files maps the names of files stored for the version to information
+	// about each file.
+	Files map[string]File
+}
+
+// File describes a single file stored in an archive.
+type File struct {
+	// Size is the length of the file, in bytes.
+	Size int64
+	// SHA256 is the hex-encoded SHA-256 hash of the content of the file.
+	SHA256 string
+}
+
+// Archive stores files of many versions within a Store. Each version is
+// identified by its GUID.
+type Archive struct {
+	Store Store
+}
+
+// New returns an Archive that uses the given store.
+func New(store Store) *Archive {
+	return &Archive{Store: store}
+}
+
+func versionKey(guid, name string) string {
+	return versionsPrefix + guid + "/" + name
+}
+
+// blobKey returns the key of the content with the given hash.
+func blobKey(sum string) string {
+	return blobsPrefix + sum[:2] + "/" + sum
+}
+
+// hasKey returns whether key exists in the store. If the store does not
+// implement Stater, the key is opened instead.
+func (a *Archive) hasKey(ctx context.Context, key string) (bool, error) {
+	var err error
+	if s, ok := a.Store.(Stater); ok {
+		_, err = s.Stat(ctx, key)
+	} else {
+		var r io.ReadCloser
+		if r, err = a.Store.Get(ctx, key); err == nil {
+			r.Close()
+		}
+	}
+	switch err {
+	case nil:
+		return true, nil
+	case ErrNotExist:
+		return false, nil
+	}
+	return false, err
+}
+
+// fileKeys returns the keys at which the content of a file of a version may
+// be stored, in order of preference. Content is stored by its hash, while
+// archives written before content addressing keep it with the version,
+// whether or not its hash was recorded.
+func fileKeys(guid, name string, file File) []string {
+	if file.SHA256 == "" {
+		return []string{versionKey(guid, name)}
+	}
+	return []string{blobKey(file.SHA256), versionKey(guid, name)}
+}
+
+// Meta returns the metadata of the version of the given GUID. Returns
+// ErrNotExist if the version is not present in the archive.
+func (a *Archive) Meta(ctx context.Context, guid string) (*Meta, error) {
+	r, err := a.Store.Get(ctx, versionKey(guid, metaFile))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var meta Meta
+	if err := json.NewDecoder(r).Decode(&meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// putMeta writes the metadata of a version.
+func (a *Archive) putMeta(ctx context.Context, meta *Meta) error {
+	b, err := json.MarshalIndent(meta, "", "\t")
+	if err != nil {
+		return err
+	}
+	return a.Store.Put(ctx, versionKey(meta.Version.GUID, metaFile), bytes.NewReader(b))
+}
+
+// Versions returns the metadata of every version present in the archive,
+// ordered by fetch.Version.Compare.
+func (a *Archive) Versions(ctx context.Context) ([]*Meta, error) {
+	keys, err := a.Store.List(ctx, versionsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var metas []*Meta
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/"+metaFile) {
+			continue
+		}
+		guid := strings.TrimSuffix(strings.TrimPrefix(key, versionsPrefix), "/"+metaFile)
+		if strings.Contains(guid, "/") {
+			continue
+		}
+		meta, err := a.Meta(ctx, guid)
+		if err != nil {
+			return nil, err
+		}
+		metas = append(metas, meta)
+	}
+	sort.SliceStable(metas, func(i, j int) bool {
+		return metas[i].Version.Compare(metas[j].Version) < 0
+	})
+	return metas, nil
+}
+
+// Between returns the metadata of versions in the archive deployed at or
+// after from, and before to. A zero time leaves the corresponding end of the
+// range unbounded.
+func (a *Archive) Between(ctx context.Context, from, to time.Time) ([]*Meta, error) {
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Meta
+	for _, meta := range metas {
+		if !from.IsZero() && meta.Version.Date.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !meta.Version.Date.Before(to) {
+			continue
+		}
+		list = append(list, meta)
+	}
+	return list, nil
+}
+
+// Annotate fills in the version number, date, and binary type of versions in
+// the archive that lack them, using builds from history with matching GUIDs.
+// Returns the number of updated versions.
+func (a *Archive) Annotate(ctx context.Context, history fetch.History) (n int, err error) {
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, meta := range metas {
+		v := meta.Version
+		if !v.Number.IsZero() && !v.Date.IsZero() && v.Type != "" {
+			continue
+		}
+		b, ok := history.GUID(v.GUID)
+		if !ok {
+			continue
+		}
+		if v.Number.IsZero() {
+			v.Number = b.Number
+		}
+		if v.Date.IsZero() {
+			v.Date = b.Date
+		}
+		if v.Type == "" {
+			v.Type = b.Type
+		}
+		if v == meta.Version {
+			continue
+		}
+		meta.Version = v
+		if err := a.putMeta(ctx, meta); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Put stores a file of the given version, reading its content from r. The
+// metadata of the version is created or updated. If identical content is
+// already present in the archive, it is shared rather than stored again.
+func (a *Archive) Put(ctx context.Context, v fetch.Version, name string, r io.Reader) error {
+	meta, err := a.Meta(ctx, v.GUID)
+	switch err {
+	case nil:
+	case ErrNotExist:
+		meta = &Meta{}
+	default:
+		return err
+	}
+	meta.Version = v
+	if meta.Files == nil {
+		meta.Files = map[string]File{}
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	h := sha256.Sum256(b)
+	sum := hex.EncodeToString(h[:])
+	if ok, err := a.hasKey(ctx, blobKey(sum)); err != nil {
+		return err
+	} else if !ok {
+		if err := a.Store.Put(ctx, blobKey(sum), bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	meta.Files[name] = File{Size: int64(len(b)), SHA256: sum}
+	return a.putMeta(ctx, meta)
+}
+
+// Fetch downloads a file of the given version using client, and stores it in
+// the archive. The download is made to a temporary directory, and is stored
+// only after it has been verified. If the archive already contains the file,
+// the download must match the recorded hash.
+func (a *Archive) Fetch(ctx context.Context, client *fetch.Client, v fetch.Version, name string) error {
+	var expected string
+	if meta, err := a.Meta(ctx, v.GUID); err == nil {
+		expected = meta.Files[name].SHA256
+	} else if err != ErrNotExist {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "rbxapi-archive-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, name)
+	if _, err := client.Download(ctx, v, name, path, expected); err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return a.Put(ctx, v, name, f)
+}
+
+// Verify checks that the content of a stored file matches the size and hash
+// recorded in the metadata of its version.
+func (a *Archive) Verify(ctx context.Context, guid, name string) error {
+	meta, err := a.Meta(ctx, guid)
+	if err != nil {
+		return err
+	}
+	file, ok := meta.Files[name]
+	if !ok {
+		return ErrNotExist
+	}
+	r, err := a.Get(ctx, guid, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	hr := &hashReader{r: r, h: sha256.New()}
+	if _, err := io.Copy(ioutil.Discard, hr); err != nil {
+		return err
+	}
+	if hr.n != file.Size {
+		return errors.New(guid + "/" + name + ": size mismatch")
+	}
+	if file.SHA256 != "" && hex.EncodeToString(hr.h.Sum(nil)) != file.SHA256 {
+		return errors.New(guid + "/" + name + ": hash mismatch")
+	}
+	return nil
+}
+
+// Get returns a reader of the content of a file of the version of the given
+// GUID. Returns ErrNotExist if the file is not present. The caller must close
+// the returned reader.
+func (a *Archive) Get(ctx context.Context, guid, name string) (io.ReadCloser, error) {
+	meta, err := a.Meta(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	file, ok := meta.Files[name]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	for _, key := range fileKeys(guid, name, file) {
+		r, err := a.Store.Get(ctx, key)
+		if err != ErrNotExist {
+			return r, err
+		}
+	}
+	return nil, ErrNotExist
+}
+
+// Map returns a read-only mapping of the content of a file of the version of
+// the given GUID. If the Store does not implement Mapper, the content is read
+// into memory instead. Returns ErrNotExist if the file is not present. The
+// caller must close the returned mapping.
+func (a *Archive) Map(ctx context.Context, guid, name string) (*mmap.Mapping, error) {
+	meta, err := a.Meta(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	file, ok := meta.Files[name]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	if m, ok := a.Store.(Mapper); ok {
+		for _, key := range fileKeys(guid, name, file) {
+			mapping, err := m.Map(ctx, key)
+			if err != ErrNotExist {
+				return mapping, err
+			}
+		}
+		return nil, ErrNotExist
+	}
+	r, err := a.Get(ctx, guid, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return mmap.New(b), nil
+}
+
+// Delete removes a version from the archive. Content that is no longer
+// referenced by any version remains in the store until GC is called.
+func (a *Archive) Delete(ctx context.Context, guid string) error {
+	keys, err := a.Store.List(ctx, versionsPrefix+guid+"/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == versionKey(guid, metaFile) {
+			continue
+		}
+		if err := a.Store.Delete(ctx, key); err != nil && err != ErrNotExist {
+			return err
+		}
+	}
+	// Remove metadata last, so that the version remains visible if removing
+	// files fails.
+	if err := a.Store.Delete(ctx, versionKey(guid, metaFile)); err != nil && err != ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+// GC removes content that is not referenced by any version. Returns the
+// number of removed blobs.
+func (a *Archive) GC(ctx context.Context) (n int, err error) {
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	used := map[string]bool{}
+	for _, meta := range metas {
+		for _, file := range meta.Files {
+			if file.SHA256 != "" {
+				used[blobKey(file.SHA256)] = true
+			}
+		}
+	}
+	keys, err := a.Store.List(ctx, blobsPrefix)
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		if used[key] {
+			continue
+		}
+		if err := a.Store.Delete(ctx, key); err != nil && err != ErrNotExist {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Identical returns the versions that have a file of the given name with
+// content identical to that of the version of the given GUID. The given
+// version itself is not included.
+func (a *Archive) Identical(ctx context.Context, guid, name string) ([]*Meta, error) {
+	meta, err := a.Meta(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	file, ok := meta.Files[name]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Meta
+	for _, m := range metas {
+		if m.Version.GUID == guid {
+			continue
+		}
+		if f, ok := m.Files[name]; ok && f.SHA256 != "" && f.SHA256 == file.SHA256 {
+			list = append(list, m)
+		}
+	}
+	return list, nil
+}
+
+// JSONDump decodes the JSON API dump of the version of the given GUID.
+func (a *Archive) JSONDump(ctx context.Context, guid string) (*rbxapijson.Root, error) {
+	r, err := a.Get(ctx, guid, fetch.JSONDumpFile)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return rbxapijson.Decode(r)
+}
+
+// hashReader counts and hashes the bytes read from a reader.
+type hashReader struct {
+	r io.Reader
+	h hash.Hash
+	n int64
+}
+
+func (r *hashReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	r.n += int64(n)
+	r.h.Write(p[:n])
+	return n, err
+}
@@ -0,0 +1,71 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+)
+
+// ErrNotFound is returned by Bisect when no version satisfies the predicate.
+var ErrNotFound = errors.New("no version satisfies the predicate")
+
+// Bisect returns the earliest version in the archive whose JSON API dump
+// satisfies fn, along with the version immediately before it, which is nil if
+// the first version satisfies fn. Versions without a JSON API dump are
+// skipped.
+//
+// Bisect assumes that once fn is satisfied by a version, it is satisfied by
+// all later versions, and so decodes only a logarithmic number of dumps. If
+// the assumption does not hold, the returned version is some version that
+// satisfies fn and is preceded by one that does not. Returns ErrNotFound if
+// the latest version does not satisfy fn.
+func (a *Archive) Bisect(ctx context.Context, fn func(root *rbxapijson.Root) bool) (first, prev *Meta, err error) {
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var list []*Meta
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; ok {
+			list = append(list, meta)
+		}
+	}
+	test := func(i int) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		root, err := a.JSONDump(ctx, list[i].Version.GUID)
+		if err != nil {
+			return false, err
+		}
+		return fn(root), nil
+	}
+	if len(list) == 0 {
+		return nil, nil, ErrNotFound
+	}
+	if ok, err := test(len(list) - 1); err != nil {
+		return nil, nil, err
+	} else if !ok {
+		return nil, nil, ErrNotFound
+	}
+	// Invariant: list[hi] satisfies fn, and every version before lo does
+	// not.
+	lo, hi := 0, len(list)-1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		ok, err := test(mid)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	if hi > 0 {
+		prev = list[hi-1]
+	}
+	return list[hi], prev, nil
+}
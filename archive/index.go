@@ -0,0 +1,319 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"sort"
+	"strings"
+)
+
+// indexKey is the key under which an Index is persisted.
+const indexKey = "index.gob"
+
+// indexFormat is the version of the persisted index format. Indexes of a
+// different format are rebuilt.
+const indexFormat = 1
+
+// Descriptor kinds used to form descriptor paths.
+const (
+	KindClass    = "Class"
+	KindMember   = "Member"
+	KindEnum     = "Enum"
+	KindEnumItem = "EnumItem"
+)
+
+// ClassPath returns the path used by an Index to identify a class.
+func ClassPath(class string) string {
+	return KindClass + " " + class
+}
+
+// MemberPath returns the path used by an Index to identify a member of a
+// class.
+func MemberPath(class, member string) string {
+	return KindMember + " " + class + "." + member
+}
+
+// EnumPath returns the path used by an Index to identify an enum.
+func EnumPath(enum string) string {
+	return KindEnum + " " + enum
+}
+
+// EnumItemPath returns the path used by an Index to identify an item of an
+// enum.
+func EnumItemPath(enum, item string) string {
+	return KindEnumItem + " " + enum + "." + item
+}
+
+// bitset is a set of version indexes.
+type bitset []uint64
+
+func (b *bitset) set(i int) {
+	for len(*b) <= i/64 {
+		*b = append(*b, 0)
+	}
+	(*b)[i/64] |= 1 << uint(i%64)
+}
+
+func (b bitset) has(i int) bool {
+	return i/64 < len(b) && b[i/64]&(1<<uint(i%64)) != 0
+}
+
+// indexEntry records the history of a single descriptor.
+type indexEntry struct {
+	// Present contains each version in which the descriptor exists.
+	Present bitset
+	// Changed contains each version in which the descriptor was added, or
+	// differs from the previous version.
+	Changed bitset
+	// Print is the fingerprint of the descriptor in the most recently indexed
+	// version containing it.
+	Print uint64
+	// Last is the index of the most recently indexed version containing the
+	// descriptor.
+	Last int
+}
+
+// Index records the presence and changes of each descriptor across the
+// versions of an archive, allowing queries such as "all versions where
+// Workspace.Gravity existed" to be answered without decoding any dumps.
+//
+// Descriptors are identified by paths, as returned by ClassPath, MemberPath,
+// EnumPath, and EnumItemPath. Only versions containing a JSON API dump are
+// indexed.
+type Index struct {
+	// Versions is the list of indexed versions, in order.
+	Versions []fetch.Version
+	// hashes contains the hash of the dump of each version.
+	hashes  []string
+	entries map[string]*indexEntry
+}
+
+// indexData is the persisted form of an Index.
+type indexData struct {
+	Format   int
+	Versions []fetch.Version
+	Hashes   []string
+	Entries  map[string]*indexEntry
+}
+
+// Index returns an up-to-date index of the archive. A previously persisted
+// index is loaded and updated with versions added since, and the result is
+// persisted. The index is rebuilt when versions were removed or were added
+// out of order.
+func (a *Archive) Index(ctx context.Context) (*Index, error) {
+	idx, err := a.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*Meta
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; ok {
+			pending = append(pending, meta)
+		}
+	}
+	// Reuse the existing index only if it is a prefix of the archive.
+	if len(idx.Versions) > len(pending) {
+		idx = newIndex()
+	} else {
+		for i, v := range idx.Versions {
+			if pending[i].Version.GUID != v.GUID || pending[i].Files[fetch.JSONDumpFile].SHA256 != idx.hashes[i] {
+				idx = newIndex()
+				break
+			}
+		}
+	}
+	pending = pending[len(idx.Versions):]
+	if len(pending) == 0 {
+		return idx, nil
+	}
+	for _, meta := range pending {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := idx.add(ctx, a, meta); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.saveIndex(ctx, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func newIndex() *Index {
+	return &Index{entries: map[string]*indexEntry{}}
+}
+
+func (a *Archive) loadIndex(ctx context.Context) (*Index, error) {
+	r, err := a.Store.Get(ctx, indexKey)
+	if err == ErrNotExist {
+		return newIndex(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var data indexData
+	if err := gob.NewDecoder(r).Decode(&data); err != nil || data.Format != indexFormat {
+		// Treat a corrupt or outdated index as absent.
+		return newIndex(), nil
+	}
+	idx := &Index{Versions: data.Versions, hashes: data.Hashes, entries: data.Entries}
+	if idx.entries == nil {
+		idx.entries = map[string]*indexEntry{}
+	}
+	return idx, nil
+}
+
+func (a *Archive) saveIndex(ctx context.Context, idx *Index) error {
+	var buf bytes.Buffer
+	data := indexData{
+		Format:   indexFormat,
+		Versions: idx.Versions,
+		Hashes:   idx.hashes,
+		Entries:  idx.entries,
+	}
+	if err := gob.NewEncoder(&buf).Encode(&data); err != nil {
+		return err
+	}
+	return a.Store.Put(ctx, indexKey, &buf)
+}
+
+// add indexes the next version.
+func (idx *Index) add(ctx context.Context, a *Archive, meta *Meta) error {
+	i := len(idx.Versions)
+	sum := meta.Files[fetch.JSONDumpFile].SHA256
+	if i > 0 && sum != "" && sum == idx.hashes[i-1] {
+		// Identical content; descriptors are present and unchanged.
+		for _, entry := range idx.entries {
+			if entry.Last == i-1 && entry.Present.has(i-1) {
+				entry.Present.set(i)
+				entry.Last = i
+			}
+		}
+	} else {
+		root, err := a.JSONDump(ctx, meta.Version.GUID)
+		if err != nil {
+			return err
+		}
+		for path, print := range fingerprints(root) {
+			entry := idx.entries[path]
+			if entry == nil {
+				entry = &indexEntry{Last: -1}
+				idx.entries[path] = entry
+			}
+			entry.Present.set(i)
+			if entry.Last != i-1 || entry.Print != print {
+				entry.Changed.set(i)
+			}
+			entry.Print = print
+			entry.Last = i
+		}
+	}
+	idx.Versions = append(idx.Versions, meta.Version)
+	idx.hashes = append(idx.hashes, sum)
+	return nil
+}
+
+// fingerprint returns a hash of the JSON encoding of v.
+func fingerprint(v interface{}) uint64 {
+	b, _ := json.Marshal(v)
+	h := sha256.Sum256(b)
+	return binary.LittleEndian.Uint64(h[:8])
+}
+
+// fingerprints returns the fingerprint of each descriptor of root, excluding
+// the descriptors it contains.
+func fingerprints(root *rbxapijson.Root) map[string]uint64 {
+	prints := map[string]uint64{}
+	for _, class := range root.Classes {
+		c := *class
+		c.Members = nil
+		prints[ClassPath(class.Name)] = fingerprint(&c)
+		for _, member := range class.Members {
+			prints[MemberPath(class.Name, member.GetName())] = fingerprint(struct {
+				MemberType string
+				Member     interface{}
+			}{member.GetMemberType(), member})
+		}
+	}
+	for _, enum := range root.Enums {
+		e := *enum
+		e.Items = nil
+		prints[EnumPath(enum.Name)] = fingerprint(&e)
+		for _, item := range enum.Items {
+			prints[EnumItemPath(enum.Name, item.Name)] = fingerprint(item)
+		}
+	}
+	return prints
+}
+
+func (idx *Index) versions(b bitset) []fetch.Version {
+	var list []fetch.Version
+	for i, v := range idx.Versions {
+		if b.has(i) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Present returns the versions in which the descriptor of the given path
+// exists.
+func (idx *Index) Present(path string) []fetch.Version {
+	entry := idx.entries[path]
+	if entry == nil {
+		return nil
+	}
+	return idx.versions(entry.Present)
+}
+
+// Changed returns the versions in which the descriptor of the given path was
+// added or modified. Changes to the descriptors contained by a class or enum
+// are not included.
+func (idx *Index) Changed(path string) []fetch.Version {
+	entry := idx.entries[path]
+	if entry == nil {
+		return nil
+	}
+	return idx.versions(entry.Changed)
+}
+
+// Removed returns the versions in which the descriptor of the given path was
+// removed; that is, versions that do not contain the descriptor, but whose
+// previous version does.
+func (idx *Index) Removed(path string) []fetch.Version {
+	entry := idx.entries[path]
+	if entry == nil {
+		return nil
+	}
+	var list []fetch.Version
+	for i := 1; i < len(idx.Versions); i++ {
+		if entry.Present.has(i-1) && !entry.Present.has(i) {
+			list = append(list, idx.Versions[i])
+		}
+	}
+	return list
+}
+
+// Descriptors returns, in lexical order, the paths of every indexed
+// descriptor that begins with the given prefix.
+func (idx *Index) Descriptors(prefix string) []string {
+	var list []string
+	for path := range idx.entries {
+		if strings.HasPrefix(path, prefix) {
+			list = append(list, path)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
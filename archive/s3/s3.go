@@ -0,0 +1,348 @@
+// The s3 package implements an archive.Store backed by S3-compatible object
+// storage.
+//
+// Requests are signed with AWS Signature Version 4, which is supported by
+// Amazon S3 as well as most compatible services, such as MinIO, Cloudflare
+// R2, and DigitalOcean Spaces.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"github.com/karl-police/rbxapi/archive"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store is an archive.Store that keeps data as objects within a bucket.
+type Store struct {
+	// Endpoint is the base URL of the service, such as
+	// "https://s3.us-east-1.amazonaws.com".
+	Endpoint string
+	// Region is the region used to sign requests, such as "us-east-1".
+	Region string
+	// Bucket is the name of the bucket containing objects.
+	Bucket string
+	// Prefix is prepended to each key to produce the name of an object. It
+	// allows an archive to occupy a portion of a bucket.
+	Prefix string
+	// PathStyle causes the bucket to be addressed as the first element of
+	// the URL path, rather than as a subdomain of the endpoint.
+	PathStyle bool
+
+	// AccessKeyID and SecretAccessKey are the credentials used to sign
+	// requests.
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is the token of temporary credentials, if any.
+	SessionToken string
+
+	// Client is the HTTP client used to make requests. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Error is returned when the service responds with an error.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (err *Error) Error() string {
+	s := strconv.Itoa(err.StatusCode) + " " + http.StatusText(err.StatusCode)
+	if err.Code != "" {
+		s += ": " + err.Code
+	}
+	if err.Message != "" {
+		s += ": " + err.Message
+	}
+	return s
+}
+
+func (s *Store) httpClient() *http.Client {
+	if s.Client == nil {
+		return http.DefaultClient
+	}
+	return s.Client
+}
+
+// objectURL returns the URL of the object of the given name. If name is
+// empty, the URL of the bucket is returned.
+func (s *Store) objectURL(name string) (*url.URL, error) {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.PathStyle {
+		path += "/" + s.Bucket
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+	}
+	u.Path = path + "/" + name
+	u.RawPath = uriEncode(path, true) + "/" + uriEncode(name, true)
+	return u, nil
+}
+
+// do signs and sends a request, returning the response of a successful
+// request.
+func (s *Store) do(ctx context.Context, method string, u *url.URL, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	s.sign(req, body, time.Now())
+	resp, err := s.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		e := &Error{StatusCode: resp.StatusCode}
+		var r struct {
+			Code    string
+			Message string
+		}
+		if b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<16)); err == nil && xml.Unmarshal(b, &r) == nil {
+			e.Code, e.Message = r.Code, r.Message
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+// Get implements the archive.Store interface.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	u, err := s.objectURL(s.Prefix + key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, "GET", u, nil)
+	if err != nil {
+		if err, ok := err.(*Error); ok && (err.StatusCode == http.StatusNotFound || err.Code == "NoSuchKey") {
+			return nil, archive.ErrNotExist
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat implements the archive.Stater interface.
+func (s *Store) Stat(ctx context.Context, key string) (size int64, err error) {
+	u, err := s.objectURL(s.Prefix + key)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.do(ctx, "HEAD", u, nil)
+	if err != nil {
+		if err, ok := err.(*Error); ok && (err.StatusCode == http.StatusNotFound || err.Code == "NoSuchKey") {
+			return 0, archive.ErrNotExist
+		}
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// Put implements the archive.Store interface. The content is read entirely
+// into memory before being sent, as the signature covers the payload.
+func (s *Store) Put(ctx context.Context, key string, r io.Reader) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	u, err := s.objectURL(s.Prefix + key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, "PUT", u, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete implements the archive.Store interface. S3 does not report whether
+// a deleted object existed, so Delete never returns archive.ErrNotExist.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	u, err := s.objectURL(s.Prefix + key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, "DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List implements the archive.Store interface.
+func (s *Store) List(ctx context.Context, prefix string) (keys []string, err error) {
+	var token string
+	for {
+		u, err := s.objectURL("")
+		if err != nil {
+			return nil, err
+		}
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", s.Prefix+prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = canonicalQuery(query)
+		resp, err := s.do(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range result.Contents {
+			keys = append(keys, strings.TrimPrefix(c.Key, s.Prefix))
+		}
+		if !result.IsTruncated {
+			break
+		}
+		if result.NextContinuationToken == "" {
+			return nil, errors.New("truncated listing without continuation token")
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+const (
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	signService   = "s3"
+	amzDateFormat = "20060102T150405Z"
+)
+
+// sign adds an AWS Signature Version 4 Authorization header to req.
+func (s *Store) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := amzDate[:8]
+	payloadHash := sha256.Sum256(body)
+	payload := hex.EncodeToString(payloadHash[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "host" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payload,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + s.Region + "/" + signService + "/aws4_request"
+	stringToSign := signAlgorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, signService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", signAlgorithm+
+		" Credential="+s.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature,
+	)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, as required by the
+// signing process.
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var params []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, uriEncode(name, false)+"="+uriEncode(value, false))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// uriEncode percent-encodes every byte of s other than unreserved characters.
+// Slashes are preserved when path is true.
+func uriEncode(s string, path bool) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~',
+			c == '/' && path:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
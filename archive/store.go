@@ -0,0 +1,198 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"github.com/karl-police/rbxapi/mmap"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotExist is returned by a Store when a key does not exist.
+var ErrNotExist = errors.New("key does not exist")
+
+// Store is a storage backend of an archive. A Store holds blobs of data
+// addressed by keys, which are slash-separated paths such as
+// "versions/version-1a2b3c4d5e6f7a8b/meta.json".
+//
+// The Dir type implements a Store on the local file system. Other backends
+// can be provided by implementing this interface.
+type Store interface {
+	// Get returns a reader of the content of the given key. Returns
+	// ErrNotExist if the key does not exist. The caller must close the
+	// returned reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Put sets the content of the given key to the data read from r,
+	// replacing any existing content.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Delete removes the given key. Returns ErrNotExist if the key does not
+	// exist.
+	Delete(ctx context.Context, key string) error
+
+	// List returns, in lexical order, every key that begins with the given
+	// prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Mapper is implemented by a Store that is able to map the content of a key
+// directly into memory.
+type Mapper interface {
+	// Map returns a read-only mapping of the content of the given key.
+	// Returns ErrNotExist if the key does not exist. The caller must close
+	// the returned mapping.
+	Map(ctx context.Context, key string) (*mmap.Mapping, error)
+}
+
+// Stater is implemented by a Store that is able to report whether a key
+// exists without reading its content.
+type Stater interface {
+	// Stat returns the size of the content of the given key. Returns
+	// ErrNotExist if the key does not exist.
+	Stat(ctx context.Context, key string) (size int64, err error)
+}
+
+// validKey returns whether key is a well-formed key.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return false
+	}
+	for _, elem := range strings.Split(key, "/") {
+		switch elem {
+		case "", ".", "..":
+			return false
+		}
+	}
+	return true
+}
+
+// Dir is a Store that keeps data as files within a directory on the local
+// file system. Each key corresponds to a file path relative to the
+// directory.
+type Dir string
+
+func (dir Dir) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", errors.New("invalid key \"" + key + "\"")
+	}
+	return filepath.Join(string(dir), filepath.FromSlash(key)), nil
+}
+
+// Get implements the Store interface.
+func (dir Dir) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := dir.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	return f, err
+}
+
+// Stat implements the Stater interface.
+func (dir Dir) Stat(ctx context.Context, key string) (size int64, err error) {
+	path, err := dir.path(key)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return 0, ErrNotExist
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Map implements the Mapper interface.
+func (dir Dir) Map(ctx context.Context, key string) (*mmap.Mapping, error) {
+	path, err := dir.path(key)
+	if err != nil {
+		return nil, err
+	}
+	m, err := mmap.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	return m, err
+}
+
+// Put implements the Store interface. Content is written to a temporary file
+// which replaces the destination only after all data has been written.
+func (dir Dir) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := dir.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete implements the Store interface.
+func (dir Dir) Delete(ctx context.Context, key string) error {
+	path, err := dir.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrNotExist
+	}
+	return err
+}
+
+// List implements the Store interface.
+func (dir Dir) List(ctx context.Context, prefix string) (keys []string, err error) {
+	root := string(dir)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
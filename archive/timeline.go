@@ -0,0 +1,154 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"strings"
+	"time"
+)
+
+// TimelineFormat is the version of the timeline document format.
+const TimelineFormat = 1
+
+// Timeline is a machine-readable summary of the history of an archive.
+type Timeline struct {
+	// Format is the version of the document format.
+	Format int
+	// Generated is the time at which the timeline was produced.
+	Generated time.Time
+	// Versions contains an entry for each version containing a JSON API
+	// dump, in order.
+	Versions []TimelineEntry
+}
+
+// TimelineEntry summarizes a single version of a timeline.
+type TimelineEntry struct {
+	// Version is the version being summarized.
+	Version fetch.Version
+	// Prev is the GUID of the previous version, if any.
+	Prev string `json:",omitempty"`
+	// SHA256 is the hash of the API dump of the version.
+	SHA256 string
+	// Identical is true when the API dump of the version is identical to
+	// that of the previous version.
+	Identical bool
+	// Counts contains the number of descriptors of the version.
+	Counts Counts
+	// Changes contains the number of differences from the previous version.
+	Changes Changes
+	// Diff is a link to the differences from the previous version, if any.
+	Diff string `json:",omitempty"`
+}
+
+// Counts contains the number of descriptors of each kind.
+type Counts struct {
+	Classes   int
+	Members   int
+	Enums     int
+	EnumItems int
+}
+
+// Changes contains the number of actions of each type.
+type Changes struct {
+	Added   int
+	Removed int
+	Changed int
+}
+
+// TimelineOptions configures the production of a timeline.
+type TimelineOptions struct {
+	// DiffURL is a template used to produce the link to the differences
+	// between consecutive versions. The strings "{prev}" and "{next}" are
+	// replaced with the GUIDs of the previous and next versions. If empty,
+	// no links are produced.
+	DiffURL string
+}
+
+// Timeline walks each version of the archive that contains a JSON API dump,
+// producing a summary of the versions and the changes between them.
+func (a *Archive) Timeline(ctx context.Context, opts *TimelineOptions) (*Timeline, error) {
+	if opts == nil {
+		opts = &TimelineOptions{}
+	}
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &Timeline{Format: TimelineFormat, Generated: time.Now().UTC()}
+	var prev *TimelineEntry
+	var prevRoot *rbxapijson.Root
+	for _, meta := range metas {
+		file, ok := meta.Files[fetch.JSONDumpFile]
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entry := TimelineEntry{Version: meta.Version, SHA256: file.SHA256}
+		if prev != nil && file.SHA256 != "" && file.SHA256 == prev.SHA256 {
+			entry.Identical = true
+			entry.Counts = prev.Counts
+		} else {
+			root, err := a.JSONDump(ctx, meta.Version.GUID)
+			if err != nil {
+				return nil, err
+			}
+			entry.Counts = countRoot(root)
+			if prevRoot != nil {
+				entry.Changes = countActions((&rbxapijson.Diff{Prev: prevRoot, Next: root}).Diff())
+			}
+			prevRoot = root
+		}
+		if prev != nil {
+			entry.Prev = prev.Version.GUID
+			if opts.DiffURL != "" {
+				entry.Diff = strings.NewReplacer(
+					"{prev}", entry.Prev,
+					"{next}", entry.Version.GUID,
+				).Replace(opts.DiffURL)
+			}
+		}
+		t.Versions = append(t.Versions, entry)
+		prev = &t.Versions[len(t.Versions)-1]
+	}
+	return t, nil
+}
+
+// Encode writes the timeline to w in JSON format.
+func (t *Timeline) Encode(w io.Writer) error {
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	je.SetEscapeHTML(false)
+	return je.Encode(t)
+}
+
+func countRoot(root *rbxapijson.Root) (c Counts) {
+	c.Classes = len(root.Classes)
+	for _, class := range root.Classes {
+		c.Members += len(class.Members)
+	}
+	c.Enums = len(root.Enums)
+	for _, enum := range root.Enums {
+		c.EnumItems += len(enum.Items)
+	}
+	return c
+}
+
+func countActions(actions []patch.Action) (c Changes) {
+	for _, action := range actions {
+		switch action.GetType() {
+		case patch.Add:
+			c.Added++
+		case patch.Remove:
+			c.Removed++
+		case patch.Change:
+			c.Changed++
+		}
+	}
+	return c
+}
@@ -0,0 +1,423 @@
+// The builder package constructs API structures programmatically.
+//
+// Descriptors are created with constructors such as NewClass and NewProperty,
+// configured with chained method calls, and added to a Builder:
+//
+//	b := builder.New().
+//		AddClass(builder.NewClass("Part").
+//			Superclass("BasePart").
+//			AddProperty(builder.NewProperty("Size", "Vector3").Security("None", "None")).
+//			AddFunction(builder.NewFunction("Resize").Param("NormalId", "normalId").Param("int", "deltaAmount").Returns("bool"))).
+//		AddEnum(builder.NewEnum("NormalId").Item("Right", 0).Item("Top", 1))
+//
+// The result can be emitted as a structure of either the rbxapijson or the
+// rbxapidump package. Types are written as a name, optionally preceded by a
+// category and a colon, as in "Enum:NormalId". Types without a category have
+// their category inferred when emitted, in the same way as
+// rbxapiconv.ToJSON.
+//
+// The builder maintains the invariants of the emitted structures: names of
+// classes, enums, members, and enum items are unique within their container,
+// with a descriptor replacing an existing descriptor of the same name, and
+// descriptors of the rbxapidump package refer to their containing class or
+// enum.
+package builder
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/index"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"strings"
+)
+
+// parseType parses a type of the form "Category:Name" or "Name".
+func parseType(s string) rbxapijson.Type {
+	if i := strings.Index(s, ":"); i >= 0 {
+		return rbxapijson.Type{Category: s[:i], Name: s[i+1:]}
+	}
+	return rbxapijson.Type{Name: s}
+}
+
+// Builder constructs an API structure.
+type Builder struct {
+	root *rbxapijson.Root
+}
+
+// New returns an empty Builder.
+func New() *Builder {
+	return &Builder{root: &rbxapijson.Root{
+		Classes: []*rbxapijson.Class{},
+		Enums:   []*rbxapijson.Enum{},
+	}}
+}
+
+// AddClass adds a class, replacing any existing class of the same name.
+func (b *Builder) AddClass(class *ClassBuilder) *Builder {
+	for i, c := range b.root.Classes {
+		if c.Name == class.class.Name {
+			b.root.Classes[i] = class.class
+			return b
+		}
+	}
+	b.root.Classes = append(b.root.Classes, class.class)
+	return b
+}
+
+// AddEnum adds an enum, replacing any existing enum of the same name.
+func (b *Builder) AddEnum(enum *EnumBuilder) *Builder {
+	for i, e := range b.root.Enums {
+		if e.Name == enum.enum.Name {
+			b.root.Enums[i] = enum.enum
+			return b
+		}
+	}
+	b.root.Enums = append(b.root.Enums, enum.enum)
+	return b
+}
+
+// JSON returns the constructed structure in the form of the rbxapijson
+// package. The result does not share descriptors with the Builder, which may
+// continue to be used.
+func (b *Builder) JSON() *rbxapijson.Root {
+	root := b.root.Copy().(*rbxapijson.Root)
+	idx := index.New(root)
+	infer := func(typ *rbxapijson.Type) {
+		if typ.Category == "" {
+			typ.Category = rbxapiconv.InferCategory(idx, typ.Name)
+		}
+	}
+	inferParams := func(params []rbxapijson.Parameter) {
+		for i := range params {
+			infer(&params[i].Type)
+		}
+	}
+	for _, class := range root.Classes {
+		for _, member := range class.Members {
+			switch member := member.(type) {
+			case *rbxapijson.Property:
+				infer(&member.ValueType)
+			case *rbxapijson.Function:
+				infer(&member.ReturnType)
+				inferParams(member.Parameters)
+			case *rbxapijson.Event:
+				inferParams(member.Parameters)
+			case *rbxapijson.Callback:
+				infer(&member.ReturnType)
+				inferParams(member.Parameters)
+			}
+		}
+	}
+	return root
+}
+
+// Dump returns the constructed structure in the form of the rbxapidump
+// package. Information that cannot be represented by the dump format is
+// dropped, as by rbxapiconv.ToDump.
+func (b *Builder) Dump() *rbxapidump.Root {
+	return rbxapiconv.ToDump(b.JSON())
+}
+
+// ClassBuilder constructs a class descriptor.
+type ClassBuilder struct {
+	class *rbxapijson.Class
+}
+
+// NewClass returns a ClassBuilder of a class with the given name, and no
+// superclass.
+func NewClass(name string) *ClassBuilder {
+	return &ClassBuilder{class: &rbxapijson.Class{
+		Name:       name,
+		Superclass: "<<<ROOT>>>",
+		Members:    []rbxapi.Member{},
+	}}
+}
+
+// Superclass sets the name of the superclass of the class. An empty string
+// indicates that the class has no superclass.
+func (c *ClassBuilder) Superclass(name string) *ClassBuilder {
+	if name == "" {
+		name = "<<<ROOT>>>"
+	}
+	c.class.Superclass = name
+	return c
+}
+
+// MemoryCategory sets the memory category of the class.
+func (c *ClassBuilder) MemoryCategory(category string) *ClassBuilder {
+	c.class.MemoryCategory = category
+	return c
+}
+
+// Tags adds tags to the class.
+func (c *ClassBuilder) Tags(tags ...string) *ClassBuilder {
+	c.class.SetTag(tags...)
+	return c
+}
+
+// addMember adds a member, replacing any existing member of the same name.
+func (c *ClassBuilder) addMember(member rbxapi.Member) *ClassBuilder {
+	for i, m := range c.class.Members {
+		if m.GetName() == member.GetName() {
+			c.class.Members[i] = member
+			return c
+		}
+	}
+	c.class.Members = append(c.class.Members, member)
+	return c
+}
+
+// AddProperty adds a property to the class, replacing any existing member of
+// the same name.
+func (c *ClassBuilder) AddProperty(p *PropertyBuilder) *ClassBuilder {
+	return c.addMember(p.member)
+}
+
+// AddFunction adds a function to the class, replacing any existing member of
+// the same name.
+func (c *ClassBuilder) AddFunction(f *FunctionBuilder) *ClassBuilder {
+	return c.addMember(f.member)
+}
+
+// AddEvent adds an event to the class, replacing any existing member of the
+// same name.
+func (c *ClassBuilder) AddEvent(e *EventBuilder) *ClassBuilder {
+	return c.addMember(e.member)
+}
+
+// AddCallback adds a callback to the class, replacing any existing member of
+// the same name.
+func (c *ClassBuilder) AddCallback(cb *CallbackBuilder) *ClassBuilder {
+	return c.addMember(cb.member)
+}
+
+// PropertyBuilder constructs a property descriptor.
+type PropertyBuilder struct {
+	member *rbxapijson.Property
+}
+
+// NewProperty returns a PropertyBuilder of a property with the given name and
+// value type. The property can be read and written from any security context.
+func NewProperty(name, valueType string) *PropertyBuilder {
+	return &PropertyBuilder{member: &rbxapijson.Property{
+		Name:          name,
+		ValueType:     parseType(valueType),
+		ReadSecurity:  "None",
+		WriteSecurity: "None",
+	}}
+}
+
+// Category sets the category of the property.
+func (p *PropertyBuilder) Category(category string) *PropertyBuilder {
+	p.member.Category = category
+	return p
+}
+
+// Security sets the security contexts required to read and write the
+// property.
+func (p *PropertyBuilder) Security(read, write string) *PropertyBuilder {
+	p.member.ReadSecurity = read
+	p.member.WriteSecurity = write
+	return p
+}
+
+// Serialization sets whether the property can be loaded and saved.
+func (p *PropertyBuilder) Serialization(canLoad, canSave bool) *PropertyBuilder {
+	p.member.CanLoad = canLoad
+	p.member.CanSave = canSave
+	return p
+}
+
+// Default sets the default value of the property.
+func (p *PropertyBuilder) Default(value string) *PropertyBuilder {
+	p.member.HasDefault = true
+	p.member.Default = value
+	return p
+}
+
+// ThreadSafety sets the thread safety of the property.
+func (p *PropertyBuilder) ThreadSafety(safety string) *PropertyBuilder {
+	p.member.ThreadSafety = safety
+	return p
+}
+
+// Tags adds tags to the property.
+func (p *PropertyBuilder) Tags(tags ...string) *PropertyBuilder {
+	p.member.SetTag(tags...)
+	return p
+}
+
+// FunctionBuilder constructs a function descriptor.
+type FunctionBuilder struct {
+	member *rbxapijson.Function
+}
+
+// NewFunction returns a FunctionBuilder of a function with the given name,
+// which has no parameters, returns void, and can be called from any security
+// context.
+func NewFunction(name string) *FunctionBuilder {
+	return &FunctionBuilder{member: &rbxapijson.Function{
+		Name:       name,
+		Parameters: []rbxapijson.Parameter{},
+		ReturnType: rbxapijson.Type{Category: "Primitive", Name: "void"},
+		Security:   "None",
+	}}
+}
+
+// Param adds a parameter with the given type and name.
+func (f *FunctionBuilder) Param(typ, name string) *FunctionBuilder {
+	f.member.Parameters = append(f.member.Parameters, rbxapijson.Parameter{Type: parseType(typ), Name: name})
+	return f
+}
+
+// ParamDefault adds a parameter with the given type, name, and default value.
+func (f *FunctionBuilder) ParamDefault(typ, name, value string) *FunctionBuilder {
+	f.member.Parameters = append(f.member.Parameters, rbxapijson.Parameter{Type: parseType(typ), Name: name, HasDefault: true, Default: value})
+	return f
+}
+
+// Returns sets the return type of the function.
+func (f *FunctionBuilder) Returns(typ string) *FunctionBuilder {
+	f.member.ReturnType = parseType(typ)
+	return f
+}
+
+// Security sets the security context required to call the function.
+func (f *FunctionBuilder) Security(security string) *FunctionBuilder {
+	f.member.Security = security
+	return f
+}
+
+// ThreadSafety sets the thread safety of the function.
+func (f *FunctionBuilder) ThreadSafety(safety string) *FunctionBuilder {
+	f.member.ThreadSafety = safety
+	return f
+}
+
+// Tags adds tags to the function.
+func (f *FunctionBuilder) Tags(tags ...string) *FunctionBuilder {
+	f.member.SetTag(tags...)
+	return f
+}
+
+// EventBuilder constructs an event descriptor.
+type EventBuilder struct {
+	member *rbxapijson.Event
+}
+
+// NewEvent returns an EventBuilder of an event with the given name, which has
+// no parameters, and can be accessed from any security context.
+func NewEvent(name string) *EventBuilder {
+	return &EventBuilder{member: &rbxapijson.Event{
+		Name:       name,
+		Parameters: []rbxapijson.Parameter{},
+		Security:   "None",
+	}}
+}
+
+// Param adds a parameter with the given type and name.
+func (e *EventBuilder) Param(typ, name string) *EventBuilder {
+	e.member.Parameters = append(e.member.Parameters, rbxapijson.Parameter{Type: parseType(typ), Name: name})
+	return e
+}
+
+// Security sets the security context required to access the event.
+func (e *EventBuilder) Security(security string) *EventBuilder {
+	e.member.Security = security
+	return e
+}
+
+// ThreadSafety sets the thread safety of the event.
+func (e *EventBuilder) ThreadSafety(safety string) *EventBuilder {
+	e.member.ThreadSafety = safety
+	return e
+}
+
+// Tags adds tags to the event.
+func (e *EventBuilder) Tags(tags ...string) *EventBuilder {
+	e.member.SetTag(tags...)
+	return e
+}
+
+// CallbackBuilder constructs a callback descriptor.
+type CallbackBuilder struct {
+	member *rbxapijson.Callback
+}
+
+// NewCallback returns a CallbackBuilder of a callback with the given name,
+// which has no parameters, returns void, and can be accessed from any
+// security context.
+func NewCallback(name string) *CallbackBuilder {
+	return &CallbackBuilder{member: &rbxapijson.Callback{
+		Name:       name,
+		Parameters: []rbxapijson.Parameter{},
+		ReturnType: rbxapijson.Type{Category: "Primitive", Name: "void"},
+		Security:   "None",
+	}}
+}
+
+// Param adds a parameter with the given type and name.
+func (cb *CallbackBuilder) Param(typ, name string) *CallbackBuilder {
+	cb.member.Parameters = append(cb.member.Parameters, rbxapijson.Parameter{Type: parseType(typ), Name: name})
+	return cb
+}
+
+// Returns sets the return type of the callback.
+func (cb *CallbackBuilder) Returns(typ string) *CallbackBuilder {
+	cb.member.ReturnType = parseType(typ)
+	return cb
+}
+
+// Security sets the security context required to access the callback.
+func (cb *CallbackBuilder) Security(security string) *CallbackBuilder {
+	cb.member.Security = security
+	return cb
+}
+
+// ThreadSafety sets the thread safety of the callback.
+func (cb *CallbackBuilder) ThreadSafety(safety string) *CallbackBuilder {
+	cb.member.ThreadSafety = safety
+	return cb
+}
+
+// Tags adds tags to the callback.
+func (cb *CallbackBuilder) Tags(tags ...string) *CallbackBuilder {
+	cb.member.SetTag(tags...)
+	return cb
+}
+
+// EnumBuilder constructs an enum descriptor.
+type EnumBuilder struct {
+	enum *rbxapijson.Enum
+}
+
+// NewEnum returns an EnumBuilder of an enum with the given name, and no
+// items.
+func NewEnum(name string) *EnumBuilder {
+	return &EnumBuilder{enum: &rbxapijson.Enum{
+		Name:  name,
+		Items: []*rbxapijson.EnumItem{},
+	}}
+}
+
+// Tags adds tags to the enum.
+func (e *EnumBuilder) Tags(tags ...string) *EnumBuilder {
+	e.enum.SetTag(tags...)
+	return e
+}
+
+// Item adds an item with the given name, value, and tags, replacing any
+// existing item of the same name.
+func (e *EnumBuilder) Item(name string, value int, tags ...string) *EnumBuilder {
+	item := &rbxapijson.EnumItem{Name: name, Value: value}
+	item.SetTag(tags...)
+	for i, it := range e.enum.Items {
+		if it.Name == name {
+			e.enum.Items[i] = item
+			return e
+		}
+	}
+	e.enum.Items = append(e.enum.Items, item)
+	return e
+}
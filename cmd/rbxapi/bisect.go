@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/query"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"github.com/karl-police/rbxapi/render"
+	"os"
+	"strings"
+	"time"
+)
+
+// lookupDescriptor returns the descriptor of the given kind and qualified
+// name, or nil if it is not present.
+func lookupDescriptor(root rbxapi.Root, kind, name string) interface{} {
+	outer, inner := name, ""
+	if i := strings.Index(name, "."); i >= 0 {
+		outer, inner = name[:i], name[i+1:]
+	}
+	switch kind {
+	case query.KindClass:
+		if class := root.GetClass(name); class != nil {
+			return class
+		}
+	case query.KindMember:
+		if class := root.GetClass(outer); class != nil {
+			if member := class.GetMember(inner); member != nil {
+				return member
+			}
+		}
+	case query.KindEnum:
+		if enum := root.GetEnum(name); enum != nil {
+			return enum
+		}
+	case query.KindEnumItem:
+		if enum := root.GetEnum(outer); enum != nil {
+			if item := enum.GetEnumItem(inner); item != nil {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	var dir, class, member, enum, item, selector, field, value string
+	var removed bool
+	register(&command{
+		Name:    "bisect",
+		Summary: "find the first archived build containing a descriptor or change",
+		Description: `
+Bisect searches the versions of an archive for the first build that contains
+a descriptor, given by exactly one of -class, -member, -enum, -item, or
+-query. With -removed, bisect finds the first build in which the descriptor
+is absent instead. With -field and -value, bisect finds the first build in
+which the given field of the descriptor has the given value, such as
+
+	rbxapi bisect -archive ./dumps -member Workspace.Gravity -field ValueType -value double
+
+Bisect decodes only a few dumps by assuming that once a build satisfies the
+condition, every later build does as well.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "archive", "", "archive `directory`")
+			fs.StringVar(&class, "class", "", "`name` of a class")
+			fs.StringVar(&member, "member", "", "`name` of a member, as Class.Member")
+			fs.StringVar(&enum, "enum", "", "`name` of an enum")
+			fs.StringVar(&item, "item", "", "`name` of an enum item, as Enum.Item")
+			fs.StringVar(&selector, "query", "", "`selector` that must match at least one descriptor")
+			fs.BoolVar(&removed, "removed", false, "find the first build without the descriptor")
+			fs.StringVar(&field, "field", "", "`name` of a field of the descriptor")
+			fs.StringVar(&value, "value", "", "`value` of the field")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			if dir == "" {
+				return usageError("-archive is required")
+			}
+			var kind, name string
+			var n int
+			for _, d := range []struct{ kind, name string }{
+				{query.KindClass, class},
+				{query.KindMember, member},
+				{query.KindEnum, enum},
+				{query.KindEnumItem, item},
+				{"query", selector},
+			} {
+				if d.name != "" {
+					kind, name = d.kind, d.name
+					n++
+				}
+			}
+			if n != 1 {
+				return usageError("expected exactly one of -class, -member, -enum, -item, or -query")
+			}
+			if field != "" && (kind == "query" || removed) {
+				return usageError("-field cannot be used with -query or -removed")
+			}
+			var sel *query.Selector
+			if kind == "query" {
+				var err error
+				if sel, err = query.Parse(selector); err != nil {
+					return usageError("selector: " + err.Error())
+				}
+			}
+			var fieldErr error
+			fn := func(root *rbxapijson.Root) bool {
+				if sel != nil {
+					return len(sel.Select(root)) > 0 != removed
+				}
+				desc := lookupDescriptor(root, kind, name)
+				if field == "" {
+					return (desc != nil) != removed
+				}
+				if desc == nil {
+					return false
+				}
+				v, ok := patch.FieldValue(desc, field)
+				if !ok {
+					fieldErr = errors.New("field " + field + " cannot be determined for " + kind + " " + name)
+					return false
+				}
+				return render.Value(v) == value || patch.ValuesEqual(v, rbxapijson.Type{Name: value})
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			first, prev, err := archive.New(archive.Dir(dir)).Bisect(ctx, fn)
+			if fieldErr != nil {
+				return fieldErr
+			}
+			if err == archive.ErrNotFound {
+				return errors.New("no archived build satisfies the condition")
+			}
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				report := struct {
+					First fetch.Version
+					Prev  *fetch.Version
+				}{First: first.Version}
+				if prev != nil {
+					report.Prev = &prev.Version
+				}
+				return writeJSON(os.Stdout, report)
+			}
+			v := first.Version
+			fmt.Printf("%s %s %s\n", v.GUID, v.Number, v.Date.Format(time.RFC3339))
+			if prev == nil {
+				fmt.Println("(first archived build)")
+			} else {
+				fmt.Printf("after %s %s %s\n", prev.Version.GUID, prev.Version.Number, prev.Version.Date.Format(time.RFC3339))
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/render"
+	"io"
+)
+
+// actionInfo describes the subject of an action.
+type actionInfo struct {
+	Kind     string // Class, Member, Enum, or EnumItem.
+	Class    string
+	Member   string
+	Enum     string
+	EnumItem string
+}
+
+// Name returns the qualified name of the subject.
+func (info actionInfo) Name() string {
+	switch info.Kind {
+	case "Member":
+		return info.Class + "." + info.Member
+	case "Enum":
+		return info.Enum
+	case "EnumItem":
+		return info.Enum + "." + info.EnumItem
+	}
+	return info.Class
+}
+
+// Outer returns the name of the class or enum containing the subject.
+func (info actionInfo) Outer() string {
+	if info.Kind == "Enum" || info.Kind == "EnumItem" {
+		return info.Enum
+	}
+	return info.Class
+}
+
+func describeAction(action patch.Action) (info actionInfo) {
+	switch a := action.(type) {
+	case patch.Member:
+		info.Kind = "Member"
+		if c := a.GetClass(); c != nil {
+			info.Class = c.GetName()
+		}
+		if m := a.GetMember(); m != nil {
+			info.Member = m.GetName()
+		}
+	case patch.Class:
+		info.Kind = "Class"
+		if c := a.GetClass(); c != nil {
+			info.Class = c.GetName()
+		}
+	case patch.EnumItem:
+		info.Kind = "EnumItem"
+		if e := a.GetEnum(); e != nil {
+			info.Enum = e.GetName()
+		}
+		if i := a.GetEnumItem(); i != nil {
+			info.EnumItem = i.GetName()
+		}
+	case patch.Enum:
+		info.Kind = "Enum"
+		if e := a.GetEnum(); e != nil {
+			info.Enum = e.GetName()
+		}
+	}
+	return info
+}
+
+// isBreaking returns whether an action may break code that uses the API:
+// removals, and changes to types, parameters, or inheritance.
+func isBreaking(action patch.Action) bool {
+	switch action.GetType() {
+	case patch.Remove:
+		return true
+	case patch.Change:
+		switch action.GetField() {
+		case "Name", "Superclass", "ValueType", "ReturnType", "Parameters", "Value":
+			return true
+		}
+	}
+	return false
+}
+
+// writeChangelog writes actions to w in the given format: text, md, json,
+// html, or patch.
+func writeChangelog(w io.Writer, format string, actions []patch.Action) error {
+	switch format {
+	case "patch":
+		return encodePatch(w, actions)
+	case "text":
+		return render.Text(w, actions)
+	case "md":
+		return render.Markdown(w, actions)
+	case "html":
+		return render.HTML(w, actions)
+	case "json":
+		return writeJSON(w, jsonActions(actions))
+	}
+	return usageError("unknown format \"" + format + "\"")
+}
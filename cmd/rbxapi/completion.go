@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// jsonFlag describes a flag of a command.
+type jsonFlag struct {
+	// Name is the name of the flag, without the leading dash.
+	Name string
+	// Usage describes the flag.
+	Usage string
+	// Default is the default value of the flag.
+	Default string
+	// Bool is whether the flag does not take a value.
+	Bool bool
+}
+
+// jsonCommand describes a command.
+type jsonCommand struct {
+	Name    string
+	Args    string
+	Summary string
+	Flags   []jsonFlag
+}
+
+// commandSpec returns a description of each command, ordered by name.
+func commandSpec() []jsonCommand {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]jsonCommand, len(names))
+	for i, name := range names {
+		cmd := commands[name]
+		list[i] = jsonCommand{Name: cmd.Name, Args: cmd.Args, Summary: cmd.Summary, Flags: []jsonFlag{}}
+		cmd.flagSet().VisitAll(func(f *flag.Flag) {
+			_, usage := flag.UnquoteUsage(f)
+			b, ok := f.Value.(interface{ IsBoolFlag() bool })
+			list[i].Flags = append(list[i].Flags, jsonFlag{
+				Name:    f.Name,
+				Usage:   usage,
+				Default: f.DefValue,
+				Bool:    ok && b.IsBoolFlag(),
+			})
+		})
+	}
+	return list
+}
+
+// completionWords contains the words used by completion scripts.
+type completionWords struct {
+	// Top contains the names that may follow the program name.
+	Top []string
+	// Groups maps the name of each command group to its subcommand names.
+	Groups map[string][]string
+	// Subcommands contains the full names of subcommands, such as
+	// "patch apply".
+	Subcommands []string
+	// Commands contains each command and group, ordered by name.
+	Commands []string
+	// Flags maps each command to its flags, with leading dashes.
+	Flags map[string][]string
+}
+
+func newCompletionWords(spec []jsonCommand) *completionWords {
+	words := &completionWords{
+		Top:    []string{"help"},
+		Groups: map[string][]string{},
+		Flags:  map[string][]string{},
+	}
+	seen := map[string]bool{}
+	for _, cmd := range spec {
+		top := cmd.Name
+		if i := strings.Index(top, " "); i >= 0 {
+			top = cmd.Name[:i]
+			words.Groups[top] = append(words.Groups[top], cmd.Name[i+1:])
+			words.Subcommands = append(words.Subcommands, cmd.Name)
+		}
+		if !seen[top] {
+			seen[top] = true
+			words.Top = append(words.Top, top)
+			words.Commands = append(words.Commands, top)
+		}
+		if top != cmd.Name {
+			words.Commands = append(words.Commands, cmd.Name)
+		}
+		flags := []string{}
+		for _, f := range cmd.Flags {
+			flags = append(flags, "-"+f.Name)
+		}
+		words.Flags[cmd.Name] = flags
+	}
+	sort.Strings(words.Commands)
+	return words
+}
+
+func writeBashCompletion(w io.Writer, spec []jsonCommand) {
+	words := newCompletionWords(spec)
+	fmt.Fprintf(w, "# bash completion for rbxapi\n\n")
+	fmt.Fprintf(w, "_rbxapi() {\n")
+	fmt.Fprintf(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} cmd=${COMP_WORDS[1]} subs=\"\" flags=\"\"\n")
+	fmt.Fprintf(w, "\tif [[ $COMP_CWORD -eq 1 || $cmd == help ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(words.Top, " "))
+	fmt.Fprintf(w, "\t\treturn\n\tfi\n")
+	if len(words.Subcommands) > 0 {
+		fmt.Fprintf(w, "\tif [[ $COMP_CWORD -gt 2 ]]; then\n")
+		fmt.Fprintf(w, "\t\tcase \"$cmd ${COMP_WORDS[2]}\" in\n")
+		fmt.Fprintf(w, "\t\t\"%s\") cmd=\"$cmd ${COMP_WORDS[2]}\" ;;\n", strings.Join(words.Subcommands, "\"|\""))
+		fmt.Fprintf(w, "\t\tesac\n\tfi\n")
+	}
+	fmt.Fprintf(w, "\tcase \"$cmd\" in\n")
+	for _, name := range words.Commands {
+		fmt.Fprintf(w, "\t%q)", name)
+		if subs, ok := words.Groups[name]; ok {
+			fmt.Fprintf(w, " subs=%q;", strings.Join(subs, " "))
+		}
+		fmt.Fprintf(w, " flags=%q ;;\n", strings.Join(words.Flags[name], " "))
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "\tif [[ $COMP_CWORD -eq 2 && -n $subs && $cur != -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"$subs\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "\telif [[ $cur == -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "\telse\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	fmt.Fprintf(w, "\tfi\n}\n\n")
+	fmt.Fprintf(w, "complete -o filenames -F _rbxapi rbxapi\n")
+}
+
+func writeZshCompletion(w io.Writer, spec []jsonCommand) {
+	words := newCompletionWords(spec)
+	fmt.Fprintf(w, "#compdef rbxapi\n\n")
+	fmt.Fprintf(w, "_rbxapi() {\n")
+	fmt.Fprintf(w, "\tlocal cmd=${words[2]}\n")
+	fmt.Fprintf(w, "\tlocal -a subs flags\n")
+	fmt.Fprintf(w, "\tif (( CURRENT == 2 )) || [[ $cmd == help ]]; then\n")
+	fmt.Fprintf(w, "\t\tcompadd -- %s\n", strings.Join(words.Top, " "))
+	fmt.Fprintf(w, "\t\treturn\n\tfi\n")
+	if len(words.Subcommands) > 0 {
+		fmt.Fprintf(w, "\tif (( CURRENT > 3 )); then\n")
+		fmt.Fprintf(w, "\t\tcase \"$cmd ${words[3]}\" in\n")
+		fmt.Fprintf(w, "\t\t\"%s\") cmd=\"$cmd ${words[3]}\" ;;\n", strings.Join(words.Subcommands, "\"|\""))
+		fmt.Fprintf(w, "\t\tesac\n\tfi\n")
+	}
+	fmt.Fprintf(w, "\tcase \"$cmd\" in\n")
+	for _, name := range words.Commands {
+		fmt.Fprintf(w, "\t%q)", name)
+		if subs, ok := words.Groups[name]; ok {
+			fmt.Fprintf(w, " subs=(%s);", strings.Join(subs, " "))
+		}
+		fmt.Fprintf(w, " flags=(%s) ;;\n", strings.Join(words.Flags[name], " "))
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "\tif (( CURRENT == 3 && ${#subs} )) && [[ $PREFIX != -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tcompadd -- $subs\n")
+	fmt.Fprintf(w, "\telif [[ $PREFIX == -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tcompadd -- $flags\n")
+	fmt.Fprintf(w, "\telse\n")
+	fmt.Fprintf(w, "\t\t_files\n")
+	fmt.Fprintf(w, "\tfi\n}\n\n")
+	fmt.Fprintf(w, "if [[ $zsh_eval_context[-1] == loadautofunc ]]; then\n")
+	fmt.Fprintf(w, "\t_rbxapi \"$@\"\nelse\n\tcompdef _rbxapi rbxapi\nfi\n")
+}
+
+// fishQuote quotes s as a single-quoted fish string.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+func writeFishCompletion(w io.Writer, spec []jsonCommand) {
+	words := newCompletionWords(spec)
+	fmt.Fprintf(w, "# fish completion for rbxapi\n\n")
+	fmt.Fprintf(w, "complete -c rbxapi -n __fish_use_subcommand -a help -d %s\n", fishQuote("print the usage of a command"))
+	summaries := map[string]string{}
+	for _, cmd := range spec {
+		summaries[cmd.Name] = cmd.Summary
+	}
+	for _, name := range words.Top[1:] {
+		fmt.Fprintf(w, "complete -c rbxapi -n __fish_use_subcommand -a %s", name)
+		if summary := summaries[name]; summary != "" {
+			fmt.Fprintf(w, " -d %s", fishQuote(summary))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	// condition returns the condition under which the flags of a command
+	// are completed.
+	condition := func(name string) string {
+		if i := strings.Index(name, " "); i >= 0 {
+			return "__fish_seen_subcommand_from " + name[:i] + "; and __fish_seen_subcommand_from " + name[i+1:]
+		}
+		if subs, ok := words.Groups[name]; ok {
+			return "__fish_seen_subcommand_from " + name + "; and not __fish_seen_subcommand_from " + strings.Join(subs, " ")
+		}
+		return "__fish_seen_subcommand_from " + name
+	}
+	for _, name := range words.Commands {
+		if subs, ok := words.Groups[name]; ok {
+			for _, sub := range subs {
+				fmt.Fprintf(w, "complete -c rbxapi -n %s -a %s -d %s\n", fishQuote(condition(name)), sub, fishQuote(summaries[name+" "+sub]))
+			}
+		}
+	}
+	for _, cmd := range spec {
+		for _, f := range cmd.Flags {
+			fmt.Fprintf(w, "complete -c rbxapi -n %s -o %s", fishQuote(condition(cmd.Name)), f.Name)
+			if !f.Bool {
+				fmt.Fprintf(w, " -r")
+			}
+			fmt.Fprintf(w, " -d %s\n", fishQuote(f.Usage))
+		}
+	}
+}
+
+func init() {
+	register(&command{
+		Name:    "completion",
+		Args:    "SHELL",
+		Summary: "generate a shell completion script",
+		Description: `
+Completion writes a script that completes the commands and flags of rbxapi in
+the given shell, which is one of bash, zsh, or fish. For example:
+
+	source <(rbxapi completion bash)
+	rbxapi completion zsh > "${fpath[1]}/_rbxapi"
+	rbxapi completion fish > ~/.config/fish/completions/rbxapi.fish
+
+With -json, the commands and their flags are written as JSON instead, and
+SHELL is not required.`,
+		Run: func(fs *flag.FlagSet, args []string) error {
+			spec := commandSpec()
+			if jsonOutput && len(args) == 0 {
+				return writeJSON(os.Stdout, spec)
+			}
+			if len(args) != 1 {
+				return usageError("expected SHELL")
+			}
+			var write func(w io.Writer, spec []jsonCommand)
+			switch args[0] {
+			case "bash":
+				write = writeBashCompletion
+			case "zsh":
+				write = writeZshCompletion
+			case "fish":
+				write = writeFishCompletion
+			default:
+				return usageError("unknown shell \"" + args[0] + "\"")
+			}
+			bw := bufio.NewWriter(os.Stdout)
+			write(bw, spec)
+			return bw.Flush()
+		},
+	})
+}
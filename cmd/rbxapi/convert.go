@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func init() {
+	var from, to string
+	var sorted, strict, report bool
+	register(&command{
+		Name:    "convert",
+		Args:    "INPUT OUTPUT",
+		Summary: "convert an API dump between formats",
+		Description: `
+Convert decodes the API dump in INPUT and encodes it to OUTPUT. Formats are
+determined from file extensions, or from the content of INPUT, unless given
+explicitly. A path of "-" refers to standard input or output.
+
+Information that cannot be represented by the output format is reported to
+standard error.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of INPUT")
+			fs.StringVar(&to, "to", "", "`format` of OUTPUT")
+			fs.BoolVar(&sorted, "sort", false, "sort descriptors by name")
+			fs.BoolVar(&strict, "strict", false, "fail if any information would be lost")
+			fs.BoolVar(&report, "report", false, "list each piece of lost information")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 2 {
+				return usageError("expected INPUT and OUTPUT")
+			}
+			root, inFormat, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			outFormat, err := outputFormat(args[1], to, inFormat)
+			if err != nil {
+				return err
+			}
+			if sorted {
+				sortRoot(root)
+			}
+			converted, lost := convertRoot(outFormat, root)
+			if len(lost) > 0 && (strict || report) && !jsonOutput {
+				for _, action := range lost {
+					fmt.Fprintln(os.Stderr, action)
+				}
+			}
+			if len(lost) > 0 && strict && jsonOutput {
+				writeReport(args[1], jsonOutputReport{Output: args[1], Format: outFormat.Name, Lost: jsonActions(lost)})
+			}
+			if len(lost) > 0 && strict {
+				return fmt.Errorf("conversion from %s to %s would lose %d pieces of information", inFormat.Name, outFormat.Name, len(lost))
+			}
+			err = writeFile(args[1], func(w io.Writer) error {
+				return outFormat.Codec.Encode(w, converted)
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return writeReport(args[1], jsonOutputReport{Output: args[1], Format: outFormat.Name, Lost: jsonActions(lost)})
+			}
+			if len(lost) > 0 {
+				fmt.Fprintf(os.Stderr, "conversion from %s to %s lost %d pieces of information\n", inFormat.Name, outFormat.Name, len(lost))
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/validate"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// exitBreaking is the exit status of the diff command when breaking changes
+// are detected.
+const exitBreaking = 3
+
+// splitList splits a comma-separated list, discarding empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// actionFilter selects actions.
+type actionFilter struct {
+	Names  string // Comma-separated glob patterns matching qualified names.
+	Kinds  string // Comma-separated descriptor kinds.
+	Types  string // Comma-separated action types.
+	Ignore string // Comma-separated fields to ignore.
+}
+
+func (f *actionFilter) flags(fs *flag.FlagSet) {
+	fs.StringVar(&f.Names, "name", "", "include only descriptors whose qualified name matches one of the comma-separated glob `patterns`")
+	fs.StringVar(&f.Kinds, "kind", "", "include only the comma-separated descriptor `kinds` (Class, Member, Enum, EnumItem)")
+	fs.StringVar(&f.Types, "type", "", "include only the comma-separated action `types` (Add, Remove, Change)")
+	fs.StringVar(&f.Ignore, "ignore", "", "ignore changes to the comma-separated `fields`, such as Tags")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the actions selected by the filter.
+func (f *actionFilter) apply(actions []patch.Action) ([]patch.Action, error) {
+	names := splitList(f.Names)
+	for _, pattern := range names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, usageError("bad pattern \"" + pattern + "\"")
+		}
+	}
+	kinds := splitList(f.Kinds)
+	types := splitList(f.Types)
+	ignore := splitList(f.Ignore)
+	var list []patch.Action
+loop:
+	for _, action := range actions {
+		if action.GetType() == patch.Change && contains(ignore, action.GetField()) {
+			continue
+		}
+		if len(types) > 0 && !contains(types, action.GetType().String()) {
+			continue
+		}
+		info := describeAction(action)
+		if len(kinds) > 0 && !contains(kinds, info.Kind) {
+			continue
+		}
+		if len(names) > 0 {
+			name := info.Name()
+			for _, pattern := range names {
+				if ok, _ := path.Match(pattern, name); ok {
+					list = append(list, action)
+					continue loop
+				}
+			}
+			continue
+		}
+		list = append(list, action)
+	}
+	return list, nil
+}
+
+// readPolicy reads a breaking-change policy from a JSON file.
+func readPolicy(path string) (*validate.Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var policy validate.Policy
+	jd := json.NewDecoder(f)
+	jd.DisallowUnknownFields()
+	if err := jd.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+func init() {
+	var format, from, output, policyFile string
+	var noFail bool
+	var filter actionFilter
+	register(&command{
+		Name:    "diff",
+		Args:    "OLD NEW",
+		Summary: "produce a changelog between two API dumps",
+		Description: `
+Diff compares the API dumps in OLD and NEW, and writes the differences as a
+changelog. The dumps may be of different formats. The patch format produces a
+patch file that can be used with the patch commands.
+
+If any breaking changes are found, such as removals or changes to types,
+diff exits with status 3.
+
+With -policy, breaking changes are instead determined by the policy in the
+given JSON file, and each change that violates the policy is reported with
+the reason. The file has the following structure:
+
+	{
+		"ForbidRemovals": true,
+		"ForbidSecurityTightening": true,
+		"ForbidTypeChanges": true,
+		"AllowTypeChanges": ["Class.Member"]
+	}`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&format, "format", "text", "output `format`: text, md, json, html, or patch")
+			fs.StringVar(&from, "from", "", "`format` of OLD and NEW")
+			fs.StringVar(&output, "o", "-", "write output to `file`")
+			fs.BoolVar(&noFail, "no-fail", false, "exit successfully even when breaking changes are found")
+			fs.StringVar(&policyFile, "policy", "", "determine breaking changes with the policy in `file`")
+			filter.flags(fs)
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 2 {
+				return usageError("expected OLD and NEW")
+			}
+			if jsonOutput {
+				format = "json"
+			}
+			switch format {
+			case "text", "md", "json", "html", "patch":
+			default:
+				return usageError("unknown format \"" + format + "\"")
+			}
+			var policy *validate.Policy
+			if policyFile != "" {
+				p, err := readPolicy(policyFile)
+				if err != nil {
+					return err
+				}
+				policy = p
+			}
+			prev, _, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			next, _, err := decodeFile(args[1], from)
+			if err != nil {
+				return err
+			}
+			actions, err := filter.apply((&diff.Diff{Prev: prev, Next: next}).Diff())
+			if err != nil {
+				return err
+			}
+			err = writeFile(output, func(w io.Writer) error {
+				return writeChangelog(w, format, actions)
+			})
+			if err != nil {
+				return err
+			}
+			var breaking int
+			if policy != nil {
+				violations := policy.Check(actions)
+				for _, d := range violations {
+					fmt.Fprintf(os.Stderr, "rbxapi diff: %s\n", d)
+				}
+				breaking = len(violations)
+			} else {
+				for _, action := range actions {
+					if isBreaking(action) {
+						breaking++
+					}
+				}
+			}
+			if breaking > 0 && !noFail {
+				if !jsonOutput {
+					fmt.Fprintf(os.Stderr, "rbxapi diff: %d breaking changes\n", breaking)
+				}
+				return &exitError{Code: exitBreaking}
+			}
+			return nil
+		},
+	})
+}
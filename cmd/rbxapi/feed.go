@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/gen/feed"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeFeed writes f to w in the given format: atom or rss.
+func writeFeed(w io.Writer, format string, f *feed.Feed) error {
+	switch format {
+	case "atom":
+		return f.WriteAtom(w)
+	case "rss":
+		return f.WriteRSS(w)
+	}
+	return usageError("unknown feed format \"" + format + "\"")
+}
+
+// updateFeed adds an entry to the feed in a file, creating the file if it
+// does not exist. The feed keeps at most limit entries, if limit is greater
+// than zero.
+func updateFeed(path string, entry feed.Entry, limit int) error {
+	f := &feed.Feed{}
+	if r, err := os.Open(path); err == nil {
+		f, err = feed.Read(r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	f.Add(entry, limit)
+	return writeFile(path, func(w io.Writer) error {
+		return writeFeed(w, feedFormat(path), f)
+	})
+}
+
+// feedFormat returns the format of a feed file, determined from its
+// extension.
+func feedFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".rss") {
+		return "rss"
+	}
+	return "atom"
+}
+
+func init() {
+	var dir, output, format string
+	var limit int
+	f := &feed.Feed{}
+	register(&command{
+		Name:    "feed",
+		Summary: "produce an Atom or RSS feed of changes from an archive",
+		Description: `
+Feed compares each version stored in an archive with the previous version,
+and writes the changes as a feed with an entry per version, newest first.
+Versions without a JSON API dump are ignored.
+
+If -link is given, it is the URL of a reference built by the site command,
+and each entry links to the page of its version.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "archive", "", "archive `directory`")
+			fs.StringVar(&output, "o", stdio, "output `file`")
+			fs.StringVar(&format, "format", "", "feed `format`: atom or rss (default determined from -o)")
+			fs.StringVar(&f.Title, "title", feed.DefaultTitle, "`title` of the feed")
+			fs.StringVar(&f.Link, "link", "", "`URL` of the site described by the feed")
+			fs.IntVar(&limit, "limit", 50, "include only the latest `n` versions, if greater than zero")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			if dir == "" {
+				return usageError("-archive is required")
+			}
+			if format == "" {
+				format = feedFormat(output)
+			}
+			if format != "atom" && format != "rss" {
+				return usageError("unknown feed format \"" + format + "\"")
+			}
+			if _, err := os.Stat(dir); err != nil {
+				return err
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			result, err := feed.FromArchive(ctx, archive.New(archive.Dir(dir)), limit)
+			if err != nil {
+				return err
+			}
+			result.Title, result.Link = f.Title, f.Link
+			return writeFile(output, func(w io.Writer) error {
+				return writeFeed(w, format, result)
+			})
+		},
+	})
+}
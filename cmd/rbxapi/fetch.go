@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/fetch"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// clientFlags configures a fetch.Client from flags.
+type clientFlags struct {
+	Channel    string
+	Type       string
+	DeployURL  string
+	VersionURL string
+	Attempts   int
+}
+
+func (f *clientFlags) flags(fs *flag.FlagSet) {
+	fs.StringVar(&f.Channel, "channel", "LIVE", "deployment `channel`")
+	fs.StringVar(&f.Type, "type", string(fetch.DefaultType), "binary `type` of builds")
+	fs.StringVar(&f.DeployURL, "deploy-url", "", "base `URL` from which builds are downloaded")
+	fs.StringVar(&f.VersionURL, "version-url", "", "base `URL` used to query the current version of a channel")
+	fs.IntVar(&f.Attempts, "attempts", fetch.DefaultAttempts, "maximum `number` of attempts per download")
+}
+
+func (f *clientFlags) client() *fetch.Client {
+	return &fetch.Client{
+		DeployURL:  f.DeployURL,
+		VersionURL: f.VersionURL,
+		Type:       fetch.BinaryType(f.Type),
+		Attempts:   f.Attempts,
+	}
+}
+
+// resolveVersion determines the version to fetch from a version string,
+// which may be empty to select the latest version.
+func resolveVersion(ctx context.Context, client *fetch.Client, channel, s string) (fetch.Version, error) {
+	if s == "" {
+		return client.Latest(ctx, channel)
+	}
+	v, err := fetch.ParseVersion(s)
+	if err != nil {
+		return v, usageError("version: " + err.Error())
+	}
+	if v.Channel == "" {
+		v.Channel = channel
+	}
+	if v.GUID != "" {
+		return v, nil
+	}
+	history, err := client.DeployHistory(ctx, v.Channel)
+	if err != nil {
+		return v, err
+	}
+	v.Type = client.Type
+	r, ok := history.Resolve(v)
+	if !ok {
+		return v, errors.New("version " + v.Number.String() + " not found in deployment history")
+	}
+	return r, nil
+}
+
+// parseBound parses the bound of a range of builds, which is either a date
+// or a version GUID present in history.
+func parseBound(history fetch.History, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if v, ok := history.GUID(s); ok {
+		return v.Date, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, fetch.HistoryLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, usageError("expected date or version GUID, got \"" + s + "\"")
+}
+
+// jsonFetched describes a downloaded file.
+type jsonFetched struct {
+	// Version is the build to which the file belongs.
+	Version fetch.Version
+	// File is the name of the file.
+	File string
+	// Output is the path to which the file was written, if not within an
+	// archive.
+	Output string `json:",omitempty"`
+	// Checksum describes the content of the file, if known.
+	Checksum *fetch.Checksum `json:",omitempty"`
+}
+
+// jsonFailed describes a file that failed to download.
+type jsonFailed struct {
+	Version fetch.Version
+	File    string
+	Error   string
+}
+
+// jsonHistoryReport describes the result of fetching a range of builds.
+type jsonHistoryReport struct {
+	// Builds is the number of builds in the range.
+	Builds  int
+	Fetched []jsonFetched
+	Failed  []jsonFailed
+}
+
+func init() {
+	var cf clientFlags
+	var version, file, output string
+	register(&command{
+		Name:    "fetch",
+		Summary: "download a file of a build",
+		Description: `
+Fetch downloads a file of a build from the Roblox deployment servers. The
+version may be a version GUID or a version number, and defaults to the build
+currently deployed to the channel. The version that was fetched is printed to
+standard error.
+
+Interrupted downloads are resumed when fetch is run again with the same
+output file.`,
+		Flags: func(fs *flag.FlagSet) {
+			cf.flags(fs)
+			fs.StringVar(&version, "version", "", "`version` GUID or number of the build")
+			fs.StringVar(&file, "file", fetch.JSONDumpFile, "`name` of the file to download")
+			fs.StringVar(&output, "o", "", "write output to `file` (default: the name of the file)")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			client := cf.client()
+			v, err := resolveVersion(ctx, client, cf.Channel, version)
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				output = file
+			}
+			path := output
+			if output == stdio {
+				dir, err := ioutil.TempDir("", "rbxapi-fetch-")
+				if err != nil {
+					return err
+				}
+				defer os.RemoveAll(dir)
+				path = filepath.Join(dir, file)
+			}
+			sum, err := client.Download(ctx, v, file, path, "")
+			if err != nil {
+				return err
+			}
+			if output == stdio {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				if _, err := io.Copy(os.Stdout, f); err != nil {
+					return err
+				}
+			}
+			if jsonOutput {
+				return writeReport(output, jsonFetched{Version: v, File: file, Output: output, Checksum: &sum})
+			}
+			info := v.String()
+			if !v.Number.IsZero() {
+				info += " (" + v.Number.String() + ")"
+			}
+			fmt.Fprintf(os.Stderr, "fetched %s of %s, %d bytes, sha256 %s\n", file, info, sum.Size, sum.SHA256)
+			return nil
+		},
+	})
+}
+
+func init() {
+	var cf clientFlags
+	var dir, from, to, files string
+	register(&command{
+		Name:    "fetch history",
+		Summary: "download a range of builds into an archive",
+		Description: `
+Fetch history downloads the files of each build in the deployment history of
+a channel into the archive directory. The range of builds may be bounded by
+dates or version GUIDs; the lower bound is inclusive and the upper bound is
+exclusive. Files already present in the archive are skipped.
+
+Builds that fail to download are reported, and fetch history exits with
+status 1 if any failed.`,
+		Flags: func(fs *flag.FlagSet) {
+			cf.flags(fs)
+			fs.StringVar(&dir, "archive", "", "archive `directory`")
+			fs.StringVar(&from, "from", "", "earliest `date` or version of builds")
+			fs.StringVar(&to, "to", "", "fetch builds before `date` or version")
+			fs.StringVar(&files, "files", fetch.JSONDumpFile, "comma-separated `names` of files to download")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			if dir == "" {
+				return usageError("-archive is required")
+			}
+			names := splitList(files)
+			if len(names) == 0 {
+				return usageError("no files to download")
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			client := cf.client()
+			history, err := client.DeployHistory(ctx, cf.Channel)
+			if err != nil {
+				return err
+			}
+			lower, err := parseBound(history, from)
+			if err != nil {
+				return err
+			}
+			upper, err := parseBound(history, to)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(dir, 0777); err != nil {
+				return err
+			}
+			a := archive.New(archive.Dir(dir))
+			builds := history.Type(client.Type).Between(lower, upper)
+			var fetched, failed int
+			report := jsonHistoryReport{Builds: len(builds), Fetched: []jsonFetched{}, Failed: []jsonFailed{}}
+			for _, v := range builds {
+				meta, err := a.Meta(ctx, v.GUID)
+				if err != nil && err != archive.ErrNotExist {
+					return err
+				}
+				for _, name := range names {
+					if meta != nil {
+						if _, ok := meta.Files[name]; ok {
+							continue
+						}
+					}
+					if err := a.Fetch(ctx, client, v, name); err != nil {
+						if ctx.Err() != nil {
+							return ctx.Err()
+						}
+						failed++
+						if jsonOutput {
+							report.Failed = append(report.Failed, jsonFailed{Version: v, File: name, Error: err.Error()})
+						} else {
+							fmt.Fprintf(os.Stderr, "%s %s: %s\n", v, name, err)
+						}
+						continue
+					}
+					fetched++
+					if jsonOutput {
+						report.Fetched = append(report.Fetched, jsonFetched{Version: v, File: name})
+					} else {
+						fmt.Fprintf(os.Stderr, "fetched %s of %s (%s)\n", name, v, v.Number)
+					}
+				}
+			}
+			if _, err := a.Annotate(ctx, history); err != nil {
+				return err
+			}
+			if jsonOutput {
+				if err := writeJSON(os.Stdout, report); err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "%d builds in range, %d files fetched, %d failed\n", len(builds), fetched, failed)
+			}
+			if failed > 0 {
+				return &exitError{Code: 1}
+			}
+			return nil
+		},
+	})
+}
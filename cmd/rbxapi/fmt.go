@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/codec"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// tagSetter is implemented by descriptors with modifiable tags.
+type tagSetter interface {
+	GetTags() []string
+	SetTag(tag ...string)
+	UnsetTag(tag ...string)
+}
+
+// normalizeTags removes empty and duplicate tags from each descriptor of
+// root, preserving the order of the remaining tags.
+func normalizeTags(root rbxapi.Root) {
+	normalize := func(v interface{}) {
+		t, ok := v.(tagSetter)
+		if !ok {
+			return
+		}
+		tags := t.GetTags()
+		list := tags[:0:0]
+		for _, tag := range tags {
+			if tag != "" {
+				list = append(list, tag)
+			}
+		}
+		t.UnsetTag(tags...)
+		t.SetTag(list...)
+	}
+	for _, class := range root.GetClasses() {
+		normalize(class)
+		for _, member := range class.GetMembers() {
+			normalize(member)
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		normalize(enum)
+		for _, item := range enum.GetEnumItems() {
+			normalize(item)
+		}
+	}
+}
+
+// formatDump decodes an API dump from src, and returns its canonical
+// encoding in the same format.
+func formatDump(path string, src []byte, name string) ([]byte, error) {
+	root, format, err := codec.Decode(bytes.NewReader(src), inputFormat(path, name))
+	if err != nil {
+		return nil, err
+	}
+	sortRoot(root)
+	normalizeTags(root)
+	var buf bytes.Buffer
+	if err := format.Codec.Encode(&buf, root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonFormatted describes a file processed by fmt.
+type jsonFormatted struct {
+	// Path is the path of the file.
+	Path string
+	// Canonical is whether the file was already in canonical form.
+	Canonical bool
+}
+
+func init() {
+	var from string
+	var list bool
+	register(&command{
+		Name:    "fmt",
+		Args:    "DUMP...",
+		Summary: "rewrite API dumps in canonical form",
+		Description: `
+Fmt re-encodes each API dump in place in canonical form: descriptors are
+sorted by name, empty and duplicate tags are removed, and the content is
+written with the standard encoding of the dump's format. Files that are
+already canonical are left untouched. A path of "-" reads standard input and
+writes to standard output.
+
+With -l, fmt lists the files that are not canonical instead of rewriting
+them, and exits with status 1 if there are any.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of each DUMP")
+			fs.BoolVar(&list, "l", false, "list files that are not in canonical form")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) == 0 {
+				return usageError("expected at least one DUMP")
+			}
+			var unformatted int
+			report := []jsonFormatted{}
+			primary := ""
+			for _, path := range args {
+				r, err := openInput(path)
+				if err != nil {
+					return err
+				}
+				src, err := ioutil.ReadAll(r)
+				r.Close()
+				if err != nil {
+					return err
+				}
+				res, err := formatDump(path, src, from)
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				if path == stdio && !list {
+					if _, err := os.Stdout.Write(res); err != nil {
+						return err
+					}
+					primary = stdio
+					report = append(report, jsonFormatted{Path: path, Canonical: bytes.Equal(src, res)})
+					continue
+				}
+				report = append(report, jsonFormatted{Path: path, Canonical: bytes.Equal(src, res)})
+				if bytes.Equal(src, res) {
+					continue
+				}
+				unformatted++
+				if list {
+					if !jsonOutput {
+						fmt.Println(path)
+					}
+					continue
+				}
+				err = writeFile(path, func(w io.Writer) error {
+					_, err := w.Write(res)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if jsonOutput {
+				if err := writeReport(primary, report); err != nil {
+					return err
+				}
+			}
+			if list && unformatted > 0 {
+				return &exitError{Code: 1}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi/gen"
+	_ "github.com/karl-police/rbxapi/gen/autocomplete"
+	_ "github.com/karl-police/rbxapi/gen/completion"
+	_ "github.com/karl-police/rbxapi/gen/dts"
+	_ "github.com/karl-police/rbxapi/gen/emmylua"
+	_ "github.com/karl-police/rbxapi/gen/golang"
+	_ "github.com/karl-police/rbxapi/gen/graphql"
+	_ "github.com/karl-police/rbxapi/gen/hierarchy"
+	_ "github.com/karl-police/rbxapi/gen/lint"
+	_ "github.com/karl-police/rbxapi/gen/luatable"
+	_ "github.com/karl-police/rbxapi/gen/luau"
+	_ "github.com/karl-police/rbxapi/gen/markdown"
+	_ "github.com/karl-police/rbxapi/gen/rbxdom"
+	_ "github.com/karl-police/rbxapi/gen/schema"
+	_ "github.com/karl-police/rbxapi/gen/sqlite"
+	"io"
+	"os"
+	"strings"
+)
+
+// recordOutput records the names of the files created in an output.
+type recordOutput struct {
+	gen.Output
+	files []string
+}
+
+// Create implements the gen.Output interface.
+func (o *recordOutput) Create(name string) (io.WriteCloser, error) {
+	w, err := o.Output.Create(name)
+	if err == nil {
+		o.files = append(o.files, name)
+	}
+	return w, err
+}
+
+// jsonGenerateReport describes the files produced by a generator.
+type jsonGenerateReport struct {
+	// Target is the name of the target.
+	Target string
+	// Output is the output directory.
+	Output string
+	// Files contains the slash-separated names of the produced files,
+	// relative to Output.
+	Files []string
+}
+
+func init() {
+	var target, from, output string
+	var list bool
+	generators := map[string]gen.Generator{}
+	register(&command{
+		Name:    "generate",
+		Args:    "DUMP",
+		Summary: "generate code or documentation from an API dump",
+		Description: `
+Generate runs the generator of the given target over the API dump in DUMP,
+writing the produced files to the output directory.
+
+Options of a target are given as flags prefixed with the name of the target,
+such as -dts.strip-deprecated. Run "rbxapi generate -list" for a list of
+targets.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&target, "target", "", "`name` of the target to generate")
+			fs.StringVar(&from, "from", "", "`format` of DUMP")
+			fs.StringVar(&output, "o", ".", "output `directory`")
+			fs.BoolVar(&list, "list", false, "list the available targets")
+			for _, t := range gen.Targets() {
+				g := t.New()
+				generators[t.Name] = g
+				f, ok := g.(gen.Flagger)
+				if !ok {
+					continue
+				}
+				tfs := flag.NewFlagSet(t.Name, flag.ContinueOnError)
+				f.Flags(tfs)
+				tfs.VisitAll(func(f *flag.Flag) {
+					fs.Var(f.Value, t.Name+"."+f.Name, f.Usage)
+				})
+			}
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if list && jsonOutput {
+				type jsonTarget struct{ Name, Summary string }
+				targets := []jsonTarget{}
+				for _, t := range gen.Targets() {
+					targets = append(targets, jsonTarget{t.Name, t.Summary})
+				}
+				return writeJSON(os.Stdout, targets)
+			}
+			if list {
+				for _, t := range gen.Targets() {
+					fmt.Printf("%-12s %s\n", t.Name, t.Summary)
+				}
+				return nil
+			}
+			if len(args) != 1 {
+				return usageError("expected DUMP")
+			}
+			g, ok := generators[target]
+			if !ok {
+				if target == "" {
+					return usageError("-target is required")
+				}
+				names := make([]string, 0, len(generators))
+				for _, t := range gen.Targets() {
+					names = append(names, t.Name)
+				}
+				return usageError("unknown target \"" + target + "\"; expected one of " + strings.Join(names, ", "))
+			}
+			var other string
+			fs.Visit(func(f *flag.Flag) {
+				if i := strings.Index(f.Name, "."); i >= 0 && f.Name[:i] != target {
+					other = f.Name
+				}
+			})
+			if other != "" {
+				return usageError("flag -" + other + " does not apply to target " + target)
+			}
+			root, _, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(output, 0777); err != nil {
+				return err
+			}
+			out := &recordOutput{Output: gen.Dir(output), files: []string{}}
+			if err := g.Generate(root, out); err != nil {
+				return err
+			}
+			if jsonOutput {
+				return writeJSON(os.Stdout, jsonGenerateReport{Target: target, Output: output, Files: out.files})
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// typeSignature returns the type of a signature.
+func typeSignature(typ rbxapi.Type) string {
+	return typ.GetName()
+}
+
+// paramsSignature returns the parameter list of a signature.
+func paramsSignature(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	ss := make([]string, len(list))
+	for i, param := range list {
+		ss[i] = param.GetName() + ": " + typeSignature(param.GetType())
+		if def, ok := param.GetDefault(); ok {
+			ss[i] += " = " + def
+		}
+	}
+	return "(" + strings.Join(ss, ", ") + ")"
+}
+
+// memberSignature returns the signature of a member, qualified by its
+// declaring class.
+func memberSignature(class rbxapi.Class, member rbxapi.Member) string {
+	name := class.GetName() + "." + member.GetName()
+	switch m := member.(type) {
+	case rbxapi.Property:
+		return name + ": " + typeSignature(m.GetValueType())
+	case rbxapi.Function:
+		if member.GetMemberType() == "Function" {
+			name = class.GetName() + ":" + member.GetName()
+		}
+		return name + paramsSignature(m.GetParameters()) + ": " + typeSignature(m.GetReturnType())
+	case rbxapi.Event:
+		return name + paramsSignature(m.GetParameters())
+	}
+	return name
+}
+
+// grepFilter selects the descriptors printed by grep.
+type grepFilter struct {
+	Pattern *regexp.Regexp
+	// Kinds contains the selected kinds, or is empty to select every kind.
+	Kinds []string
+	// Tags contains tags that must be present.
+	Tags []string
+	// Security compares the security context of a member, returning whether
+	// the member is selected. It is nil if the filter has no security
+	// condition.
+	Security func(security rbxapi.Security) bool
+}
+
+// kind returns whether a descriptor of the given kind and member type is
+// selected.
+func (f *grepFilter) kind(kind, memberType string) bool {
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	return contains(f.Kinds, kind) || memberType != "" && contains(f.Kinds, memberType)
+}
+
+// match returns whether a descriptor of the given kind and name is selected.
+func (f *grepFilter) match(kind, memberType, name string, t rbxapi.Taggable) bool {
+	if !f.kind(kind, memberType) || !f.Pattern.MatchString(name) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !t.GetTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// grepMatch is a descriptor found by grep.
+type grepMatch struct {
+	Kind       string
+	Class      string `json:",omitempty"`
+	Enum       string `json:",omitempty"`
+	Name       string
+	MemberType string `json:",omitempty"`
+	Security   string `json:",omitempty"`
+	Signature  string
+	Tags       []string
+}
+
+// grepRoot returns the descriptors of root selected by f.
+func grepRoot(root rbxapi.Root, f *grepFilter) []grepMatch {
+	matches := []grepMatch{}
+	tags := func(t rbxapi.Taggable) []string {
+		if tags := t.GetTags(); len(tags) > 0 {
+			return tags
+		}
+		return []string{}
+	}
+	for _, class := range root.GetClasses() {
+		if f.Security == nil && f.match("Class", "", class.GetName(), class) {
+			sig := class.GetName()
+			if super := class.GetSuperclass(); root.GetClass(super) != nil {
+				sig += " : " + super
+			}
+			matches = append(matches, grepMatch{Kind: "Class", Name: class.GetName(), Signature: sig, Tags: tags(class)})
+		}
+		for _, member := range class.GetMembers() {
+			if !f.match("Member", member.GetMemberType(), member.GetName(), member) {
+				continue
+			}
+			security := rbxapi.MemberSecurity(member)
+			if f.Security != nil && !f.Security(rbxapi.ParseSecurity(security)) {
+				continue
+			}
+			matches = append(matches, grepMatch{
+				Kind:       "Member",
+				Class:      class.GetName(),
+				Name:       member.GetName(),
+				MemberType: member.GetMemberType(),
+				Security:   security,
+				Signature:  memberSignature(class, member),
+				Tags:       tags(member),
+			})
+		}
+	}
+	if f.Security != nil {
+		return matches
+	}
+	for _, enum := range root.GetEnums() {
+		if f.match("Enum", "", enum.GetName(), enum) {
+			matches = append(matches, grepMatch{Kind: "Enum", Name: enum.GetName(), Signature: enum.GetName(), Tags: tags(enum)})
+		}
+		for _, item := range enum.GetEnumItems() {
+			if f.match("EnumItem", "", item.GetName(), item) {
+				matches = append(matches, grepMatch{
+					Kind:      "EnumItem",
+					Enum:      enum.GetName(),
+					Name:      item.GetName(),
+					Signature: enum.GetName() + "." + item.GetName() + " = " + strconv.Itoa(item.GetValue()),
+					Tags:      tags(item),
+				})
+			}
+		}
+	}
+	return matches
+}
+
+func init() {
+	var from, kinds, tags, security, securityMax, securityMin string
+	var ignoreCase, count bool
+	register(&command{
+		Name:    "grep",
+		Args:    "DUMP PATTERN",
+		Summary: "search the descriptors of an API dump by name",
+		Description: `
+Grep prints each descriptor in DUMP whose name matches the regular expression
+PATTERN, along with its declaring class and signature. For example:
+
+	rbxapi grep API-Dump.json 'Async$' -kind=Function '-security<=PluginSecurity'
+
+The -kind flag selects descriptors by kind (Class, Member, Enum, EnumItem) or
+by member type (Property, Function, Event, Callback). The security flags
+compare the security context of members, ordered from None to
+NotAccessibleSecurity; when one is given, only members are printed. The "<"
+must be quoted from most shells.
+
+Grep exits with status 1 if nothing matches.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of DUMP")
+			fs.StringVar(&kinds, "kind", "", "include only the comma-separated descriptor `kinds` or member types")
+			fs.StringVar(&tags, "tag", "", "include only descriptors with each of the comma-separated `tags`")
+			fs.StringVar(&security, "security", "", "include only members of the given security `context`")
+			fs.StringVar(&securityMax, "security<", "", "include only members of at most the given security `context`, as -security<=context")
+			fs.StringVar(&securityMin, "security>", "", "include only members of at least the given security `context`, as -security>=context")
+			fs.BoolVar(&ignoreCase, "i", false, "match PATTERN case-insensitively")
+			fs.BoolVar(&count, "count", false, "print only the number of matches")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 2 {
+				return usageError("expected DUMP and PATTERN")
+			}
+			pattern := args[1]
+			if ignoreCase {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return usageError("pattern: " + err.Error())
+			}
+			filter := grepFilter{Pattern: re, Kinds: splitList(kinds), Tags: splitList(tags)}
+			var n int
+			for _, cond := range []struct {
+				name, context string
+				cmp           func(c int) bool
+			}{
+				{"-security", security, func(c int) bool { return c == 0 }},
+				{"-security<=", securityMax, func(c int) bool { return c <= 0 }},
+				{"-security>=", securityMin, func(c int) bool { return c >= 0 }},
+			} {
+				if cond.context == "" {
+					continue
+				}
+				ref := rbxapi.ParseSecurity(cond.context)
+				if !ref.Known() {
+					return usageError(cond.name + ": unknown security context \"" + cond.context + "\"")
+				}
+				cmp := cond.cmp
+				filter.Security = func(s rbxapi.Security) bool { return s.Known() && cmp(s.Compare(ref)) }
+				n++
+			}
+			if n > 1 {
+				return usageError("expected at most one security condition")
+			}
+			root, _, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			matches := grepRoot(root, &filter)
+			switch {
+			case count && jsonOutput:
+				if err := writeJSON(os.Stdout, struct{ Count int }{len(matches)}); err != nil {
+					return err
+				}
+			case count:
+				fmt.Println(len(matches))
+			case jsonOutput:
+				if err := writeJSON(os.Stdout, matches); err != nil {
+					return err
+				}
+			default:
+				for _, m := range matches {
+					kind := m.Kind
+					if m.MemberType != "" {
+						kind = m.MemberType
+					}
+					fmt.Printf("%-8s %s", kind, m.Signature)
+					if m.Security != "" && m.Security != "None" {
+						fmt.Printf(" [%s]", m.Security)
+					}
+					fmt.Println()
+				}
+			}
+			if len(matches) == 0 {
+				return &exitError{Code: 1}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/codec"
+	"github.com/karl-police/rbxapi/patch"
+	_ "github.com/karl-police/rbxapi/rbxapicache"
+	_ "github.com/karl-police/rbxapi/rbxapicsv"
+	_ "github.com/karl-police/rbxapi/rbxapimsgpack"
+	_ "github.com/karl-police/rbxapi/rbxapipb"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// stdio is the path that refers to standard input or output.
+const stdio = "-"
+
+// openInput opens a file for reading. The path "-" refers to standard input.
+func openInput(path string) (io.ReadCloser, error) {
+	if path == stdio {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// output is a file being written. Content is written to a temporary file,
+// which replaces the destination when committed.
+type output struct {
+	io.Writer
+	path string
+	file *os.File
+}
+
+// createOutput creates a file for writing. The path "-" refers to standard
+// output.
+func createOutput(path string) (*output, error) {
+	if path == stdio {
+		return &output{Writer: os.Stdout}, nil
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return nil, err
+	}
+	return &output{Writer: f, path: path, file: f}, nil
+}
+
+// Commit finishes writing the file.
+func (o *output) Commit() error {
+	if o.file == nil {
+		return nil
+	}
+	f := o.file
+	o.file = nil
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), o.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort discards the file if it has not been committed.
+func (o *output) Abort() {
+	if o.file == nil {
+		return
+	}
+	o.file.Close()
+	os.Remove(o.file.Name())
+	o.file = nil
+}
+
+// writeFile writes a file by calling fn with a writer, replacing the file
+// only if fn succeeds. The content is compressed if the extension of path
+// indicates a compression, such as ".gz".
+func writeFile(path string, fn func(w io.Writer) error) error {
+	o, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	defer o.Abort()
+	var compression string
+	if comp, ok := codec.CompressionForPath(path); ok {
+		compression = comp.Name
+	}
+	w, err := codec.Compress(o, compression)
+	if err != nil {
+		return err
+	}
+	if err := fn(w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return o.Commit()
+}
+
+// inputFormat determines the format of an input file. If name is empty, the
+// format is determined from the extension of path, and is otherwise left to
+// be detected from the content.
+func inputFormat(path, name string) string {
+	if name != "" {
+		return name
+	}
+	if format, ok := codec.ForPath(path); ok {
+		return format.Name
+	}
+	return ""
+}
+
+// outputFormat determines the format of an output file. If name is empty,
+// the format is determined from the extension of path, falling back to def.
+func outputFormat(path, name string, def codec.Format) (codec.Format, error) {
+	if name != "" {
+		format, ok := codec.Lookup(name)
+		if !ok {
+			return codec.Format{}, errors.New("unknown format \"" + name + "\"")
+		}
+		return format, nil
+	}
+	if format, ok := codec.ForPath(path); ok {
+		return format, nil
+	}
+	if def.Codec == nil {
+		return codec.Format{}, errors.New("cannot determine format of " + path)
+	}
+	return def, nil
+}
+
+// decodeFile decodes an API structure from a file. If format is empty, it is
+// determined from the file.
+func decodeFile(path, format string) (rbxapi.Root, codec.Format, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, codec.Format{}, err
+	}
+	defer r.Close()
+	root, f, err := codec.Decode(r, inputFormat(path, format))
+	if err != nil {
+		return nil, f, errors.New(path + ": " + err.Error())
+	}
+	return root, f, nil
+}
+
+// convertRoot converts root to the given format, returning the information
+// lost in conversion.
+func convertRoot(format codec.Format, root rbxapi.Root) (rbxapi.Root, []patch.Action) {
+	return codec.Convert(format.Codec, root)
+}
+
+// encodeFile converts root to the given format and writes it to a file.
+// Returns the information lost in conversion.
+func encodeFile(path string, format codec.Format, root rbxapi.Root) ([]patch.Action, error) {
+	root, lost := convertRoot(format, root)
+	err := writeFile(path, func(w io.Writer) error {
+		return format.Codec.Encode(w, root)
+	})
+	return lost, err
+}
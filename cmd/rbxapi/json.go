@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/render"
+	"io"
+	"os"
+)
+
+// jsonOutput is set by the -json flag, which is accepted by every command.
+// When set, commands write their results as JSON, and errors are written to
+// standard error as a jsonError.
+//
+// Commands that already produce a file write a report describing the result
+// instead. The report is written to standard output, unless the primary
+// output of the command is standard output, in which case it is written to
+// standard error.
+var jsonOutput bool
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v interface{}) error {
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	je.SetEscapeHTML(false)
+	return je.Encode(v)
+}
+
+// writeReport writes the JSON report of a command whose primary output is
+// written to output.
+func writeReport(output string, v interface{}) error {
+	if output == stdio {
+		return writeJSON(os.Stderr, v)
+	}
+	return writeJSON(os.Stdout, v)
+}
+
+// jsonError describes a failed command.
+type jsonError struct {
+	// Error is the message of the error, prefixed with the command name.
+	Error string
+	// Code is the exit status of the program.
+	Code int
+}
+
+// jsonAction describes an action.
+type jsonAction struct {
+	Type     string
+	Kind     string
+	Class    string `json:",omitempty"`
+	Member   string `json:",omitempty"`
+	Enum     string `json:",omitempty"`
+	EnumItem string `json:",omitempty"`
+	Field    string `json:",omitempty"`
+	Prev     string `json:",omitempty"`
+	Next     string `json:",omitempty"`
+	Breaking bool
+}
+
+// jsonActions returns a description of each action. The result is not nil.
+func jsonActions(actions []patch.Action) []jsonAction {
+	list := make([]jsonAction, len(actions))
+	for i, action := range actions {
+		info := describeAction(action)
+		list[i] = jsonAction{
+			Type:     action.GetType().String(),
+			Kind:     info.Kind,
+			Class:    info.Class,
+			Member:   info.Member,
+			Enum:     info.Enum,
+			EnumItem: info.EnumItem,
+			Field:    action.GetField(),
+			Prev:     render.Value(action.GetPrev()),
+			Next:     render.Value(action.GetNext()),
+			Breaking: isBreaking(action),
+		}
+	}
+	return list
+}
+
+// jsonConflict describes an action of a patch that does not apply cleanly.
+type jsonConflict struct {
+	Index  int
+	Action jsonAction
+	Reason string
+}
+
+// jsonConflicts returns a description of each conflict. The result is not
+// nil.
+func jsonConflicts(conflicts []patch.Result) []jsonConflict {
+	list := make([]jsonConflict, len(conflicts))
+	for i, c := range conflicts {
+		list[i] = jsonConflict{
+			Index:  c.Index,
+			Action: jsonActions([]patch.Action{c.Action})[0],
+			Reason: c.Reason,
+		}
+	}
+	return list
+}
+
+// jsonOutputReport describes a dump written by a command.
+type jsonOutputReport struct {
+	// Output is the path of the written dump.
+	Output string
+	// Format is the name of the format of the written dump.
+	Format string
+	// Lost describes the information lost in conversion to Format.
+	Lost []jsonAction
+}
+
+// jsonApplyReport describes the result of applying a patch.
+type jsonApplyReport struct {
+	jsonOutputReport
+	// Conflicts contains each action that did not apply cleanly.
+	Conflicts []jsonConflict
+}
+
+// jsonPatchReport describes a written patch file.
+type jsonPatchReport struct {
+	// Output is the path of the patch file.
+	Output string
+	// Actions is the number of actions in the patch.
+	Actions int
+}
@@ -0,0 +1,248 @@
+// The rbxapi command provides tools for working with Roblox API dumps.
+//
+// Usage:
+//
+//	rbxapi <command> [arguments]
+//
+// Run "rbxapi help" for a list of commands, and "rbxapi help <command>" for
+// the usage of a particular command.
+//
+// Every command accepts the -json flag, which causes results and errors to be
+// written as JSON, so that the output can be consumed by other programs. Run
+// "rbxapi completion -json" for a description of each command and its flags,
+// and "rbxapi completion <shell>" for a shell completion script.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+)
+
+// command describes a subcommand.
+type command struct {
+	// Name is the name of the command, as typed by the user.
+	Name string
+	// Args describes the positional arguments of the command.
+	Args string
+	// Summary is a one-line description of the command.
+	Summary string
+	// Description is a longer description of the command.
+	Description string
+	// Flags configures the flags of the command. It may be nil.
+	Flags func(fs *flag.FlagSet)
+	// Run executes the command with the remaining positional arguments.
+	Run func(fs *flag.FlagSet, args []string) error
+}
+
+var commands = map[string]*command{}
+
+// register adds a command. Each command registers itself from an init
+// function in its own file. The name of a subcommand is the name of its group
+// and the name of the subcommand separated by a space, such as "patch apply".
+func register(cmd *command) {
+	if _, ok := commands[cmd.Name]; ok {
+		panic("command " + cmd.Name + " registered twice")
+	}
+	commands[cmd.Name] = cmd
+}
+
+// usageError indicates that a command was invoked incorrectly.
+type usageError string
+
+func (err usageError) Error() string { return string(err) }
+
+// exitError causes the program to exit with the given status, after
+// printing the error if it is not nil.
+type exitError struct {
+	Code int
+	Err  error
+}
+
+func (err *exitError) Error() string {
+	if err.Err == nil {
+		return "exit status " + fmt.Sprint(err.Code)
+	}
+	return err.Err.Error()
+}
+
+func (cmd *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	fs.Bool("json", false, "write machine-readable JSON output")
+	return fs
+}
+
+func (cmd *command) usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: rbxapi %s [flags] %s\n", cmd.Name, cmd.Args)
+	if cmd.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(cmd.Description))
+	}
+	fs := cmd.flagSet()
+	var hasFlags bool
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintf(w, "\nFlags:\n")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+// parseArgs parses flags from args, permitting flags to be interleaved with
+// positional arguments. Arguments following "--" are always positional.
+func parseArgs(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	return positional, nil
+}
+
+// interruptContext returns a context that is canceled when the program
+// receives an interrupt signal.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: rbxapi <command> [arguments]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	width := 10
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%-*s %s\n", width, name, commands[name].Summary)
+	}
+	fmt.Fprintf(w, "\nRun \"rbxapi help <command>\" for more information about a command.\n")
+}
+
+// lookup returns the command named by the leading arguments, and the
+// remaining arguments.
+func lookup(args []string) (cmd *command, rest []string, err error) {
+	name, args := args[0], args[1:]
+	if len(args) > 0 {
+		if cmd, ok := commands[name+" "+args[0]]; ok {
+			return cmd, args[1:], nil
+		}
+	}
+	if cmd, ok := commands[name]; ok {
+		return cmd, args, nil
+	}
+	var subs []string
+	for sub := range commands {
+		if strings.HasPrefix(sub, name+" ") {
+			subs = append(subs, strings.TrimPrefix(sub, name+" "))
+		}
+	}
+	if len(subs) > 0 {
+		sort.Strings(subs)
+		return nil, nil, usageError(name + ": expected one of " + strings.Join(subs, ", "))
+	}
+	return nil, nil, usageError("unknown command \"" + name + "\"")
+}
+
+// usageFailure reports an invocation error of the command, and returns an
+// error that exits with status 2.
+func (cmd *command) usageFailure(err error) error {
+	if jsonOutput {
+		return &exitError{Code: 2, Err: fmt.Errorf("%s: %w", cmd.Name, err)}
+	}
+	fmt.Fprintf(os.Stderr, "rbxapi %s: %s\n", cmd.Name, err)
+	cmd.usage(os.Stderr)
+	return &exitError{Code: 2}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return &exitError{Code: 2}
+	}
+	name, args := args[0], args[1:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) == 0 {
+			usage(os.Stdout)
+			return nil
+		}
+		cmd, _, err := lookup(args)
+		if err != nil {
+			return err
+		}
+		cmd.usage(os.Stdout)
+		return nil
+	}
+	cmd, args, err := lookup(append([]string{name}, args...))
+	if err != nil {
+		return err
+	}
+	fs := cmd.flagSet()
+	positional, err := parseArgs(fs, args)
+	jsonOutput = fs.Lookup("json").Value.(flag.Getter).Get().(bool)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			cmd.usage(os.Stdout)
+			return nil
+		}
+		return cmd.usageFailure(err)
+	}
+	if err := cmd.Run(fs, positional); err != nil {
+		if err, ok := err.(usageError); ok {
+			return cmd.usageFailure(err)
+		}
+		if err, ok := err.(*exitError); ok && err.Err == nil {
+			return err
+		}
+		return &exitError{Code: 1, Err: fmt.Errorf("%s: %w", cmd.Name, err)}
+	}
+	return nil
+}
+
+func main() {
+	err := run(os.Args[1:])
+	switch e := err.(type) {
+	case nil:
+		return
+	case usageError:
+		fmt.Fprintf(os.Stderr, "rbxapi: %s\n", e)
+		usage(os.Stderr)
+		os.Exit(2)
+	case *exitError:
+		if e.Err != nil {
+			if jsonOutput {
+				writeJSON(os.Stderr, jsonError{Error: e.Err.Error(), Code: e.Code})
+			} else {
+				fmt.Fprintf(os.Stderr, "rbxapi %s\n", e.Err)
+			}
+		}
+		os.Exit(e.Code)
+	default:
+		fmt.Fprintf(os.Stderr, "rbxapi: %s\n", err)
+		os.Exit(1)
+	}
+}
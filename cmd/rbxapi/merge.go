@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi/merge"
+	"github.com/karl-police/rbxapi/patch"
+	"os"
+)
+
+// jsonMergeConflict describes a conflict between a base and an overlay.
+type jsonMergeConflict struct {
+	// Overlay is the path of the overlay.
+	Overlay string
+	// Action changes the field from the base value to the overlay value.
+	Action jsonAction
+}
+
+// jsonMergeReport describes the result of a merge.
+type jsonMergeReport struct {
+	jsonOutputReport
+	// Policy is the policy used to resolve conflicts.
+	Policy string
+	// Conflicts contains each conflict, in the order of the overlays.
+	Conflicts []jsonMergeConflict
+}
+
+func init() {
+	var from, to, output, onConflict string
+	var quiet bool
+	register(&command{
+		Name:    "merge",
+		Args:    "BASE OVERLAY...",
+		Summary: "merge overlays into an API dump",
+		Description: `
+Merge combines the API dump in BASE with each OVERLAY in order. Descriptors
+present only in an overlay are added, and the tags of descriptors present in
+both are combined. Other fields with different values are conflicts, which
+are reported to standard error and resolved according to -on-conflict:
+
+	prefer-overlay  use the value of the overlay
+	prefer-base     keep the value of the base
+	fail            fail without writing the output
+
+The output has the format of BASE unless specified otherwise.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of the inputs")
+			fs.StringVar(&to, "to", "", "`format` of the output")
+			fs.StringVar(&output, "o", "-", "write output to `file`")
+			fs.StringVar(&onConflict, "on-conflict", "prefer-overlay", "conflict `policy`: prefer-overlay, prefer-base, or fail")
+			fs.BoolVar(&quiet, "q", false, "do not report conflicts")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) < 2 {
+				return usageError("expected BASE and at least one OVERLAY")
+			}
+			policy, ok := merge.ParsePolicy(onConflict)
+			if !ok {
+				return usageError("unknown conflict policy \"" + onConflict + "\"")
+			}
+			root, inFormat, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			outFormat, err := outputFormat(output, to, inFormat)
+			if err != nil {
+				return err
+			}
+			var total int
+			report := jsonMergeReport{Policy: policy.String(), Conflicts: []jsonMergeConflict{}}
+			for _, path := range args[1:] {
+				overlay, _, err := decodeFile(path, from)
+				if err != nil {
+					return err
+				}
+				merged, conflicts, err := merge.Overlay(root, overlay, policy)
+				for _, c := range conflicts {
+					report.Conflicts = append(report.Conflicts, jsonMergeConflict{Overlay: path, Action: jsonActions([]patch.Action{c.Action})[0]})
+				}
+				if jsonOutput {
+					if err != nil {
+						writeReport(output, report)
+					}
+				} else if !quiet || err != nil {
+					for _, c := range conflicts {
+						fmt.Fprintf(os.Stderr, "%s: conflict: %s\n", path, c)
+					}
+				}
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				total += len(conflicts)
+				root = merged
+			}
+			lost, err := encodeFile(output, outFormat, root)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				report.Output = output
+				report.Format = outFormat.Name
+				report.Lost = jsonActions(lost)
+				return writeReport(output, report)
+			}
+			if len(lost) > 0 {
+				fmt.Fprintf(os.Stderr, "conversion from %s to %s lost %d pieces of information\n", inFormat.Name, outFormat.Name, len(lost))
+			}
+			if total > 0 && !quiet {
+				fmt.Fprintf(os.Stderr, "resolved %d conflicts with %s\n", total, policy)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/patchfile"
+	"io"
+	"os"
+)
+
+// applyPatch applies actions one at a time to a copy of root, returning each
+// action that does not apply cleanly. root must implement patch.Patcher.
+func applyPatch(root rbxapi.Root, actions []patch.Action) (rbxapi.Root, []patch.Result, error) {
+	root, report, err := patch.Apply(root, actions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return root, report.Conflicts(), nil
+}
+
+// renamed returns a copy of an action whose descriptor is renamed to name.
+func renamed(action patch.Action, name string) patch.Action {
+	class, enum := patchfile.Descriptors(action)
+	switch a := action.(type) {
+	case patch.Member:
+		if class == nil {
+			break
+		}
+		class.Members[0].(patch.Patcher).Patch([]patch.Action{&diff.MemberAction{Type: patch.Change, Field: "Name", Next: name}})
+		return &diff.MemberAction{Type: a.GetType(), Class: class, Member: class.Members[0], Field: a.GetField(), Prev: a.GetPrev(), Next: a.GetNext()}
+	case patch.Class:
+		if class == nil {
+			break
+		}
+		class.Name = name
+		return &diff.ClassAction{Type: a.GetType(), Class: class, Field: a.GetField(), Prev: a.GetPrev(), Next: a.GetNext()}
+	case patch.EnumItem:
+		if enum == nil {
+			break
+		}
+		enum.Items[0].Name = name
+		return &diff.EnumItemAction{Type: a.GetType(), Enum: enum, EnumItem: enum.Items[0], Field: a.GetField(), Prev: a.GetPrev(), Next: a.GetNext()}
+	case patch.Enum:
+		if enum == nil {
+			break
+		}
+		enum.Name = name
+		return &diff.EnumAction{Type: a.GetType(), Enum: enum, Field: a.GetField(), Prev: a.GetPrev(), Next: a.GetNext()}
+	}
+	return action
+}
+
+// withType returns a copy of an action with a different type and values.
+func withType(action patch.Action, typ patch.Type, prev, next interface{}) patch.Action {
+	switch a := action.(type) {
+	case patch.Member:
+		return &diff.MemberAction{Type: typ, Class: a.GetClass(), Member: a.GetMember(), Field: a.GetField(), Prev: prev, Next: next}
+	case patch.Class:
+		return &diff.ClassAction{Type: typ, Class: a.GetClass(), Field: a.GetField(), Prev: prev, Next: next}
+	case patch.EnumItem:
+		return &diff.EnumItemAction{Type: typ, Enum: a.GetEnum(), EnumItem: a.GetEnumItem(), Field: a.GetField(), Prev: prev, Next: next}
+	case patch.Enum:
+		return &diff.EnumAction{Type: typ, Enum: a.GetEnum(), Field: a.GetField(), Prev: prev, Next: next}
+	}
+	return action
+}
+
+// invertPatch returns actions that undo the given actions.
+func invertPatch(actions []patch.Action) []patch.Action {
+	inverted := make([]patch.Action, len(actions))
+	for i, action := range actions {
+		var a patch.Action
+		switch action.GetType() {
+		case patch.Add:
+			a = withType(action, patch.Remove, nil, nil)
+		case patch.Remove:
+			a = withType(action, patch.Add, nil, nil)
+		case patch.Change:
+			a = withType(action, patch.Change, action.GetNext(), action.GetPrev())
+			if action.GetField() == "Name" {
+				if name, ok := action.GetNext().(string); ok {
+					a = renamed(a, name)
+				}
+			}
+		}
+		inverted[len(actions)-1-i] = a
+	}
+	return inverted
+}
+
+// actionKey returns a string identifying the subject of an action.
+func actionKey(action patch.Action) string {
+	info := describeAction(action)
+	key := info.Kind + " " + info.Name()
+	if a, ok := action.(patch.Member); ok && a.GetMember() != nil {
+		key += " " + a.GetMember().GetMemberType()
+	}
+	return key
+}
+
+// foldChange returns a copy of an Add action with a Change action applied
+// to its descriptor.
+func foldChange(add, change patch.Action) patch.Action {
+	class, enum := patchfile.Descriptors(add)
+	switch a := add.(type) {
+	case patch.Member:
+		if class == nil {
+			break
+		}
+		class.Members[0].(patch.Patcher).Patch([]patch.Action{change})
+		return &diff.MemberAction{Type: patch.Add, Class: a.GetClass(), Member: class.Members[0]}
+	case patch.Class:
+		if class == nil {
+			break
+		}
+		class.Patch([]patch.Action{change})
+		return &diff.ClassAction{Type: patch.Add, Class: class}
+	case patch.EnumItem:
+		if enum == nil {
+			break
+		}
+		enum.Items[0].Patch([]patch.Action{change})
+		return &diff.EnumItemAction{Type: patch.Add, Enum: a.GetEnum(), EnumItem: enum.Items[0]}
+	case patch.Enum:
+		if enum == nil {
+			break
+		}
+		enum.Patch([]patch.Action{change})
+		return &diff.EnumAction{Type: patch.Add, Enum: enum}
+	}
+	return nil
+}
+
+// squashPatch combines a sequence of actions into an equivalent, shorter
+// sequence. Descriptors that are added and then removed are dropped,
+// successive changes to a field are merged, and changes to added descriptors
+// are folded into the descriptor. Renames are kept as they are.
+func squashPatch(actions []patch.Action) []patch.Action {
+	var list []patch.Action
+	added := map[string]int{}
+	changed := map[string]map[string]int{}
+	for _, action := range actions {
+		key := actionKey(action)
+		switch action.GetType() {
+		case patch.Add:
+			added[key] = len(list)
+			list = append(list, action)
+			continue
+		case patch.Remove:
+			for _, i := range changed[key] {
+				list[i] = nil
+			}
+			delete(changed, key)
+			if i, ok := added[key]; ok {
+				list[i] = nil
+				delete(added, key)
+				continue
+			}
+			list = append(list, action)
+			continue
+		}
+		field := action.GetField()
+		if field == "Name" {
+			list = append(list, action)
+			continue
+		}
+		if i, ok := added[key]; ok {
+			if a := foldChange(list[i], action); a != nil {
+				list[i] = a
+				continue
+			}
+		}
+		if i, ok := changed[key][field]; ok {
+			prev := list[i].GetPrev()
+			if patch.ValuesEqual(prev, action.GetNext()) {
+				list[i] = nil
+				delete(changed[key], field)
+			} else {
+				list[i] = withType(action, patch.Change, prev, action.GetNext())
+			}
+			continue
+		}
+		if changed[key] == nil {
+			changed[key] = map[string]int{}
+		}
+		changed[key][field] = len(list)
+		list = append(list, action)
+	}
+	squashed := list[:0]
+	for _, action := range list {
+		if action != nil {
+			squashed = append(squashed, action)
+		}
+	}
+	return squashed
+}
+
+func init() {
+	var from, to, output string
+	var strict bool
+	register(&command{
+		Name:    "patch apply",
+		Args:    "BASE PATCH",
+		Summary: "apply a patch file to an API dump",
+		Description: `
+Apply applies the actions in the patch file PATCH to the API dump in BASE, and
+writes the result. The output has the format of BASE unless specified
+otherwise.
+
+Actions that do not apply cleanly are skipped, and reported to standard error.
+Patch files can be produced with "rbxapi diff -format patch".`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of BASE")
+			fs.StringVar(&to, "to", "", "`format` of the output")
+			fs.StringVar(&output, "o", "-", "write output to `file`")
+			fs.BoolVar(&strict, "strict", false, "fail if any action does not apply cleanly")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 2 {
+				return usageError("expected BASE and PATCH")
+			}
+			root, inFormat, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			outFormat, err := outputFormat(output, to, inFormat)
+			if err != nil {
+				return err
+			}
+			actions, err := readPatch(args[1])
+			if err != nil {
+				return err
+			}
+			root, conflicts, err := applyPatch(root, actions)
+			if err != nil {
+				return err
+			}
+			report := jsonApplyReport{Conflicts: jsonConflicts(conflicts)}
+			if !jsonOutput {
+				for _, c := range conflicts {
+					fmt.Fprintln(os.Stderr, c)
+				}
+			}
+			if len(conflicts) > 0 && strict {
+				if jsonOutput {
+					writeReport(output, report)
+				}
+				return fmt.Errorf("%d actions do not apply cleanly", len(conflicts))
+			}
+			lost, err := encodeFile(output, outFormat, root)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				report.Output = output
+				report.Format = outFormat.Name
+				report.Lost = jsonActions(lost)
+				return writeReport(output, report)
+			}
+			if len(lost) > 0 {
+				fmt.Fprintf(os.Stderr, "conversion from %s to %s lost %d pieces of information\n", inFormat.Name, outFormat.Name, len(lost))
+			}
+			return nil
+		},
+	})
+}
+
+func init() {
+	var output string
+	register(&command{
+		Name:    "patch invert",
+		Args:    "PATCH",
+		Summary: "write a patch file that undoes a patch",
+		Description: `
+Invert writes a patch file that reverts the changes made by PATCH.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&output, "o", "-", "write output to `file`")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 1 {
+				return usageError("expected PATCH")
+			}
+			actions, err := readPatch(args[0])
+			if err != nil {
+				return err
+			}
+			actions = invertPatch(actions)
+			err = writeFile(output, func(w io.Writer) error {
+				return encodePatch(w, actions)
+			})
+			if err != nil || !jsonOutput {
+				return err
+			}
+			return writeReport(output, jsonPatchReport{Output: output, Actions: len(actions)})
+		},
+	})
+}
+
+func init() {
+	var output string
+	register(&command{
+		Name:    "patch squash",
+		Args:    "PATCH...",
+		Summary: "combine patch files into one",
+		Description: `
+Squash concatenates the given patch files in order, and combines actions that
+apply to the same descriptor. Descriptors that are added and then removed are
+dropped, successive changes to the same field are merged, and changes to added
+descriptors are folded into the added descriptor.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&output, "o", "-", "write output to `file`")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) == 0 {
+				return usageError("expected at least one PATCH")
+			}
+			var actions []patch.Action
+			for _, path := range args {
+				a, err := readPatch(path)
+				if err != nil {
+					return err
+				}
+				actions = append(actions, a...)
+			}
+			actions = squashPatch(actions)
+			err := writeFile(output, func(w io.Writer) error {
+				return encodePatch(w, actions)
+			})
+			if err != nil || !jsonOutput {
+				return err
+			}
+			return writeReport(output, jsonPatchReport{Output: output, Actions: len(actions)})
+		},
+	})
+}
+
+func init() {
+	var from string
+	register(&command{
+		Name:    "patch check",
+		Args:    "BASE PATCH",
+		Summary: "check that a patch file applies cleanly",
+		Description: `
+Check verifies that each action in PATCH applies cleanly to the API dump in
+BASE: added descriptors must not exist, removed and changed descriptors must
+exist, and the previous values of changed fields must match. Each problem is
+printed, and check exits with status 1 if any are found.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of BASE")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 2 {
+				return usageError("expected BASE and PATCH")
+			}
+			root, _, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			actions, err := readPatch(args[1])
+			if err != nil {
+				return err
+			}
+			_, conflicts, err := applyPatch(root, actions)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				if err := writeJSON(os.Stdout, jsonConflicts(conflicts)); err != nil {
+					return err
+				}
+			} else {
+				for _, c := range conflicts {
+					fmt.Println(c)
+				}
+			}
+			if len(conflicts) > 0 {
+				return &exitError{Code: 1}
+			}
+			return nil
+		},
+	})
+}
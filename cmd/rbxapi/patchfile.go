@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/patchfile"
+	"io"
+)
+
+// encodePatch writes actions to w as a patch file.
+func encodePatch(w io.Writer, actions []patch.Action) error {
+	return patchfile.Encode(w, actions)
+}
+
+// readPatch reads a patch file from path.
+func readPatch(path string) ([]patch.Action, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	actions, err := patchfile.Decode(r)
+	if err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	return actions, nil
+}
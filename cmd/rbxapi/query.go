@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/query"
+	"os"
+)
+
+func init() {
+	var format, from string
+	var count, glob bool
+	register(&command{
+		Name:    "query",
+		Args:    "DUMP SELECTOR",
+		Summary: "select descriptors from an API dump",
+		Description: `
+Query prints the descriptors in DUMP that match SELECTOR, one per line. For
+example:
+
+	rbxapi query API-Dump.json 'Class[Tag=Service] Member[MemberType=Event]'
+
+See the documentation of the query package for the selector language. With
+-glob, SELECTOR is instead a pattern matched against qualified names, such as
+'*Service.Get*'. Query exits with status 1 if nothing matches.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&format, "format", "text", "output `format`: text, name, or json")
+			fs.StringVar(&from, "from", "", "`format` of DUMP")
+			fs.BoolVar(&count, "count", false, "print only the number of matches")
+			fs.BoolVar(&glob, "glob", false, "treat SELECTOR as a pattern of qualified names")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 2 {
+				return usageError("expected DUMP and SELECTOR")
+			}
+			if jsonOutput {
+				format = "json"
+			}
+			switch format {
+			case "text", "name", "json":
+			default:
+				return usageError("unknown format \"" + format + "\"")
+			}
+			var sel *query.Selector
+			if !glob {
+				var err error
+				if sel, err = query.Parse(args[1]); err != nil {
+					return usageError("selector: " + err.Error())
+				}
+			}
+			root, _, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			var matches []query.Match
+			if glob {
+				if matches, err = query.Glob(root, args[1]); err != nil {
+					return usageError("pattern: " + err.Error())
+				}
+			} else {
+				matches = sel.Select(root)
+			}
+			switch {
+			case count && format == "json":
+				if err := writeJSON(os.Stdout, struct{ Count int }{len(matches)}); err != nil {
+					return err
+				}
+			case count:
+				fmt.Println(len(matches))
+			case format == "json":
+				type jsonMatch struct {
+					Kind       string
+					Name       string
+					MemberType string `json:",omitempty"`
+					Tags       []string
+				}
+				list := make([]jsonMatch, len(matches))
+				for i, m := range matches {
+					list[i] = jsonMatch{Kind: m.Kind(), Name: m.Name(), Tags: []string{}}
+					if m.Member != nil {
+						list[i].MemberType = m.Member.GetMemberType()
+					}
+					if t, ok := m.Descriptor().(rbxapi.Taggable); ok && len(t.GetTags()) > 0 {
+						list[i].Tags = t.GetTags()
+					}
+				}
+				if err := writeJSON(os.Stdout, list); err != nil {
+					return err
+				}
+			case format == "name":
+				for _, m := range matches {
+					fmt.Println(m.Name())
+				}
+			default:
+				for _, m := range matches {
+					fmt.Println(m)
+				}
+			}
+			if len(matches) == 0 {
+				return &exitError{Code: 1}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/gen/graphql"
+	"github.com/karl-police/rbxapi/query"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// server serves API data from an archive over HTTP.
+//
+//	GET /versions                              list archived versions
+//	GET /versions/{v}                          metadata of a version
+//	GET /versions/{v}/classes                  names of classes
+//	GET /versions/{v}/classes/{class}          class descriptor
+//	GET /versions/{v}/classes/{class}/{member} member descriptor
+//	GET /versions/{v}/enums                    names of enums
+//	GET /versions/{v}/enums/{enum}             enum descriptor
+//	GET /versions/{v}/search?q=&kind=&regexp=  search descriptor names
+//	GET /versions/{v}/query?selector=          select descriptors
+//	GET /diff?from={v}&to={v}&format=          changes between versions
+//	GET, POST /graphql                         GraphQL queries
+//
+// A version {v} is a GUID, or "latest" for the most recent version.
+type server struct {
+	archive *archive.Archive
+	// cacheSize is the maximum number of decoded dumps kept in memory.
+	cacheSize int
+
+	mu    sync.Mutex
+	cache []cachedRoot // Most recently used last.
+}
+
+type cachedRoot struct {
+	guid string
+	root *rbxapijson.Root
+}
+
+// httpError is an error with an HTTP status.
+type httpError struct {
+	Status int
+	Msg    string
+}
+
+func (err *httpError) Error() string { return err.Msg }
+
+func notFound(msg string) error {
+	return &httpError{Status: http.StatusNotFound, Msg: msg}
+}
+
+func badRequest(msg string) error {
+	return &httpError{Status: http.StatusBadRequest, Msg: msg}
+}
+
+// resolve returns the metadata of a version named in a request.
+func (s *server) resolve(ctx context.Context, name string) (*archive.Meta, error) {
+	if name == "latest" {
+		metas, err := s.archive.Versions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(metas) == 0 {
+			return nil, notFound("archive is empty")
+		}
+		return metas[len(metas)-1], nil
+	}
+	meta, err := s.archive.Meta(ctx, strings.ToLower(name))
+	if err == archive.ErrNotExist {
+		return nil, notFound("version " + name + " not found")
+	}
+	return meta, err
+}
+
+// root returns the decoded dump of a version, using the cache if possible.
+func (s *server) root(ctx context.Context, guid string) (*rbxapijson.Root, error) {
+	s.mu.Lock()
+	for i, c := range s.cache {
+		if c.guid == guid {
+			copy(s.cache[i:], s.cache[i+1:])
+			s.cache[len(s.cache)-1] = c
+			s.mu.Unlock()
+			return c.root, nil
+		}
+	}
+	s.mu.Unlock()
+
+	root, err := s.archive.JSONDump(ctx, guid)
+	if err == archive.ErrNotExist {
+		return nil, notFound("version " + guid + " has no API dump")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) >= s.cacheSize && len(s.cache) > 0 {
+		s.cache = append(s.cache[:0], s.cache[1:]...)
+	}
+	s.cache = append(s.cache, cachedRoot{guid: guid, root: root})
+	return root, nil
+}
+
+// respondJSON writes v as the response.
+func respondJSON(w http.ResponseWriter, v interface{}) error {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// memberJSON returns the JSON dump representation of a member.
+func memberJSON(member rbxapi.Member) (json.RawMessage, error) {
+	b, err := json.Marshal(&rbxapijson.Class{Members: []rbxapi.Member{member}})
+	if err != nil {
+		return nil, err
+	}
+	var c struct{ Members []json.RawMessage }
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return c.Members[0], nil
+}
+
+// searchResult is a descriptor found by a search.
+type searchResult struct {
+	Kind       string
+	Name       string
+	MemberType string `json:",omitempty"`
+}
+
+// graphqlSource implements the graphql.Source interface for a server.
+type graphqlSource struct {
+	*server
+}
+
+// Versions implements the graphql.Source interface.
+func (s graphqlSource) Versions(ctx context.Context) ([]fetch.Version, error) {
+	metas, err := s.archive.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []fetch.Version
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; ok {
+			list = append(list, meta.Version)
+		}
+	}
+	return list, nil
+}
+
+// Root implements the graphql.Source interface.
+func (s graphqlSource) Root(ctx context.Context, guid string) (rbxapi.Root, error) {
+	return s.root(ctx, guid)
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/graphql" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		graphql.Handler(graphql.NewArchive(graphqlSource{s})).ServeHTTP(w, r)
+		return
+	}
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if err := s.serve(w, r); err != nil {
+		if err, ok := err.(*httpError); ok {
+			http.Error(w, err.Msg, err.Status)
+			return
+		}
+		log.Printf("%s %s: %s", r.Method, r.URL, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+func (s *server) serve(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "versions":
+		metas, err := s.archive.Versions(ctx)
+		if err != nil {
+			return err
+		}
+		if metas == nil {
+			metas = []*archive.Meta{}
+		}
+		return respondJSON(w, metas)
+	case len(parts) == 1 && parts[0] == "diff":
+		return s.serveDiff(w, r)
+	case len(parts) >= 2 && parts[0] == "versions":
+		meta, err := s.resolve(ctx, parts[1])
+		if err != nil {
+			return err
+		}
+		if len(parts) == 2 {
+			return respondJSON(w, meta)
+		}
+		root, err := s.root(ctx, meta.Version.GUID)
+		if err != nil {
+			return err
+		}
+		return s.serveVersion(w, r, root, parts[2:])
+	}
+	return notFound("not found")
+}
+
+func (s *server) serveVersion(w http.ResponseWriter, r *http.Request, root *rbxapijson.Root, parts []string) error {
+	switch parts[0] {
+	case "classes":
+		switch len(parts) {
+		case 1:
+			names := make([]string, len(root.Classes))
+			for i, class := range root.Classes {
+				names[i] = class.Name
+			}
+			return respondJSON(w, names)
+		case 2, 3:
+			class, _ := root.GetClass(parts[1]).(*rbxapijson.Class)
+			if class == nil {
+				return notFound("class " + parts[1] + " not found")
+			}
+			if len(parts) == 2 {
+				return respondJSON(w, class)
+			}
+			member := class.GetMember(parts[2])
+			if member == nil {
+				return notFound("member " + parts[1] + "." + parts[2] + " not found")
+			}
+			b, err := memberJSON(member)
+			if err != nil {
+				return err
+			}
+			return respondJSON(w, b)
+		}
+	case "enums":
+		switch len(parts) {
+		case 1:
+			names := make([]string, len(root.Enums))
+			for i, enum := range root.Enums {
+				names[i] = enum.Name
+			}
+			return respondJSON(w, names)
+		case 2:
+			enum := root.GetEnum(parts[1])
+			if enum == nil {
+				return notFound("enum " + parts[1] + " not found")
+			}
+			return respondJSON(w, enum)
+		}
+	case "search":
+		if len(parts) == 1 {
+			return s.serveSearch(w, r, root)
+		}
+	case "query":
+		if len(parts) == 1 {
+			sel, err := query.Parse(r.FormValue("selector"))
+			if err != nil {
+				return badRequest("selector: " + err.Error())
+			}
+			matches := sel.Select(root)
+			results := make([]searchResult, len(matches))
+			for i, m := range matches {
+				results[i] = searchResult{Kind: m.Kind(), Name: m.Name()}
+				if m.Member != nil {
+					results[i].MemberType = m.Member.GetMemberType()
+				}
+			}
+			return respondJSON(w, results)
+		}
+	}
+	return notFound("not found")
+}
+
+func (s *server) serveSearch(w http.ResponseWriter, r *http.Request, root *rbxapijson.Root) error {
+	q := r.FormValue("q")
+	if q == "" {
+		return badRequest("missing q parameter")
+	}
+	var match func(string) bool
+	if r.FormValue("regexp") != "" {
+		re, err := regexp.Compile(q)
+		if err != nil {
+			return badRequest("q: " + err.Error())
+		}
+		match = re.MatchString
+	} else {
+		q = strings.ToLower(q)
+		match = func(s string) bool { return strings.Contains(strings.ToLower(s), q) }
+	}
+	kind := r.FormValue("kind")
+	limit := 100
+	if v := r.FormValue("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return badRequest("invalid limit")
+		}
+		limit = n
+	}
+	results := []searchResult{}
+	add := func(res searchResult) bool {
+		if kind != "" && kind != res.Kind && kind != res.MemberType {
+			return true
+		}
+		if !match(res.Name) {
+			return true
+		}
+		results = append(results, res)
+		return len(results) < limit
+	}
+loop:
+	for _, class := range root.Classes {
+		if !add(searchResult{Kind: query.KindClass, Name: class.Name}) {
+			break loop
+		}
+		for _, member := range class.Members {
+			if !add(searchResult{Kind: query.KindMember, Name: class.Name + "." + member.GetName(), MemberType: member.GetMemberType()}) {
+				break loop
+			}
+		}
+	}
+	if len(results) < limit {
+	enums:
+		for _, enum := range root.Enums {
+			if !add(searchResult{Kind: query.KindEnum, Name: enum.Name}) {
+				break enums
+			}
+			for _, item := range enum.Items {
+				if !add(searchResult{Kind: query.KindEnumItem, Name: enum.Name + "." + item.Name}) {
+					break enums
+				}
+			}
+		}
+	}
+	return respondJSON(w, results)
+}
+
+func (s *server) serveDiff(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	format := r.FormValue("format")
+	if format == "" {
+		format = "json"
+	}
+	contentType := map[string]string{
+		"json":  "application/json; charset=utf-8",
+		"patch": "application/json; charset=utf-8",
+		"text":  "text/plain; charset=utf-8",
+		"md":    "text/markdown; charset=utf-8",
+		"html":  "text/html; charset=utf-8",
+	}[format]
+	if contentType == "" {
+		return badRequest("unknown format \"" + format + "\"")
+	}
+	var roots [2]*rbxapijson.Root
+	for i, param := range []string{"from", "to"} {
+		v := r.FormValue(param)
+		if v == "" {
+			return badRequest("missing " + param + " parameter")
+		}
+		meta, err := s.resolve(ctx, v)
+		if err != nil {
+			return err
+		}
+		if roots[i], err = s.root(ctx, meta.Version.GUID); err != nil {
+			return err
+		}
+	}
+	actions := (&rbxapijson.Diff{Prev: roots[0], Next: roots[1]}).Diff()
+	var buf bytes.Buffer
+	if err := writeChangelog(&buf, format, actions); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func init() {
+	var dir, listen string
+	var cacheSize int
+	register(&command{
+		Name:    "serve",
+		Summary: "serve API data from an archive over HTTP",
+		Description: `
+Serve runs an HTTP server that exposes the API dumps of an archive as JSON.
+
+	GET /versions                              list archived versions
+	GET /versions/{v}                          metadata of a version
+	GET /versions/{v}/classes                  names of classes
+	GET /versions/{v}/classes/{class}          class descriptor
+	GET /versions/{v}/classes/{class}/{member} member descriptor
+	GET /versions/{v}/enums                    names of enums
+	GET /versions/{v}/enums/{enum}             enum descriptor
+	GET /versions/{v}/search?q=&kind=&regexp=  search descriptor names
+	GET /versions/{v}/query?selector=          select descriptors
+	GET /diff?from={v}&to={v}&format=          changes between versions
+	GET, POST /graphql                         GraphQL queries
+
+A version {v} is a GUID, or "latest" for the most recent version. The format
+of a diff is one of json, text, md, html, or patch. Run "rbxapi generate
+-target graphql" for the GraphQL schema.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "archive", "", "archive `directory`")
+			fs.StringVar(&listen, "listen", ":8080", "`address` on which to listen")
+			fs.IntVar(&cacheSize, "cache", 8, "maximum `number` of decoded dumps kept in memory")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			if dir == "" {
+				return usageError("-archive is required")
+			}
+			if _, err := os.Stat(dir); err != nil {
+				return err
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			srv := &http.Server{
+				Addr:              listen,
+				Handler:           &server{archive: archive.New(archive.Dir(dir)), cacheSize: cacheSize},
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				srv.Shutdown(shutdown)
+			}()
+			if jsonOutput {
+				writeJSON(os.Stdout, struct{ Archive, Listen string }{dir, listen})
+			} else {
+				fmt.Fprintf(os.Stderr, "serving %s on %s\n", dir, listen)
+			}
+			if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+	})
+}
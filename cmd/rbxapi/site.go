@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/docs"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/gen/site"
+	"os"
+)
+
+func init() {
+	var dir, output, docsFile string
+	s := &site.Site{}
+	register(&command{
+		Name:    "site",
+		Summary: "build a static HTML reference from an archive",
+		Description: `
+Site builds a browsable HTML reference from the versions stored in an archive,
+writing the pages to the output directory. The reference describes the
+classes and enums of the latest version, and the changes made by every
+version.
+
+The pages are rendered from templates that may be replaced with -template.
+Run "go doc github.com/karl-police/rbxapi/gen/site" for the names of the
+templates and the data they receive.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "archive", "", "archive `directory`")
+			fs.StringVar(&output, "o", ".", "output `directory`")
+			fs.StringVar(&docsFile, "docs", "", "read descriptions from API documentation `file`")
+			fs.StringVar(&s.TemplateFile, "template", "", "replace the default templates with those defined in `file`")
+			s.Filter.Flags(fs)
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			if dir == "" {
+				return usageError("-archive is required")
+			}
+			if _, err := os.Stat(dir); err != nil {
+				return err
+			}
+			if docsFile != "" {
+				f, err := os.Open(docsFile)
+				if err != nil {
+					return err
+				}
+				s.Docs, err = docs.Decode(f)
+				f.Close()
+				if err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(output, 0777); err != nil {
+				return err
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			s.Archive = archive.New(archive.Dir(dir))
+			out := &recordOutput{Output: gen.Dir(output), files: []string{}}
+			if err := s.Build(ctx, out); err != nil {
+				return err
+			}
+			if jsonOutput {
+				return writeJSON(os.Stdout, jsonGenerateReport{Target: "site", Output: output, Files: out.files})
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"sort"
+)
+
+// sortRoot sorts the classes, members, enums, and enum items of root by
+// name, when root is of a known type.
+func sortRoot(root rbxapi.Root) {
+	switch root := root.(type) {
+	case *rbxapijson.Root:
+		sort.SliceStable(root.Classes, func(i, j int) bool { return root.Classes[i].Name < root.Classes[j].Name })
+		for _, class := range root.Classes {
+			sortMembers(class.Members)
+		}
+		sort.SliceStable(root.Enums, func(i, j int) bool { return root.Enums[i].Name < root.Enums[j].Name })
+		for _, enum := range root.Enums {
+			sort.SliceStable(enum.Items, func(i, j int) bool { return enum.Items[i].Name < enum.Items[j].Name })
+		}
+	case *rbxapidump.Root:
+		sort.SliceStable(root.Classes, func(i, j int) bool { return root.Classes[i].Name < root.Classes[j].Name })
+		for _, class := range root.Classes {
+			sortMembers(class.Members)
+		}
+		sort.SliceStable(root.Enums, func(i, j int) bool { return root.Enums[i].Name < root.Enums[j].Name })
+		for _, enum := range root.Enums {
+			sort.SliceStable(enum.Items, func(i, j int) bool { return enum.Items[i].Name < enum.Items[j].Name })
+		}
+	}
+}
+
+func sortMembers(members []rbxapi.Member) {
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].GetName() < members[j].GetName()
+	})
+}
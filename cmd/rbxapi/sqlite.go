@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/gen/sqlite"
+	"io"
+	"os"
+)
+
+func init() {
+	var dir, output string
+	register(&command{
+		Name:    "sqlite",
+		Summary: "export the versions of an archive to a SQLite database",
+		Description: `
+Sqlite writes every version of an archive to a SQLite database, so that the
+history of the API can be queried with SQL. Run "go doc
+github.com/karl-police/rbxapi/gen/sqlite" for a description of the tables.
+
+To export a single API dump, run "rbxapi generate -target sqlite".`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "archive", "", "archive `directory`")
+			fs.StringVar(&output, "o", "api.db", "output `file`")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			if dir == "" {
+				return usageError("-archive is required")
+			}
+			if _, err := os.Stat(dir); err != nil {
+				return err
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			db := sqlite.New()
+			if err := db.AddArchive(ctx, archive.New(archive.Dir(dir))); err != nil {
+				return err
+			}
+			if err := writeFile(output, func(w io.Writer) error {
+				_, err := db.WriteTo(w)
+				return err
+			}); err != nil {
+				return err
+			}
+			if jsonOutput {
+				return writeReport(output, jsonOutputReport{Output: output, Format: "sqlite", Lost: []jsonAction{}})
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/validate"
+	"os"
+	"sort"
+	"strings"
+)
+
+// stats contains counts of the descriptors of an API structure.
+type stats struct {
+	Classes     int
+	Members     int
+	Enums       int
+	EnumItems   int
+	MemberTypes map[string]int
+	Security    map[string]int
+	Tags        map[string]int
+}
+
+// computeStats counts the descriptors of root. Tags are counted per
+// descriptor of any kind. Security is counted per member.
+func computeStats(root rbxapi.Root) *stats {
+	s := &stats{
+		MemberTypes: map[string]int{},
+		Security:    map[string]int{},
+		Tags:        map[string]int{},
+	}
+	tags := func(t rbxapi.Taggable) {
+		for _, tag := range t.GetTags() {
+			s.Tags[tag]++
+		}
+	}
+	for _, class := range root.GetClasses() {
+		s.Classes++
+		tags(class)
+		for _, member := range class.GetMembers() {
+			s.Members++
+			s.MemberTypes[member.GetMemberType()]++
+			security := rbxapi.MemberSecurity(member)
+			if security == "" {
+				security = "None"
+			}
+			s.Security[security]++
+			tags(member)
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		s.Enums++
+		tags(enum)
+		for _, item := range enum.GetEnumItems() {
+			s.EnumItems++
+			tags(item)
+		}
+	}
+	return s
+}
+
+// sortedKeys returns the keys of the given maps, sorted.
+func sortedKeys(maps ...map[string]int) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeStats prints the counts of s. If prev is not nil, the difference from
+// prev is printed alongside each count.
+func writeStats(s, prev *stats) {
+	delta := prev != nil
+	if prev == nil {
+		prev = &stats{}
+	}
+	row := func(name string, n, p int) {
+		if delta && n != p {
+			fmt.Printf("\t%-28s %7d %+7d\n", name, n, n-p)
+			return
+		}
+		fmt.Printf("\t%-28s %7d\n", name, n)
+	}
+	fmt.Println("Descriptors:")
+	row("Classes", s.Classes, prev.Classes)
+	row("Members", s.Members, prev.Members)
+	row("Enums", s.Enums, prev.Enums)
+	row("EnumItems", s.EnumItems, prev.EnumItems)
+	section := func(title string, m, p map[string]int) {
+		fmt.Println(title + ":")
+		for _, k := range sortedKeys(m, p) {
+			row(k, m[k], p[k])
+		}
+	}
+	section("Member types", s.MemberTypes, prev.MemberTypes)
+	section("Security", s.Security, prev.Security)
+	section("Tags", s.Tags, prev.Tags)
+}
+
+// writeEnumReports prints how the items of each enum are numbered.
+func writeEnumReports(reports []validate.EnumReport) {
+	for _, r := range reports {
+		safe := "safe"
+		if !r.SafeByValue {
+			safe = "unsafe"
+		}
+		fmt.Printf("%s: %d items, values %d to %d, %s to store by value\n", r.Enum, r.Items, r.Min, r.Max, safe)
+		for _, d := range r.Duplicates {
+			fmt.Printf("\tduplicate value %d: %s\n", d.Value, strings.Join(d.Items, ", "))
+		}
+		for _, g := range r.Gaps {
+			fmt.Printf("\tgap of %d values between %d and %d\n", g.Size(), g.After, g.Before)
+		}
+		if len(r.Decreasing) > 0 {
+			fmt.Printf("\tdecreasing values at: %s\n", strings.Join(r.Decreasing, ", "))
+		}
+	}
+}
+
+func init() {
+	var from, format string
+	var enums bool
+	var gap int
+	register(&command{
+		Name:    "stats",
+		Args:    "DUMP [NEXT]",
+		Summary: "print counts of the descriptors of an API dump",
+		Description: `
+Stats prints the number of descriptors of each kind in DUMP, along with the
+number of members of each member type and security context, and the number
+of descriptors with each tag.
+
+If NEXT is given, the counts of NEXT are printed, along with the difference
+from the counts of DUMP.
+
+With -enums, stats instead reports how the items of each enum of DUMP are
+numbered: values shared by several items, gaps of more than -gap unused
+values, and items listed after an item with a higher value. An enum is safe
+to store by value if no value is shared.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of the dumps")
+			fs.StringVar(&format, "format", "text", "output `format`: text or json")
+			fs.BoolVar(&enums, "enums", false, "report the numbering of enum items")
+			fs.IntVar(&gap, "gap", 16, "with -enums, report gaps of more than `n` unused values")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 1 && len(args) != 2 {
+				return usageError("expected DUMP and optional NEXT")
+			}
+			if jsonOutput {
+				format = "json"
+			}
+			if format != "text" && format != "json" {
+				return usageError("unknown format \"" + format + "\"")
+			}
+			if enums {
+				if len(args) != 1 {
+					return usageError("-enums expects only DUMP")
+				}
+				root, _, err := decodeFile(args[0], from)
+				if err != nil {
+					return err
+				}
+				reports := validate.AnalyzeEnums(root, gap)
+				if format == "json" {
+					return writeJSON(os.Stdout, reports)
+				}
+				writeEnumReports(reports)
+				return nil
+			}
+			var list []*stats
+			for _, path := range args {
+				root, _, err := decodeFile(path, from)
+				if err != nil {
+					return err
+				}
+				list = append(list, computeStats(root))
+			}
+			if format == "json" {
+				var v interface{} = list[0]
+				if len(list) == 2 {
+					v = struct{ Prev, Next *stats }{list[0], list[1]}
+				}
+				return writeJSON(os.Stdout, v)
+			}
+			if len(list) == 2 {
+				writeStats(list[1], list[0])
+			} else {
+				writeStats(list[0], nil)
+			}
+			return nil
+		},
+	})
+}
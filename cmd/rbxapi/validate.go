@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi/validate"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// readValidateConfig reads a validation config from a JSON file.
+func readValidateConfig(path string) (*validate.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var config validate.Config
+	jd := json.NewDecoder(f)
+	jd.DisallowUnknownFields()
+	if err := jd.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func init() {
+	var from, rules, format, failOn, configFile, patchFile, compareFile string
+	var list bool
+	register(&command{
+		Name:    "validate",
+		Args:    "DUMP",
+		Summary: "check an API dump for problems",
+		Description: `
+Validate runs validation rules over the API dump in DUMP, and prints each
+diagnostic with the location of the offending descriptor. Validate exits with
+status 1 if any diagnostic is at least as severe as the -fail-on severity.
+
+Rules may be configured with the JSON file given by -config, which has the
+following structure:
+
+	{
+		"Rules": ["all"],
+		"Severity": {"member-case": "error"},
+		"Options": {"member-case": {"exceptions": "Class.member"}}
+	}
+
+Rules selects the rules to run, unless -rules is given. Severity overrides the
+severity of rules, and Options sets the options of rules.
+
+With -patch, the actions of the given patch file are applied one at a time to
+a copy of DUMP, and only the diagnostics introduced by each action are
+printed, with the index of the action. DUMP itself is not modified.
+
+With -compare, no rules are run. Instead, DUMP is compared with the given
+dump of the same build, typically the text dump and the JSON dump, and each
+disagreement between them is printed. Differences that are only due to the
+formats, such as the case of tags, are ignored.
+
+With -format sarif, the diagnostics are written as a SARIF log, which can be
+uploaded to code scanning tools. The path of each diagnostic is written as a
+logical location within DUMP.
+
+Run "rbxapi validate -list" for a list of rules and their options. Builds of
+rbxapi that link in additional rules, added with validate.Register, list and
+run them like the built-in rules.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, "from", "", "`format` of DUMP")
+			fs.StringVar(&rules, "rules", "all", "comma-separated `names` of rules to run, or \"all\"")
+			fs.StringVar(&configFile, "config", "", "read rule configuration from `file`")
+			fs.StringVar(&patchFile, "patch", "", "check the diagnostics introduced by the patch `file`")
+			fs.StringVar(&compareFile, "compare", "", "report disagreements with the dump in `file` of the same build")
+			fs.StringVar(&format, "format", "text", "output `format`: text, json, or sarif")
+			fs.StringVar(&failOn, "fail-on", "error", "minimum `severity` that causes failure: info, warning, or error")
+			fs.BoolVar(&list, "list", false, "list the available rules")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if jsonOutput {
+				format = "json"
+			}
+			if list && format == "json" {
+				return writeJSON(os.Stdout, validate.Rules())
+			}
+			if list {
+				for _, r := range validate.Rules() {
+					fmt.Printf("%-28s %-8s %s\n", r.Name, r.Severity, r.Summary)
+					options := make([]string, 0, len(r.Options))
+					for name := range r.Options {
+						options = append(options, name)
+					}
+					sort.Strings(options)
+					for _, name := range options {
+						fmt.Printf("%-28s %-8s option %s=%q\n", "", "", name, r.Options[name])
+					}
+				}
+				return nil
+			}
+			if len(args) != 1 {
+				return usageError("expected DUMP")
+			}
+			if format != "text" && format != "json" && format != "sarif" {
+				return usageError("unknown format \"" + format + "\"")
+			}
+			threshold, ok := validate.ParseSeverity(failOn)
+			if !ok {
+				return usageError("unknown severity \"" + failOn + "\"")
+			}
+			root, _, err := decodeFile(args[0], from)
+			if err != nil {
+				return err
+			}
+			config := &validate.Config{}
+			if configFile != "" {
+				if config, err = readValidateConfig(configFile); err != nil {
+					return err
+				}
+			}
+			fs.Visit(func(f *flag.Flag) {
+				if f.Name == "rules" {
+					config.Rules = splitList(rules)
+				}
+			})
+			if patchFile != "" && compareFile != "" {
+				return usageError("-patch and -compare cannot be used together")
+			}
+			var diagnostics []validate.Diagnostic
+			switch {
+			case patchFile != "":
+				actions, err := readPatch(patchFile)
+				if err != nil {
+					return err
+				}
+				introduced, err := config.ValidatePatch(root, actions)
+				if err != nil {
+					return usageError(err.Error())
+				}
+				if format == "json" {
+					if introduced == nil {
+						introduced = []validate.PatchDiagnostic{}
+					}
+					if err := writeJSON(os.Stdout, introduced); err != nil {
+						return err
+					}
+				}
+				for _, d := range introduced {
+					switch format {
+					case "text":
+						fmt.Printf("%s: %s\n", patchFile, d)
+					case "sarif":
+						// Attribute the diagnostic to its action in the message.
+						d.Message = fmt.Sprintf("action %d: %s", d.Index, d.Message)
+					}
+					diagnostics = append(diagnostics, d.Diagnostic)
+				}
+			case compareFile != "":
+				other, _, err := decodeFile(compareFile, from)
+				if err != nil {
+					return err
+				}
+				diagnostics = validate.Compare(
+					validate.Dump{Name: args[0], Root: root},
+					validate.Dump{Name: compareFile, Root: other},
+				)
+			default:
+				if diagnostics, err = config.Validate(root); err != nil {
+					return usageError(err.Error())
+				}
+			}
+			if format == "sarif" {
+				if err := validate.WriteSARIF(os.Stdout, filepath.ToSlash(args[0]), diagnostics); err != nil {
+					return err
+				}
+			} else if patchFile == "" {
+				if format == "json" {
+					if diagnostics == nil {
+						diagnostics = []validate.Diagnostic{}
+					}
+					if err := writeJSON(os.Stdout, diagnostics); err != nil {
+						return err
+					}
+				} else {
+					for _, d := range diagnostics {
+						fmt.Printf("%s: %s\n", args[0], d)
+					}
+				}
+			}
+			if validate.Max(diagnostics) >= threshold {
+				return &exitError{Code: 1}
+			}
+			return nil
+		},
+	})
+}
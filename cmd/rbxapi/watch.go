@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/gen/feed"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// notifyExec runs a command for an event, passing the rendered changes on
+// standard input, and describing the event with environment variables.
+func notifyExec(ctx context.Context, command string, event fetch.Event, body []byte) error {
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"RBXAPI_CHANNEL="+event.Next.Channel,
+		"RBXAPI_PREV="+event.Prev.GUID,
+		"RBXAPI_PREV_NUMBER="+event.Prev.Number.String(),
+		"RBXAPI_NEXT="+event.Next.GUID,
+		"RBXAPI_NEXT_NUMBER="+event.Next.Number.String(),
+		"RBXAPI_CHANGES="+strconv.Itoa(len(event.Actions)),
+	)
+	return cmd.Run()
+}
+
+// notifyWebhook posts the rendered changes of an event to a URL.
+func notifyWebhook(ctx context.Context, url, contentType string, event fetch.Event, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Rbxapi-Prev", event.Prev.GUID)
+	req.Header.Set("X-Rbxapi-Next", event.Next.GUID)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &fetch.StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// jsonEvent describes a new build found by watch.
+type jsonEvent struct {
+	Prev    fetch.Version
+	Next    fetch.Version
+	Actions []jsonAction
+}
+
+func init() {
+	var cf clientFlags
+	var interval time.Duration
+	var program, webhook, format, feedFile string
+	var skipEmpty bool
+	var count, feedLimit int
+	register(&command{
+		Name:    "watch",
+		Summary: "report changes to the API as new builds are deployed",
+		Description: `
+Watch polls a deployment channel for new builds. When a new build appears,
+its API dump is compared with the dump of the previous build, and the changes
+are rendered in the given format.
+
+The rendered changes are written to standard output, passed on standard input
+to the -exec command, and posted to the -webhook URL. With -feed, an entry
+for each build is added to an Atom feed, or an RSS feed if the file has the
+.rss extension; the feed is created if it does not exist. The command also
+receives the environment variables RBXAPI_CHANNEL, RBXAPI_PREV,
+RBXAPI_PREV_NUMBER, RBXAPI_NEXT, RBXAPI_NEXT_NUMBER, and RBXAPI_CHANGES.
+With -json, an object describing each build and its changes is written to
+standard output instead of the rendered changes.
+
+Errors while polling or notifying are reported, and watching continues.`,
+		Flags: func(fs *flag.FlagSet) {
+			cf.flags(fs)
+			fs.DurationVar(&interval, "interval", time.Minute, "`duration` between polls")
+			fs.StringVar(&program, "exec", "", "run `command` for each new build")
+			fs.StringVar(&webhook, "webhook", "", "post changes to `URL` for each new build")
+			fs.StringVar(&format, "format", "text", "`format` of changes: text, md, json, html, or patch")
+			fs.StringVar(&feedFile, "feed", "", "add an entry for each new build to the feed in `file`")
+			fs.IntVar(&feedLimit, "feed-limit", 50, "keep at most `n` entries in the feed, if greater than zero")
+			fs.BoolVar(&skipEmpty, "skip-empty", false, "ignore builds that do not change the API")
+			fs.IntVar(&count, "count", 0, "exit after `n` new builds, if greater than zero")
+		},
+		Run: func(fs *flag.FlagSet, args []string) error {
+			if len(args) != 0 {
+				return usageError("unexpected arguments")
+			}
+			contentType := map[string]string{
+				"json":  "application/json; charset=utf-8",
+				"patch": "application/json; charset=utf-8",
+				"text":  "text/plain; charset=utf-8",
+				"md":    "text/markdown; charset=utf-8",
+				"html":  "text/html; charset=utf-8",
+			}[format]
+			if contentType == "" {
+				return usageError("unknown format \"" + format + "\"")
+			}
+			if interval <= 0 {
+				return usageError("interval must be positive")
+			}
+			ctx, cancel := interruptContext()
+			defer cancel()
+			client := cf.client()
+			var seen int
+			for event := range client.Watch(ctx, cf.Channel, interval) {
+				if event.Err != nil {
+					fmt.Fprintf(os.Stderr, "rbxapi watch: %s\n", event.Err)
+					continue
+				}
+				if skipEmpty && len(event.Actions) == 0 {
+					continue
+				}
+				var buf bytes.Buffer
+				if err := writeChangelog(&buf, format, event.Actions); err != nil {
+					return err
+				}
+				body := buf.Bytes()
+				if jsonOutput {
+					if err := writeJSON(os.Stdout, jsonEvent{Prev: event.Prev, Next: event.Next, Actions: jsonActions(event.Actions)}); err != nil {
+						return err
+					}
+				} else {
+					fmt.Fprintf(os.Stderr, "new build %s (%s), %d changes\n", event.Next, event.Next.Number, len(event.Actions))
+					os.Stdout.Write(body)
+				}
+				if program != "" {
+					if err := notifyExec(ctx, program, event, body); err != nil {
+						fmt.Fprintf(os.Stderr, "rbxapi watch: exec: %s\n", err)
+					}
+				}
+				if webhook != "" {
+					if err := notifyWebhook(ctx, webhook, contentType, event, body); err != nil {
+						fmt.Fprintf(os.Stderr, "rbxapi watch: webhook: %s\n", err)
+					}
+				}
+				if feedFile != "" {
+					if err := updateFeed(feedFile, feed.NewEntry(event.Prev, event.Next, event.Actions), feedLimit); err != nil {
+						fmt.Fprintf(os.Stderr, "rbxapi watch: feed: %s\n", err)
+					}
+				}
+				if seen++; count > 0 && seen >= count {
+					break
+				}
+			}
+			return nil
+		},
+	})
+}
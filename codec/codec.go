@@ -0,0 +1,291 @@
+// The codec package provides a registry of API formats, allowing API
+// structures to be decoded, encoded, and converted between formats by name.
+//
+// The "json" and "dump" formats, implemented by the rbxapijson and
+// rbxapidump packages, are registered by default. Other packages may
+// register additional formats with Register, typically in an init function.
+//
+// Decode transparently decompresses compressed content, and Encode can
+// compress its output. See Compression for the supported compressions.
+package codec
+
+import (
+	"bufio"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Codec decodes and encodes API structures of a particular format.
+type Codec interface {
+	// Decode parses an API structure from r.
+	Decode(r io.Reader) (rbxapi.Root, error)
+
+	// Encode writes root to w. Encode may fail if root is not of the native
+	// type of the codec, in which case it should first be converted with
+	// Convert.
+	Encode(w io.Writer, root rbxapi.Root) error
+
+	// Convert returns root as a value of the native type of the codec. If
+	// root is already of the native type, it is returned as-is. Otherwise,
+	// as much information as possible is copied into a new value.
+	Convert(root rbxapi.Root) rbxapi.Root
+}
+
+// Format describes a registered codec.
+type Format struct {
+	// Name is the name of the format.
+	Name string
+	// Extensions is a list of file extensions, including the leading dot,
+	// associated with the format.
+	Extensions []string
+	// Sniff returns whether the given prefix of the content of a file
+	// appears to be of the format. It may be nil.
+	Sniff func(prefix []byte) bool
+	// Codec implements the format.
+	Codec Codec
+}
+
+var (
+	mutex   sync.RWMutex
+	formats []Format
+)
+
+// Register adds a format to the registry. A format registered with the same
+// name as an existing format replaces it.
+func Register(format Format) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, f := range formats {
+		if f.Name == format.Name {
+			formats[i] = format
+			return
+		}
+	}
+	formats = append(formats, format)
+}
+
+// Formats returns the registered formats, sorted by name.
+func Formats() []Format {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	list := make([]Format, len(formats))
+	copy(list, formats)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Lookup returns the format of the given name.
+func Lookup(name string) (format Format, ok bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, f := range formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// ForPath returns the format associated with the extension of a file path.
+// The extension of a compression is skipped, so that "API-Dump.json.gz"
+// returns the "json" format.
+func ForPath(path string) (format Format, ok bool) {
+	if _, ok := CompressionForPath(path); ok {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return Format{}, false
+	}
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, f := range formats {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// Sniff returns the first format whose Sniff function recognizes the given
+// prefix of content.
+func Sniff(prefix []byte) (format Format, ok bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, f := range formats {
+		if f.Sniff != nil && f.Sniff(prefix) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// sniffLen is the length of the prefix passed to Sniff functions by Decode.
+const sniffLen = 512
+
+// Decode parses an API structure from r using the format of the given name.
+// If name is empty, the format is detected from the content. Compressed
+// content is decompressed before it is decoded.
+func Decode(r io.Reader, name string) (rbxapi.Root, Format, error) {
+	dr, _, err := Decompress(r)
+	if err != nil {
+		return nil, Format{}, err
+	}
+	defer dr.Close()
+	r = dr
+	if name != "" {
+		format, ok := Lookup(name)
+		if !ok {
+			return nil, Format{}, errors.New("unknown format \"" + name + "\"")
+		}
+		root, err := format.Codec.Decode(r)
+		return root, format, err
+	}
+	br := bufio.NewReaderSize(r, sniffLen)
+	prefix, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, Format{}, err
+	}
+	format, ok := Sniff(prefix)
+	if !ok {
+		return nil, Format{}, errors.New("unrecognized format")
+	}
+	root, err := format.Codec.Decode(br)
+	return root, format, err
+}
+
+// Convert converts root to the native type of c. Also returned are the
+// differences between root and the result, as seen through the rbxapi
+// interface, which indicate information that could not be transferred. The
+// differences are empty for a lossless conversion.
+//
+// If root is of the native type of the JSON or Dump codec, the result is
+// converted back to that type before it is compared, so that differences in
+// how each format represents the same information are not reported.
+func Convert(c Codec, root rbxapi.Root) (rbxapi.Root, []patch.Action) {
+	result := c.Convert(root)
+	if result == root {
+		return result, nil
+	}
+	next := result
+	switch root.(type) {
+	case *rbxapijson.Root:
+		next = JSON.Convert(result)
+	case *rbxapidump.Root:
+		next = Dump.Convert(result)
+	}
+	return result, (&diff.Diff{Prev: root, Next: next}).Diff()
+}
+
+// genericRoot copies root of any implementation into a rbxapijson.Root,
+// through the rbxapi interface.
+func genericRoot(root rbxapi.Root) *rbxapijson.Root {
+	r := &rbxapijson.Root{}
+	r.Patch((&diff.Diff{Next: root}).Diff())
+	return r
+}
+
+// skipSpace returns b without leading whitespace.
+func skipSpace(b []byte) []byte {
+	for len(b) > 0 {
+		switch b[0] {
+		case ' ', '\t', '\r', '\n', '\f':
+			b = b[1:]
+			continue
+		}
+		break
+	}
+	return b
+}
+
+// JSON is the codec of the "json" format.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(r io.Reader) (rbxapi.Root, error) {
+	return rbxapijson.Decode(r)
+}
+
+func (jsonCodec) Encode(w io.Writer, root rbxapi.Root) error {
+	r, ok := root.(*rbxapijson.Root)
+	if !ok {
+		return errors.New("json: cannot encode root of non-native type")
+	}
+	return rbxapijson.Encode(w, r)
+}
+
+// Convert maps a root of the rbxapidump package with rbxapiconv.ToJSON. Roots
+// of other implementations are copied through the rbxapi interface.
+func (jsonCodec) Convert(root rbxapi.Root) rbxapi.Root {
+	switch root := root.(type) {
+	case *rbxapijson.Root:
+		return root
+	case *rbxapidump.Root:
+		return rbxapiconv.ToJSON(root)
+	}
+	return genericRoot(root)
+}
+
+// Dump is the codec of the "dump" format.
+var Dump Codec = dumpCodec{}
+
+type dumpCodec struct{}
+
+func (dumpCodec) Decode(r io.Reader) (rbxapi.Root, error) {
+	return rbxapidump.Decode(r)
+}
+
+func (dumpCodec) Encode(w io.Writer, root rbxapi.Root) error {
+	r, ok := root.(*rbxapidump.Root)
+	if !ok {
+		return errors.New("dump: cannot encode root of non-native type")
+	}
+	return rbxapidump.Encode(w, r)
+}
+
+// Convert maps a root of the rbxapijson package with rbxapiconv.ToDump. Roots
+// of other implementations are first copied into a rbxapijson.Root through the
+// rbxapi interface.
+func (dumpCodec) Convert(root rbxapi.Root) rbxapi.Root {
+	switch root := root.(type) {
+	case *rbxapidump.Root:
+		return root
+	case *rbxapijson.Root:
+		return rbxapiconv.ToDump(root)
+	}
+	return rbxapiconv.ToDump(genericRoot(root))
+}
+
+func init() {
+	Register(Format{
+		Name:       "json",
+		Extensions: []string{".json"},
+		Sniff: func(prefix []byte) bool {
+			prefix = skipSpace(prefix)
+			return len(prefix) > 0 && prefix[0] == '{'
+		},
+		Codec: JSON,
+	})
+	Register(Format{
+		Name:       "dump",
+		Extensions: []string{".txt", ".dump"},
+		Sniff: func(prefix []byte) bool {
+			prefix = skipSpace(prefix)
+			return len(prefix) == 0 || strings.HasPrefix(string(prefix), "Class ") || strings.HasPrefix(string(prefix), "Enum ")
+		},
+		Codec: Dump,
+	})
+}
@@ -0,0 +1,173 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Compression describes a compression format that may wrap the content of
+// any API format.
+//
+// The "gzip" compression is registered by default. Compressions implemented
+// outside of the standard library, such as zstd, may be registered with
+// RegisterCompression.
+type Compression struct {
+	// Name is the name of the compression.
+	Name string
+	// Extensions is a list of file extensions, including the leading dot,
+	// associated with the compression.
+	Extensions []string
+	// Magic is the prefix with which compressed content begins.
+	Magic []byte
+	// NewReader returns a reader that decompresses the content of r.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+	// NewWriter returns a writer that compresses content written to w. The
+	// writer must be closed to flush the compressed content.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// zstdMagic is the prefix of zstd-compressed content, which is recognized in
+// order to report that no zstd compression is registered.
+var zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+
+var compressions []Compression
+
+// RegisterCompression adds a compression to the registry. A compression
+// registered with the same name as an existing compression replaces it.
+func RegisterCompression(c Compression) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, comp := range compressions {
+		if comp.Name == c.Name {
+			compressions[i] = c
+			return
+		}
+	}
+	compressions = append(compressions, c)
+}
+
+// LookupCompression returns the compression of the given name.
+func LookupCompression(name string) (c Compression, ok bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, comp := range compressions {
+		if comp.Name == name {
+			return comp, true
+		}
+	}
+	return Compression{}, false
+}
+
+// CompressionForPath returns the compression associated with the extension
+// of a file path, such as ".gz".
+func CompressionForPath(path string) (c Compression, ok bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return Compression{}, false
+	}
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, comp := range compressions {
+		for _, e := range comp.Extensions {
+			if e == ext {
+				return comp, true
+			}
+		}
+	}
+	return Compression{}, false
+}
+
+// Decompress returns a reader of the decompressed content of r, detecting the
+// compression from the content. If the content is not compressed, a reader of
+// the content is returned, along with a zero Compression. The caller must
+// close the returned reader.
+func Decompress(r io.Reader) (io.ReadCloser, Compression, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	prefix, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, Compression{}, err
+	}
+	mutex.RLock()
+	var comp Compression
+	for _, c := range compressions {
+		if len(c.Magic) > 0 && bytes.HasPrefix(prefix, c.Magic) {
+			comp = c
+			break
+		}
+	}
+	mutex.RUnlock()
+	if comp.NewReader == nil {
+		if bytes.HasPrefix(prefix, zstdMagic) {
+			return nil, Compression{}, errors.New("zstd compression is not registered")
+		}
+		return ioutil.NopCloser(br), Compression{}, nil
+	}
+	dr, err := comp.NewReader(br)
+	if err != nil {
+		return nil, comp, err
+	}
+	return dr, comp, nil
+}
+
+// Compress returns a writer that compresses content written to w using the
+// compression of the given name. If name is empty, content is written to w
+// as-is. The returned writer must be closed to flush the compressed content,
+// which does not close w.
+func Compress(w io.Writer, name string) (io.WriteCloser, error) {
+	if name == "" {
+		return nopWriteCloser{w}, nil
+	}
+	comp, ok := LookupCompression(name)
+	if !ok {
+		return nil, errors.New("unknown compression \"" + name + "\"")
+	}
+	return comp.NewWriter(w)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// Encode writes root to w using the format of the given name, first
+// converting root to the native type of the format. If compression is not
+// empty, the content is compressed using the compression of that name.
+func Encode(w io.Writer, root rbxapi.Root, name, compression string) error {
+	format, ok := Lookup(name)
+	if !ok {
+		return errors.New("unknown format \"" + name + "\"")
+	}
+	cw, err := Compress(w, compression)
+	if err != nil {
+		return err
+	}
+	if err := format.Codec.Encode(cw, format.Codec.Convert(root)); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+func init() {
+	RegisterCompression(Compression{
+		Name:       "gzip",
+		Extensions: []string{".gz"},
+		Magic:      []byte{0x1F, 0x8B},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+	})
+}
@@ -0,0 +1,259 @@
+// The deprecation package lists the deprecated descriptors of an API
+// structure, along with suggested replacements, and reports changes in
+// deprecation between two structures.
+package deprecation
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"strings"
+)
+
+// Kinds of descriptors.
+const (
+	KindClass    = "Class"
+	KindMember   = "Member"
+	KindEnum     = "Enum"
+	KindEnumItem = "EnumItem"
+)
+
+// Source indicates how the replacement of a deprecated descriptor was
+// determined.
+type Source int
+
+const (
+	NoReplacement Source = iota // No replacement was found.
+	Preferred                   // The preferred descriptor named by the structure.
+	Heuristic                   // A descriptor with a similar name.
+)
+
+// String returns a string representation of the source.
+func (s Source) String() string {
+	switch s {
+	case Preferred:
+		return "Preferred"
+	case Heuristic:
+		return "Heuristic"
+	}
+	return "None"
+}
+
+// Entry describes a deprecated descriptor.
+type Entry struct {
+	// Kind is the kind of descriptor.
+	Kind string
+	// Parent is the name of the class of a member, or the enum of an item.
+	// Empty for classes and enums.
+	Parent string
+	// Name is the name of the descriptor.
+	Name string
+	// Replacement is the name of the descriptor suggested in place of the
+	// deprecated descriptor. Empty if no replacement was found.
+	Replacement string
+	// Source indicates how the replacement was determined.
+	Source Source
+}
+
+// Path returns the qualified name of the descriptor, such as
+// "Instance.findFirstChild".
+func (e Entry) Path() string {
+	if e.Parent == "" {
+		return e.Name
+	}
+	return e.Parent + "." + e.Name
+}
+
+// String returns the kind and qualified name of the descriptor, followed by
+// its replacement, if any.
+func (e Entry) String() string {
+	s := e.Kind + " " + e.Path()
+	if e.Replacement != "" {
+		s += " (use " + e.Replacement + ")"
+	}
+	return s
+}
+
+// isDeprecated returns whether a descriptor is tagged as deprecated. The tag
+// is capitalized in the JSON format, and lowercase in the dump format.
+func isDeprecated(t rbxapi.Taggable) bool {
+	return t.GetTag("Deprecated") || t.GetTag("deprecated")
+}
+
+// preferred returns the name of the preferred descriptor of v, if the
+// structure of v is able to name one.
+func preferred(v interface{}) string {
+	switch v := v.(type) {
+	case *rbxapijson.Class:
+		return v.PreferredDescriptor
+	case *rbxapijson.Property:
+		return v.PreferredDescriptor
+	case *rbxapijson.Function:
+		return v.PreferredDescriptor
+	case *rbxapijson.Event:
+		return v.PreferredDescriptor
+	case *rbxapijson.Callback:
+		return v.PreferredDescriptor
+	case *rbxapijson.Enum:
+		return v.PreferredDescriptor
+	case *rbxapijson.EnumItem:
+		return v.PreferredDescriptor
+	}
+	return ""
+}
+
+// candidates returns names similar to name that may replace a deprecated
+// descriptor: the name with its first letter capitalized, as with
+// "findFirstChild" and "FindFirstChild", and the name with an "Async"
+// suffix.
+func candidates(name string) []string {
+	var list []string
+	if name != "" {
+		if upper := strings.ToUpper(name[:1]) + name[1:]; upper != name {
+			list = append(list, upper)
+		}
+	}
+	if !strings.HasSuffix(name, "Async") {
+		list = append(list, name+"Async")
+	}
+	return list
+}
+
+// guess returns the first candidate for which find returns a descriptor
+// that is not deprecated.
+func guess(name string, find func(name string) rbxapi.Taggable) string {
+	for _, c := range candidates(name) {
+		if t := find(c); t != nil && !isDeprecated(t) {
+			return c
+		}
+	}
+	return ""
+}
+
+// replacement sets the replacement of an entry.
+func (e *Entry) replacement(desc interface{}, find func(name string) rbxapi.Taggable) {
+	if e.Replacement = preferred(desc); e.Replacement != "" {
+		e.Source = Preferred
+	} else if e.Replacement = guess(e.Name, find); e.Replacement != "" {
+		e.Source = Heuristic
+	}
+}
+
+// List returns the deprecated descriptors of root, in the order of root.
+// Members of a deprecated class are not listed individually. Replacements of
+// members are searched for in the class of the member and its superclasses.
+func List(root rbxapi.Root) []Entry {
+	var entries []Entry
+	rbxapi.RangeClasses(root, func(class rbxapi.Class) bool {
+		if isDeprecated(class) {
+			e := Entry{Kind: KindClass, Name: class.GetName()}
+			e.replacement(class, func(name string) rbxapi.Taggable {
+				if c := root.GetClass(name); c != nil {
+					return c
+				}
+				return nil
+			})
+			entries = append(entries, e)
+			return true
+		}
+		rbxapi.RangeMembers(class, func(member rbxapi.Member) bool {
+			if !isDeprecated(member) {
+				return true
+			}
+			e := Entry{Kind: KindMember, Parent: class.GetName(), Name: member.GetName()}
+			e.replacement(member, func(name string) rbxapi.Taggable {
+				if m := rbxapi.GetMemberInherited(root, class.GetName(), name); m != nil {
+					return m
+				}
+				return nil
+			})
+			entries = append(entries, e)
+			return true
+		})
+		return true
+	})
+	rbxapi.RangeEnums(root, func(enum rbxapi.Enum) bool {
+		if isDeprecated(enum) {
+			e := Entry{Kind: KindEnum, Name: enum.GetName()}
+			e.replacement(enum, func(name string) rbxapi.Taggable {
+				if e := root.GetEnum(name); e != nil {
+					return e
+				}
+				return nil
+			})
+			entries = append(entries, e)
+			return true
+		}
+		rbxapi.RangeEnumItems(enum, func(item rbxapi.EnumItem) bool {
+			if !isDeprecated(item) {
+				return true
+			}
+			e := Entry{Kind: KindEnumItem, Parent: enum.GetName(), Name: item.GetName()}
+			e.replacement(item, func(name string) rbxapi.Taggable {
+				if i := enum.GetEnumItem(name); i != nil {
+					return i
+				}
+				return nil
+			})
+			entries = append(entries, e)
+			return true
+		})
+		return true
+	})
+	return entries
+}
+
+// status returns whether the descriptor of an entry is present in root, and
+// whether it is deprecated, either itself or through its class or enum.
+func (e Entry) status(root rbxapi.Root) (exists, deprecated bool) {
+	switch e.Kind {
+	case KindClass:
+		if class := root.GetClass(e.Name); class != nil {
+			return true, isDeprecated(class)
+		}
+	case KindMember:
+		if class := root.GetClass(e.Parent); class != nil {
+			if member := class.GetMember(e.Name); member != nil {
+				return true, isDeprecated(class) || isDeprecated(member)
+			}
+		}
+	case KindEnum:
+		if enum := root.GetEnum(e.Name); enum != nil {
+			return true, isDeprecated(enum)
+		}
+	case KindEnumItem:
+		if enum := root.GetEnum(e.Parent); enum != nil {
+			if item := enum.GetEnumItem(e.Name); item != nil {
+				return true, isDeprecated(enum) || isDeprecated(item)
+			}
+		}
+	}
+	return false, false
+}
+
+// Report describes the changes in deprecation between two structures.
+type Report struct {
+	// Deprecated lists descriptors present in both structures that became
+	// deprecated, as described by the next structure.
+	Deprecated []Entry
+	// Undeprecated lists descriptors present in both structures that are no
+	// longer deprecated, as described by the previous structure.
+	Undeprecated []Entry
+}
+
+// Diff compares the deprecated descriptors of prev and next. Descriptors that
+// were added or removed are not reported, nor are members of a class, or
+// items of an enum, that was deprecated or undeprecated along with them.
+func Diff(prev, next rbxapi.Root) *Report {
+	report := &Report{}
+	for _, e := range List(next) {
+		if exists, deprecated := e.status(prev); exists && !deprecated {
+			report.Deprecated = append(report.Deprecated, e)
+		}
+	}
+	for _, e := range List(prev) {
+		if exists, deprecated := e.status(next); exists && !deprecated {
+			report.Undeprecated = append(report.Undeprecated, e)
+		}
+	}
+	return report
+}
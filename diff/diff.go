@@ -1,9 +1,21 @@
 // The diff package provides an implementation of the patch package for the
 // generic rbxapi types.
+//
+// Diff finds the differences between two Root values, covering the addition
+// and removal of classes, members, enums, and enum items, as well as changes
+// to each of their fields. The resulting actions can be passed directly to
+// the Patch method of a Root that implements patch.Patcher:
+//
+//	actions := (&diff.Diff{Prev: prev, Next: next}).Diff()
+//	prev.(patch.Patcher).Patch(actions)
+//
+// Only fields exposed by the rbxapi interfaces are compared. Implementations
+// with additional fields, such as rbxapijson, provide their own Differ.
 package diff
 
 import (
 	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/internal/index"
 	"github.com/karl-police/rbxapi/patch"
 )
 
@@ -26,6 +38,12 @@ neq:
 	return false, p, n
 }
 
+// equalTypes returns whether two types have the same name and category. This
+// allows types of different implementations to be compared.
+func equalTypes(a, b rbxapi.Type) bool {
+	return a.GetName() == b.GetName() && a.GetCategory() == b.GetCategory()
+}
+
 // compareAndCopyParameters compares two parameter lists, and return copies if
 // they are not equal.
 func compareAndCopyParameters(prev, next rbxapi.Parameters) (eq bool, p, n rbxapi.Parameters) {
@@ -37,7 +55,7 @@ func compareAndCopyParameters(prev, next rbxapi.Parameters) (eq bool, p, n rbxap
 	for i := 0; i < plen; i++ {
 		pparam := prev.GetParameter(i)
 		nparam := next.GetParameter(i)
-		if nparam.GetType() != pparam.GetType() {
+		if !equalTypes(nparam.GetType(), pparam.GetType()) {
 			goto neq
 		}
 		if nparam.GetName() != pparam.GetName() {
@@ -73,9 +91,10 @@ func (d *Diff) Diff() (actions []patch.Action) {
 					actions = append(actions, &ClassAction{Type: patch.Remove, Class: p})
 				}
 			} else {
+				next := index.Classes(d.Next)
 				for _, p := range classes {
 					names[p.GetName()] = struct{}{}
-					n := d.Next.GetClass(p.GetName())
+					n := next[p.GetName()]
 					if n == nil {
 						actions = append(actions, &ClassAction{Type: patch.Remove, Class: p})
 						continue
@@ -103,9 +122,10 @@ func (d *Diff) Diff() (actions []patch.Action) {
 					actions = append(actions, &EnumAction{Type: patch.Remove, Enum: p})
 				}
 			} else {
+				next := index.Enums(d.Next)
 				for _, p := range enums {
 					names[p.GetName()] = struct{}{}
-					n := d.Next.GetEnum(p.GetName())
+					n := next[p.GetName()]
 					if n == nil {
 						actions = append(actions, &EnumAction{Type: patch.Remove, Enum: p})
 						continue
@@ -156,45 +176,50 @@ func (d *DiffClass) Diff() (actions []patch.Action) {
 	if !d.ExcludeMembers {
 		members := d.Prev.GetMembers()
 		names := make(map[string]struct{}, len(members))
+		getMember := d.Next.GetMember
+		if len(members) >= index.MinLen {
+			next := index.Members(d.Next)
+			getMember = func(name string) rbxapi.Member { return next[name] }
+		}
 		for _, p := range members {
 			names[p.GetName()] = struct{}{}
-			n := d.Next.GetMember(p.GetName())
+			n := getMember(p.GetName())
 			if n == nil {
 				actions = append(actions, &MemberAction{Type: patch.Remove, Class: d.Prev, Member: p})
 				continue
 			}
 			switch p.GetMemberType() {
 			case "Property":
-				if p, ok := n.(rbxapi.Property); ok {
-					if n, ok := n.(rbxapi.Property); ok {
+				if p, ok := p.(rbxapi.Property); ok {
+					if n, ok := n.(rbxapi.Property); ok && n.GetMemberType() == "Property" {
 						actions = append(actions, (&DiffProperty{d.Prev, p, n}).Diff()...)
 						continue
 					}
 				}
 			case "Function":
-				if p, ok := n.(rbxapi.Function); ok {
-					if n, ok := n.(rbxapi.Function); ok {
+				if p, ok := p.(rbxapi.Function); ok {
+					if n, ok := n.(rbxapi.Function); ok && n.GetMemberType() == "Function" {
 						actions = append(actions, (&DiffFunction{d.Prev, p, n}).Diff()...)
 						continue
 					}
 				}
 			case "Event":
-				if p, ok := n.(rbxapi.Event); ok {
-					if n, ok := n.(rbxapi.Event); ok {
+				if p, ok := p.(rbxapi.Event); ok {
+					if n, ok := n.(rbxapi.Event); ok && n.GetMemberType() == "Event" {
 						actions = append(actions, (&DiffEvent{d.Prev, p, n}).Diff()...)
 						continue
 					}
 				}
 			case "Callback":
-				if p, ok := n.(rbxapi.Callback); ok {
-					if n, ok := n.(rbxapi.Callback); ok {
+				if p, ok := p.(rbxapi.Callback); ok {
+					if n, ok := n.(rbxapi.Callback); ok && n.GetMemberType() == "Callback" {
 						actions = append(actions, (&DiffCallback{d.Prev, p, n}).Diff()...)
 						continue
 					}
 				}
 			}
 			actions = append(actions, &MemberAction{Type: patch.Remove, Class: d.Prev, Member: p})
-			actions = append(actions, &MemberAction{Type: patch.Add, Class: d.Prev, Member: p})
+			actions = append(actions, &MemberAction{Type: patch.Add, Class: d.Prev, Member: n})
 		}
 		for _, n := range d.Next.GetMembers() {
 			if _, ok := names[n.GetName()]; !ok {
@@ -227,7 +252,7 @@ func (d *DiffProperty) Diff() (actions []patch.Action) {
 	if p, n := (d.Prev.GetName()), d.Next.GetName(); p != n {
 		actions = append(actions, &MemberAction{patch.Change, d.Class, d.Prev, "Name", p, n})
 	}
-	if p, n := (d.Prev.GetValueType()), d.Next.GetValueType(); p != n {
+	if p, n := (d.Prev.GetValueType()), d.Next.GetValueType(); !equalTypes(p, n) {
 		actions = append(actions, &MemberAction{patch.Change, d.Class, d.Prev, "ValueType", p, n})
 	}
 	pr, pw := d.Prev.GetSecurity()
@@ -269,7 +294,7 @@ func (d *DiffFunction) Diff() (actions []patch.Action) {
 	if eq, p, n := compareAndCopyParameters(d.Prev.GetParameters(), d.Next.GetParameters()); !eq {
 		actions = append(actions, &MemberAction{patch.Change, d.Class, d.Prev, "Parameters", p, n})
 	}
-	if p, n := (d.Prev.GetReturnType()), d.Next.GetReturnType(); p != n {
+	if p, n := (d.Prev.GetReturnType()), d.Next.GetReturnType(); !equalTypes(p, n) {
 		actions = append(actions, &MemberAction{patch.Change, d.Class, d.Prev, "ReturnType", p, n})
 	}
 	if p, n := (d.Prev.GetSecurity()), d.Next.GetSecurity(); p != n {
@@ -340,7 +365,7 @@ func (d *DiffCallback) Diff() (actions []patch.Action) {
 	if eq, p, n := compareAndCopyParameters(d.Prev.GetParameters(), d.Next.GetParameters()); !eq {
 		actions = append(actions, &MemberAction{patch.Change, d.Class, d.Prev, "Parameters", p, n})
 	}
-	if p, n := (d.Prev.GetReturnType()), d.Next.GetReturnType(); p != n {
+	if p, n := (d.Prev.GetReturnType()), d.Next.GetReturnType(); !equalTypes(p, n) {
 		actions = append(actions, &MemberAction{patch.Change, d.Class, d.Prev, "ReturnType", p, n})
 	}
 	if p, n := (d.Prev.GetSecurity()), d.Next.GetSecurity(); p != n {
@@ -380,9 +405,14 @@ func (d *DiffEnum) Diff() (actions []patch.Action) {
 	if !d.ExcludeEnumItems {
 		items := d.Prev.GetEnumItems()
 		names := make(map[string]struct{}, len(items))
+		getEnumItem := d.Next.GetEnumItem
+		if len(items) >= index.MinLen {
+			next := index.EnumItems(d.Next)
+			getEnumItem = func(name string) rbxapi.EnumItem { return next[name] }
+		}
 		for _, p := range items {
 			names[p.GetName()] = struct{}{}
-			n := d.Next.GetEnumItem(p.GetName())
+			n := getEnumItem(p.GetName())
 			if n == nil {
 				actions = append(actions, &EnumItemAction{Type: patch.Remove, Enum: d.Prev, EnumItem: p})
 				continue
@@ -0,0 +1,16 @@
+package diff_test
+
+import (
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"testing"
+)
+
+func BenchmarkDiff(b *testing.B) {
+	prev, next := apitest.Builds(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		(&diff.Diff{Prev: prev, Next: next}).Diff()
+	}
+}
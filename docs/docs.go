@@ -0,0 +1,86 @@
+// The docs package is used to represent the API documentation published by
+// Roblox alongside each API dump.
+//
+// Documentation is distributed as a JSON object per locale (e.g.
+// "en-us.json"), mapping keys such as "@roblox/globaltype/Instance.Name" to
+// entries. Join associates the entries with the descriptors of an API
+// structure.
+package docs
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Key prefixes used by documentation entries.
+const (
+	GlobalTypePrefix = "@roblox/globaltype/"
+	EnumPrefix       = "@roblox/enum/"
+)
+
+// Docs maps documentation keys to entries.
+type Docs map[string]*Entry
+
+// Entry represents the documentation of a single descriptor.
+type Entry struct {
+	// Documentation is the description of the descriptor, in Markdown.
+	Documentation string `json:"documentation"`
+	// LearnMoreLink is a URL pointing to further documentation.
+	LearnMoreLink string `json:"learn_more_link,omitempty"`
+	// CodeSample is the key of a code sample associated with the descriptor.
+	CodeSample string `json:"code_sample,omitempty"`
+	// Keys maps the names of members of a class or items of an enum to the
+	// keys of their entries.
+	Keys map[string]string `json:"keys,omitempty"`
+	// Params describes the parameters of a function, event, or callback. The
+	// Documentation field of each parameter refers to the key of an entry.
+	Params []Param `json:"params,omitempty"`
+	// Returns contains the keys of entries describing the values returned by
+	// a function or callback.
+	Returns []string `json:"returns,omitempty"`
+}
+
+// Param describes a parameter of a documented member.
+type Param struct {
+	Name          string `json:"name"`
+	Documentation string `json:"documentation"`
+}
+
+// Decode parses API documentation from r in JSON format.
+func Decode(r io.Reader) (docs Docs, err error) {
+	err = json.NewDecoder(r).Decode(&docs)
+	return docs, err
+}
+
+// Encode writes docs to w in JSON format.
+func Encode(w io.Writer, docs Docs) error {
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	je.SetEscapeHTML(false)
+	return je.Encode(docs)
+}
+
+// Get returns the entry of the given key, or nil if no such entry exists.
+func (docs Docs) Get(key string) *Entry {
+	return docs[key]
+}
+
+// ClassKey returns the key of the entry documenting a class.
+func ClassKey(class string) string {
+	return GlobalTypePrefix + class
+}
+
+// MemberKey returns the key of the entry documenting a member of a class.
+func MemberKey(class, member string) string {
+	return GlobalTypePrefix + class + "." + member
+}
+
+// EnumKey returns the key of the entry documenting an enum.
+func EnumKey(enum string) string {
+	return EnumPrefix + enum
+}
+
+// EnumItemKey returns the key of the entry documenting an item of an enum.
+func EnumItemKey(enum, item string) string {
+	return EnumPrefix + enum + "." + item
+}
@@ -0,0 +1,157 @@
+package docs
+
+import (
+	"github.com/karl-police/rbxapi"
+)
+
+// Doc is the documentation of a single descriptor, with references to other
+// entries resolved.
+type Doc struct {
+	// Key is the key of the entry from which the documentation was taken.
+	Key string
+	// Documentation is the description of the descriptor, in Markdown.
+	Documentation string
+	// LearnMoreLink is a URL pointing to further documentation.
+	LearnMoreLink string
+	// CodeSample is the key of a code sample associated with the descriptor.
+	CodeSample string
+	// Params describes the parameters of a function, event, or callback. The
+	// Documentation field of each parameter contains the description of the
+	// parameter.
+	Params []Param
+	// Returns contains the descriptions of the values returned by a function
+	// or callback.
+	Returns []string
+}
+
+// Joined is a side table that associates the descriptors of an API structure
+// with their documentation. Descriptors without documentation are absent.
+type Joined struct {
+	Classes   map[string]*Doc
+	Members   map[string]map[string]*Doc
+	Enums     map[string]*Doc
+	EnumItems map[string]map[string]*Doc
+}
+
+// Class returns the documentation of a class, or nil if the class is not
+// documented.
+func (j *Joined) Class(class string) *Doc {
+	return j.Classes[class]
+}
+
+// Member returns the documentation of a member of a class, or nil if the
+// member is not documented.
+func (j *Joined) Member(class, member string) *Doc {
+	return j.Members[class][member]
+}
+
+// Enum returns the documentation of an enum, or nil if the enum is not
+// documented.
+func (j *Joined) Enum(enum string) *Doc {
+	return j.Enums[enum]
+}
+
+// EnumItem returns the documentation of an item of an enum, or nil if the item
+// is not documented.
+func (j *Joined) EnumItem(enum, item string) *Doc {
+	return j.EnumItems[enum][item]
+}
+
+// text returns the description of the entry of the given key. If no such entry
+// exists, the key itself is returned, since some entries contain descriptions
+// in place of keys.
+func (docs Docs) text(key string) string {
+	if entry := docs[key]; entry != nil {
+		return entry.Documentation
+	}
+	return key
+}
+
+// doc returns the resolved documentation of the entry of the given key, or nil
+// if no such entry exists.
+func (docs Docs) doc(key string) *Doc {
+	entry := docs[key]
+	if entry == nil {
+		return nil
+	}
+	d := &Doc{
+		Key:           key,
+		Documentation: entry.Documentation,
+		LearnMoreLink: entry.LearnMoreLink,
+		CodeSample:    entry.CodeSample,
+	}
+	if len(entry.Params) > 0 {
+		d.Params = make([]Param, len(entry.Params))
+		for i, p := range entry.Params {
+			d.Params[i] = Param{Name: p.Name, Documentation: docs.text(p.Documentation)}
+		}
+	}
+	if len(entry.Returns) > 0 {
+		d.Returns = make([]string, len(entry.Returns))
+		for i, key := range entry.Returns {
+			d.Returns[i] = docs.text(key)
+		}
+	}
+	return d
+}
+
+// child returns the resolved documentation of a member or enum item. The
+// default key is used, falling back to the key listed by the entry of the
+// parent.
+func (docs Docs) child(parent *Doc, key, name string) *Doc {
+	if d := docs.doc(key); d != nil {
+		return d
+	}
+	if parent == nil {
+		return nil
+	}
+	if key, ok := docs[parent.Key].Keys[name]; ok {
+		return docs.doc(key)
+	}
+	return nil
+}
+
+// Join associates the descriptors of root with their entries in docs.
+func Join(root rbxapi.Root, docs Docs) *Joined {
+	j := &Joined{
+		Classes:   map[string]*Doc{},
+		Members:   map[string]map[string]*Doc{},
+		Enums:     map[string]*Doc{},
+		EnumItems: map[string]map[string]*Doc{},
+	}
+	rbxapi.RangeClasses(root, func(class rbxapi.Class) bool {
+		name := class.GetName()
+		d := docs.doc(ClassKey(name))
+		if d != nil {
+			j.Classes[name] = d
+		}
+		rbxapi.RangeMembers(class, func(member rbxapi.Member) bool {
+			if m := docs.child(d, MemberKey(name, member.GetName()), member.GetName()); m != nil {
+				if j.Members[name] == nil {
+					j.Members[name] = map[string]*Doc{}
+				}
+				j.Members[name][member.GetName()] = m
+			}
+			return true
+		})
+		return true
+	})
+	rbxapi.RangeEnums(root, func(enum rbxapi.Enum) bool {
+		name := enum.GetName()
+		d := docs.doc(EnumKey(name))
+		if d != nil {
+			j.Enums[name] = d
+		}
+		rbxapi.RangeEnumItems(enum, func(item rbxapi.EnumItem) bool {
+			if i := docs.child(d, EnumItemKey(name, item.GetName()), item.GetName()); i != nil {
+				if j.EnumItems[name] == nil {
+					j.EnumItems[name] = map[string]*Doc{}
+				}
+				j.EnumItems[name][item.GetName()] = i
+			}
+			return true
+		})
+		return true
+	})
+	return j
+}
@@ -0,0 +1,195 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNotCached is returned by a Cache when a file is not present.
+var ErrNotCached = errors.New("file not cached")
+
+// Cache stores files retrieved by a Client, so that repeated requests for the
+// same file of a build do not download it again. Files are identified by the
+// GUID of their version and their name, such as "API-Dump.json".
+//
+// The DirCache type implements a Cache on the local file system. Other
+// backends can be provided by implementing this interface.
+type Cache interface {
+	// Get returns a reader of the content of the given file, along with the
+	// checksum recorded when the file was stored. Returns ErrNotCached if the
+	// file is not present. The caller must close the returned reader.
+	Get(ctx context.Context, guid, name string) (io.ReadCloser, Checksum, error)
+
+	// Put stores the content read from r as the given file, with the given
+	// checksum, replacing any existing content.
+	Put(ctx context.Context, guid, name string, sum Checksum, r io.Reader) error
+
+	// Delete removes the given file. Returns ErrNotCached if the file is not
+	// present.
+	Delete(ctx context.Context, guid, name string) error
+}
+
+// getFile returns the content of a file of the given version. If the Client
+// has a Cache, the content is retrieved from the cache when present, and is
+// added to the cache when downloaded. Cached content that does not match its
+// recorded checksum is discarded and downloaded again. Failures to update the
+// cache are ignored.
+func (c *Client) getFile(ctx context.Context, v Version, name string) (io.ReadCloser, error) {
+	if c.Cache == nil {
+		return c.get(ctx, c.URL(v.Channel, v.GUID, name))
+	}
+	r, sum, err := c.Cache.Get(ctx, v.GUID, name)
+	switch err {
+	case nil:
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		if checksumOf(b) == sum {
+			return ioutil.NopCloser(bytes.NewReader(b)), nil
+		}
+		c.Cache.Delete(ctx, v.GUID, name)
+	case ErrNotCached:
+	default:
+		return nil, err
+	}
+	body, err := c.get(ctx, c.URL(v.Channel, v.GUID, name))
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.Cache.Put(ctx, v.GUID, name, checksumOf(b), bytes.NewReader(b))
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+// checksumOf returns the checksum of b.
+func checksumOf(b []byte) Checksum {
+	h := sha256.Sum256(b)
+	return Checksum{Size: int64(len(b)), SHA256: hex.EncodeToString(h[:])}
+}
+
+// sumSuffix is appended to the path of a cached file to produce the path of
+// the file containing its checksum.
+const sumSuffix = ".sum"
+
+// DirCache is a Cache that keeps files within a directory on the local file
+// system. Each file is located at "<GUID>/<name>", with its checksum stored
+// alongside it in a file with an additional ".sum" extension.
+type DirCache string
+
+func (dir DirCache) path(guid, name string) (string, error) {
+	key := guid + "/" + name
+	for _, elem := range strings.Split(key, "/") {
+		switch elem {
+		case "", ".", "..":
+			return "", errors.New("invalid cache file \"" + key + "\"")
+		}
+	}
+	return filepath.Join(string(dir), filepath.FromSlash(key)), nil
+}
+
+// Get implements the Cache interface.
+func (dir DirCache) Get(ctx context.Context, guid, name string) (io.ReadCloser, Checksum, error) {
+	var sum Checksum
+	path, err := dir.path(guid, name)
+	if err != nil {
+		return nil, sum, err
+	}
+	b, err := ioutil.ReadFile(path + sumSuffix)
+	if os.IsNotExist(err) {
+		return nil, sum, ErrNotCached
+	} else if err != nil {
+		return nil, sum, err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) != 2 {
+		return nil, sum, errors.New(path + sumSuffix + ": malformed checksum")
+	}
+	if sum.Size, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return nil, sum, errors.New(path + sumSuffix + ": malformed checksum")
+	}
+	sum.SHA256 = fields[1]
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, sum, ErrNotCached
+	}
+	return f, sum, err
+}
+
+// writeFile writes the content of r to path. Content is written to a
+// temporary file which replaces the destination only after all data has been
+// written.
+func writeFile(path string, r io.Reader) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Put implements the Cache interface. The checksum is written after the
+// content, so that an interrupted Put leaves no file with a stale checksum.
+func (dir DirCache) Put(ctx context.Context, guid, name string, sum Checksum, r io.Reader) error {
+	path, err := dir.path(guid, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(path + sumSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := writeFile(path, r); err != nil {
+		return err
+	}
+	s := strconv.FormatInt(sum.Size, 10) + " " + sum.SHA256 + "\n"
+	return writeFile(path+sumSuffix, strings.NewReader(s))
+}
+
+// Delete implements the Cache interface.
+func (dir DirCache) Delete(ctx context.Context, guid, name string) error {
+	path, err := dir.path(guid, name)
+	if err != nil {
+		return err
+	}
+	errSum := os.Remove(path + sumSuffix)
+	err = os.Remove(path)
+	if os.IsNotExist(err) && os.IsNotExist(errSum) {
+		return ErrNotCached
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if errSum != nil && !os.IsNotExist(errSum) {
+		return errSum
+	}
+	return nil
+}
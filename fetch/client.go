@@ -0,0 +1,289 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/karl-police/rbxapi/docs"
+	"github.com/karl-police/rbxapi/fflag"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDeployURL is the base URL from which builds are downloaded when
+	// Client.DeployURL is empty.
+	DefaultDeployURL = "https://setup.rbxcdn.com"
+	// DefaultVersionURL is the base URL used to query the current version of
+	// a channel when Client.VersionURL is empty.
+	DefaultVersionURL = "https://clientsettings.roblox.com/v2/client-version"
+	// DefaultType is the binary type queried when Client.Type is empty.
+	DefaultType = WindowsStudio64
+	// DefaultSettingsURL is the base URL used to retrieve fast flags when
+	// Client.SettingsURL is empty.
+	DefaultSettingsURL = "https://clientsettingscdn.roblox.com/v2/settings/application"
+	// DefaultLocale is the locale of API documentation retrieved when no
+	// locale is specified.
+	DefaultLocale = "en-us"
+)
+
+// Names of files distributed with each deployment.
+const (
+	// JSONDumpFile is the API dump in JSON format.
+	JSONDumpFile = "API-Dump.json"
+	// DumpFile is the legacy text API dump, distributed by older
+	// deployments.
+	DumpFile = "API-Dump.txt"
+)
+
+// RetryDelay is the base duration waited between failed attempts to complete
+// a request. The delay is multiplied by the number of the failed attempt.
+const RetryDelay = 500 * time.Millisecond
+
+// StatusError is returned when a request receives an unsuccessful response.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (err *StatusError) Error() string {
+	return "GET " + err.URL + ": " + strconv.Itoa(err.StatusCode) + " " + http.StatusText(err.StatusCode)
+}
+
+// Client retrieves data from Roblox deployment servers. The zero value is
+// ready to use.
+type Client struct {
+	// Client is the HTTP client used to make requests. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+	// DeployURL is the base URL from which builds are downloaded.
+	DeployURL string
+	// VersionURL is the base URL used to query the current version of a
+	// channel.
+	VersionURL string
+	// SettingsURL is the base URL used to retrieve fast flags.
+	SettingsURL string
+	// Type is the binary type for which versions are queried.
+	Type BinaryType
+	// Attempts is the maximum number of attempts made to complete a request
+	// or download. If zero, DefaultAttempts is used.
+	Attempts int
+	// Cache, if not nil, stores the API dumps and documentation retrieved
+	// by the client, which are then retrieved from the cache when requested
+	// again.
+	Cache Cache
+}
+
+// DefaultClient is the Client used by package-level functions.
+var DefaultClient = &Client{}
+
+func (c *Client) httpClient() *http.Client {
+	if c.Client == nil {
+		return http.DefaultClient
+	}
+	return c.Client
+}
+
+func (c *Client) binaryType() BinaryType {
+	if c.Type == "" {
+		return DefaultType
+	}
+	return c.Type
+}
+
+// URL returns the location of a file belonging to a deployment. For example,
+// the file "API-Dump.json" of a version is located at
+// "<DeployURL>/<GUID>-API-Dump.json". Deployments of non-LIVE channels are
+// located under "<DeployURL>/channel/<channel>".
+func (c *Client) URL(channel, guid, file string) string {
+	u := c.DeployURL
+	if u == "" {
+		u = DefaultDeployURL
+	}
+	u = strings.TrimSuffix(u, "/")
+	if !IsLive(channel) {
+		u += "/channel/" + strings.ToLower(channel)
+	}
+	return u + "/" + guid + "-" + file
+}
+
+// temporary returns whether a request that failed with err may succeed if
+// attempted again.
+func temporary(err error) bool {
+	if err, ok := err.(*StatusError); ok {
+		return err.StatusCode >= 500 || err.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// get performs a GET request, returning the body of a successful response.
+// Requests that fail due to network errors or server errors are attempted
+// again, up to the number of attempts configured by Client.Attempts.
+func (c *Client) get(ctx context.Context, url string) (body io.ReadCloser, err error) {
+	for attempt := 1; ; attempt++ {
+		if body, err = c.getOnce(ctx, url); err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil || attempt >= c.attempts() || !temporary(err) {
+			return nil, err
+		}
+		t := time.NewTimer(time.Duration(attempt) * RetryDelay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, err
+		case <-t.C:
+		}
+	}
+}
+
+// getOnce performs a single GET request.
+func (c *Client) getOnce(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, &StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+// Latest returns the version currently deployed to the given channel.
+func (c *Client) Latest(ctx context.Context, channel string) (v Version, err error) {
+	u := c.VersionURL
+	if u == "" {
+		u = DefaultVersionURL
+	}
+	u = strings.TrimSuffix(u, "/") + "/" + string(c.binaryType())
+	if !IsLive(channel) {
+		u += "/channel/" + channel
+	}
+	body, err := c.get(ctx, u)
+	if err != nil {
+		return Version{}, err
+	}
+	defer body.Close()
+	var r struct {
+		Version             string `json:"version"`
+		ClientVersionUpload string `json:"clientVersionUpload"`
+	}
+	if err := json.NewDecoder(body).Decode(&r); err != nil {
+		return Version{}, err
+	}
+	if v.GUID, err = ParseGUID(r.ClientVersionUpload); err != nil {
+		return Version{}, err
+	}
+	if v.Number, err = ParseNumber(r.Version); err != nil {
+		return Version{}, err
+	}
+	v.Channel = channel
+	v.Type = c.binaryType()
+	return v, nil
+}
+
+// JSONDump downloads and decodes the JSON API dump of the given version.
+func (c *Client) JSONDump(ctx context.Context, v Version) (*rbxapijson.Root, error) {
+	body, err := c.getFile(ctx, v, JSONDumpFile)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return rbxapijson.Decode(body)
+}
+
+// Dump downloads and decodes the legacy text API dump of the given version.
+// Only older deployments distribute this file.
+func (c *Client) Dump(ctx context.Context, v Version) (*rbxapidump.Root, error) {
+	body, err := c.getFile(ctx, v, DumpFile)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return rbxapidump.Decode(body)
+}
+
+// Docs downloads and decodes the API documentation of the given version, in
+// the given locale. If locale is empty, DefaultLocale is used.
+func (c *Client) Docs(ctx context.Context, v Version, locale string) (docs.Docs, error) {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	body, err := c.getFile(ctx, v, "api-docs/"+strings.ToLower(locale)+".json")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return docs.Decode(body)
+}
+
+// LatestDocs downloads and decodes the API documentation of the version
+// currently deployed to the given channel.
+func (c *Client) LatestDocs(ctx context.Context, channel, locale string) (docs.Docs, Version, error) {
+	v, err := c.Latest(ctx, channel)
+	if err != nil {
+		return nil, Version{}, err
+	}
+	d, err := c.Docs(ctx, v, locale)
+	return d, v, err
+}
+
+// FastFlags downloads and decodes the fast flags of the given application,
+// such as "PCStudioApp" or "PCDesktopClient".
+func (c *Client) FastFlags(ctx context.Context, app string) (fflag.Flags, error) {
+	u := c.SettingsURL
+	if u == "" {
+		u = DefaultSettingsURL
+	}
+	body, err := c.get(ctx, strings.TrimSuffix(u, "/")+"/"+app)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return fflag.Decode(body)
+}
+
+// Latest returns the version currently deployed to the given channel using
+// DefaultClient.
+func Latest(ctx context.Context, channel string) (Version, error) {
+	return DefaultClient.Latest(ctx, channel)
+}
+
+// JSONDump downloads and decodes the JSON API dump of the given version using
+// DefaultClient.
+func JSONDump(ctx context.Context, v Version) (*rbxapijson.Root, error) {
+	return DefaultClient.JSONDump(ctx, v)
+}
+
+// Dump downloads and decodes the legacy text API dump of the given version
+// using DefaultClient.
+func Dump(ctx context.Context, v Version) (*rbxapidump.Root, error) {
+	return DefaultClient.Dump(ctx, v)
+}
+
+// Docs downloads and decodes the API documentation of the given version using
+// DefaultClient.
+func Docs(ctx context.Context, v Version, locale string) (docs.Docs, error) {
+	return DefaultClient.Docs(ctx, v, locale)
+}
+
+// LatestDocs downloads and decodes the API documentation of the version
+// currently deployed to the given channel using DefaultClient.
+func LatestDocs(ctx context.Context, channel, locale string) (docs.Docs, Version, error) {
+	return DefaultClient.LatestDocs(ctx, channel, locale)
+}
+
+// FastFlags downloads and decodes the fast flags of the given application
+// using DefaultClient.
+func FastFlags(ctx context.Context, app string) (fflag.Flags, error) {
+	return DefaultClient.FastFlags(ctx, app)
+}
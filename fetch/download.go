@@ -0,0 +1,249 @@
+package fetch
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultAttempts is the number of attempts made to complete a download when
+// Client.Attempts is zero.
+const DefaultAttempts = 3
+
+// partSuffix is appended to the path of a file while it is being downloaded.
+const partSuffix = ".part"
+
+// Checksum describes the content of a downloaded file.
+type Checksum struct {
+	// Size is the length of the content, in bytes.
+	Size int64
+	// SHA256 is the hex-encoded SHA-256 hash of the content.
+	SHA256 string
+}
+
+// IntegrityError is returned when downloaded content does not match what was
+// expected.
+type IntegrityError struct {
+	URL      string
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (err *IntegrityError) Error() string {
+	return "GET " + err.URL + ": " + err.Field + " mismatch: expected " + err.Expected + ", got " + err.Actual
+}
+
+func (c *Client) attempts() int {
+	if c.Attempts <= 0 {
+		return DefaultAttempts
+	}
+	return c.Attempts
+}
+
+// Download retrieves a file of the given version, writing it to path.
+//
+// Content is first written to path with a ".part" suffix. If such a file
+// already exists, such as from an interrupted download, the download is
+// resumed from the end of the file using an HTTP range request. Likewise, if
+// the connection is interrupted, the download is resumed, up to the number of
+// attempts configured by Client.Attempts. The partial file is renamed to path
+// only once the download is complete and verified.
+//
+// The completed content is verified against the length reported by the
+// server, and, when the server reports an MD5 ETag, against its MD5 hash. If
+// expected is not empty, the content must also have the given SHA-256 hash.
+func (c *Client) Download(ctx context.Context, v Version, name, path, expected string) (sum Checksum, err error) {
+	url := c.URL(v.Channel, v.GUID, name)
+	part := path + partSuffix
+	f, err := os.OpenFile(part, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return sum, err
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	var total int64 = -1
+	var etag string
+	for attempt := 1; ; attempt++ {
+		offset, err := f.Seek(0, io.SeekEnd)
+		if err != nil {
+			return sum, err
+		}
+		if total >= 0 && offset == total {
+			break
+		}
+		var done bool
+		done, total, etag, err = c.downloadRange(ctx, url, f, offset, etag)
+		if done {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil || attempt >= c.attempts() {
+				return sum, err
+			}
+			if _, ok := err.(*StatusError); ok {
+				return sum, err
+			}
+		}
+	}
+
+	if sum, err = c.verify(url, f, total, etag, expected); err != nil {
+		// Corrupt content cannot be resumed, so start over next time.
+		f.Close()
+		f = nil
+		os.Remove(part)
+		return sum, err
+	}
+	if err = f.Close(); err != nil {
+		return sum, err
+	}
+	f = nil
+	return sum, os.Rename(part, path)
+}
+
+// downloadRange requests the content of url from the given offset, appending
+// it to f. Returns whether the content is complete, the total length of the
+// content, or -1 if unknown, and the ETag of the content.
+func (c *Client) downloadRange(ctx context.Context, url string, f *os.File, offset int64, etag string) (done bool, total int64, tag string, err error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, -1, etag, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		if etag != "" {
+			req.Header.Set("If-Range", etag)
+		}
+	}
+	resp, err := c.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return false, -1, etag, err
+	}
+	defer resp.Body.Close()
+	tag = resp.Header.Get("ETag")
+	total = -1
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Server sent the entire content; discard partial data.
+		if err := f.Truncate(0); err != nil {
+			return false, -1, tag, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return false, -1, tag, err
+		}
+		total = resp.ContentLength
+	case http.StatusPartialContent:
+		start, length, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return false, -1, tag, errors.New("GET " + url + ": unexpected Content-Range")
+		}
+		total = length
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is at least as long as the content.
+		if _, length, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && length == offset {
+			return true, length, etag, nil
+		}
+		if err := f.Truncate(0); err != nil {
+			return false, -1, tag, err
+		}
+		return false, -1, "", errors.New("GET " + url + ": partial file exceeds content length")
+	default:
+		return false, -1, tag, &StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		return false, total, tag, err
+	}
+	return true, total, tag, nil
+}
+
+// parseContentRange parses the value of a Content-Range header in the form
+// "bytes start-end/length". A length of -1 is returned when the length is
+// unknown.
+func parseContentRange(s string) (start, length int64, ok bool) {
+	const prefix = "bytes "
+	if !strings.HasPrefix(s, prefix) {
+		return 0, 0, false
+	}
+	s = s[len(prefix):]
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return 0, 0, false
+	}
+	rng, size := s[:i], s[i+1:]
+	if size == "*" {
+		length = -1
+	} else if length, ok = parseInt(size); !ok {
+		return 0, 0, false
+	}
+	if rng == "*" {
+		return 0, length, true
+	}
+	j := strings.IndexByte(rng, '-')
+	if j < 0 {
+		return 0, 0, false
+	}
+	if start, ok = parseInt(rng[:j]); !ok {
+		return 0, 0, false
+	}
+	return start, length, true
+}
+
+func parseInt(s string) (int64, bool) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	return i, err == nil && i >= 0
+}
+
+// verify checks the complete content of f.
+func (c *Client) verify(url string, f *os.File, total int64, etag, expected string) (sum Checksum, err error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return sum, err
+	}
+	hSHA := sha256.New()
+	hashes := []hash.Hash{hSHA}
+	var hMD5 hash.Hash
+	if md5Tag := strings.Trim(etag, "\""); isHex(md5Tag, md5.Size) {
+		hMD5 = md5.New()
+		hashes = append(hashes, hMD5)
+	}
+	w := make([]io.Writer, len(hashes))
+	for i, h := range hashes {
+		w[i] = h
+	}
+	if sum.Size, err = io.Copy(io.MultiWriter(w...), f); err != nil {
+		return sum, err
+	}
+	sum.SHA256 = hex.EncodeToString(hSHA.Sum(nil))
+	if total >= 0 && sum.Size != total {
+		return sum, &IntegrityError{URL: url, Field: "length", Expected: strconv.FormatInt(total, 10), Actual: strconv.FormatInt(sum.Size, 10)}
+	}
+	if hMD5 != nil {
+		if md5Tag, actual := strings.ToLower(strings.Trim(etag, "\"")), hex.EncodeToString(hMD5.Sum(nil)); md5Tag != actual {
+			return sum, &IntegrityError{URL: url, Field: "MD5", Expected: md5Tag, Actual: actual}
+		}
+	}
+	if expected != "" && !strings.EqualFold(expected, sum.SHA256) {
+		return sum, &IntegrityError{URL: url, Field: "SHA-256", Expected: expected, Actual: sum.SHA256}
+	}
+	return sum, nil
+}
+
+// isHex returns whether s is the hex encoding of n bytes.
+func isHex(s string, n int) bool {
+	if len(s) != n*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
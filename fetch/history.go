@@ -0,0 +1,252 @@
+package fetch
+
+import (
+	"bufio"
+	"context"
+	"github.com/karl-police/rbxapi"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeployHistoryFile is the name of the file listing the deployments of a
+// channel.
+const DeployHistoryFile = "DeployHistory.txt"
+
+// historyTypes maps the build types that appear in DeployHistory to binary
+// types.
+var historyTypes = map[string]BinaryType{
+	"Client":        WindowsPlayer,
+	"WindowsPlayer": WindowsPlayer,
+	"Studio":        WindowsStudio,
+	"Studio64":      WindowsStudio64,
+	"MacPlayer":     MacPlayer,
+	"MacStudio":     MacStudio,
+}
+
+// historyDateFormat is the format of dates in DeployHistory.
+const historyDateFormat = "1/2/2006 3:04:05 PM"
+
+// HistoryLocation is the time zone in which DeployHistory dates are
+// interpreted. It defaults to the Pacific time zone, falling back to UTC if
+// time zone data is unavailable.
+var HistoryLocation = func() *time.Location {
+	if loc, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		return loc
+	}
+	return time.UTC
+}()
+
+var historyLine = regexp.MustCompile(`^New (\w+) (version-[0-9A-Fa-f]+) at (\d+/\d+/\d+ \d+:\d+:\d+ [AP]M)(?:, file version: (\d+, ?\d+, ?\d+, ?\d+))?`)
+
+// History is a list of builds, ordered by date.
+type History []Version
+
+// ParseDeployHistory parses the content of a DeployHistory file. Lines that
+// do not describe a deployment are skipped, as are deployments of unknown
+// build types. The returned builds have no channel set.
+func ParseDeployHistory(r io.Reader) (History, error) {
+	var history History
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		m := historyLine.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if m == nil {
+			continue
+		}
+		typ, ok := historyTypes[m[1]]
+		if !ok {
+			continue
+		}
+		guid, err := ParseGUID(m[2])
+		if err != nil {
+			continue
+		}
+		date, err := time.ParseInLocation(historyDateFormat, m[3], HistoryLocation)
+		if err != nil {
+			continue
+		}
+		v := Version{GUID: guid, Type: typ, Date: date}
+		if m[4] != "" {
+			if v.Number, err = ParseNumber(m[4]); err != nil {
+				continue
+			}
+		}
+		history = append(history, v)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	return history, nil
+}
+
+// DeployHistory downloads and parses the deployment history of the given
+// channel. The channel of each returned build is set to the given channel.
+func (c *Client) DeployHistory(ctx context.Context, channel string) (History, error) {
+	url := c.DeployURL
+	if url == "" {
+		url = DefaultDeployURL
+	}
+	url = strings.TrimSuffix(url, "/")
+	if !IsLive(channel) {
+		url += "/channel/" + strings.ToLower(channel)
+	}
+	body, err := c.get(ctx, url+"/"+DeployHistoryFile)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	history, err := ParseDeployHistory(body)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history {
+		history[i].Channel = channel
+	}
+	return history, nil
+}
+
+// DeployHistory downloads and parses the deployment history of the given
+// channel using DefaultClient.
+func DeployHistory(ctx context.Context, channel string) (History, error) {
+	return DefaultClient.DeployHistory(ctx, channel)
+}
+
+// Type returns the builds of the given binary type.
+func (h History) Type(typ BinaryType) History {
+	var list History
+	for _, v := range h {
+		if v.Type == typ {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Between returns the builds deployed at or after from, and before to. A zero
+// time leaves the corresponding end of the range unbounded.
+func (h History) Between(from, to time.Time) History {
+	var list History
+	for _, v := range h {
+		if !from.IsZero() && v.Date.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !v.Date.Before(to) {
+			continue
+		}
+		list = append(list, v)
+	}
+	return list
+}
+
+// GUID returns the build of the given version GUID.
+func (h History) GUID(guid string) (v Version, ok bool) {
+	guid = strings.ToLower(guid)
+	for _, v := range h {
+		if v.GUID == guid {
+			return v, true
+		}
+	}
+	return Version{}, false
+}
+
+// Number returns the builds of the given version number. A version number is
+// usually shared by builds of several binary types.
+func (h History) Number(n Number) History {
+	var list History
+	for _, v := range h {
+		if v.Number == n {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// At returns the most recent build deployed at or before the given time.
+func (h History) At(t time.Time) (v Version, ok bool) {
+	for i := len(h) - 1; i >= 0; i-- {
+		if !h[i].Date.After(t) {
+			return h[i], true
+		}
+	}
+	return Version{}, false
+}
+
+// Resolve fills in the number, date, and type of v from the build in the
+// history that has the same GUID or, if v has no GUID, the same number and
+// type. Returns false if no such build is present.
+func (h History) Resolve(v Version) (Version, bool) {
+	var b Version
+	var ok bool
+	if v.GUID != "" {
+		b, ok = h.GUID(v.GUID)
+	} else {
+		for _, u := range h.Number(v.Number) {
+			if v.Type == "" || u.Type == v.Type {
+				b, ok = u, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return v, false
+	}
+	if v.Channel != "" {
+		b.Channel = v.Channel
+	}
+	return b, true
+}
+
+// missing returns whether err indicates that a requested file does not exist.
+// Deployment servers respond to requests for missing files with either 403 or
+// 404.
+func missing(err error) bool {
+	if err, ok := err.(*StatusError); ok {
+		return err.StatusCode == http.StatusNotFound || err.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// RangeDumps downloads the API dump of each build in h, in order, passing the
+// build and its dump to fn. The JSON dump of a build is preferred, falling
+// back to the legacy text dump. Builds that distribute neither are skipped.
+// Iteration stops when fn returns false, or when a dump cannot be retrieved
+// or decoded, in which case the error is returned.
+//
+// Builds of several binary types and channels may distribute the same dump,
+// so h is usually first narrowed with History.Type.
+func (c *Client) RangeDumps(ctx context.Context, h History, fn func(v Version, root rbxapi.Root) bool) error {
+	for _, v := range h {
+		var root rbxapi.Root
+		jroot, err := c.JSONDump(ctx, v)
+		switch {
+		case err == nil:
+			root = jroot
+		case missing(err):
+			droot, err := c.Dump(ctx, v)
+			if missing(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			root = droot
+		default:
+			return err
+		}
+		if !fn(v, root) {
+			break
+		}
+	}
+	return nil
+}
+
+// RangeDumps downloads the API dump of each build in h using DefaultClient.
+func RangeDumps(ctx context.Context, h History, fn func(v Version, root rbxapi.Root) bool) error {
+	return DefaultClient.RangeDumps(ctx, h, fn)
+}
@@ -0,0 +1,192 @@
+// The fetch package is used to retrieve Roblox API dumps and related data from
+// Roblox deployment servers.
+package fetch
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BinaryType indicates the kind of binary distributed by a deployment.
+type BinaryType string
+
+const (
+	WindowsPlayer   BinaryType = "WindowsPlayer"
+	WindowsStudio   BinaryType = "WindowsStudio"
+	WindowsStudio64 BinaryType = "WindowsStudio64"
+	MacPlayer       BinaryType = "MacPlayer"
+	MacStudio       BinaryType = "MacStudio"
+)
+
+// Number is the four-component version number of a build, such as
+// 0.512.0.5120423.
+type Number [4]int
+
+// ParseNumber parses a version number. Components may be separated by dots
+// ("0.512.0.5120423") or by commas ("0, 512, 0, 5120423"), the latter being
+// the form used by DeployHistory.
+func ParseNumber(s string) (n Number, err error) {
+	sep := "."
+	if strings.Contains(s, ",") {
+		sep = ","
+	}
+	parts := strings.Split(s, sep)
+	if len(parts) != len(n) {
+		return Number{}, errors.New("version number must have " + strconv.Itoa(len(n)) + " components")
+	}
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v < 0 {
+			return Number{}, errors.New("invalid version number component \"" + part + "\"")
+		}
+		n[i] = v
+	}
+	return n, nil
+}
+
+// String returns the number in dotted form.
+func (n Number) String() string {
+	var b []byte
+	for i, v := range n {
+		if i > 0 {
+			b = append(b, '.')
+		}
+		b = strconv.AppendInt(b, int64(v), 10)
+	}
+	return string(b)
+}
+
+// IsZero returns whether each component of the number is zero.
+func (n Number) IsZero() bool {
+	return n == Number{}
+}
+
+// Compare returns -1 if n is less than m, 1 if n is greater than m, and 0 if
+// they are equal.
+func (n Number) Compare(m Number) int {
+	for i := range n {
+		switch {
+		case n[i] < m[i]:
+			return -1
+		case n[i] > m[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (n Number) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (n *Number) UnmarshalText(b []byte) (err error) {
+	*n, err = ParseNumber(string(b))
+	return err
+}
+
+// guidPrefix is the prefix of every version GUID.
+const guidPrefix = "version-"
+
+// ParseGUID validates and normalizes a version GUID, such as
+// "version-1a2b3c4d5e6f7a8b". The hash portion is converted to lowercase.
+func ParseGUID(s string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(s), guidPrefix) {
+		return "", errors.New("version GUID must begin with \"" + guidPrefix + "\"")
+	}
+	hash := strings.ToLower(s[len(guidPrefix):])
+	if hash == "" {
+		return "", errors.New("version GUID has empty hash")
+	}
+	for i := 0; i < len(hash); i++ {
+		if c := hash[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return "", errors.New("version GUID has invalid character '" + string(c) + "'")
+		}
+	}
+	return guidPrefix + hash, nil
+}
+
+// Version describes a single build deployed by Roblox.
+type Version struct {
+	// GUID is the unique identifier of the deployment, such as
+	// "version-1a2b3c4d5e6f7a8b".
+	GUID string
+	// Channel is the deployment channel from which the build was retrieved.
+	// An empty string is equivalent to the LIVE channel.
+	Channel string `json:",omitempty"`
+	// Type is the kind of binary of the build.
+	Type BinaryType `json:",omitempty"`
+	// Number is the version number of the build.
+	Number Number
+	// Date is the time at which the build was deployed.
+	Date time.Time
+}
+
+// ParseVersion parses a string in the form produced by Version.String. The
+// string must contain a version GUID or a version number, which may be
+// preceded by a channel name separated by a slash.
+//
+//	version-1a2b3c4d5e6f7a8b
+//	0.512.0.5120423
+//	zcanary/version-1a2b3c4d5e6f7a8b
+func ParseVersion(s string) (v Version, err error) {
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		v.Channel, s = s[:i], s[i+1:]
+		if v.Channel == "" {
+			return Version{}, errors.New("empty channel")
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(s), guidPrefix) {
+		v.GUID, err = ParseGUID(s)
+	} else {
+		v.Number, err = ParseNumber(s)
+	}
+	if err != nil {
+		return Version{}, err
+	}
+	return v, nil
+}
+
+// String returns a string representation of the version. The GUID is
+// preferred, falling back to the version number. A non-LIVE channel is
+// included as a prefix.
+func (v Version) String() string {
+	var s string
+	if v.GUID != "" {
+		s = v.GUID
+	} else {
+		s = v.Number.String()
+	}
+	if !IsLive(v.Channel) {
+		s = v.Channel + "/" + s
+	}
+	return s
+}
+
+// Compare orders v and u by version number, then by date, then by GUID. It
+// returns -1 if v is less than u, 1 if v is greater than u, and 0 if they are
+// equal.
+func (v Version) Compare(u Version) int {
+	if c := v.Number.Compare(u.Number); c != 0 {
+		return c
+	}
+	switch {
+	case v.Date.Before(u.Date):
+		return -1
+	case v.Date.After(u.Date):
+		return 1
+	case v.GUID < u.GUID:
+		return -1
+	case v.GUID > u.GUID:
+		return 1
+	}
+	return 0
+}
+
+// IsLive returns whether channel refers to the LIVE deployment channel.
+func IsLive(channel string) bool {
+	return channel == "" || strings.EqualFold(channel, "live")
+}
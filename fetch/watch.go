@@ -0,0 +1,94 @@
+package fetch
+
+import (
+	"context"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"time"
+)
+
+// Event is delivered by a watcher when a new build is detected, or when an
+// error occurs while polling.
+type Event struct {
+	// Prev is the version that was deployed before the change.
+	Prev Version
+	// Next is the newly deployed version.
+	Next Version
+	// Root is the API dump of Next.
+	Root *rbxapijson.Root
+	// Actions are the differences between the API dumps of Prev and Next.
+	// This is empty for builds that do not change the API.
+	Actions []patch.Action
+	// Err is set when polling failed. The remaining fields are unset in this
+	// case. The watcher continues polling after an error.
+	Err error
+}
+
+// Watch polls the given channel every interval for new deployments, using
+// DefaultClient.
+func Watch(ctx context.Context, channel string, interval time.Duration) <-chan Event {
+	return DefaultClient.Watch(ctx, channel, interval)
+}
+
+// Watch polls the given channel every interval for new deployments. When the
+// deployed version changes, the API dump of the new version is downloaded and
+// compared with the dump of the previous version, and the result is
+// delivered as an Event on the returned channel.
+//
+// The version deployed when watching begins is used as the initial baseline,
+// and does not produce an event. The channel is closed after ctx is done.
+func (c *Client) Watch(ctx context.Context, channel string, interval time.Duration) <-chan Event {
+	events := make(chan Event)
+	go c.watch(ctx, channel, interval, events)
+	return events
+}
+
+func (c *Client) watch(ctx context.Context, channel string, interval time.Duration, events chan<- Event) {
+	defer close(events)
+	send := func(event Event) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var prev Version
+	var prevRoot *rbxapijson.Root
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if next, err := c.Latest(ctx, channel); err != nil {
+			if ctx.Err() != nil || !send(Event{Err: err}) {
+				return
+			}
+		} else if next.GUID != prev.GUID {
+			root, err := c.JSONDump(ctx, next)
+			switch {
+			case err != nil:
+				if ctx.Err() != nil || !send(Event{Err: err}) {
+					return
+				}
+			case prevRoot == nil:
+				prev, prevRoot = next, root
+			default:
+				event := Event{
+					Prev:    prev,
+					Next:    next,
+					Root:    root,
+					Actions: (&rbxapijson.Diff{Prev: prevRoot, Next: root}).Diff(),
+				}
+				prev, prevRoot = next, root
+				if !send(event) {
+					return
+				}
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
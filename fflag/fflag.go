@@ -0,0 +1,215 @@
+// The fflag package associates Roblox fast flags with API descriptors.
+//
+// Many members are added to the API before they are usable, being gated
+// behind a fast flag until the feature is released. Roblox does not publish
+// which flags gate which descriptors, so this package combines a fast flag
+// dump with a user-maintained Mapping of descriptors to flags.
+//
+// Descriptors are identified by paths in the following forms:
+//
+//	Workspace                  class
+//	Workspace.FluidForces      member
+//	Enum.Material              enum
+//	Enum.Material.Plastic      enum item
+package fflag
+
+import (
+	"encoding/json"
+	"github.com/karl-police/rbxapi"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Flags maps the names of fast flags to their values. Values are represented
+// as strings, as they appear in flag dumps.
+type Flags map[string]string
+
+// Decode parses a fast flag dump from r. Both a plain JSON object of flags,
+// and the {"applicationSettings": {...}} form returned by the client settings
+// service are accepted.
+func Decode(r io.Reader) (Flags, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	if settings, ok := raw["applicationSettings"]; ok && len(raw) == 1 {
+		if err := json.Unmarshal(settings, &raw); err != nil {
+			return nil, err
+		}
+	}
+	flags := make(Flags, len(raw))
+	for name, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			// Non-string values are kept in their JSON form.
+			s = string(value)
+		}
+		flags[name] = s
+	}
+	return flags, nil
+}
+
+// Enabled returns whether the given flag is present and has a true value.
+func (flags Flags) Enabled(name string) bool {
+	return strings.EqualFold(flags[name], "true")
+}
+
+// Mapping maps descriptor paths to the names of the flags known to gate
+// them.
+type Mapping map[string][]string
+
+// DecodeMapping parses a mapping from r in JSON format, as an object mapping
+// descriptor paths to arrays of flag names.
+func DecodeMapping(r io.Reader) (m Mapping, err error) {
+	err = json.NewDecoder(r).Decode(&m)
+	return m, err
+}
+
+// EncodeMapping writes m to w in JSON format.
+func EncodeMapping(w io.Writer, m Mapping) error {
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	return je.Encode(m)
+}
+
+// ClassPath returns the path identifying a class.
+func ClassPath(class string) string {
+	return class
+}
+
+// MemberPath returns the path identifying a member of a class.
+func MemberPath(class, member string) string {
+	return class + "." + member
+}
+
+// EnumPath returns the path identifying an enum.
+func EnumPath(enum string) string {
+	return "Enum." + enum
+}
+
+// EnumItemPath returns the path identifying an item of an enum.
+func EnumItemPath(enum, item string) string {
+	return "Enum." + enum + "." + item
+}
+
+// Gate describes a flag that gates a descriptor.
+type Gate struct {
+	// Flag is the name of the flag.
+	Flag string
+	// Known is whether the flag is present in the flag dump.
+	Known bool
+	// Value is the value of the flag, if known.
+	Value string
+}
+
+// Enabled returns whether the flag is known and has a true value.
+func (g Gate) Enabled() bool {
+	return g.Known && strings.EqualFold(g.Value, "true")
+}
+
+// Decorator annotates descriptors with the flags that gate them.
+type Decorator interface {
+	// DecorateClass returns the flags gating a class.
+	DecorateClass(class rbxapi.Class) []Gate
+	// DecorateMember returns the flags gating a member of a class.
+	DecorateMember(class rbxapi.Class, member rbxapi.Member) []Gate
+	// DecorateEnum returns the flags gating an enum.
+	DecorateEnum(enum rbxapi.Enum) []Gate
+	// DecorateEnumItem returns the flags gating an item of an enum.
+	DecorateEnumItem(enum rbxapi.Enum, item rbxapi.EnumItem) []Gate
+}
+
+// Annotator is a Decorator that combines a mapping with the values of a flag
+// dump. Flags may be nil, in which case every gate is unknown.
+type Annotator struct {
+	Mapping Mapping
+	Flags   Flags
+}
+
+func (a *Annotator) gates(path string) []Gate {
+	names := a.Mapping[path]
+	if len(names) == 0 {
+		return nil
+	}
+	gates := make([]Gate, len(names))
+	for i, name := range names {
+		gates[i].Flag = name
+		gates[i].Value, gates[i].Known = a.Flags[name]
+	}
+	return gates
+}
+
+// DecorateClass implements the Decorator interface.
+func (a *Annotator) DecorateClass(class rbxapi.Class) []Gate {
+	return a.gates(ClassPath(class.GetName()))
+}
+
+// DecorateMember implements the Decorator interface.
+func (a *Annotator) DecorateMember(class rbxapi.Class, member rbxapi.Member) []Gate {
+	return a.gates(MemberPath(class.GetName(), member.GetName()))
+}
+
+// DecorateEnum implements the Decorator interface.
+func (a *Annotator) DecorateEnum(enum rbxapi.Enum) []Gate {
+	return a.gates(EnumPath(enum.GetName()))
+}
+
+// DecorateEnumItem implements the Decorator interface.
+func (a *Annotator) DecorateEnumItem(enum rbxapi.Enum, item rbxapi.EnumItem) []Gate {
+	return a.gates(EnumItemPath(enum.GetName(), item.GetName()))
+}
+
+// Annotate applies d to every descriptor of root, returning the gates of
+// each gated descriptor by path.
+func Annotate(root rbxapi.Root, d Decorator) map[string][]Gate {
+	gated := map[string][]Gate{}
+	for _, class := range root.GetClasses() {
+		if gates := d.DecorateClass(class); len(gates) > 0 {
+			gated[ClassPath(class.GetName())] = gates
+		}
+		for _, member := range class.GetMembers() {
+			if gates := d.DecorateMember(class, member); len(gates) > 0 {
+				gated[MemberPath(class.GetName(), member.GetName())] = gates
+			}
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		if gates := d.DecorateEnum(enum); len(gates) > 0 {
+			gated[EnumPath(enum.GetName())] = gates
+		}
+		for _, item := range enum.GetEnumItems() {
+			if gates := d.DecorateEnumItem(enum, item); len(gates) > 0 {
+				gated[EnumItemPath(enum.GetName(), item.GetName())] = gates
+			}
+		}
+	}
+	return gated
+}
+
+// Unmapped returns, in lexical order, the paths of the mapping that do not
+// refer to any descriptor of root. This is useful for pruning stale entries
+// from a mapping.
+func (m Mapping) Unmapped(root rbxapi.Root) []string {
+	present := map[string]bool{}
+	for _, class := range root.GetClasses() {
+		present[ClassPath(class.GetName())] = true
+		for _, member := range class.GetMembers() {
+			present[MemberPath(class.GetName(), member.GetName())] = true
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		present[EnumPath(enum.GetName())] = true
+		for _, item := range enum.GetEnumItems() {
+			present[EnumItemPath(enum.GetName(), item.GetName())] = true
+		}
+	}
+	var list []string
+	for path := range m {
+		if !present[path] {
+			list = append(list, path)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
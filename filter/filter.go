@@ -0,0 +1,236 @@
+// The filter package produces subsets of API structures.
+package filter
+
+import (
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+)
+
+// Predicate selects the descriptors kept by Filter. Each function returns
+// whether a descriptor is kept. A nil function keeps every descriptor of its
+// kind. Members and items are considered only if their class or enum is kept.
+type Predicate struct {
+	Class    func(class rbxapi.Class) bool
+	Member   func(class rbxapi.Class, member rbxapi.Member) bool
+	Enum     func(enum rbxapi.Enum) bool
+	EnumItem func(enum rbxapi.Enum, item rbxapi.EnumItem) bool
+}
+
+// Options configures the removal of descriptors made redundant by Filter.
+type Options struct {
+	// EmptyClasses removes classes that had members, but have none after
+	// filtering. Such a class is kept if it is the superclass of a kept
+	// class.
+	EmptyClasses bool
+	// OrphanedEnums removes enums that were referred to by the type of a
+	// member, but are no longer referred to by any kept member.
+	OrphanedEnums bool
+}
+
+// And returns a Predicate that keeps a descriptor only if every one of preds
+// keeps it.
+func And(preds ...*Predicate) *Predicate {
+	return &Predicate{
+		Class: func(class rbxapi.Class) bool {
+			for _, p := range preds {
+				if p.Class != nil && !p.Class(class) {
+					return false
+				}
+			}
+			return true
+		},
+		Member: func(class rbxapi.Class, member rbxapi.Member) bool {
+			for _, p := range preds {
+				if p.Member != nil && !p.Member(class, member) {
+					return false
+				}
+			}
+			return true
+		},
+		Enum: func(enum rbxapi.Enum) bool {
+			for _, p := range preds {
+				if p.Enum != nil && !p.Enum(enum) {
+					return false
+				}
+			}
+			return true
+		},
+		EnumItem: func(enum rbxapi.Enum, item rbxapi.EnumItem) bool {
+			for _, p := range preds {
+				if p.EnumItem != nil && !p.EnumItem(enum, item) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// WithoutTags returns a Predicate that removes every descriptor that has any
+// of the given tags, such as "Deprecated" or "NotScriptable".
+func WithoutTags(tags ...string) *Predicate {
+	keep := func(t rbxapi.Taggable) bool {
+		for _, tag := range tags {
+			if t.GetTag(tag) {
+				return false
+			}
+		}
+		return true
+	}
+	return &Predicate{
+		Class: func(class rbxapi.Class) bool {
+			return keep(class)
+		},
+		Member: func(class rbxapi.Class, member rbxapi.Member) bool {
+			return keep(member)
+		},
+		Enum: func(enum rbxapi.Enum) bool {
+			return keep(enum)
+		},
+		EnumItem: func(enum rbxapi.Enum, item rbxapi.EnumItem) bool {
+			return keep(item)
+		},
+	}
+}
+
+// MaxSecurity returns a Predicate that removes members that require a
+// security context more restrictive than the given context, as ordered by
+// rbxapi.Securities. Members with an unknown security context are
+// removed.
+func MaxSecurity(security string) *Predicate {
+	context := rbxapi.ParseSecurity(security)
+	return &Predicate{
+		Member: func(class rbxapi.Class, member rbxapi.Member) bool {
+			return rbxapi.MemberAccessible(member, context)
+		},
+	}
+}
+
+// enumRefs returns the names of the types referred to by members of root
+// that may be enums. If keep is not nil, only members it keeps are
+// considered.
+func enumRefs(root rbxapi.Root, keep func(class rbxapi.Class, member rbxapi.Member) bool) map[string]bool {
+	refs := map[string]bool{}
+	rbxapi.Walk(root, &rbxapi.Visitor{
+		Member: func(class rbxapi.Class, member rbxapi.Member) error {
+			if keep != nil && !keep(class, member) {
+				return rbxapi.SkipChildren
+			}
+			return nil
+		},
+		Type: func(class rbxapi.Class, member rbxapi.Member, typ rbxapi.Type) error {
+			if c := typ.GetCategory(); c == "" || c == "Enum" {
+				refs[typ.GetName()] = true
+			}
+			return nil
+		},
+	})
+	return refs
+}
+
+// Filter returns a copy of root containing only the descriptors kept by pred.
+// If opts is not nil, descriptors made redundant by the removal of other
+// descriptors are also removed, as configured by opts.
+//
+// The result has the same underlying type as root, which must implement
+// patch.Patcher. Removing a class does not affect its subclasses, which
+// continue to refer to the removed class as their superclass.
+func Filter(root rbxapi.Root, pred *Predicate, opts *Options) (rbxapi.Root, error) {
+	result := root.Copy()
+	patcher, ok := result.(patch.Patcher)
+	if !ok {
+		return nil, errors.New("root cannot be patched")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var actions []patch.Action
+	removedClasses := map[string]bool{}
+	removedMembers := map[rbxapi.Member]bool{}
+	emptied := map[string]rbxapi.Class{}
+	rbxapi.RangeClasses(result, func(class rbxapi.Class) bool {
+		if pred.Class != nil && !pred.Class(class) {
+			removedClasses[class.GetName()] = true
+			actions = append(actions, &diff.ClassAction{Type: patch.Remove, Class: class})
+			return true
+		}
+		var total, kept int
+		rbxapi.RangeMembers(class, func(member rbxapi.Member) bool {
+			total++
+			if pred.Member != nil && !pred.Member(class, member) {
+				removedMembers[member] = true
+				actions = append(actions, &diff.MemberAction{Type: patch.Remove, Class: class, Member: member})
+				return true
+			}
+			kept++
+			return true
+		})
+		if total > 0 && kept == 0 {
+			emptied[class.GetName()] = class
+		}
+		return true
+	})
+
+	if opts.EmptyClasses {
+		// Keep emptied classes that are superclasses of kept classes, which
+		// in turn keeps their own emptied superclasses.
+		for changed := true; changed; {
+			changed = false
+			rbxapi.RangeClasses(result, func(class rbxapi.Class) bool {
+				name := class.GetName()
+				if removedClasses[name] || emptied[name] != nil {
+					return true
+				}
+				if super := class.GetSuperclass(); emptied[super] != nil {
+					delete(emptied, super)
+					changed = true
+				}
+				return true
+			})
+		}
+		rbxapi.RangeClasses(result, func(class rbxapi.Class) bool {
+			if emptied[class.GetName()] != nil {
+				removedClasses[class.GetName()] = true
+				actions = append(actions, &diff.ClassAction{Type: patch.Remove, Class: class})
+			}
+			return true
+		})
+	}
+
+	var orphaned map[string]bool
+	if opts.OrphanedEnums {
+		before := enumRefs(result, nil)
+		after := enumRefs(result, func(class rbxapi.Class, member rbxapi.Member) bool {
+			return !removedClasses[class.GetName()] && !removedMembers[member]
+		})
+		orphaned = map[string]bool{}
+		for name := range before {
+			if !after[name] {
+				orphaned[name] = true
+			}
+		}
+	}
+
+	rbxapi.RangeEnums(result, func(enum rbxapi.Enum) bool {
+		if pred.Enum != nil && !pred.Enum(enum) || orphaned[enum.GetName()] {
+			actions = append(actions, &diff.EnumAction{Type: patch.Remove, Enum: enum})
+			return true
+		}
+		if pred.EnumItem == nil {
+			return true
+		}
+		rbxapi.RangeEnumItems(enum, func(item rbxapi.EnumItem) bool {
+			if !pred.EnumItem(enum, item) {
+				actions = append(actions, &diff.EnumItemAction{Type: patch.Remove, Enum: enum, EnumItem: item})
+			}
+			return true
+		})
+		return true
+	})
+
+	patcher.Patch(actions)
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package rbxapi
+
+import (
+	"strings"
+)
+
+// FoldIndex is an index of the descriptors of a Root that supports
+// case-insensitive lookup of names. When several descriptors have names that
+// differ only in case, the first descriptor is returned, following the order
+// of the Root.
+//
+// A FoldIndex reflects the Root at the time the index was created, and must
+// be recreated if the Root is modified.
+type FoldIndex struct {
+	classes map[string]foldClass
+	enums   map[string]foldEnum
+}
+
+type foldClass struct {
+	class   Class
+	members map[string]Member
+}
+
+type foldEnum struct {
+	enum  Enum
+	items map[string]EnumItem
+}
+
+// foldKey returns the key of a name within a FoldIndex.
+func foldKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// NewFoldIndex returns a FoldIndex of the descriptors of root.
+func NewFoldIndex(root Root) *FoldIndex {
+	idx := &FoldIndex{
+		classes: map[string]foldClass{},
+		enums:   map[string]foldEnum{},
+	}
+	RangeClasses(root, func(class Class) bool {
+		key := foldKey(class.GetName())
+		if _, ok := idx.classes[key]; ok {
+			return true
+		}
+		c := foldClass{class: class, members: map[string]Member{}}
+		RangeMembers(class, func(member Member) bool {
+			key := foldKey(member.GetName())
+			if _, ok := c.members[key]; !ok {
+				c.members[key] = member
+			}
+			return true
+		})
+		idx.classes[key] = c
+		return true
+	})
+	RangeEnums(root, func(enum Enum) bool {
+		key := foldKey(enum.GetName())
+		if _, ok := idx.enums[key]; ok {
+			return true
+		}
+		e := foldEnum{enum: enum, items: map[string]EnumItem{}}
+		RangeEnumItems(enum, func(item EnumItem) bool {
+			key := foldKey(item.GetName())
+			if _, ok := e.items[key]; !ok {
+				e.items[key] = item
+			}
+			return true
+		})
+		idx.enums[key] = e
+		return true
+	})
+	return idx
+}
+
+// GetClassFold returns the first class descriptor whose name matches the given
+// name without regard to case, or nil if no such class is present.
+func (idx *FoldIndex) GetClassFold(name string) Class {
+	return idx.classes[foldKey(name)].class
+}
+
+// GetMemberFold returns the first member descriptor of the class of the given
+// name, where the names of the class and member are matched without regard to
+// case. Returns nil if no such member is present.
+func (idx *FoldIndex) GetMemberFold(class, member string) Member {
+	c, ok := idx.classes[foldKey(class)]
+	if !ok {
+		return nil
+	}
+	return c.members[foldKey(member)]
+}
+
+// GetEnumFold returns the first enum descriptor whose name matches the given
+// name without regard to case, or nil if no such enum is present.
+func (idx *FoldIndex) GetEnumFold(name string) Enum {
+	return idx.enums[foldKey(name)].enum
+}
+
+// GetEnumItemFold returns the first item of the enum of the given name, where
+// the names of the enum and item are matched without regard to case. Returns
+// nil if no such item is present.
+func (idx *FoldIndex) GetEnumItemFold(enum, item string) EnumItem {
+	e, ok := idx.enums[foldKey(enum)]
+	if !ok {
+		return nil
+	}
+	return e.items[foldKey(item)]
+}
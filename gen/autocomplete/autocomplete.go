@@ -0,0 +1,248 @@
+// The autocomplete package generates autocomplete metadata in the XML format
+// once shipped with Roblox Studio.
+//
+// The document has the following structure:
+//
+//	<StudioAutocomplete>
+//		<Keywords>
+//			<Keyword name="and"/>
+//		</Keywords>
+//		<ItemStruct name="Instance" superclass="" tags="NotCreatable">
+//			<Property name="Name" type="string" security="None"/>
+//			<Function name="FindFirstChild" security="None">
+//				<Parameters>
+//					<Parameter name="name" type="string"/>
+//					<Parameter name="recursive" type="bool" default="false"/>
+//				</Parameters>
+//				<ReturnValues>
+//					<ReturnValue type="Instance"/>
+//				</ReturnValues>
+//			</Function>
+//			<Event name="Changed" security="None">...</Event>
+//			<Callback name="OnInvoke" security="None">...</Callback>
+//		</ItemStruct>
+//		<Enum name="Material">
+//			<Item name="Plastic" value="256"/>
+//		</Enum>
+//	</StudioAutocomplete>
+//
+// Items deprecated by the API have the attribute deprecated="true". The tags
+// attribute is omitted when a descriptor has no tags.
+//
+// The package registers the "autocomplete" target with the gen package.
+package autocomplete
+
+import (
+	"encoding/xml"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/internal/luaname"
+	"io"
+	"strings"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "AutocompleteMetadata.xml"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "autocomplete",
+		Summary: "Studio autocomplete metadata XML",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates autocomplete metadata.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Filter selects the included descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type document struct {
+	XMLName  xml.Name     `xml:"StudioAutocomplete"`
+	Keywords []named      `xml:"Keywords>Keyword"`
+	Classes  []itemStruct `xml:"ItemStruct"`
+	Enums    []enum       `xml:"Enum"`
+}
+
+type named struct {
+	Name string `xml:"name,attr"`
+}
+
+type tagged struct {
+	Tags       string `xml:"tags,attr,omitempty"`
+	Deprecated bool   `xml:"deprecated,attr,omitempty"`
+}
+
+func newTagged(t rbxapi.Taggable) tagged {
+	return tagged{
+		Tags:       strings.Join(t.GetTags(), " "),
+		Deprecated: t.GetTag("Deprecated"),
+	}
+}
+
+type itemStruct struct {
+	Name       string `xml:"name,attr"`
+	Superclass string `xml:"superclass,attr"`
+	tagged
+	Members []interface{}
+}
+
+type property struct {
+	XMLName       xml.Name `xml:"Property"`
+	Name          string   `xml:"name,attr"`
+	Type          string   `xml:"type,attr"`
+	Security      string   `xml:"security,attr"`
+	WriteSecurity string   `xml:"writeSecurity,attr,omitempty"`
+	tagged
+}
+
+type parameter struct {
+	Name    string  `xml:"name,attr"`
+	Type    string  `xml:"type,attr"`
+	Default *string `xml:"default,attr"`
+}
+
+type returnValue struct {
+	Type string `xml:"type,attr"`
+}
+
+type function struct {
+	XMLName      xml.Name      `xml:""`
+	Name         string        `xml:"name,attr"`
+	Security     string        `xml:"security,attr"`
+	Parameters   []parameter   `xml:"Parameters>Parameter"`
+	ReturnValues []returnValue `xml:"ReturnValues>ReturnValue,omitempty"`
+	tagged
+}
+
+type enum struct {
+	Name string `xml:"name,attr"`
+	tagged
+	Items []enumItem `xml:"Item"`
+}
+
+type enumItem struct {
+	Name  string `xml:"name,attr"`
+	Value int    `xml:"value,attr"`
+	tagged
+}
+
+func parameters(params rbxapi.Parameters) []parameter {
+	list := params.GetParameters()
+	out := make([]parameter, len(list))
+	for i, param := range list {
+		out[i] = parameter{Name: param.GetName(), Type: param.GetType().GetName()}
+		if def, ok := param.GetDefault(); ok {
+			out[i].Default = &def
+		}
+	}
+	return out
+}
+
+func member(m rbxapi.Member) interface{} {
+	switch m := m.(type) {
+	case rbxapi.Property:
+		read, write := m.GetSecurity()
+		p := property{Name: m.GetName(), Type: m.GetValueType().GetName(), Security: read, tagged: newTagged(m)}
+		if write != read {
+			p.WriteSecurity = write
+		}
+		return p
+	case rbxapi.Function:
+		// Also matches callbacks.
+		return function{
+			XMLName:      xml.Name{Local: m.GetMemberType()},
+			Name:         m.GetName(),
+			Security:     m.GetSecurity(),
+			Parameters:   parameters(m.GetParameters()),
+			ReturnValues: []returnValue{{Type: m.GetReturnType().GetName()}},
+			tagged:       newTagged(m),
+		}
+	case rbxapi.Event:
+		return function{
+			XMLName:    xml.Name{Local: "Event"},
+			Name:       m.GetName(),
+			Security:   m.GetSecurity(),
+			Parameters: parameters(m.GetParameters()),
+			tagged:     newTagged(m),
+		}
+	}
+	return nil
+}
+
+// Write writes the autocomplete metadata of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	var doc document
+	for _, keyword := range luaname.Keywords {
+		doc.Keywords = append(doc.Keywords, named{Name: keyword})
+	}
+	for _, class := range root.GetClasses() {
+		if !g.Class(class) {
+			continue
+		}
+		s := itemStruct{Name: class.GetName(), Superclass: class.GetSuperclass(), tagged: newTagged(class)}
+		if root.GetClass(s.Superclass) == nil {
+			s.Superclass = ""
+		}
+		for _, m := range class.GetMembers() {
+			if g.Member(m) {
+				if v := member(m); v != nil {
+					s.Members = append(s.Members, v)
+				}
+			}
+		}
+		doc.Classes = append(doc.Classes, s)
+	}
+	for _, e := range root.GetEnums() {
+		if !g.Enum(e) {
+			continue
+		}
+		en := enum{Name: e.GetName(), tagged: newTagged(e)}
+		for _, item := range e.GetEnumItems() {
+			if g.EnumItem(item) {
+				en.Items = append(en.Items, enumItem{Name: item.GetName(), Value: item.GetValue(), tagged: newTagged(item)})
+			}
+		}
+		doc.Enums = append(doc.Enums, en)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	xe := xml.NewEncoder(w)
+	xe.Indent("", "\t")
+	if err := xe.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
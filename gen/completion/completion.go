@@ -0,0 +1,336 @@
+// The completion package generates completion metadata for editors from an
+// API structure.
+//
+// The produced JSON file contains, for each class, a completion item for each
+// member of the class and of its superclasses, and for each enum, an item for
+// each of its items. Items are modeled after the CompletionItem and
+// SignatureInformation structures of the Language Server Protocol, so that
+// editor extensions and language servers can use them with little
+// conversion. Descriptions are included when API documentation is provided.
+//
+// The package registers the "completion" target with the gen package.
+package completion
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/docs"
+	"github.com/karl-police/rbxapi/gen"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "completion.json"
+
+// Kinds of completion items, as defined by the CompletionItemKind enumeration
+// of the Language Server Protocol.
+const (
+	KindMethod     = 2
+	KindField      = 5
+	KindClass      = 7
+	KindProperty   = 10
+	KindEnum       = 13
+	KindEnumMember = 20
+	KindEvent      = 23
+)
+
+// Data is the content of the produced file.
+type Data struct {
+	Classes []*Class `json:"classes"`
+	Enums   []*Enum  `json:"enums"`
+}
+
+// Class describes a class and the members available on its instances.
+type Class struct {
+	Name          string   `json:"name"`
+	Superclass    string   `json:"superclass,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Documentation string   `json:"documentation,omitempty"`
+	LearnMoreLink string   `json:"learnMoreLink,omitempty"`
+	// Members contains the members of the class, followed by the inherited
+	// members that it does not override, nearest superclass first.
+	Members []*Item `json:"members"`
+}
+
+// Enum describes an enum and its items.
+type Enum struct {
+	Name          string   `json:"name"`
+	Tags          []string `json:"tags,omitempty"`
+	Documentation string   `json:"documentation,omitempty"`
+	LearnMoreLink string   `json:"learnMoreLink,omitempty"`
+	Items         []*Item  `json:"items"`
+}
+
+// Item is a completion item.
+type Item struct {
+	// Label is the name of the member or enum item.
+	Label string `json:"label"`
+	// Kind is the kind of the item, one of the Kind constants.
+	Kind int `json:"kind"`
+	// Detail is the signature of a member, or the value of an enum item.
+	Detail string `json:"detail"`
+	// InsertText is the text inserted when the item is selected. For
+	// functions, it is a snippet with a placeholder for each parameter
+	// without a default value.
+	InsertText       string `json:"insertText"`
+	InsertTextFormat int    `json:"insertTextFormat,omitempty"`
+	Documentation    string `json:"documentation,omitempty"`
+	LearnMoreLink    string `json:"learnMoreLink,omitempty"`
+	Deprecated       bool   `json:"deprecated,omitempty"`
+	// Class is the name of the class that declares a member.
+	Class string `json:"class,omitempty"`
+	// MemberType is the type of a member.
+	MemberType string   `json:"memberType,omitempty"`
+	Security   string   `json:"security,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// Parameters describes the parameters of a function, event, or callback,
+	// for use in signature help.
+	Parameters []Parameter `json:"parameters,omitempty"`
+}
+
+// Parameter describes a parameter of a member.
+type Parameter struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// insertTextSnippet indicates that the insert text of an item is a snippet.
+const insertTextSnippet = 2
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "completion",
+		Summary: "editor completion metadata",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates completion metadata.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Docs provides descriptions of the documented descriptors.
+	Docs docs.Docs
+	// DocsFile is the path to a file of API documentation in JSON format.
+	// If not empty, it is read into Docs before generating.
+	DocsFile string
+	// Filter selects the included descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	fs.StringVar(&g.DocsFile, "docs", g.DocsFile, "read descriptions from API documentation `file`")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	if g.DocsFile != "" {
+		f, err := os.Open(g.DocsFile)
+		if err != nil {
+			return err
+		}
+		g.Docs, err = docs.Decode(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the completion metadata of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	return je.Encode(g.Data(root))
+}
+
+// entry returns the trimmed description and link of the entry of the given
+// key.
+func (g *Generator) entry(key string) (doc, link string) {
+	if entry := g.Docs.Get(key); entry != nil {
+		return strings.TrimSpace(entry.Documentation), entry.LearnMoreLink
+	}
+	return "", ""
+}
+
+// Data returns the completion metadata of root.
+func (g *Generator) Data(root rbxapi.Root) *Data {
+	data := &Data{Classes: []*Class{}, Enums: []*Enum{}}
+	for _, class := range root.GetClasses() {
+		if !g.Class(class) {
+			continue
+		}
+		c := &Class{
+			Name:       class.GetName(),
+			Superclass: class.GetSuperclass(),
+			Tags:       class.GetTags(),
+			Members:    []*Item{},
+		}
+		c.Documentation, c.LearnMoreLink = g.entry(docs.ClassKey(c.Name))
+		if root.GetClass(c.Superclass) == nil {
+			c.Superclass = ""
+		}
+		defined := map[string]bool{}
+		classes := []rbxapi.Class{class}
+		for _, name := range gen.Superclasses(root, class) {
+			classes = append(classes, root.GetClass(name))
+		}
+		for _, decl := range classes {
+			if !g.Class(decl) {
+				continue
+			}
+			for _, member := range decl.GetMembers() {
+				if defined[member.GetName()] || !g.Member(member) {
+					continue
+				}
+				defined[member.GetName()] = true
+				c.Members = append(c.Members, g.memberItem(decl.GetName(), member))
+			}
+		}
+		data.Classes = append(data.Classes, c)
+	}
+	for _, enum := range root.GetEnums() {
+		if !g.Enum(enum) {
+			continue
+		}
+		e := &Enum{Name: enum.GetName(), Tags: enum.GetTags(), Items: []*Item{}}
+		e.Documentation, e.LearnMoreLink = g.entry(docs.EnumKey(e.Name))
+		for _, item := range enum.GetEnumItems() {
+			if !g.EnumItem(item) {
+				continue
+			}
+			i := &Item{
+				Label:      item.GetName(),
+				Kind:       KindEnumMember,
+				Detail:     "Enum." + e.Name + "." + item.GetName() + " = " + strconv.Itoa(item.GetValue()),
+				InsertText: item.GetName(),
+				Deprecated: item.GetTag("Deprecated"),
+				Tags:       item.GetTags(),
+			}
+			i.Documentation, i.LearnMoreLink = g.entry(docs.EnumItemKey(e.Name, item.GetName()))
+			e.Items = append(e.Items, i)
+		}
+		data.Enums = append(data.Enums, e)
+	}
+	return data
+}
+
+// typeString returns the name of a type as written in signatures.
+func typeString(typ rbxapi.Type) string {
+	if typ.GetCategory() == "Enum" {
+		return "Enum." + typ.GetName()
+	}
+	return typ.GetName()
+}
+
+// memberItem returns the completion item of a member declared by a class.
+func (g *Generator) memberItem(class string, member rbxapi.Member) *Item {
+	name := member.GetName()
+	item := &Item{
+		Label:      name,
+		InsertText: name,
+		Deprecated: member.GetTag("Deprecated"),
+		Class:      class,
+		MemberType: member.GetMemberType(),
+		Security:   rbxapi.MemberSecurity(member),
+		Tags:       member.GetTags(),
+	}
+	key := docs.MemberKey(class, name)
+	item.Documentation, item.LearnMoreLink = g.entry(key)
+	switch m := member.(type) {
+	case rbxapi.Property:
+		item.Kind = KindProperty
+		item.Detail = class + "." + name + ": " + typeString(m.GetValueType())
+	case rbxapi.Function:
+		params := g.parameters(key, m.GetParameters())
+		item.Parameters = params
+		signature := "(" + joinLabels(params) + "): " + typeString(m.GetReturnType())
+		if member.GetMemberType() == "Callback" {
+			item.Kind = KindField
+			item.Detail = class + "." + name + ": " + signature
+			break
+		}
+		item.Kind = KindMethod
+		item.Detail = class + ":" + name + signature
+		item.InsertText = snippet(name, m.GetParameters())
+		item.InsertTextFormat = insertTextSnippet
+	case rbxapi.Event:
+		params := g.parameters(key, m.GetParameters())
+		item.Parameters = params
+		item.Kind = KindEvent
+		item.Detail = class + "." + name + ": RBXScriptSignal(" + joinLabels(params) + ")"
+	}
+	return item
+}
+
+// parameters returns the parameters of the member documented by the entry of
+// the given key.
+func (g *Generator) parameters(key string, params rbxapi.Parameters) []Parameter {
+	list := params.GetParameters()
+	out := make([]Parameter, len(list))
+	var docParams []docs.Param
+	if entry := g.Docs.Get(key); entry != nil {
+		docParams = entry.Params
+	}
+	for i, param := range list {
+		label := param.GetName() + ": " + typeString(param.GetType())
+		if def, ok := param.GetDefault(); ok {
+			label += " = " + def
+		}
+		out[i].Label = label
+		for _, p := range docParams {
+			if p.Name == param.GetName() {
+				out[i].Documentation, _ = g.entry(p.Documentation)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func joinLabels(params []Parameter) string {
+	labels := make([]string, len(params))
+	for i, p := range params {
+		labels[i] = p.Label
+	}
+	return strings.Join(labels, ", ")
+}
+
+// snippetEscaper escapes characters that have meaning in snippets.
+var snippetEscaper = strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`)
+
+// snippet returns a snippet calling a method, with a placeholder for each
+// required parameter.
+func snippet(name string, params rbxapi.Parameters) string {
+	var args []string
+	for _, param := range params.GetParameters() {
+		if _, ok := param.GetDefault(); ok {
+			break
+		}
+		args = append(args, "${"+strconv.Itoa(len(args)+1)+":"+snippetEscaper.Replace(param.GetName())+"}")
+	}
+	return snippetEscaper.Replace(name) + "(" + strings.Join(args, ", ") + ")"
+}
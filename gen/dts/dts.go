@@ -0,0 +1,348 @@
+// The dts package generates TypeScript declarations from an API structure.
+//
+// Each class is declared as an interface extending its superclass, and enums
+// are declared within the Enum namespace. Data types referred to by the API
+// are declared as opaque interfaces, and the types common to all Roblox
+// scripts, such as RBXScriptSignal, are declared in a prelude.
+//
+// The package registers the "dts" target with the gen package.
+package dts
+
+import (
+	"bufio"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "roblox.d.ts"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "dts",
+		Summary: "TypeScript declarations",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates TypeScript declarations.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Filter selects the declared descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// prelude declares the types referred to by all declarations.
+const prelude = `
+type Callback = (...args: Array<any>) => any;
+type LuaTuple<T extends Array<unknown>> = T;
+
+interface EnumItem {
+	readonly Name: string;
+	readonly Value: number;
+	readonly EnumType: unknown;
+}
+
+interface RBXScriptConnection {
+	readonly Connected: boolean;
+	Disconnect(this: RBXScriptConnection): void;
+}
+
+interface RBXScriptSignal<T extends Callback = Callback> {
+	Connect(this: RBXScriptSignal, callback: T): RBXScriptConnection;
+	Wait(this: RBXScriptSignal): LuaTuple<Parameters<T>>;
+}
+`
+
+// preludeTypes contains the names of types declared by the prelude.
+var preludeTypes = map[string]bool{
+	"Callback":            true,
+	"LuaTuple":            true,
+	"EnumItem":            true,
+	"RBXScriptConnection": true,
+	"RBXScriptSignal":     true,
+}
+
+// reserved contains names that cannot be used as parameter names.
+var reserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true,
+	"do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true,
+	"import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true,
+}
+
+// isIdentifier returns whether s can be used as an identifier.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r == '$',
+			'A' <= r && r <= 'Z',
+			'a' <= r && r <= 'z',
+			i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// propertyName returns s as a property name, quoting it if necessary.
+func propertyName(s string) string {
+	if isIdentifier(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// paramName returns a valid parameter name for the parameter at index i.
+func paramName(s string, i int) string {
+	if !isIdentifier(s) {
+		return "arg" + strconv.Itoa(i)
+	}
+	if reserved[s] {
+		return s + "_"
+	}
+	return s
+}
+
+// writer accumulates output and the data types referred to.
+type writer struct {
+	*bufio.Writer
+	g         *Generator
+	root      rbxapi.Root
+	datatypes map[string]bool
+}
+
+// typeString returns the TypeScript type of an API type.
+func (w *writer) typeString(typ rbxapi.Type) string {
+	name := typ.GetName()
+	switch typ.GetCategory() {
+	case "Class":
+		if w.root.GetClass(name) == nil {
+			return "Instance | undefined"
+		}
+		return name + " | undefined"
+	case "Enum":
+		if w.root.GetEnum(name) == nil {
+			return "EnumItem"
+		}
+		return "Enum." + name
+	case "Group":
+		switch name {
+		case "Tuple":
+			return "LuaTuple<Array<unknown>>"
+		case "Array":
+			return "Array<unknown>"
+		case "Dictionary", "Map":
+			return "Map<unknown, unknown>"
+		}
+		return "unknown"
+	}
+	switch name {
+	case "bool":
+		return "boolean"
+	case "int", "int64", "float", "double", "number":
+		return "number"
+	case "string", "Content":
+		return "string"
+	case "void", "null":
+		return "void"
+	case "Variant", "any":
+		return "unknown"
+	case "Function":
+		return "Callback"
+	case "Objects":
+		return "Array<Instance>"
+	case "":
+		return "unknown"
+	}
+	if !isIdentifier(name) {
+		return "unknown"
+	}
+	w.datatypes[name] = true
+	return name
+}
+
+// params returns the parameter list of a member.
+func (w *writer) params(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	ss := make([]string, len(list))
+	for i, param := range list {
+		s := paramName(param.GetName(), i)
+		if _, ok := param.GetDefault(); ok {
+			s += "?"
+		}
+		ss[i] = s + ": " + w.typeString(param.GetType())
+	}
+	return strings.Join(ss, ", ")
+}
+
+// comment writes a documentation comment for the tags of a descriptor.
+func (w *writer) comment(indent string, t rbxapi.Taggable, security string) {
+	var lines []string
+	if t.GetTag("Deprecated") {
+		lines = append(lines, "@deprecated")
+	}
+	if security != "" && security != "None" {
+		lines = append(lines, "Security: "+security)
+	}
+	if tags := t.GetTags(); len(tags) > 0 {
+		lines = append(lines, "Tags: "+strings.Join(tags, ", "))
+	}
+	switch len(lines) {
+	case 0:
+	case 1:
+		w.WriteString(indent + "/** " + lines[0] + " */\n")
+	default:
+		w.WriteString(indent + "/**\n")
+		for _, line := range lines {
+			w.WriteString(indent + " * " + line + "\n")
+		}
+		w.WriteString(indent + " */\n")
+	}
+}
+
+// member writes the declaration of a member.
+func (w *writer) member(class rbxapi.Class, member rbxapi.Member) {
+	const indent = "\t"
+	w.comment(indent, member, rbxapi.MemberSecurity(member))
+	name := propertyName(member.GetName())
+	switch m := member.(type) {
+	case rbxapi.Property:
+		w.WriteString(indent)
+		if m.GetTag("ReadOnly") {
+			w.WriteString("readonly ")
+		}
+		w.WriteString(name + ": " + w.typeString(m.GetValueType()) + ";\n")
+	case rbxapi.Function:
+		if member.GetMemberType() == "Callback" {
+			w.WriteString(indent + name + ": ((" + w.params(m.GetParameters()) + ") => " + w.typeString(m.GetReturnType()) + ") | undefined;\n")
+			break
+		}
+		params := "this: " + class.GetName()
+		if p := w.params(m.GetParameters()); p != "" {
+			params += ", " + p
+		}
+		w.WriteString(indent + name + "(" + params + "): " + w.typeString(m.GetReturnType()) + ";\n")
+	case rbxapi.Event:
+		w.WriteString(indent + "readonly " + name + ": RBXScriptSignal<(" + w.params(m.GetParameters()) + ") => void>;\n")
+	}
+}
+
+// Write writes the declarations of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	bw := &writer{Writer: bufio.NewWriter(w), g: g, root: root, datatypes: map[string]bool{}}
+	bw.WriteString("// Generated from the Roblox API dump. DO NOT EDIT.\n")
+	bw.WriteString(prelude)
+
+	classes := root.GetClasses()
+	for _, class := range classes {
+		if !g.Class(class) {
+			continue
+		}
+		bw.WriteString("\n")
+		bw.comment("", class, "")
+		bw.WriteString("interface " + class.GetName())
+		if super := root.GetClass(class.GetSuperclass()); super != nil && g.Class(super) {
+			bw.WriteString(" extends " + super.GetName())
+		}
+		bw.WriteString(" {\n")
+		for _, member := range class.GetMembers() {
+			if g.Member(member) {
+				bw.member(class, member)
+			}
+		}
+		bw.WriteString("}\n")
+	}
+
+	bw.WriteString("\ndeclare namespace Enum {\n")
+	for _, enum := range root.GetEnums() {
+		if !g.Enum(enum) {
+			continue
+		}
+		bw.comment("\t", enum, "")
+		bw.WriteString("\tnamespace " + enum.GetName() + " {\n")
+		var names []string
+		for _, item := range enum.GetEnumItems() {
+			if !g.EnumItem(item) || !isIdentifier(item.GetName()) {
+				continue
+			}
+			names = append(names, item.GetName())
+			bw.comment("\t\t", item, "")
+			bw.WriteString("\t\tinterface " + item.GetName() + " extends EnumItem {\n")
+			bw.WriteString("\t\t\treadonly Name: \"" + item.GetName() + "\";\n")
+			bw.WriteString("\t\t\treadonly Value: " + strconv.Itoa(item.GetValue()) + ";\n")
+			bw.WriteString("\t\t\treadonly EnumType: Enum." + enum.GetName() + ";\n")
+			bw.WriteString("\t\t}\n")
+			bw.WriteString("\t\tconst " + item.GetName() + ": " + item.GetName() + ";\n")
+		}
+		bw.WriteString("\t}\n")
+		bw.WriteString("\ttype " + enum.GetName() + " = ")
+		if len(names) == 0 {
+			bw.WriteString("never")
+		} else {
+			for i, name := range names {
+				if i > 0 {
+					bw.WriteString(" | ")
+				}
+				bw.WriteString(enum.GetName() + "." + name)
+			}
+		}
+		bw.WriteString(";\n")
+	}
+	bw.WriteString("}\n")
+
+	names := make([]string, 0, len(bw.datatypes))
+	for name := range bw.datatypes {
+		if root.GetClass(name) == nil && !preludeTypes[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		bw.WriteString("\n")
+	}
+	for _, name := range names {
+		bw.WriteString("interface " + name + " {}\n")
+	}
+	return bw.Flush()
+}
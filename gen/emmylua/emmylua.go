@@ -0,0 +1,312 @@
+// The emmylua package generates EmmyLua annotations from an API structure.
+//
+// The output is a definition file, marked with ---@meta, understood by Lua
+// language servers that support EmmyLua and LDoc-style annotations. Each
+// class is annotated with ---@class, inheriting from its superclass, and its
+// properties, events, and callbacks with ---@field. Functions are declared
+// as methods of the class, annotated with ---@param and ---@return. Each
+// enum is a class of items, and the enums are exposed through the global
+// Enum.
+//
+// Declarations are filtered in the same way as by the luau package, and
+// descriptors with names that are not valid Lua identifiers are omitted.
+//
+// The package registers the "emmylua" target with the gen package.
+package emmylua
+
+import (
+	"bufio"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/internal/luaname"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "roblox.lua"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "emmylua",
+		Summary: "EmmyLua annotations",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates EmmyLua annotations.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Filter selects the declared descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// prelude declares the types referred to by all declarations.
+const prelude = `
+---@class EnumItem
+---@field Name string
+---@field Value number
+---@field EnumType Enum
+
+---@class Enum
+---@field GetEnumItems fun(self: Enum): EnumItem[]
+
+---@class RBXScriptConnection
+---@field Connected boolean
+---@field Disconnect fun(self: RBXScriptConnection)
+
+---@class RBXScriptSignal
+---@field Connect fun(self: RBXScriptSignal, callback: function): RBXScriptConnection
+---@field Once fun(self: RBXScriptSignal, callback: function): RBXScriptConnection
+---@field Wait fun(self: RBXScriptSignal): ...
+`
+
+// paramName returns a valid parameter name for the parameter at index i. A
+// parameter named self would conflict with the self parameter of methods.
+func paramName(s string, i int) string {
+	if s == "self" || luaname.IsKeyword(s) {
+		return s + "_"
+	}
+	if luaname.IsName(s) {
+		return s
+	}
+	return "arg" + strconv.Itoa(i)
+}
+
+// enumType returns the name of the class of the items of an enum.
+func enumType(name string) string {
+	return "Enum." + name
+}
+
+// writer accumulates output.
+type writer struct {
+	*bufio.Writer
+	g    *Generator
+	root rbxapi.Root
+}
+
+// typeString returns the EmmyLua type of an API type.
+func (w *writer) typeString(typ rbxapi.Type) string {
+	name := typ.GetName()
+	switch typ.GetCategory() {
+	case "Class":
+		if class := w.root.GetClass(name); class == nil || !w.g.Class(class) || !luaname.IsName(name) {
+			return "Instance?"
+		}
+		return name + "?"
+	case "Enum":
+		if enum := w.root.GetEnum(name); enum == nil || !w.g.Enum(enum) || !luaname.IsName(name) {
+			return "EnumItem"
+		}
+		return enumType(name)
+	case "Group":
+		switch name {
+		case "Array", "Objects":
+			return "any[]"
+		case "Dictionary", "Map":
+			return "table<any, any>"
+		}
+		return "any"
+	}
+	switch name {
+	case "bool":
+		return "boolean"
+	case "int", "int64", "float", "double", "number":
+		return "number"
+	case "string", "Content":
+		return "string"
+	case "void", "null":
+		return "nil"
+	case "Function":
+		return "function"
+	case "Objects":
+		return "Instance[]"
+	}
+	if !luaname.IsName(name) {
+		return "any"
+	}
+	return name
+}
+
+// isTuple returns whether a type is a tuple.
+func isTuple(typ rbxapi.Type) bool {
+	return typ.GetCategory() == "Group" && typ.GetName() == "Tuple"
+}
+
+// funcType returns the type of a function with the given parameters.
+func (w *writer) funcType(self string, params rbxapi.Parameters, ret rbxapi.Type) string {
+	var ss []string
+	if self != "" {
+		ss = append(ss, "self: "+self)
+	}
+	list := params.GetParameters()
+	for i, param := range list {
+		if isTuple(param.GetType()) && i == len(list)-1 {
+			ss = append(ss, "...: any")
+			continue
+		}
+		name := paramName(param.GetName(), i)
+		if _, ok := param.GetDefault(); ok {
+			name += "?"
+		}
+		ss = append(ss, name+": "+w.typeString(param.GetType()))
+	}
+	s := "fun(" + strings.Join(ss, ", ") + ")"
+	if ret != nil && ret.GetName() != "void" {
+		if isTuple(ret) {
+			return s + ": ..."
+		}
+		s += ": " + w.typeString(ret)
+	}
+	return s
+}
+
+// comment writes a comment for the tags and security of a descriptor.
+func (w *writer) comment(indent string, t rbxapi.Taggable, security string) {
+	if security != "" && security != "None" {
+		w.WriteString(indent + "---Security: " + security + "\n")
+	}
+	if tags := t.GetTags(); len(tags) > 0 {
+		w.WriteString(indent + "---Tags: " + strings.Join(tags, ", ") + "\n")
+	}
+}
+
+// method writes the declaration of a function of a class.
+func (w *writer) method(class string, member rbxapi.Function) {
+	w.WriteString("\n")
+	w.comment("\t", member, member.GetSecurity())
+	if member.GetTag("Deprecated") {
+		w.WriteString("\t---@deprecated\n")
+	}
+	list := member.GetParameters().GetParameters()
+	names := make([]string, len(list))
+	for i, param := range list {
+		if isTuple(param.GetType()) && i == len(list)-1 {
+			names[i] = "..."
+			w.WriteString("\t---@param ... any\n")
+			continue
+		}
+		names[i] = paramName(param.GetName(), i)
+		opt := ""
+		if _, ok := param.GetDefault(); ok {
+			opt = "?"
+		}
+		w.WriteString("\t---@param " + names[i] + opt + " " + w.typeString(param.GetType()))
+		if def, ok := param.GetDefault(); ok {
+			w.WriteString(" Defaults to " + def + ".")
+		}
+		w.WriteString("\n")
+	}
+	if ret := member.GetReturnType(); isTuple(ret) {
+		w.WriteString("\t---@return any ...\n")
+	} else if ret.GetName() != "void" {
+		w.WriteString("\t---@return " + w.typeString(ret) + "\n")
+	}
+	w.WriteString("\tfunction " + class + ":" + member.GetName() + "(" + strings.Join(names, ", ") + ") end\n")
+}
+
+// Write writes the annotations of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	bw := &writer{Writer: bufio.NewWriter(w), g: g, root: root}
+	bw.WriteString("---@meta\n-- Generated from the Roblox API dump. DO NOT EDIT.\n")
+	bw.WriteString(prelude)
+
+	var enums []rbxapi.Enum
+	for _, enum := range root.GetEnums() {
+		if g.Enum(enum) && luaname.IsName(enum.GetName()) {
+			enums = append(enums, enum)
+		}
+	}
+	for _, enum := range enums {
+		name := enum.GetName()
+		bw.WriteString("\n")
+		bw.comment("", enum, "")
+		bw.WriteString("---@class " + enumType(name) + " : EnumItem\n\n")
+		bw.WriteString("---@class " + enumType(name) + "Items : Enum\n")
+		for _, item := range enum.GetEnumItems() {
+			if !g.EnumItem(item) || !luaname.IsName(item.GetName()) {
+				continue
+			}
+			bw.WriteString("---@field " + item.GetName() + " " + enumType(name) + "\n")
+		}
+	}
+
+	for _, class := range root.GetClasses() {
+		name := class.GetName()
+		if !g.Class(class) || !luaname.IsName(name) {
+			continue
+		}
+		// Each class is declared as a local within a block, so that the number
+		// of locals is not limited by the number of classes.
+		var methods []rbxapi.Function
+		bw.WriteString("\ndo\n")
+		bw.comment("\t", class, "")
+		bw.WriteString("\t---@class " + name)
+		if super := root.GetClass(class.GetSuperclass()); super != nil && g.Class(super) && luaname.IsName(super.GetName()) {
+			bw.WriteString(" : " + super.GetName())
+		}
+		bw.WriteString("\n")
+		for _, member := range class.GetMembers() {
+			if !g.Member(member) || !luaname.IsName(member.GetName()) {
+				continue
+			}
+			var typ string
+			switch m := member.(type) {
+			case rbxapi.Property:
+				typ = bw.typeString(m.GetValueType())
+			case rbxapi.Function:
+				if member.GetMemberType() != "Callback" {
+					methods = append(methods, m)
+					continue
+				}
+				typ = bw.funcType("", m.GetParameters(), m.GetReturnType()) + "?"
+			case rbxapi.Event:
+				typ = "RBXScriptSignal"
+			default:
+				continue
+			}
+			bw.WriteString("\t---@field " + member.GetName() + " " + typ + "\n")
+		}
+		bw.WriteString("\tlocal " + name + " = {}\n")
+		for _, m := range methods {
+			bw.method(name, m)
+		}
+		bw.WriteString("end\n")
+	}
+
+	bw.WriteString("\n---@class Enums\n")
+	for _, enum := range enums {
+		bw.WriteString("---@field " + enum.GetName() + " " + enumType(enum.GetName()) + "Items\n")
+	}
+	bw.WriteString("\n---@type Enums\nEnum = {}\n")
+	return bw.Flush()
+}
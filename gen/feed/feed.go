@@ -0,0 +1,352 @@
+// The feed package produces Atom and RSS feeds of changes to the API.
+//
+// Each entry of a feed describes the changes made by a version, rendered as
+// an HTML list. Entries may be created from the versions of an archive, or
+// from the builds reported by a watcher. A feed written by this package can
+// be read back, so that a long-running process can add entries to an
+// existing feed.
+//
+// When the feed has a link, each entry links to the page of its version in a
+// reference built by the site package at that location.
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/patch"
+	"html"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTitle is the title of a feed that has no title.
+const DefaultTitle = "Roblox API changes"
+
+// idPrefix prefixes the GUID of a version to form the identifier of an entry.
+const idPrefix = "urn:rbxapi:version:"
+
+// Feed is a feed of changes.
+type Feed struct {
+	// Title is the title of the feed.
+	Title string
+	// Link is the URL of the site described by the feed, such as a reference
+	// built by the site package. It may be empty.
+	Link string
+	// Entries contains the entries of the feed, newest first.
+	Entries []Entry
+}
+
+// Entry describes the changes made by a version.
+type Entry struct {
+	// GUID is the GUID of the version.
+	GUID string
+	// Title is the title of the entry.
+	Title string
+	// Updated is the time at which the version was deployed.
+	Updated time.Time
+	// Content is the description of the changes, in HTML.
+	Content string
+}
+
+// NewEntry returns an entry describing the actions that change prev into
+// next. If the date of next is unknown, the current time is used.
+func NewEntry(prev, next fetch.Version, actions []patch.Action) Entry {
+	e := Entry{
+		GUID:    next.GUID,
+		Title:   "Version " + next.Number.String(),
+		Updated: next.Date,
+	}
+	if e.Updated.IsZero() {
+		e.Updated = time.Now()
+	}
+	switch len(actions) {
+	case 0:
+		e.Title += ": no changes"
+	case 1:
+		e.Title += ": 1 change"
+	default:
+		e.Title += ": " + strconv.Itoa(len(actions)) + " changes"
+	}
+	var b strings.Builder
+	if prev.GUID != "" {
+		fmt.Fprintf(&b, "<p>Changes from version %s to %s.</p>\n", html.EscapeString(prev.Number.String()), html.EscapeString(next.Number.String()))
+	}
+	if len(actions) == 0 {
+		b.WriteString("<p>No changes to the API.</p>\n")
+	} else {
+		b.WriteString("<ul>\n")
+		for _, action := range actions {
+			fmt.Fprintf(&b, "<li class=\"%s\">%s</li>\n", strings.ToLower(action.GetType().String()), html.EscapeString(action.String()))
+		}
+		b.WriteString("</ul>\n")
+	}
+	e.Content = b.String()
+	return e
+}
+
+// Add adds an entry as the newest entry, replacing an existing entry of the
+// same version. If limit is greater than zero, the oldest entries are removed
+// so that the feed has at most limit entries.
+func (f *Feed) Add(e Entry, limit int) {
+	entries := []Entry{e}
+	for _, old := range f.Entries {
+		if old.GUID != e.GUID {
+			entries = append(entries, old)
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	f.Entries = entries
+}
+
+// FromArchive returns a feed of the versions of an archive that have a JSON
+// API dump. If limit is greater than zero, only the latest limit versions are
+// included.
+func FromArchive(ctx context.Context, a *archive.Archive, limit int) (*Feed, error) {
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var versions []fetch.Version
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; ok {
+			versions = append(versions, meta.Version)
+		}
+	}
+	// The version before the first included version is needed to describe
+	// its changes.
+	first := 0
+	if limit > 0 && len(versions) > limit {
+		first = len(versions) - limit
+	}
+	f := &Feed{}
+	var prev rbxapi.Root
+	for i := first - 1; i < len(versions); i++ {
+		if i < 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		v := versions[i]
+		root, err := a.JSONDump(ctx, v.GUID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.GUID, err)
+		}
+		if i >= first {
+			var pv fetch.Version
+			if i > 0 {
+				pv = versions[i-1]
+			}
+			actions := (&diff.Diff{Prev: prev, Next: root}).Diff()
+			f.Add(NewEntry(pv, v, actions), 0)
+		}
+		prev = root
+	}
+	return f, nil
+}
+
+// entryLink returns the URL of the page of a version.
+func (f *Feed) entryLink(guid string) string {
+	if f.Link == "" {
+		return ""
+	}
+	link := f.Link
+	if !strings.HasSuffix(link, "/") {
+		link += "/"
+	}
+	return link + "version/" + url.PathEscape(guid) + ".html"
+}
+
+func (f *Feed) title() string {
+	if f.Title == "" {
+		return DefaultTitle
+	}
+	return f.Title
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Link    *atomLink   `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssFeed struct {
+	XMLName     xml.Name  `xml:"rss"`
+	Version     string    `xml:"version,attr"`
+	Title       string    `xml:"channel>title"`
+	Link        string    `xml:"channel>link"`
+	Description string    `xml:"channel>description"`
+	Items       []rssItem `xml:"channel>item"`
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	xe := xml.NewEncoder(w)
+	xe.Indent("", "\t")
+	if err := xe.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteAtom writes the feed to w in the Atom format.
+func (f *Feed) WriteAtom(w io.Writer) error {
+	feed := atomFeed{ID: "urn:rbxapi:changes", Title: f.title(), Author: "rbxapi"}
+	if f.Link != "" {
+		feed.ID = f.Link
+		feed.Link = &atomLink{Href: f.Link}
+	}
+	// A feed without entries was last updated when it was written.
+	updated := time.Now()
+	if len(f.Entries) > 0 {
+		updated = f.Entries[0].Updated
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:      idPrefix + e.GUID,
+			Title:   e.Title,
+			Updated: e.Updated.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "html", Body: e.Content},
+		}
+		if link := f.entryLink(e.GUID); link != "" {
+			entry.Link = &atomLink{Href: link, Rel: "alternate"}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeXML(w, feed)
+}
+
+// WriteRSS writes the feed to w in the RSS 2.0 format.
+func (f *Feed) WriteRSS(w io.Writer) error {
+	feed := rssFeed{
+		Version:     "2.0",
+		Title:       f.title(),
+		Link:        f.Link,
+		Description: f.title(),
+	}
+	for _, e := range f.Entries {
+		feed.Items = append(feed.Items, rssItem{
+			Title:       e.Title,
+			Link:        f.entryLink(e.GUID),
+			GUID:        rssGUID{Value: idPrefix + e.GUID},
+			PubDate:     e.Updated.UTC().Format(time.RFC1123Z),
+			Description: e.Content,
+		})
+	}
+	return writeXML(w, feed)
+}
+
+// Read reads a feed in the Atom or RSS format, as written by WriteAtom or
+// WriteRSS. Entries that were not written by this package are ignored.
+func Read(r io.Reader) (*Feed, error) {
+	// The elements of both formats are decoded, distinguished by the name of
+	// the root element.
+	var data struct {
+		XMLName xml.Name
+		Title   string      `xml:"title"`
+		Link    *atomLink   `xml:"link"`
+		Entries []atomEntry `xml:"entry"`
+		Channel struct {
+			Title string    `xml:"title"`
+			Link  string    `xml:"link"`
+			Items []rssItem `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+	f := &Feed{}
+	switch data.XMLName.Local {
+	case "feed":
+		f.Title = data.Title
+		if data.Link != nil {
+			f.Link = data.Link.Href
+		}
+		for _, entry := range data.Entries {
+			if !strings.HasPrefix(entry.ID, idPrefix) {
+				continue
+			}
+			updated, err := time.Parse(time.RFC3339, entry.Updated)
+			if err != nil {
+				return nil, err
+			}
+			f.Entries = append(f.Entries, Entry{
+				GUID:    strings.TrimPrefix(entry.ID, idPrefix),
+				Title:   entry.Title,
+				Updated: updated,
+				Content: entry.Content.Body,
+			})
+		}
+	case "rss":
+		f.Title = data.Channel.Title
+		f.Link = data.Channel.Link
+		for _, item := range data.Channel.Items {
+			if !strings.HasPrefix(item.GUID.Value, idPrefix) {
+				continue
+			}
+			updated, err := time.Parse(time.RFC1123Z, item.PubDate)
+			if err != nil {
+				return nil, err
+			}
+			f.Entries = append(f.Entries, Entry{
+				GUID:    strings.TrimPrefix(item.GUID.Value, idPrefix),
+				Title:   item.Title,
+				Updated: updated,
+				Content: item.Description,
+			})
+		}
+	default:
+		return nil, errors.New("unknown feed element \"" + data.XMLName.Local + "\"")
+	}
+	return f, nil
+}
@@ -0,0 +1,166 @@
+// The gen package provides a common interface for generators, which produce
+// code, documentation, and other files from an API structure.
+//
+// Generators are provided by subpackages, which register themselves as
+// targets when imported:
+//
+//	import _ "github.com/karl-police/rbxapi/gen/dts"
+package gen
+
+import (
+	"errors"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Output receives the files produced by a generator.
+type Output interface {
+	// Create creates a file of the given slash-separated name, relative to
+	// the output. The file is complete when it is closed.
+	Create(name string) (io.WriteCloser, error)
+}
+
+// Dir is an Output that writes files to a directory of the file system.
+// Subdirectories are created as needed.
+type Dir string
+
+// Create implements the Output interface.
+func (d Dir) Create(name string) (io.WriteCloser, error) {
+	path := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// Generator produces files from an API structure.
+type Generator interface {
+	// Generate writes the files produced from root to out.
+	Generate(root rbxapi.Root, out Output) error
+}
+
+// Flagger is implemented by generators with options that can be configured
+// from command-line flags.
+type Flagger interface {
+	// Flags defines a flag for each option on fs.
+	Flags(fs *flag.FlagSet)
+}
+
+// Target describes a registered generator.
+type Target struct {
+	// Name is the name of the target, such as "dts".
+	Name string
+	// Summary is a one-line description of the target.
+	Summary string
+	// New returns a generator with default options.
+	New func() Generator
+}
+
+var targets = map[string]Target{}
+
+// Register adds a target, replacing any existing target of the same name.
+func Register(target Target) {
+	targets[target.Name] = target
+}
+
+// Targets returns the registered targets, ordered by name.
+func Targets() []Target {
+	list := make([]Target, 0, len(targets))
+	for _, t := range targets {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Lookup returns the target of the given name.
+func Lookup(name string) (target Target, ok bool) {
+	target, ok = targets[name]
+	return target, ok
+}
+
+// Filter selects the descriptors included by a generator. The zero value
+// includes everything.
+type Filter struct {
+	// Deprecated excludes descriptors that have the Deprecated tag.
+	Deprecated bool
+	// Hidden excludes descriptors that have the Hidden or NotBrowsable tag.
+	Hidden bool
+	// Security, if not empty, excludes members that require a security
+	// context more restrictive than the given context.
+	Security string
+}
+
+// Flags defines flags for the options of the filter.
+func (f *Filter) Flags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.Deprecated, "strip-deprecated", f.Deprecated, "exclude deprecated descriptors")
+	fs.BoolVar(&f.Hidden, "strip-hidden", f.Hidden, "exclude hidden and non-browsable descriptors")
+	fs.StringVar(&f.Security, "max-security", f.Security, "exclude members requiring a security `context` more restrictive than this")
+}
+
+// Check returns an error if the options of the filter are invalid.
+func (f *Filter) Check() error {
+	if f.Security != "" && !rbxapi.ParseSecurity(f.Security).Known() {
+		return errors.New("unknown security context \"" + f.Security + "\"")
+	}
+	return nil
+}
+
+func (f *Filter) tags(t rbxapi.Taggable) bool {
+	if f.Deprecated && t.GetTag("Deprecated") {
+		return false
+	}
+	if f.Hidden && (t.GetTag("Hidden") || t.GetTag("NotBrowsable")) {
+		return false
+	}
+	return true
+}
+
+// Class returns whether a class is included.
+func (f *Filter) Class(class rbxapi.Class) bool {
+	return f.tags(class)
+}
+
+// Member returns whether a member is included.
+func (f *Filter) Member(member rbxapi.Member) bool {
+	if !f.tags(member) {
+		return false
+	}
+	if f.Security != "" {
+		if !rbxapi.MemberAccessible(member, rbxapi.ParseSecurity(f.Security)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Enum returns whether an enum is included.
+func (f *Filter) Enum(enum rbxapi.Enum) bool {
+	return f.tags(enum)
+}
+
+// EnumItem returns whether an enum item is included.
+func (f *Filter) EnumItem(item rbxapi.EnumItem) bool {
+	return f.tags(item)
+}
+
+// Superclasses returns the names of the classes from which class inherits,
+// nearest first. Inheritance ends at a class that is not present in root.
+func Superclasses(root rbxapi.Root, class rbxapi.Class) []string {
+	var list []string
+	seen := map[string]bool{class.GetName(): true}
+	for name := class.GetSuperclass(); !seen[name]; {
+		super := root.GetClass(name)
+		if super == nil {
+			break
+		}
+		seen[name] = true
+		list = append(list, name)
+		name = super.GetSuperclass()
+	}
+	return list
+}
@@ -0,0 +1,240 @@
+// The golang package generates Go source code from an API structure.
+//
+// The produced file declares a type for each enum, with a constant for each
+// of its items. A constant is named by joining the names of the enum and the
+// item, such that the item Material.Plastic is declared as MaterialPlastic,
+// of type Material. Each enum type has a String method that returns the name
+// of an item.
+//
+// The file also declares the following variables:
+//
+//	Classes    set of the names of all classes
+//	IsService  set of the names of classes that have the Service tag
+//	Enums      set of the names of all enums
+//
+// Names that are not valid Go identifiers have invalid characters replaced
+// with underscores.
+//
+// The package registers the "go" target with the gen package.
+package golang
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "api.go"
+
+// DefaultPackage is the name of the package declared when Generator.Package
+// is empty.
+const DefaultPackage = "api"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "go",
+		Summary: "Go enum constants and class name sets",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates Go source code.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Package is the name of the declared package.
+	Package string
+	// Filter selects the declared descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	fs.StringVar(&g.Package, "package", DefaultPackage, "`name` of the declared package")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// reserved contains the names declared by the file that are not derived from
+// enums.
+var reserved = map[string]bool{
+	"Classes":   true,
+	"IsService": true,
+	"Enums":     true,
+}
+
+// sanitize replaces the characters of s that cannot appear in an identifier
+// with underscores.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// identifier returns s as an exported Go identifier.
+func identifier(s string) string {
+	id := sanitize(s)
+	if r := []rune(id); len(r) == 0 || !unicode.IsUpper(r[0]) {
+		id = "X" + id
+	}
+	return id
+}
+
+// namer produces unique identifiers.
+type namer map[string]bool
+
+// name returns an identifier derived from s that has not yet been returned.
+func (n namer) name(s string) string {
+	id := identifier(s)
+	for n[id] || reserved[id] {
+		id += "_"
+	}
+	n[id] = true
+	return id
+}
+
+// Write writes the Go source file produced from root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	pkg := g.Package
+	if pkg == "" {
+		pkg = DefaultPackage
+	}
+	if !token.IsIdentifier(pkg) {
+		return errors.New("invalid package name " + strconv.Quote(pkg))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by rbxapi; DO NOT EDIT.\n\n")
+	b.WriteString("package " + pkg + "\n")
+
+	var classes, services []string
+	for _, class := range root.GetClasses() {
+		if !g.Filter.Class(class) {
+			continue
+		}
+		classes = append(classes, class.GetName())
+		if rbxapiconv.HasTag(class, "Service") {
+			services = append(services, class.GetName())
+		}
+	}
+	var enums []rbxapi.Enum
+	for _, enum := range root.GetEnums() {
+		if g.Filter.Enum(enum) {
+			enums = append(enums, enum)
+		}
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].GetName() < enums[j].GetName() })
+	enumNames := make([]string, len(enums))
+	for i, enum := range enums {
+		enumNames[i] = enum.GetName()
+	}
+	if len(enums) > 0 {
+		// Used by the String method of each enum.
+		b.WriteString("\nimport \"strconv\"\n")
+	}
+
+	writeSet(&b, "Classes", "contains the name of every class.", classes)
+	writeSet(&b, "IsService", "contains the name of every class that has the Service tag.", services)
+	writeSet(&b, "Enums", "contains the name of every enum.", enumNames)
+
+	// Types are named before constants, so that the name of a type is not
+	// taken by a constant of another enum.
+	n := namer{}
+	types := make([]string, len(enums))
+	for i, enum := range enums {
+		types[i] = n.name(enum.GetName())
+	}
+	for i, enum := range enums {
+		g.writeEnum(&b, n, types[i], enum)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// writeSet writes a variable containing a set of strings.
+func writeSet(b *bytes.Buffer, name, doc string, list []string) {
+	list = append([]string(nil), list...)
+	sort.Strings(list)
+	b.WriteString("\n// " + name + " " + doc + "\n")
+	b.WriteString("var " + name + " = map[string]bool{\n")
+	for _, s := range list {
+		b.WriteString(strconv.Quote(s) + ": true,\n")
+	}
+	b.WriteString("}\n")
+}
+
+// writeEnum writes the type, constants, and String method of an enum.
+func (g *Generator) writeEnum(b *bytes.Buffer, n namer, typ string, enum rbxapi.Enum) {
+	name := enum.GetName()
+	b.WriteString("\n// " + typ + " is an item of the " + name + " enum.\n")
+	b.WriteString("type " + typ + " int\n")
+
+	var names []string
+	var values []int
+	for _, item := range enum.GetEnumItems() {
+		if !g.Filter.EnumItem(item) {
+			continue
+		}
+		names = append(names, item.GetName())
+		values = append(values, item.GetValue())
+	}
+	if len(names) > 0 {
+		b.WriteString("\n// Items of the " + name + " enum.\nconst (\n")
+		for i, item := range names {
+			b.WriteString(n.name(typ+sanitize(item)) + " " + typ + " = " + strconv.Itoa(values[i]) + "\n")
+		}
+		b.WriteString(")\n")
+	}
+
+	b.WriteString("\n// String returns the name of the item, or the value if it is unknown.\n")
+	b.WriteString("func (v " + typ + ") String() string {\nswitch v {\n")
+	seen := map[int]bool{}
+	for i, item := range names {
+		// Items that share a value are named by the first.
+		if seen[values[i]] {
+			continue
+		}
+		seen[values[i]] = true
+		b.WriteString("case " + strconv.Itoa(values[i]) + ":\nreturn " + strconv.Quote(item) + "\n")
+	}
+	b.WriteString("}\nreturn \"" + typ + "(\" + strconv.Itoa(int(v)) + \")\"\n}\n")
+}
@@ -0,0 +1,30 @@
+package golang_test
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/gen/golang"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"strings"
+	"testing"
+)
+
+func TestServiceDump(t *testing.T) {
+	root, err := rbxapidump.Decode(strings.NewReader(`Class Instance [notCreatable]
+Class Workspace : Instance [notCreatable] [service]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := (&golang.Generator{}).Write(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	i := strings.Index(src, "var IsService = map[string]bool{\n")
+	if i < 0 {
+		t.Fatalf("IsService not declared:\n%s", src)
+	}
+	if set := src[i : i+strings.Index(src[i:], "}")]; !strings.Contains(set, `"Workspace": true`) {
+		t.Errorf("Workspace not in IsService:\n%s", src)
+	}
+}
@@ -0,0 +1,377 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Object is a value of an object type of the schema.
+type Object interface {
+	// TypeName returns the name of the type of the object.
+	TypeName() string
+	// Field resolves the field of the given name, with the given arguments.
+	// Argument values are of type string, int, float64, bool, nil,
+	// []interface{}, or map[string]interface{}; enum values are strings.
+	//
+	// The result is an Object, a list of values, a string, int, float64,
+	// bool, or nil. Field returns ErrUnknownField if the object has no field
+	// of the given name.
+	Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error)
+}
+
+// ErrUnknownField is returned by Object.Field when the object has no field
+// of the given name.
+var ErrUnknownField = errors.New("unknown field")
+
+// interfaces maps each object type to the interfaces it implements.
+var interfaces = map[string][]string{
+	"Property": {"Member"},
+	"Function": {"Member"},
+	"Event":    {"Member"},
+	"Callback": {"Member"},
+}
+
+// isType returns whether an object is of the given type, or implements the
+// given interface.
+func isType(obj Object, name string) bool {
+	typ := obj.TypeName()
+	if typ == name {
+		return true
+	}
+	for _, iface := range interfaces[typ] {
+		if iface == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Request is a GraphQL request.
+type Request struct {
+	// Query is the source of the query document.
+	Query string `json:"query"`
+	// OperationName selects the operation to execute. It may be empty if
+	// the document contains one operation.
+	OperationName string `json:"operationName,omitempty"`
+	// Variables contains the values of the variables of the operation.
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Error is an error that occurred while executing a request.
+type Error struct {
+	Message string `json:"message"`
+	// Path locates the field that produced the error, as a list of
+	// response keys and list indices.
+	Path []interface{} `json:"path,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Response is the result of executing a request.
+type Response struct {
+	// Data is the result of the operation. It is nil if the request could
+	// not be executed.
+	Data *Result `json:"data"`
+	// Errors contains the errors that occurred.
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Result is a JSON object whose fields are encoded in the order of the
+// query.
+type Result struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// set sets a field of the result.
+func (r *Result) set(key string, v interface{}) {
+	if _, ok := r.Values[key]; !ok {
+		r.Keys = append(r.Keys, key)
+	}
+	r.Values[key] = v
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range r.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(r.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// executor holds the state of an executing request.
+type executor struct {
+	ctx       context.Context
+	doc       *document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// Execute executes a request against the given query object, which
+// resolves the fields of the Query type.
+func Execute(ctx context.Context, query Object, req Request) *Response {
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	var op *operation
+	for _, o := range doc.operations {
+		if req.OperationName == "" && len(doc.operations) == 1 || o.name == req.OperationName {
+			op = o
+			break
+		}
+	}
+	if op == nil {
+		if req.OperationName == "" {
+			return &Response{Errors: []*Error{{Message: "operation name required for document with multiple operations"}}}
+		}
+		return &Response{Errors: []*Error{{Message: "unknown operation " + strconv.Quote(req.OperationName)}}}
+	}
+	if op.kind != "query" {
+		return &Response{Errors: []*Error{{Message: op.kind + " operations are not supported"}}}
+	}
+	e := &executor{ctx: ctx, doc: doc, variables: map[string]interface{}{}}
+	for _, v := range op.variables {
+		if value, ok := req.Variables[v.name]; ok {
+			e.variables[v.name] = value
+		} else if v.def.kind != "" {
+			value, err := e.value(v.def)
+			if err != nil {
+				return &Response{Errors: []*Error{{Message: err.Error()}}}
+			}
+			e.variables[v.name] = value
+		}
+	}
+	data := e.selectionSet(query, op.selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// value evaluates a value of a query.
+func (e *executor) value(v value) (interface{}, error) {
+	switch v.kind {
+	case "variable":
+		return e.variables[v.text], nil
+	case "int":
+		n, err := strconv.Atoi(v.text)
+		if err != nil {
+			return nil, errors.New("integer " + v.text + " out of range")
+		}
+		return n, nil
+	case "float":
+		return strconv.ParseFloat(v.text, 64)
+	case "string", "enum":
+		return v.text, nil
+	case "boolean":
+		return v.text == "true", nil
+	case "list":
+		list := make([]interface{}, len(v.list))
+		for i, item := range v.list {
+			var err error
+			if list[i], err = e.value(item); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case "object":
+		obj := map[string]interface{}{}
+		for _, field := range v.fields {
+			var err error
+			if obj[field.name], err = e.value(field.value); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	}
+	return nil, nil
+}
+
+// arguments evaluates a list of arguments.
+func (e *executor) arguments(list []argument) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(list))
+	for _, a := range list {
+		if a.value.kind == "variable" {
+			if _, ok := e.variables[a.value.text]; !ok {
+				continue
+			}
+		}
+		v, err := e.value(a.value)
+		if err != nil {
+			return nil, err
+		}
+		args[a.name] = v
+	}
+	return args, nil
+}
+
+// included evaluates the @skip and @include directives of a selection.
+func (e *executor) included(directives []directive) (bool, error) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			continue
+		}
+		args, err := e.arguments(d.arguments)
+		if err != nil {
+			return false, err
+		}
+		cond, ok := args["if"].(bool)
+		if !ok {
+			return false, errors.New("directive @" + d.name + " requires a Boolean argument \"if\"")
+		}
+		if cond == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// collect appends the fields selected on obj to fields, flattening
+// fragments.
+func (e *executor) collect(obj Object, selections []selection, fields []selection, visited map[string]bool) ([]selection, error) {
+	for _, s := range selections {
+		ok, err := e.included(s.directives)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		switch {
+		case s.isField():
+			fields = append(fields, s)
+		case s.spread != "":
+			if visited[s.spread] {
+				continue
+			}
+			visited[s.spread] = true
+			f, ok := e.doc.fragments[s.spread]
+			if !ok {
+				return nil, errors.New("unknown fragment " + strconv.Quote(s.spread))
+			}
+			if !isType(obj, f.on) {
+				continue
+			}
+			if fields, err = e.collect(obj, f.selections, fields, visited); err != nil {
+				return nil, err
+			}
+		default:
+			if s.on != "" && !isType(obj, s.on) {
+				continue
+			}
+			if fields, err = e.collect(obj, s.selections, fields, visited); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fields, nil
+}
+
+// fail records an error at a path.
+func (e *executor) fail(path []interface{}, err error) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Path: append([]interface{}{}, path...)})
+}
+
+// selectionSet resolves the selected fields of obj.
+func (e *executor) selectionSet(obj Object, selections []selection, path []interface{}) *Result {
+	fields, err := e.collect(obj, selections, nil, map[string]bool{})
+	if err != nil {
+		e.fail(path, err)
+		return nil
+	}
+	// Fields of the same response key are merged.
+	var keys []string
+	groups := map[string][]selection{}
+	for _, f := range fields {
+		if _, ok := groups[f.key()]; !ok {
+			keys = append(keys, f.key())
+		}
+		groups[f.key()] = append(groups[f.key()], f)
+	}
+	result := &Result{Values: map[string]interface{}{}}
+	for _, key := range keys {
+		group := groups[key]
+		f := group[0]
+		for _, g := range group[1:] {
+			f.selections = append(f.selections[:len(f.selections):len(f.selections)], g.selections...)
+		}
+		result.set(key, nil)
+		fieldPath := append(path, key)
+		if f.name == "__typename" {
+			result.set(key, obj.TypeName())
+			continue
+		}
+		if err := e.ctx.Err(); err != nil {
+			e.fail(fieldPath, err)
+			continue
+		}
+		args, err := e.arguments(f.arguments)
+		if err != nil {
+			e.fail(fieldPath, err)
+			continue
+		}
+		v, err := obj.Field(e.ctx, f.name, args)
+		if err == ErrUnknownField {
+			err = errors.New("cannot query field " + strconv.Quote(f.name) + " on type " + strconv.Quote(obj.TypeName()))
+		}
+		if err != nil {
+			e.fail(fieldPath, err)
+			continue
+		}
+		result.set(key, e.complete(v, f.selections, fieldPath))
+	}
+	return result
+}
+
+// complete resolves the selected fields of a value.
+func (e *executor) complete(v interface{}, selections []selection, path []interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case Object:
+		if len(selections) == 0 {
+			e.fail(path, errors.New("field of type "+strconv.Quote(v.TypeName())+" must have a selection of subfields"))
+			return nil
+		}
+		if r := e.selectionSet(v, selections, path); r != nil {
+			return r
+		}
+		return nil
+	case []Object:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = e.complete(item, selections, append(path, i))
+		}
+		return list
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = e.complete(item, selections, append(path, i))
+		}
+		return list
+	}
+	if len(selections) > 0 {
+		e.fail(path, errors.New("field of scalar type cannot have a selection of subfields"))
+		return nil
+	}
+	return v
+}
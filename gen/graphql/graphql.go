@@ -0,0 +1,186 @@
+// The graphql package provides a GraphQL query layer over API structures.
+//
+// The package generates the GraphQL schema of the layer, and provides
+// resolvers that execute queries against a single Root, or against every
+// version of an archive. Handler serves the resolvers over HTTP.
+//
+// The executor implements the query language of GraphQL, including
+// variables, aliases, fragments, and the @include and @skip directives.
+// Queries are not validated against the schema before execution; a field
+// that does not exist is reported as an error during execution instead.
+// Introspection is limited to the __typename field.
+//
+// The package registers the "graphql" target with the gen package, which
+// writes the schema.
+package graphql
+
+import (
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"io"
+)
+
+// Schema is the GraphQL schema implemented by the resolvers of the package.
+const Schema = `schema {
+	query: Query
+}
+
+type Query {
+	"The versions of the archive, oldest first. Empty when bound to a single root."
+	versions(first: Int, last: Int): [Version!]!
+	"A version of the archive, by GUID or number, or \"latest\"."
+	version(id: String!): Version
+	"The bound root, or the API of the latest version of the archive."
+	api: API
+}
+
+type Version {
+	guid: String!
+	number: String!
+	channel: String
+	"The deployment date, formatted as RFC 3339."
+	date: String
+	api: API
+}
+
+type API {
+	classes(tag: String, superclass: String): [Class!]!
+	class(name: String!): Class
+	enums(tag: String): [Enum!]!
+	enum(name: String!): Enum
+}
+
+type Class {
+	name: String!
+	superclassName: String!
+	superclass: Class
+	"The classes from which the class inherits, nearest first."
+	superclasses: [Class!]!
+	subclasses: [Class!]!
+	tags: [String!]!
+	"Members of the class. With inherited, includes the members of superclasses."
+	members(memberType: MemberType, tag: String, inherited: Boolean): [Member!]!
+	member(name: String!): Member
+}
+
+enum MemberType {
+	Property
+	Function
+	Event
+	Callback
+}
+
+interface Member {
+	name: String!
+	memberType: MemberType!
+	class: Class!
+	security: String!
+	tags: [String!]!
+}
+
+type Property implements Member {
+	name: String!
+	memberType: MemberType!
+	class: Class!
+	security: String!
+	tags: [String!]!
+	readSecurity: String!
+	writeSecurity: String!
+	valueType: Type!
+}
+
+type Function implements Member {
+	name: String!
+	memberType: MemberType!
+	class: Class!
+	security: String!
+	tags: [String!]!
+	parameters: [Parameter!]!
+	returnType: Type!
+}
+
+type Event implements Member {
+	name: String!
+	memberType: MemberType!
+	class: Class!
+	security: String!
+	tags: [String!]!
+	parameters: [Parameter!]!
+}
+
+type Callback implements Member {
+	name: String!
+	memberType: MemberType!
+	class: Class!
+	security: String!
+	tags: [String!]!
+	parameters: [Parameter!]!
+	returnType: Type!
+}
+
+type Parameter {
+	name: String!
+	type: Type!
+	default: String
+}
+
+type Type {
+	category: String!
+	name: String!
+}
+
+type Enum {
+	name: String!
+	tags: [String!]!
+	items: [EnumItem!]!
+	item(name: String!): EnumItem
+}
+
+type EnumItem {
+	name: String!
+	value: Int!
+	tags: [String!]!
+	enum: Enum!
+}
+`
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "schema.graphql"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "graphql",
+		Summary: "GraphQL schema",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates the GraphQL schema. The schema does not depend on the
+// content of the API structure.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, Schema); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
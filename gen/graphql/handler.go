@@ -0,0 +1,64 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// Handler returns an HTTP handler that executes requests against the given
+// query object.
+//
+// A GET request takes the request from the query, operationName, and
+// variables parameters of the URL, where variables is a JSON object. A POST
+// request takes the request from a JSON body. The response is a JSON
+// Response.
+func Handler(query Object) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		switch r.Method {
+		case "GET":
+			q := r.URL.Query()
+			req.Query = q.Get("query")
+			req.OperationName = q.Get("operationName")
+			if v := q.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					respond(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "variables: " + err.Error()}}})
+					return
+				}
+			}
+		case "POST":
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				respond(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: err.Error()}}})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			respond(w, http.StatusMethodNotAllowed, &Response{Errors: []*Error{{Message: "method not allowed"}}})
+			return
+		}
+		if req.Query == "" {
+			respond(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "missing query"}}})
+			return
+		}
+		resp := Execute(r.Context(), query, req)
+		status := http.StatusOK
+		if resp.Data == nil {
+			status = http.StatusBadRequest
+		}
+		respond(w, status, resp)
+	})
+}
+
+func respond(w http.ResponseWriter, status int, resp *Response) {
+	var buf bytes.Buffer
+	je := json.NewEncoder(&buf)
+	je.SetEscapeHTML(false)
+	if err := je.Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,515 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError indicates that a query could not be parsed.
+type SyntaxError struct {
+	// Offset is the byte offset in the query at which the error occurred.
+	Offset int
+	Msg    string
+}
+
+func (err *SyntaxError) Error() string {
+	return "syntax error at offset " + strconv.Itoa(err.Offset) + ": " + err.Msg
+}
+
+// document is a parsed query document.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is an operation definition.
+type operation struct {
+	kind       string
+	name       string
+	variables  []variable
+	directives []directive
+	selections []selection
+}
+
+// variable is a variable definition of an operation.
+type variable struct {
+	name string
+	def  value
+}
+
+// fragment is a fragment definition.
+type fragment struct {
+	name       string
+	on         string
+	directives []directive
+	selections []selection
+}
+
+// selection is a field, fragment spread, or inline fragment.
+type selection struct {
+	// Set for fields.
+	alias     string
+	name      string
+	arguments []argument
+	// Set for fragment spreads.
+	spread string
+	// Set for inline fragments, which have no name or spread.
+	on string
+
+	directives []directive
+	selections []selection
+}
+
+// isField returns whether the selection is a field.
+func (s *selection) isField() bool {
+	return s.name != ""
+}
+
+// key returns the response key of a field.
+func (s *selection) key() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+type argument struct {
+	name  string
+	value value
+}
+
+type directive struct {
+	name      string
+	arguments []argument
+}
+
+// value is a literal or variable reference in a query.
+type value struct {
+	// kind is one of "variable", "int", "float", "string", "boolean",
+	// "null", "enum", "list", or "object".
+	kind string
+	// text is the name of a variable or enum, or the source of a scalar.
+	text   string
+	list   []value
+	fields []argument
+}
+
+// parser parses a query document.
+type parser struct {
+	src string
+	pos int
+	// tok is the current token, and tokPos is its offset.
+	tok    string
+	tokPos int
+	// str is the decoded content of the current token, if it is a string.
+	str   string
+	isStr bool
+}
+
+func (p *parser) fail(msg string) {
+	panic(&SyntaxError{Offset: p.tokPos, Msg: msg})
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z'
+}
+
+func isNameByte(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
+
+// next advances to the next token. The empty token indicates the end of the
+// source.
+func (p *parser) next() {
+	// Skip ignored tokens.
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+			continue
+		}
+		if strings.HasPrefix(p.src[p.pos:], "\uFEFF") {
+			p.pos += len("\uFEFF")
+			continue
+		}
+		break
+	}
+	p.tokPos = p.pos
+	p.isStr = false
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		p.pos++
+	case isNameStart(c):
+		for p.pos < len(p.src) && isNameByte(p.src[p.pos]) {
+			p.pos++
+		}
+	case c == '-' || '0' <= c && c <= '9':
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+	case strings.HasPrefix(p.src[p.pos:], `"""`):
+		p.pos += 3
+		end := -1
+		for i := p.pos; i+3 <= len(p.src); i++ {
+			if p.src[i] == '\\' && strings.HasPrefix(p.src[i+1:], `"""`) {
+				i += 3
+				continue
+			}
+			if strings.HasPrefix(p.src[i:], `"""`) {
+				end = i - p.pos
+				break
+			}
+		}
+		if end < 0 {
+			p.fail("unterminated block string")
+		}
+		p.str = blockString(strings.ReplaceAll(p.src[p.pos:p.pos+end], `\"""`, `"""`))
+		p.isStr = true
+		p.pos += end + 3
+	case c == '"':
+		p.str = p.quoted()
+		p.isStr = true
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.fail("unexpected character " + strconv.QuoteRune(r))
+	}
+	p.tok = p.src[start:p.pos]
+}
+
+// quoted scans a string starting at the current position.
+func (p *parser) quoted() string {
+	var b strings.Builder
+	p.pos++
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+			p.fail("unterminated string")
+		}
+		c := p.src[p.pos]
+		if c == '"' {
+			p.pos++
+			return b.String()
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(p.src) {
+			p.fail("unterminated string")
+		}
+		esc := p.src[p.pos+1]
+		p.pos += 2
+		switch esc {
+		case '"', '\\', '/':
+			b.WriteByte(esc)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(p.src) {
+				p.fail("invalid unicode escape")
+			}
+			n, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				p.fail("invalid unicode escape")
+			}
+			b.WriteRune(rune(n))
+			p.pos += 4
+		default:
+			p.fail("invalid escape sequence")
+		}
+	}
+}
+
+// blockString returns the value of the raw content of a block string, with
+// common indentation and leading and trailing blank lines removed.
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isName returns whether the current token is a name.
+func (p *parser) isName() bool {
+	return p.tok != "" && isNameStart(p.tok[0])
+}
+
+// expect consumes the given punctuator.
+func (p *parser) expect(tok string) {
+	if p.tok != tok || p.isStr {
+		p.fail("expected " + strconv.Quote(tok))
+	}
+	p.next()
+}
+
+// name consumes a name.
+func (p *parser) name() string {
+	if !p.isName() || p.isStr {
+		p.fail("expected name")
+	}
+	name := p.tok
+	p.next()
+	return name
+}
+
+// parseDocument parses a query document.
+func parseDocument(src string) (doc *document, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			serr, ok := e.(*SyntaxError)
+			if !ok {
+				panic(e)
+			}
+			doc, err = nil, serr
+		}
+	}()
+	p := &parser{src: src}
+	p.next()
+	doc = &document{fragments: map[string]*fragment{}}
+	for p.tok != "" {
+		switch {
+		case p.tok == "{":
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: p.selectionSet()})
+		case p.tok == "fragment":
+			p.next()
+			f := &fragment{name: p.name()}
+			if f.name == "on" {
+				p.fail("fragment cannot be named \"on\"")
+			}
+			if p.name() != "on" {
+				p.fail("expected \"on\"")
+			}
+			f.on = p.name()
+			f.directives = p.directives()
+			f.selections = p.selectionSet()
+			if _, ok := doc.fragments[f.name]; ok {
+				p.fail("fragment " + f.name + " defined more than once")
+			}
+			doc.fragments[f.name] = f
+		case p.tok == "query" || p.tok == "mutation" || p.tok == "subscription":
+			op := &operation{kind: p.tok}
+			p.next()
+			if p.isName() {
+				op.name = p.name()
+			}
+			if p.tok == "(" {
+				p.next()
+				for p.tok != ")" {
+					p.expect("$")
+					v := variable{name: p.name()}
+					p.expect(":")
+					p.typeRef()
+					if p.tok == "=" {
+						p.next()
+						v.def = p.value(true)
+					}
+					p.directives()
+					op.variables = append(op.variables, v)
+				}
+				p.next()
+			}
+			op.directives = p.directives()
+			op.selections = p.selectionSet()
+			doc.operations = append(doc.operations, op)
+		default:
+			p.fail("expected definition")
+		}
+	}
+	if len(doc.operations) == 0 {
+		p.fail("document contains no operations")
+	}
+	return doc, nil
+}
+
+// typeRef consumes a type reference. Types of variables are not checked, so
+// the type is discarded.
+func (p *parser) typeRef() {
+	if p.tok == "[" {
+		p.next()
+		p.typeRef()
+		p.expect("]")
+	} else {
+		p.name()
+	}
+	if p.tok == "!" {
+		p.next()
+	}
+}
+
+func (p *parser) selectionSet() []selection {
+	p.expect("{")
+	var list []selection
+	for p.tok != "}" {
+		if p.tok == "" {
+			p.fail("expected \"}\"")
+		}
+		list = append(list, p.selection())
+	}
+	p.next()
+	if len(list) == 0 {
+		p.fail("empty selection set")
+	}
+	return list
+}
+
+func (p *parser) selection() selection {
+	var s selection
+	if p.tok == "..." {
+		p.next()
+		if p.isName() && p.tok != "on" {
+			s.spread = p.name()
+			s.directives = p.directives()
+			return s
+		}
+		if p.tok == "on" {
+			p.next()
+			s.on = p.name()
+		}
+		s.directives = p.directives()
+		s.selections = p.selectionSet()
+		return s
+	}
+	s.name = p.name()
+	if p.tok == ":" {
+		p.next()
+		s.alias, s.name = s.name, p.name()
+	}
+	s.arguments = p.arguments(false)
+	s.directives = p.directives()
+	if p.tok == "{" {
+		s.selections = p.selectionSet()
+	}
+	return s
+}
+
+func (p *parser) arguments(constant bool) []argument {
+	if p.tok != "(" {
+		return nil
+	}
+	p.next()
+	var list []argument
+	for p.tok != ")" {
+		a := argument{name: p.name()}
+		p.expect(":")
+		a.value = p.value(constant)
+		list = append(list, a)
+	}
+	p.next()
+	return list
+}
+
+func (p *parser) directives() []directive {
+	var list []directive
+	for p.tok == "@" {
+		p.next()
+		d := directive{name: p.name()}
+		d.arguments = p.arguments(false)
+		list = append(list, d)
+	}
+	return list
+}
+
+// value consumes a value. If constant is true, then variables are not
+// permitted.
+func (p *parser) value(constant bool) value {
+	var v value
+	switch {
+	case p.isStr:
+		v = value{kind: "string", text: p.str}
+		p.next()
+	case p.tok == "$":
+		if constant {
+			p.fail("unexpected variable")
+		}
+		p.next()
+		v = value{kind: "variable", text: p.name()}
+	case p.tok == "[":
+		p.next()
+		v.kind = "list"
+		v.list = []value{}
+		for p.tok != "]" {
+			if p.tok == "" {
+				p.fail("expected \"]\"")
+			}
+			v.list = append(v.list, p.value(constant))
+		}
+		p.next()
+	case p.tok == "{":
+		p.next()
+		v.kind = "object"
+		for p.tok != "}" {
+			a := argument{name: p.name()}
+			p.expect(":")
+			a.value = p.value(constant)
+			v.fields = append(v.fields, a)
+		}
+		p.next()
+	case p.tok == "true" || p.tok == "false":
+		v = value{kind: "boolean", text: p.tok}
+		p.next()
+	case p.tok == "null":
+		v = value{kind: "null"}
+		p.next()
+	case p.isName():
+		v = value{kind: "enum", text: p.name()}
+	case p.tok != "" && (p.tok[0] == '-' || '0' <= p.tok[0] && p.tok[0] <= '9'):
+		if _, err := strconv.ParseInt(p.tok, 10, 64); err == nil {
+			v = value{kind: "int", text: p.tok}
+		} else if _, err := strconv.ParseFloat(p.tok, 64); err == nil {
+			v = value{kind: "float", text: p.tok}
+		} else {
+			p.fail("invalid number " + strconv.Quote(p.tok))
+		}
+		p.next()
+	default:
+		p.fail("expected value")
+	}
+	return v
+}
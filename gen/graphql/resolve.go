@@ -0,0 +1,504 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/gen"
+	"strings"
+	"time"
+)
+
+// stringArg returns the string argument of the given name.
+func stringArg(args map[string]interface{}, name string) (string, bool, error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false, errors.New("argument \"" + name + "\" must be a String")
+	}
+	return s, true, nil
+}
+
+// intArg returns the integer argument of the given name.
+func intArg(args map[string]interface{}, name string) (int, bool, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return v, true, nil
+	case float64:
+		// Variables decoded from JSON are floats.
+		if v == float64(int(v)) {
+			return int(v), true, nil
+		}
+	}
+	return 0, false, errors.New("argument \"" + name + "\" must be an Int")
+}
+
+// boolArg returns the boolean argument of the given name.
+func boolArg(args map[string]interface{}, name string) (bool, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return false, errors.New("argument \"" + name + "\" must be a Boolean")
+}
+
+// tags returns the tags of a descriptor as a non-nil list.
+func tags(t rbxapi.Taggable) []interface{} {
+	list := []interface{}{}
+	for _, tag := range t.GetTags() {
+		list = append(list, tag)
+	}
+	return list
+}
+
+// Source provides the versions of an archive to the resolvers returned by
+// NewArchive.
+type Source interface {
+	// Versions returns the versions that have an API dump, oldest first.
+	Versions(ctx context.Context) ([]fetch.Version, error)
+	// Root returns the API dump of a version.
+	Root(ctx context.Context, guid string) (rbxapi.Root, error)
+}
+
+// archiveSource implements Source for an archive.
+type archiveSource struct {
+	archive *archive.Archive
+}
+
+// ArchiveSource returns a Source that reads from an archive. Dumps are
+// decoded each time they are requested.
+func ArchiveSource(a *archive.Archive) Source {
+	return archiveSource{archive: a}
+}
+
+// Versions implements the Source interface.
+func (s archiveSource) Versions(ctx context.Context) ([]fetch.Version, error) {
+	metas, err := s.archive.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []fetch.Version
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; ok {
+			list = append(list, meta.Version)
+		}
+	}
+	return list, nil
+}
+
+// Root implements the Source interface.
+func (s archiveSource) Root(ctx context.Context, guid string) (rbxapi.Root, error) {
+	return s.archive.JSONDump(ctx, guid)
+}
+
+// query resolves the Query type.
+type query struct {
+	// Exactly one of root or source is set.
+	root   rbxapi.Root
+	source Source
+}
+
+// NewRoot returns an Object that resolves the Query type for a single root.
+func NewRoot(root rbxapi.Root) Object {
+	return &query{root: root}
+}
+
+// NewArchive returns an Object that resolves the Query type for the versions
+// provided by source.
+func NewArchive(source Source) Object {
+	return &query{source: source}
+}
+
+func (q *query) TypeName() string { return "Query" }
+
+func (q *query) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "versions":
+		list := []Object{}
+		if q.source == nil {
+			return list, nil
+		}
+		versions, err := q.source.Versions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if n, ok, err := intArg(args, "first"); err != nil {
+			return nil, err
+		} else if ok && n >= 0 && n < len(versions) {
+			versions = versions[:n]
+		}
+		if n, ok, err := intArg(args, "last"); err != nil {
+			return nil, err
+		} else if ok && n >= 0 && n < len(versions) {
+			versions = versions[len(versions)-n:]
+		}
+		for _, v := range versions {
+			list = append(list, &version{source: q.source, version: v})
+		}
+		return list, nil
+	case "version":
+		id, _, err := stringArg(args, "id")
+		if err != nil {
+			return nil, err
+		}
+		if q.source == nil {
+			return nil, nil
+		}
+		versions, err := q.source.Versions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if id == "latest" {
+			if len(versions) == 0 {
+				return nil, nil
+			}
+			return &version{source: q.source, version: versions[len(versions)-1]}, nil
+		}
+		for _, v := range versions {
+			if strings.EqualFold(v.GUID, id) || v.Number.String() == id {
+				return &version{source: q.source, version: v}, nil
+			}
+		}
+		return nil, nil
+	case "api":
+		if q.source == nil {
+			return &api{root: q.root}, nil
+		}
+		versions, err := q.source.Versions(ctx)
+		if err != nil || len(versions) == 0 {
+			return nil, err
+		}
+		return (&version{source: q.source, version: versions[len(versions)-1]}).Field(ctx, "api", nil)
+	}
+	return nil, ErrUnknownField
+}
+
+// version resolves the Version type.
+type version struct {
+	source  Source
+	version fetch.Version
+}
+
+func (v *version) TypeName() string { return "Version" }
+
+func (v *version) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "guid":
+		return v.version.GUID, nil
+	case "number":
+		return v.version.Number.String(), nil
+	case "channel":
+		if v.version.Channel == "" {
+			return nil, nil
+		}
+		return v.version.Channel, nil
+	case "date":
+		if v.version.Date.IsZero() {
+			return nil, nil
+		}
+		return v.version.Date.Format(time.RFC3339), nil
+	case "api":
+		root, err := v.source.Root(ctx, v.version.GUID)
+		if err != nil {
+			return nil, err
+		}
+		return &api{root: root}, nil
+	}
+	return nil, ErrUnknownField
+}
+
+// api resolves the API type.
+type api struct {
+	root rbxapi.Root
+}
+
+func (a *api) TypeName() string { return "API" }
+
+func (a *api) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "classes":
+		tag, hasTag, err := stringArg(args, "tag")
+		if err != nil {
+			return nil, err
+		}
+		super, hasSuper, err := stringArg(args, "superclass")
+		if err != nil {
+			return nil, err
+		}
+		list := []Object{}
+		for _, c := range a.root.GetClasses() {
+			if hasTag && !c.GetTag(tag) || hasSuper && c.GetSuperclass() != super {
+				continue
+			}
+			list = append(list, &class{root: a.root, class: c})
+		}
+		return list, nil
+	case "class":
+		name, _, err := stringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if c := a.root.GetClass(name); c != nil {
+			return &class{root: a.root, class: c}, nil
+		}
+		return nil, nil
+	case "enums":
+		tag, hasTag, err := stringArg(args, "tag")
+		if err != nil {
+			return nil, err
+		}
+		list := []Object{}
+		for _, e := range a.root.GetEnums() {
+			if hasTag && !e.GetTag(tag) {
+				continue
+			}
+			list = append(list, &enum{enum: e})
+		}
+		return list, nil
+	case "enum":
+		name, _, err := stringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if e := a.root.GetEnum(name); e != nil {
+			return &enum{enum: e}, nil
+		}
+		return nil, nil
+	}
+	return nil, ErrUnknownField
+}
+
+// class resolves the Class type.
+type class struct {
+	root  rbxapi.Root
+	class rbxapi.Class
+}
+
+func (c *class) TypeName() string { return "Class" }
+
+func (c *class) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return c.class.GetName(), nil
+	case "superclassName":
+		return c.class.GetSuperclass(), nil
+	case "superclass":
+		if super := c.root.GetClass(c.class.GetSuperclass()); super != nil {
+			return &class{root: c.root, class: super}, nil
+		}
+		return nil, nil
+	case "superclasses":
+		list := []Object{}
+		for _, name := range gen.Superclasses(c.root, c.class) {
+			list = append(list, &class{root: c.root, class: c.root.GetClass(name)})
+		}
+		return list, nil
+	case "subclasses":
+		list := []Object{}
+		for _, sub := range c.root.GetClasses() {
+			if sub.GetSuperclass() == c.class.GetName() && sub.GetName() != c.class.GetName() {
+				list = append(list, &class{root: c.root, class: sub})
+			}
+		}
+		return list, nil
+	case "tags":
+		return tags(c.class), nil
+	case "members":
+		memberType, hasType, err := stringArg(args, "memberType")
+		if err != nil {
+			return nil, err
+		}
+		tag, hasTag, err := stringArg(args, "tag")
+		if err != nil {
+			return nil, err
+		}
+		inherited, err := boolArg(args, "inherited")
+		if err != nil {
+			return nil, err
+		}
+		classes := []rbxapi.Class{c.class}
+		if inherited {
+			for _, name := range gen.Superclasses(c.root, c.class) {
+				classes = append(classes, c.root.GetClass(name))
+			}
+		}
+		list := []Object{}
+		for _, owner := range classes {
+			for _, m := range owner.GetMembers() {
+				if hasType && m.GetMemberType() != memberType || hasTag && !m.GetTag(tag) {
+					continue
+				}
+				list = append(list, &member{class: &class{root: c.root, class: owner}, member: m})
+			}
+		}
+		return list, nil
+	case "member":
+		name, _, err := stringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if m := c.class.GetMember(name); m != nil {
+			return &member{class: c, member: m}, nil
+		}
+		return nil, nil
+	}
+	return nil, ErrUnknownField
+}
+
+// member resolves the types that implement the Member interface.
+type member struct {
+	class  *class
+	member rbxapi.Member
+}
+
+func (m *member) TypeName() string { return m.member.GetMemberType() }
+
+func (m *member) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return m.member.GetName(), nil
+	case "memberType":
+		return m.member.GetMemberType(), nil
+	case "class":
+		return m.class, nil
+	case "security":
+		return rbxapi.MemberSecurity(m.member), nil
+	case "tags":
+		return tags(m.member), nil
+	}
+	switch v := m.member.(type) {
+	case rbxapi.Property:
+		read, write := v.GetSecurity()
+		switch name {
+		case "readSecurity":
+			return read, nil
+		case "writeSecurity":
+			return write, nil
+		case "valueType":
+			return &typ{typ: v.GetValueType()}, nil
+		}
+	case rbxapi.Function:
+		switch name {
+		case "parameters":
+			return parameters(v.GetParameters()), nil
+		case "returnType":
+			return &typ{typ: v.GetReturnType()}, nil
+		}
+	case rbxapi.Event:
+		if name == "parameters" {
+			return parameters(v.GetParameters()), nil
+		}
+	}
+	return nil, ErrUnknownField
+}
+
+// parameters returns a list of parameters as objects.
+func parameters(params rbxapi.Parameters) []Object {
+	list := []Object{}
+	for _, p := range params.GetParameters() {
+		list = append(list, &parameter{param: p})
+	}
+	return list
+}
+
+// parameter resolves the Parameter type.
+type parameter struct {
+	param rbxapi.Parameter
+}
+
+func (p *parameter) TypeName() string { return "Parameter" }
+
+func (p *parameter) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return p.param.GetName(), nil
+	case "type":
+		return &typ{typ: p.param.GetType()}, nil
+	case "default":
+		if def, ok := p.param.GetDefault(); ok {
+			return def, nil
+		}
+		return nil, nil
+	}
+	return nil, ErrUnknownField
+}
+
+// typ resolves the Type type.
+type typ struct {
+	typ rbxapi.Type
+}
+
+func (t *typ) TypeName() string { return "Type" }
+
+func (t *typ) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "category":
+		return t.typ.GetCategory(), nil
+	case "name":
+		return t.typ.GetName(), nil
+	}
+	return nil, ErrUnknownField
+}
+
+// enum resolves the Enum type.
+type enum struct {
+	enum rbxapi.Enum
+}
+
+func (e *enum) TypeName() string { return "Enum" }
+
+func (e *enum) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return e.enum.GetName(), nil
+	case "tags":
+		return tags(e.enum), nil
+	case "items":
+		list := []Object{}
+		for _, item := range e.enum.GetEnumItems() {
+			list = append(list, &enumItem{enum: e, item: item})
+		}
+		return list, nil
+	case "item":
+		name, _, err := stringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if item := e.enum.GetEnumItem(name); item != nil {
+			return &enumItem{enum: e, item: item}, nil
+		}
+		return nil, nil
+	}
+	return nil, ErrUnknownField
+}
+
+// enumItem resolves the EnumItem type.
+type enumItem struct {
+	enum *enum
+	item rbxapi.EnumItem
+}
+
+func (i *enumItem) TypeName() string { return "EnumItem" }
+
+func (i *enumItem) Field(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return i.item.GetName(), nil
+	case "value":
+		return i.item.GetValue(), nil
+	case "tags":
+		return tags(i.item), nil
+	case "enum":
+		return i.enum, nil
+	}
+	return nil, ErrUnknownField
+}
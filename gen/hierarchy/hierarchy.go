@@ -0,0 +1,365 @@
+// The hierarchy package renders the class inheritance tree of an API
+// structure as a diagram.
+//
+// The tree may be restricted to the descendants of a class, and to classes
+// that have a given tag. When a class is excluded, its subclasses are
+// connected to the nearest included superclass, so that the tree remains
+// connected.
+//
+// The package registers the "dot" target with the gen package, which writes
+// the tree as a Graphviz graph, and the "mermaid" target, which writes the
+// tree as a Mermaid class diagram. Mermaid diagrams may also list the members
+// of each class, and associate classes with the classes referred to by their
+// members, which suits diagrams of a small subtree embedded in Markdown.
+package hierarchy
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is the format of a diagram.
+type Format int
+
+const (
+	// DOT is the graph description language of Graphviz.
+	DOT Format = iota
+	// Mermaid is the class diagram syntax of Mermaid.
+	Mermaid
+)
+
+// DefaultFile returns the name of the file produced when Generator.File is
+// empty.
+func (f Format) DefaultFile() string {
+	if f == Mermaid {
+		return "classes.mmd"
+	}
+	return "classes.dot"
+}
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "dot",
+		Summary: "class hierarchy as a Graphviz graph",
+		New:     func() gen.Generator { return &Generator{Format: DOT} },
+	})
+	gen.Register(gen.Target{
+		Name:    "mermaid",
+		Summary: "class hierarchy as a Mermaid diagram",
+		New:     func() gen.Generator { return &Generator{Format: Mermaid} },
+	})
+}
+
+// Generator renders the class hierarchy.
+type Generator struct {
+	// Format is the format of the diagram.
+	Format Format
+	// File is the name of the produced file.
+	File string
+	// Root, if not empty, restricts the tree to the named class and its
+	// descendants.
+	Root string
+	// Tag, if not empty, restricts the tree to classes that have the tag,
+	// in the spelling of either the JSON or the dump format.
+	Tag string
+	// Members, if greater than zero, is the maximum number of members listed
+	// in each class of a Mermaid diagram. Classes referred to by the types
+	// of listed members are connected to the class with an association
+	// labeled with the names of the members. Deprecated members are listed
+	// last.
+	Members int
+	// Filter selects the included classes. When Security is set, classes
+	// with members, none of which are included by the filter, are excluded.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", g.Format.DefaultFile(), "`name` of the produced file")
+	fs.StringVar(&g.Root, "root", g.Root, "include only the descendants of `class`")
+	fs.StringVar(&g.Tag, "tag", g.Tag, "include only classes with `tag`")
+	if g.Format == Mermaid {
+		fs.IntVar(&g.Members, "members", g.Members, "list up to `n` members of each class, with their type references")
+	}
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	if g.Root != "" && root.GetClass(g.Root) == nil {
+		return errors.New("unknown class \"" + g.Root + "\"")
+	}
+	name := g.File
+	if name == "" {
+		name = g.Format.DefaultFile()
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// include returns whether a class is included, disregarding Root.
+func (g *Generator) include(class rbxapi.Class) bool {
+	if !g.Class(class) || g.Tag != "" && !rbxapiconv.HasTag(class, g.Tag) {
+		return false
+	}
+	if g.Security == "" {
+		return true
+	}
+	members := class.GetMembers()
+	for _, member := range members {
+		if g.Member(member) {
+			return true
+		}
+	}
+	return len(members) == 0
+}
+
+// Edge connects a class to its nearest included superclass.
+type Edge struct {
+	Superclass string
+	Class      string
+}
+
+// Tree returns the included classes, ordered by name, and the edges between
+// them.
+func (g *Generator) Tree(root rbxapi.Root) (classes []rbxapi.Class, edges []Edge) {
+	parent := map[string]string{}
+	for _, class := range root.GetClasses() {
+		if !g.include(class) {
+			continue
+		}
+		// Root is an ancestor of a descendant regardless of whether it is
+		// included.
+		name := class.GetName()
+		descends := g.Root == "" || name == g.Root
+		for _, super := range gen.Superclasses(root, class) {
+			if super == g.Root {
+				descends = true
+			}
+			if _, ok := parent[name]; !ok && g.include(root.GetClass(super)) {
+				parent[name] = super
+			}
+		}
+		if !descends {
+			delete(parent, name)
+			continue
+		}
+		if _, ok := parent[name]; !ok {
+			parent[name] = ""
+		}
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].GetName() < classes[j].GetName() })
+	for _, class := range classes {
+		super := parent[class.GetName()]
+		if _, ok := parent[super]; ok && super != "" {
+			edges = append(edges, Edge{Superclass: super, Class: class.GetName()})
+		}
+	}
+	return classes, edges
+}
+
+// Write writes the diagram of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	classes, edges := g.Tree(root)
+	bw := bufio.NewWriter(w)
+	if g.Format == Mermaid {
+		g.writeMermaid(bw, classes, edges)
+	} else {
+		writeDOT(bw, classes, edges)
+	}
+	return bw.Flush()
+}
+
+// writeDOT writes a diagram as a Graphviz graph. Edges point from subclasses
+// to superclasses. Services are drawn in bold, classes that are not creatable
+// are dashed, and deprecated classes are gray.
+func writeDOT(w *bufio.Writer, classes []rbxapi.Class, edges []Edge) {
+	w.WriteString("digraph classes {\n")
+	w.WriteString("\trankdir=BT;\n")
+	w.WriteString("\tnode [shape=box];\n")
+	w.WriteString("\tedge [arrowhead=empty];\n")
+	for _, class := range classes {
+		var styles []string
+		if rbxapiconv.HasTag(class, "Service") {
+			styles = append(styles, "bold")
+		}
+		if rbxapiconv.HasTag(class, "NotCreatable") {
+			styles = append(styles, "dashed")
+		}
+		w.WriteString("\t" + strconv.Quote(class.GetName()))
+		var attrs []string
+		if len(styles) > 0 {
+			attrs = append(attrs, "style="+strconv.Quote(strings.Join(styles, ",")))
+		}
+		if rbxapiconv.HasTag(class, "Deprecated") {
+			attrs = append(attrs, "color=gray", "fontcolor=gray")
+		}
+		if len(attrs) > 0 {
+			w.WriteString(" [" + strings.Join(attrs, ", ") + "]")
+		}
+		w.WriteString(";\n")
+	}
+	for _, edge := range edges {
+		w.WriteString("\t" + strconv.Quote(edge.Class) + " -> " + strconv.Quote(edge.Superclass) + ";\n")
+	}
+	w.WriteString("}\n")
+}
+
+// mermaidName returns a name usable as a Mermaid class identifier.
+func mermaidName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '_',
+			'A' <= r && r <= 'Z',
+			'a' <= r && r <= 'z',
+			'0' <= r && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// members returns the members of a class listed in a Mermaid diagram, and
+// the number of included members that are not listed.
+func (g *Generator) members(class rbxapi.Class) (list []rbxapi.Member, rest int) {
+	var deprecated []rbxapi.Member
+	for _, member := range class.GetMembers() {
+		if !g.Member(member) {
+			continue
+		}
+		if rbxapiconv.HasTag(member, "Deprecated") {
+			deprecated = append(deprecated, member)
+		} else {
+			list = append(list, member)
+		}
+	}
+	list = append(list, deprecated...)
+	if len(list) > g.Members {
+		return list[:g.Members], len(list) - g.Members
+	}
+	return list, 0
+}
+
+// memberTypes returns the types referred to by a member.
+func memberTypes(member rbxapi.Member) (types []rbxapi.Type) {
+	switch m := member.(type) {
+	case rbxapi.Property:
+		return []rbxapi.Type{m.GetValueType()}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		types = append(types, m.GetReturnType())
+	}
+	if m, ok := member.(interface{ GetParameters() rbxapi.Parameters }); ok {
+		for _, param := range m.GetParameters().GetParameters() {
+			types = append(types, param.GetType())
+		}
+	}
+	return types
+}
+
+// mermaidParams returns the parameter list of a member.
+func mermaidParams(member rbxapi.Member) string {
+	m, ok := member.(interface{ GetParameters() rbxapi.Parameters })
+	if !ok {
+		return ""
+	}
+	params := m.GetParameters().GetParameters()
+	ss := make([]string, len(params))
+	for i, param := range params {
+		ss[i] = mermaidName(param.GetType().GetName()) + " " + mermaidName(param.GetName())
+	}
+	return strings.Join(ss, ", ")
+}
+
+// mermaidMember returns the line declaring a member in a Mermaid class.
+func mermaidMember(member rbxapi.Member) string {
+	name := mermaidName(member.GetName())
+	switch m := member.(type) {
+	case rbxapi.Property:
+		return "+" + mermaidName(m.GetValueType().GetName()) + " " + name
+	case rbxapi.Function:
+		ret := mermaidName(m.GetReturnType().GetName())
+		if member.GetMemberType() == "Callback" {
+			return "+" + name + "(" + mermaidParams(member) + ") callback " + ret
+		}
+		return "+" + name + "(" + mermaidParams(member) + ") " + ret
+	case rbxapi.Event:
+		return "+" + name + "(" + mermaidParams(member) + ") event"
+	}
+	return "+" + name
+}
+
+// writeMermaid writes a diagram as a Mermaid class diagram. The tags of a
+// class are written as an annotation.
+func (g *Generator) writeMermaid(w *bufio.Writer, classes []rbxapi.Class, edges []Edge) {
+	included := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		included[class.GetName()] = true
+	}
+	type reference struct{ from, to string }
+	var refs []reference
+	labels := map[reference][]string{}
+
+	w.WriteString("classDiagram\n")
+	for _, class := range classes {
+		name := mermaidName(class.GetName())
+		tags := class.GetTags()
+		var members []rbxapi.Member
+		var rest int
+		if g.Members > 0 {
+			members, rest = g.members(class)
+		}
+		if len(tags) == 0 && len(members) == 0 {
+			w.WriteString("\tclass " + name + "\n")
+			continue
+		}
+		w.WriteString("\tclass " + name + " {\n")
+		if len(tags) > 0 {
+			w.WriteString("\t\t<<" + strings.Join(tags, ", ") + ">>\n")
+		}
+		for _, member := range members {
+			w.WriteString("\t\t" + mermaidMember(member) + "\n")
+			for _, typ := range memberTypes(member) {
+				if typ.GetCategory() != "Class" || !included[typ.GetName()] || typ.GetName() == class.GetName() {
+					continue
+				}
+				ref := reference{from: class.GetName(), to: typ.GetName()}
+				if _, ok := labels[ref]; !ok {
+					refs = append(refs, ref)
+				}
+				if l := labels[ref]; len(l) == 0 || l[len(l)-1] != member.GetName() {
+					labels[ref] = append(l, member.GetName())
+				}
+			}
+		}
+		if rest > 0 {
+			w.WriteString("\t\t+..." + strconv.Itoa(rest) + " more\n")
+		}
+		w.WriteString("\t}\n")
+	}
+	for _, edge := range edges {
+		w.WriteString("\t" + mermaidName(edge.Superclass) + " <|-- " + mermaidName(edge.Class) + "\n")
+	}
+	for _, ref := range refs {
+		w.WriteString("\t" + mermaidName(ref.from) + " --> " + mermaidName(ref.to) + " : " + strings.Join(labels[ref], ", ") + "\n")
+	}
+}
@@ -0,0 +1,41 @@
+package hierarchy_test
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/gen/hierarchy"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"strings"
+	"testing"
+)
+
+func TestStylesDump(t *testing.T) {
+	root, err := rbxapidump.Decode(strings.NewReader(`Class Instance [notCreatable]
+Class Hat : Instance [deprecated]
+Class Workspace : Instance [notCreatable] [service]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := (&hierarchy.Generator{Format: hierarchy.DOT}).Write(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"\t\"Instance\" [style=\"dashed\"];\n",
+		"\t\"Hat\" [color=gray, fontcolor=gray];\n",
+		"\t\"Workspace\" [style=\"bold,dashed\"];\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("missing %q:\n%s", line, out)
+		}
+	}
+
+	buf.Reset()
+	if err := (&hierarchy.Generator{Format: hierarchy.DOT, Tag: "Service"}).Write(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `"Workspace"`) || strings.Contains(out, `"Instance"`) {
+		t.Errorf("expected only Workspace with the Service tag:\n%s", out)
+	}
+}
@@ -0,0 +1,377 @@
+// The lint package generates standard library definitions for Lua linters
+// from an API structure.
+//
+// The "selene" target writes a standard library for selene in the TOML
+// format. Each class is described by a struct containing its members and
+// those it inherits, and the globals game, workspace, and script refer to the
+// structs of their classes. Items of each enum are declared under the Enum
+// global, and Instance.new accepts the names of creatable classes.
+//
+// The "luacheck" target writes a Lua module returning a luacheck std
+// definition, which may be added to a .luacheckrc file:
+//
+//	stds.roblox = dofile("roblox_std.lua")
+//	std = "lua51+roblox"
+//
+// Luacheck does not track the types of values, so only the fields of global
+// tables, such as the items of each enum, are checked.
+//
+// Data types and globals that are not described by the API, such as Vector3,
+// are not declared, and can be added by a base standard library.
+package lint
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/internal/luaname"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Format is the format of a standard library definition.
+type Format int
+
+const (
+	// Selene is the TOML standard library format of selene.
+	Selene Format = iota
+	// Luacheck is the std definition table of luacheck.
+	Luacheck
+)
+
+// DefaultFile returns the name of the file produced when Generator.File is
+// empty.
+func (f Format) DefaultFile() string {
+	if f == Luacheck {
+		return "roblox_std.lua"
+	}
+	return "roblox.toml"
+}
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "selene",
+		Summary: "selene standard library",
+		New:     func() gen.Generator { return &Generator{Format: Selene} },
+	})
+	gen.Register(gen.Target{
+		Name:    "luacheck",
+		Summary: "luacheck std definition",
+		New:     func() gen.Generator { return &Generator{Format: Luacheck} },
+	})
+}
+
+// Generator generates a standard library definition.
+type Generator struct {
+	// Format is the format of the definition.
+	Format Format
+	// File is the name of the produced file.
+	File string
+	// Filter selects the declared descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", g.Format.DefaultFile(), "`name` of the produced file")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = g.Format.DefaultFile()
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the definition produced from root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	bw := bufio.NewWriter(w)
+	if g.Format == Luacheck {
+		g.writeLuacheck(bw, root)
+	} else {
+		g.writeSelene(bw, root)
+	}
+	return bw.Flush()
+}
+
+// globals maps the globals referring to instances to their classes.
+var globals = []struct{ name, class string }{
+	{"game", "DataModel"},
+	{"script", "LuaSourceContainer"},
+	{"workspace", "Workspace"},
+}
+
+// enums returns the included enums, and the names of the included items of
+// each. The GetEnumItems method is included as an item.
+func (g *Generator) enums(root rbxapi.Root) (enums []rbxapi.Enum, items map[string][]string) {
+	items = map[string][]string{}
+	for _, enum := range root.GetEnums() {
+		if !g.Enum(enum) {
+			continue
+		}
+		enums = append(enums, enum)
+		seen := map[string]bool{"GetEnumItems": true}
+		for _, item := range enum.GetEnumItems() {
+			if name := item.GetName(); g.EnumItem(item) && !seen[name] {
+				seen[name] = true
+				items[enum.GetName()] = append(items[enum.GetName()], name)
+			}
+		}
+	}
+	return enums, items
+}
+
+// creatable returns the names of included classes that can be created with
+// Instance.new, in order.
+func (g *Generator) creatable(root rbxapi.Root) []string {
+	var names []string
+	for _, class := range root.GetClasses() {
+		if g.Class(class) && !rbxapiconv.HasTag(class, "NotCreatable") && !rbxapiconv.HasTag(class, "Service") {
+			names = append(names, class.GetName())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tomlString returns s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r < 0x20 || r == 0x7F:
+			b.WriteString(fmt.Sprintf(`\u%04X`, r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlKey returns s as a TOML key, quoting it if it is not a bare key.
+func tomlKey(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if !('A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_' || r == '-') {
+			return tomlString(s)
+		}
+	}
+	return s
+}
+
+// tomlTable returns the header of the table of the given dotted key.
+func tomlTable(keys ...string) string {
+	for i, k := range keys {
+		keys[i] = tomlKey(k)
+	}
+	return "\n[" + strings.Join(keys, ".") + "]\n"
+}
+
+// seleneType returns the selene argument type of an API type.
+func seleneType(typ rbxapi.Type) string {
+	name := typ.GetName()
+	switch typ.GetCategory() {
+	case "Class":
+		return "{ display = " + tomlString(name) + " }"
+	case "Enum":
+		return "{ display = " + tomlString("Enum."+name) + " }"
+	case "Group":
+		switch name {
+		case "Tuple":
+			return `"..."`
+		case "Array", "Dictionary", "Map":
+			return `"table"`
+		}
+		return `"any"`
+	}
+	switch name {
+	case "bool":
+		return `"bool"`
+	case "int", "int64", "float", "double", "number":
+		return `"number"`
+	case "string", "Content":
+		return `"string"`
+	case "Function":
+		return `"function"`
+	case "null":
+		return `"nil"`
+	case "Variant", "any", "":
+		return `"any"`
+	}
+	return "{ display = " + tomlString(name) + " }"
+}
+
+// seleneArgs returns the selene arguments of a parameter list.
+func seleneArgs(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	args := make([]string, len(list))
+	for i, param := range list {
+		arg := "type = " + seleneType(param.GetType())
+		if _, ok := param.GetDefault(); ok {
+			arg += ", required = false"
+		}
+		args[i] = "{ " + arg + " }"
+	}
+	return "[" + strings.Join(args, ", ") + "]"
+}
+
+// writeSelene writes a selene standard library.
+func (g *Generator) writeSelene(w *bufio.Writer, root rbxapi.Root) {
+	w.WriteString("# Generated from the Roblox API dump. DO NOT EDIT.\n")
+	w.WriteString(tomlTable("selene"))
+	w.WriteString("base = \"lua51\"\nname = \"roblox\"\n")
+
+	for _, global := range globals {
+		if class := root.GetClass(global.class); class != nil && g.Class(class) {
+			w.WriteString(tomlTable(global.name))
+			w.WriteString("struct = " + tomlString(global.class) + "\n")
+		}
+	}
+
+	w.WriteString(tomlTable("Instance", "new"))
+	class := `"string"`
+	if types := g.creatable(root); len(types) > 0 {
+		for i, name := range types {
+			types[i] = tomlString(name)
+		}
+		class = "[" + strings.Join(types, ", ") + "]"
+	}
+	w.WriteString("args = [{ type = " + class + " }, { type = { display = \"Instance\" }, required = false }]\n")
+
+	enums, items := g.enums(root)
+	for _, enum := range enums {
+		name := enum.GetName()
+		for _, item := range items[name] {
+			w.WriteString(tomlTable("Enum", name, item))
+			w.WriteString("struct = \"EnumItem\"\n")
+		}
+		w.WriteString(tomlTable("Enum", name, "GetEnumItems"))
+		w.WriteString("method = true\nargs = []\n")
+	}
+
+	w.WriteString(tomlTable("selene", "structs", "EnumItem", "Name"))
+	w.WriteString("property = true\n")
+	w.WriteString(tomlTable("selene", "structs", "EnumItem", "Value"))
+	w.WriteString("property = true\n")
+	w.WriteString(tomlTable("selene", "structs", "EnumItem", "EnumType"))
+	w.WriteString("property = true\n")
+	for _, method := range []string{"Connect", "Once"} {
+		w.WriteString(tomlTable("selene", "structs", "Event", method))
+		w.WriteString("method = true\nargs = [{ type = \"function\" }]\n")
+	}
+	w.WriteString(tomlTable("selene", "structs", "Event", "Wait"))
+	w.WriteString("method = true\nargs = []\n")
+
+	// Structs have no inheritance, so the members of superclasses are
+	// repeated in each struct.
+	for _, class := range root.GetClasses() {
+		if !g.Class(class) {
+			continue
+		}
+		defined := map[string]bool{}
+		classes := []rbxapi.Class{class}
+		for _, name := range gen.Superclasses(root, class) {
+			classes = append(classes, root.GetClass(name))
+		}
+		for _, c := range classes {
+			for _, member := range c.GetMembers() {
+				name := member.GetName()
+				if defined[name] || !g.Member(member) {
+					continue
+				}
+				defined[name] = true
+				w.WriteString(tomlTable("selene", "structs", class.GetName(), name))
+				switch m := member.(type) {
+				case rbxapi.Property:
+					w.WriteString("property = true\n")
+					if !rbxapiconv.HasTag(member, "ReadOnly") {
+						w.WriteString("writable = \"overridden\"\n")
+					}
+				case rbxapi.Function:
+					if member.GetMemberType() == "Callback" {
+						w.WriteString("property = true\nwritable = \"overridden\"\n")
+						break
+					}
+					w.WriteString("method = true\nargs = " + seleneArgs(m.GetParameters()) + "\n")
+				case rbxapi.Event:
+					w.WriteString("struct = \"Event\"\n")
+				}
+			}
+		}
+	}
+}
+
+// luaKey returns s as the key of a Lua table field. Bytes other than
+// printable ASCII are written as decimal escapes.
+func luaKey(s string) string {
+	if luaname.IsName(s) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteString(`["`)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7F:
+			b.WriteString(fmt.Sprintf("\\%03d", c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString(`"]`)
+	return b.String()
+}
+
+// writeLuacheck writes a luacheck std definition.
+func (g *Generator) writeLuacheck(w *bufio.Writer, root rbxapi.Root) {
+	w.WriteString("-- Generated from the Roblox API dump. DO NOT EDIT.\n\n")
+	w.WriteString("return {\n\tread_globals = {\n")
+	for _, global := range globals {
+		if class := root.GetClass(global.class); class != nil && g.Class(class) {
+			// Fields of instances depend on their class, which is not known.
+			w.WriteString("\t\t" + global.name + " = { other_fields = true },\n")
+		}
+	}
+	w.WriteString("\t\tInstance = { fields = { new = {} } },\n")
+	w.WriteString("\t\tEnum = {\n\t\t\tfields = {\n")
+	enums, items := g.enums(root)
+	for _, enum := range enums {
+		name := enum.GetName()
+		w.WriteString("\t\t\t\t" + luaKey(name) + " = {\n\t\t\t\t\tfields = {\n")
+		w.WriteString("\t\t\t\t\t\tGetEnumItems = {},\n")
+		for _, item := range items[name] {
+			w.WriteString("\t\t\t\t\t\t" + luaKey(item) + " = { other_fields = true },\n")
+		}
+		w.WriteString("\t\t\t\t\t},\n\t\t\t\t},\n")
+	}
+	w.WriteString("\t\t\t},\n\t\t},\n\t},\n}\n")
+}
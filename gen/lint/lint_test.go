@@ -0,0 +1,32 @@
+package lint_test
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/gen/lint"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"strings"
+	"testing"
+)
+
+func TestCreatableDump(t *testing.T) {
+	root, err := rbxapidump.Decode(strings.NewReader(`Class Instance [notCreatable]
+Class Part : Instance
+	Property bool Part.Anchored
+	Property string Part.ClassName [readonly]
+Class Workspace : Instance [service]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := (&lint.Generator{Format: lint.Selene}).Write(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `args = [{ type = ["Part"] }, `) {
+		t.Errorf("expected only Part to be creatable:\n%s", out)
+	}
+	if i := strings.Index(out, "[selene.structs.Part.ClassName]\n"); i < 0 || strings.HasPrefix(out[i:], "[selene.structs.Part.ClassName]\nproperty = true\nwritable") {
+		t.Errorf("expected Part.ClassName to be read-only:\n%s", out)
+	}
+}
@@ -0,0 +1,291 @@
+// The luatable package exports an API structure as a Lua module.
+//
+// The module returns a table literal with the same layout as a JSON dump,
+// except that classes and enums are keyed by name:
+//
+//	return {
+//		Classes = {
+//			Instance = {
+//				Name = "Instance",
+//				Superclass = "<<<ROOT>>>",
+//				Members = {
+//					{ MemberType = "Property", Name = "Name", ... },
+//				},
+//				Tags = { "NotCreatable" },
+//			},
+//		},
+//		Enums = {
+//			Material = {
+//				Name = "Material",
+//				Items = {
+//					{ Name = "Plastic", Value = 256 },
+//				},
+//			},
+//		},
+//	}
+//
+// The syntax is valid in both Lua and Luau, so the module can be embedded in
+// plugins and other in-game tools.
+//
+// The package registers the "luatable" target with the gen package.
+package luatable
+
+import (
+	"bufio"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/internal/luaname"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "API.lua"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "luatable",
+		Summary: "Lua module returning a table of the API",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates a Lua module.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Filter selects the exported descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// quote returns s as a Lua string literal. Bytes other than printable ASCII
+// are written as decimal escapes, which are understood by every version of
+// Lua.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c < 0x20 || c >= 0x7F:
+			// Three digits, so that a following digit is not consumed.
+			b.WriteString(`\` + strconv.Itoa(int(c)/100) + strconv.Itoa(int(c)/10%10) + strconv.Itoa(int(c)%10))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// key returns s as the key of a table field.
+func key(s string) string {
+	if luaname.IsName(s) {
+		return s
+	}
+	return "[" + quote(s) + "]"
+}
+
+// writer writes an indented table literal.
+type writer struct {
+	*bufio.Writer
+	depth int
+}
+
+// open begins a table, given the key of the field containing it.
+func (w *writer) open(k string) {
+	w.indent()
+	if k != "" {
+		w.WriteString(k + " = ")
+	}
+	w.WriteString("{\n")
+	w.depth++
+}
+
+// close ends a table.
+func (w *writer) close() {
+	w.depth--
+	w.indent()
+	w.WriteString("},\n")
+}
+
+func (w *writer) indent() {
+	for i := 0; i < w.depth; i++ {
+		w.WriteByte('\t')
+	}
+}
+
+// field writes a field whose value is already formatted.
+func (w *writer) field(k, v string) {
+	w.indent()
+	w.WriteString(k + " = " + v + ",\n")
+}
+
+// inline returns a table of fields on a single line.
+func inline(fields ...string) string {
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+func typeTable(typ rbxapi.Type) string {
+	return inline("Category = "+quote(typ.GetCategory()), "Name = "+quote(typ.GetName()))
+}
+
+// tagList returns the tags of a descriptor as a table, or an empty string if
+// it has no tags.
+func tagList(t rbxapi.Taggable) string {
+	tags := t.GetTags()
+	if len(tags) == 0 {
+		return ""
+	}
+	list := make([]string, len(tags))
+	for i, tag := range tags {
+		list[i] = quote(tag)
+	}
+	return inline(list...)
+}
+
+// tags writes the tags of a descriptor, if it has any.
+func (w *writer) tags(t rbxapi.Taggable) {
+	if tags := tagList(t); tags != "" {
+		w.field("Tags", tags)
+	}
+}
+
+func (w *writer) parameters(params rbxapi.Parameters) {
+	list := params.GetParameters()
+	if len(list) == 0 {
+		w.field("Parameters", "{}")
+		return
+	}
+	w.open("Parameters")
+	for _, param := range list {
+		fields := []string{"Type = " + typeTable(param.GetType()), "Name = " + quote(param.GetName())}
+		if def, ok := param.GetDefault(); ok {
+			fields = append(fields, "Default = "+quote(def))
+		}
+		w.indent()
+		w.WriteString(inline(fields...) + ",\n")
+	}
+	w.close()
+}
+
+func (w *writer) member(member rbxapi.Member) {
+	w.open("")
+	w.field("MemberType", quote(member.GetMemberType()))
+	w.field("Name", quote(member.GetName()))
+	switch m := member.(type) {
+	case rbxapi.Property:
+		w.field("ValueType", typeTable(m.GetValueType()))
+		p, _ := m.(*rbxapijson.Property)
+		if p != nil {
+			w.field("Category", quote(p.Category))
+		}
+		read, write := m.GetSecurity()
+		w.field("Security", inline("Read = "+quote(read), "Write = "+quote(write)))
+		if p != nil {
+			w.field("Serialization", inline("CanLoad = "+strconv.FormatBool(p.CanLoad), "CanSave = "+strconv.FormatBool(p.CanSave)))
+		}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		w.parameters(m.GetParameters())
+		w.field("ReturnType", typeTable(m.GetReturnType()))
+		w.field("Security", quote(m.GetSecurity()))
+	case rbxapi.Event:
+		w.parameters(m.GetParameters())
+		w.field("Security", quote(m.GetSecurity()))
+	}
+	w.tags(member)
+	w.close()
+}
+
+// Write writes the module produced from root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	bw := &writer{Writer: bufio.NewWriter(w)}
+	bw.WriteString("-- Generated from the Roblox API dump. DO NOT EDIT.\n\n")
+	bw.WriteString("return {\n")
+	bw.depth++
+
+	bw.open("Classes")
+	for _, class := range root.GetClasses() {
+		if !g.Class(class) {
+			continue
+		}
+		bw.open(key(class.GetName()))
+		bw.field("Name", quote(class.GetName()))
+		bw.field("Superclass", quote(class.GetSuperclass()))
+		if c, ok := class.(*rbxapijson.Class); ok {
+			bw.field("MemoryCategory", quote(c.MemoryCategory))
+		}
+		bw.open("Members")
+		for _, member := range class.GetMembers() {
+			if g.Member(member) {
+				bw.member(member)
+			}
+		}
+		bw.close()
+		bw.tags(class)
+		bw.close()
+	}
+	bw.close()
+
+	bw.open("Enums")
+	for _, enum := range root.GetEnums() {
+		if !g.Enum(enum) {
+			continue
+		}
+		bw.open(key(enum.GetName()))
+		bw.field("Name", quote(enum.GetName()))
+		bw.open("Items")
+		for _, item := range enum.GetEnumItems() {
+			if !g.EnumItem(item) {
+				continue
+			}
+			fields := []string{"Name = " + quote(item.GetName()), "Value = " + strconv.Itoa(item.GetValue())}
+			if tags := tagList(item); tags != "" {
+				fields = append(fields, "Tags = "+tags)
+			}
+			bw.indent()
+			bw.WriteString(inline(fields...) + ",\n")
+		}
+		bw.close()
+		bw.tags(enum)
+		bw.close()
+	}
+	bw.close()
+
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
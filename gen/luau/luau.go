@@ -0,0 +1,353 @@
+// The luau package generates Luau type definitions from an API structure.
+//
+// The output uses the definition file syntax understood by Luau language
+// servers. Each class is declared with "declare class", extending its
+// superclass, so that inherited members are available on every subclass.
+// Each enum is declared as a class of items along with a table of its items,
+// and the enums are exposed through the global Enum. Data types referred to
+// by the API are declared as opaque classes, and the types common to all
+// Roblox scripts, such as RBXScriptSignal, are declared in a prelude.
+//
+// The package registers the "luau" target with the gen package.
+package luau
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/internal/luaname"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "globalTypes.d.luau"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "luau",
+		Summary: "Luau type definitions",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates Luau type definitions.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Filter selects the declared descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// prelude declares the types referred to by all declarations.
+const prelude = `
+declare class EnumItem
+	Name: string
+	Value: number
+	EnumType: Enum
+	function IsA(self, enumName: string): boolean
+end
+
+declare class Enum
+	function GetEnumItems(self): { any }
+end
+
+declare class RBXScriptConnection
+	Connected: boolean
+	function Disconnect(self): ()
+end
+
+declare class RBXScriptSignal<T...>
+	function Connect(self, callback: (T...) -> ()): RBXScriptConnection
+	function Once(self, callback: (T...) -> ()): RBXScriptConnection
+	function Wait(self): T...
+end
+`
+
+// preludeTypes contains the names of types declared by the prelude.
+var preludeTypes = map[string]bool{
+	"EnumItem":            true,
+	"Enum":                true,
+	"RBXScriptConnection": true,
+	"RBXScriptSignal":     true,
+}
+
+// paramName returns a valid parameter name for the parameter at index i. A
+// parameter named self would conflict with the self parameter of methods.
+func paramName(s string, i int) string {
+	if s == "self" || luaname.IsKeyword(s) {
+		return s + "_"
+	}
+	if luaname.IsName(s) {
+		return s
+	}
+	return "arg" + strconv.Itoa(i)
+}
+
+// enumType returns the name of the class declaring the items of an enum.
+func enumType(name string) string {
+	return "Enum" + name
+}
+
+// writer accumulates output and the data types referred to.
+type writer struct {
+	*bufio.Writer
+	g         *Generator
+	root      rbxapi.Root
+	datatypes map[string]bool
+}
+
+// typeString returns the Luau type of an API type. If tuple is true, then
+// the type is in a position that permits a type pack.
+func (w *writer) typeString(typ rbxapi.Type, tuple bool) string {
+	name := typ.GetName()
+	switch typ.GetCategory() {
+	case "Class":
+		if w.root.GetClass(name) == nil || !luaname.IsName(name) {
+			return "Instance?"
+		}
+		return name + "?"
+	case "Enum":
+		if w.root.GetEnum(name) == nil || !luaname.IsName(name) {
+			return "EnumItem"
+		}
+		return enumType(name)
+	case "Group":
+		switch name {
+		case "Tuple":
+			if tuple {
+				return "...any"
+			}
+			return "{ any }"
+		case "Array":
+			return "{ any }"
+		case "Dictionary", "Map":
+			return "{ [any]: any }"
+		}
+		return "any"
+	}
+	switch name {
+	case "bool":
+		return "boolean"
+	case "int", "int64", "float", "double", "number":
+		return "number"
+	case "string", "Content":
+		return "string"
+	case "void":
+		if tuple {
+			return "()"
+		}
+		return "nil"
+	case "null":
+		return "nil"
+	case "Variant", "any", "":
+		return "any"
+	case "Function":
+		return "(...any) -> ...any"
+	case "Objects":
+		return "{ Instance }"
+	}
+	if !luaname.IsName(name) {
+		return "any"
+	}
+	w.datatypes[name] = true
+	return name
+}
+
+// params returns the parameter list of a member. Parameters with a default
+// value are optional, and the default is noted in a comment.
+func (w *writer) params(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	ss := make([]string, len(list))
+	for i, param := range list {
+		typ := param.GetType()
+		if typ.GetCategory() == "Group" && typ.GetName() == "Tuple" && i == len(list)-1 {
+			ss[i] = "...: any"
+			continue
+		}
+		s := paramName(param.GetName(), i) + ": " + w.typeString(typ, false)
+		if def, ok := param.GetDefault(); ok {
+			if !strings.HasSuffix(s, "?") {
+				s += "?"
+			}
+			s += " --[[ = " + strings.ReplaceAll(def, "]]", "] ]") + " ]]"
+		}
+		ss[i] = s
+	}
+	return strings.Join(ss, ", ")
+}
+
+// signalTypes returns the type pack of the parameters of an event.
+func (w *writer) signalTypes(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	if len(list) == 0 {
+		return "()"
+	}
+	ss := make([]string, len(list))
+	for i, param := range list {
+		ss[i] = w.typeString(param.GetType(), i == len(list)-1)
+	}
+	if len(ss) == 1 && !strings.HasPrefix(ss[0], "...") {
+		return ss[0]
+	}
+	return "(" + strings.Join(ss, ", ") + ")"
+}
+
+// comment writes a comment for the tags of a descriptor.
+func (w *writer) comment(indent string, t rbxapi.Taggable, security string) {
+	if t.GetTag("Deprecated") {
+		w.WriteString(indent + "--- @deprecated\n")
+	}
+	if security != "" && security != "None" {
+		w.WriteString(indent + "--- Security: " + security + "\n")
+	}
+	if tags := t.GetTags(); len(tags) > 0 {
+		w.WriteString(indent + "--- Tags: " + strings.Join(tags, ", ") + "\n")
+	}
+}
+
+// member writes the declaration of a member.
+func (w *writer) member(member rbxapi.Member) {
+	const indent = "\t"
+	name := member.GetName()
+	if !luaname.IsName(name) {
+		return
+	}
+	w.comment(indent, member, rbxapi.MemberSecurity(member))
+	switch m := member.(type) {
+	case rbxapi.Property:
+		w.WriteString(indent + name + ": " + w.typeString(m.GetValueType(), false) + "\n")
+	case rbxapi.Function:
+		params := w.params(m.GetParameters())
+		ret := w.typeString(m.GetReturnType(), true)
+		if member.GetMemberType() == "Callback" {
+			w.WriteString(indent + name + ": ((" + params + ") -> " + ret + ")?\n")
+			break
+		}
+		if params != "" {
+			params = ", " + params
+		}
+		w.WriteString(indent + "function " + name + "(self" + params + "): " + ret + "\n")
+	case rbxapi.Event:
+		w.WriteString(indent + name + ": RBXScriptSignal<" + w.signalTypes(m.GetParameters()) + ">\n")
+	}
+}
+
+// Write writes the definitions of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	// Declarations are buffered so that the data types they refer to can be
+	// declared first.
+	var body bytes.Buffer
+	bw := &writer{Writer: bufio.NewWriter(&body), g: g, root: root, datatypes: map[string]bool{}}
+
+	var enums []rbxapi.Enum
+	for _, enum := range root.GetEnums() {
+		if g.Enum(enum) && luaname.IsName(enum.GetName()) {
+			enums = append(enums, enum)
+		}
+	}
+	for _, enum := range enums {
+		bw.WriteString("\n")
+		bw.comment("", enum, "")
+		bw.WriteString("declare class " + enumType(enum.GetName()) + " extends EnumItem end\n")
+		bw.WriteString("declare class " + enumType(enum.GetName()) + "_INTERNAL extends Enum\n")
+		for _, item := range enum.GetEnumItems() {
+			if !g.EnumItem(item) || !luaname.IsName(item.GetName()) {
+				continue
+			}
+			bw.comment("\t", item, "")
+			bw.WriteString("\t" + item.GetName() + ": " + enumType(enum.GetName()) + "\n")
+		}
+		bw.WriteString("\tfunction GetEnumItems(self): { " + enumType(enum.GetName()) + " }\n")
+		bw.WriteString("end\n")
+	}
+
+	// Classes are declared after their superclasses.
+	declared := map[string]bool{}
+	var declare func(class rbxapi.Class)
+	declare = func(class rbxapi.Class) {
+		name := class.GetName()
+		if declared[name] || !g.Class(class) || !luaname.IsName(name) {
+			return
+		}
+		declared[name] = true
+		super := root.GetClass(class.GetSuperclass())
+		if super != nil {
+			declare(super)
+		}
+		bw.WriteString("\n")
+		bw.comment("", class, "")
+		bw.WriteString("declare class " + name)
+		if super != nil && declared[super.GetName()] {
+			bw.WriteString(" extends " + super.GetName())
+		}
+		bw.WriteString("\n")
+		for _, member := range class.GetMembers() {
+			if g.Member(member) {
+				bw.member(member)
+			}
+		}
+		bw.WriteString("end\n")
+	}
+	for _, class := range root.GetClasses() {
+		declare(class)
+	}
+
+	bw.WriteString("\ntype ENUM_LIST = {\n")
+	for _, enum := range enums {
+		bw.WriteString("\t" + enum.GetName() + ": " + enumType(enum.GetName()) + "_INTERNAL,\n")
+	}
+	bw.WriteString("}\n\ndeclare Enum: ENUM_LIST\n")
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	out.WriteString("-- Generated from the Roblox API dump. DO NOT EDIT.\n")
+	out.WriteString(prelude)
+	names := make([]string, 0, len(bw.datatypes))
+	for name := range bw.datatypes {
+		if !declared[name] && !preludeTypes[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		out.WriteString("\n")
+	}
+	for _, name := range names {
+		out.WriteString("declare class " + name + " end\n")
+	}
+	body.WriteTo(out)
+	return out.Flush()
+}
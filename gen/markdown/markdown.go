@@ -0,0 +1,438 @@
+// The markdown package generates Markdown documentation from an API
+// structure.
+//
+// The output contains one page per class, one page per enum, and an index of
+// the pages, laid out as follows:
+//
+//	index.md
+//	classes/<class>.md
+//	enums/<enum>.md
+//
+// A class page shows the inheritance of the class, and a table of its
+// members with their types, security contexts, and tags. An enum page shows
+// a table of the items of the enum. When API documentation is provided,
+// descriptions of classes, members, enums, and items are included.
+//
+// Pages are rendered with the text/template package. The default templates
+// may be replaced by a template file that defines any of the templates named
+// "class", "enum", and "index".
+//
+// The package registers the "markdown" target with the gen package.
+package markdown
+
+import (
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/docs"
+	"github.com/karl-police/rbxapi/gen"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "markdown",
+		Summary: "Markdown documentation pages",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates Markdown documentation.
+type Generator struct {
+	// Docs provides descriptions of the documented descriptors.
+	Docs docs.Docs
+	// DocsFile is the path to a file of API documentation in JSON format.
+	// If not empty, it is read into Docs before generating.
+	DocsFile string
+	// TemplateFile is the path to a file defining templates that replace the
+	// default templates.
+	TemplateFile string
+	// Filter selects the documented descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.DocsFile, "docs", g.DocsFile, "read descriptions from API documentation `file`")
+	fs.StringVar(&g.TemplateFile, "template", g.TemplateFile, "replace the default templates with those defined in `file`")
+	g.Filter.Flags(fs)
+}
+
+// DefaultTemplates defines the default templates of the pages. The "class"
+// template receives a ClassPage, the "enum" template receives an EnumPage,
+// and the "index" template receives an IndexPage.
+const DefaultTemplates = `
+{{- define "class" -}}
+# {{.Name}}
+{{- if .Tags}}
+
+Tags: {{join .Tags ", "}}
+{{- end}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+{{- if .Superclasses}}
+
+Inherits: {{range $i, $c := .Superclasses}}{{if $i}} → {{end}}[{{$c.Name}}]({{$c.Path}}){{end}}
+{{- end}}
+{{- if .Subclasses}}
+
+Inherited by: {{range $i, $c := .Subclasses}}{{if $i}}, {{end}}[{{$c.Name}}]({{$c.Path}}){{end}}
+{{- end}}
+{{- if .Members}}
+
+## Members
+
+| Member | Type | Kind | Security | Tags | Description |
+|--------|------|------|----------|------|-------------|
+{{- range .Members}}
+| ` + "`{{cell .Signature}}`" + ` | {{cell .Type}} | {{.MemberType}} | {{cell .Security}} | {{cell (join .Tags ", ")}} | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{- if .Inherited}}
+
+## Inherited members
+{{- range .Inherited}}
+
+From [{{.Class.Name}}]({{.Class.Path}}): {{join .Members ", "}}
+{{- end}}
+{{- end}}
+{{end -}}
+
+{{- define "enum" -}}
+# {{.Name}}
+{{- if .Tags}}
+
+Tags: {{join .Tags ", "}}
+{{- end}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+{{- if .Items}}
+
+## Items
+
+| Name | Value | Tags | Description |
+|------|-------|------|-------------|
+{{- range .Items}}
+| {{cell .Name}} | {{.Value}} | {{cell (join .Tags ", ")}} | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{end -}}
+
+{{- define "index" -}}
+# API Reference
+{{- if .Classes}}
+
+## Classes
+{{range .Classes}}
+- [{{.Name}}]({{.Path}})
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+
+## Enums
+{{range .Enums}}
+- [{{.Name}}]({{.Path}})
+{{- end}}
+{{- end}}
+{{end -}}
+`
+
+// Link refers to the page of a class or enum.
+type Link struct {
+	Name string
+	// Path is the location of the page, relative to the referring page.
+	Path string
+}
+
+// ClassPage is the data of a class page.
+type ClassPage struct {
+	Name        string
+	Tags        []string
+	Description string
+	// Superclasses contains the classes from which the class inherits,
+	// nearest first.
+	Superclasses []Link
+	// Subclasses contains the classes that directly inherit from the class.
+	Subclasses []Link
+	Members    []MemberRow
+	// Inherited contains the members inherited from each superclass.
+	Inherited []InheritedMembers
+}
+
+// MemberRow describes a member of a class.
+type MemberRow struct {
+	Name       string
+	MemberType string
+	// Signature is the name of the member, followed by its parameters if
+	// the member has any.
+	Signature string
+	// Type is the value type of a property, or the return type of a function
+	// or callback, linked to its page if it has one.
+	Type string
+	// Security is the security context of the member. For properties with
+	// different read and write contexts, both are included.
+	Security    string
+	Tags        []string
+	Description string
+}
+
+// InheritedMembers lists the names of the members inherited from a class.
+type InheritedMembers struct {
+	Class   Link
+	Members []string
+}
+
+// EnumPage is the data of an enum page.
+type EnumPage struct {
+	Name        string
+	Tags        []string
+	Description string
+	Items       []ItemRow
+}
+
+// ItemRow describes an item of an enum.
+type ItemRow struct {
+	Name        string
+	Value       int
+	Tags        []string
+	Description string
+}
+
+// IndexPage is the data of the index page.
+type IndexPage struct {
+	Classes []Link
+	Enums   []Link
+}
+
+// funcs contains the functions available to templates.
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	// cell escapes text for use within a table cell.
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// Templates returns the templates used to render pages.
+func (g *Generator) Templates() (*template.Template, error) {
+	t, err := template.New("markdown").Funcs(funcs).Parse(DefaultTemplates)
+	if err != nil {
+		return nil, err
+	}
+	if g.TemplateFile == "" {
+		return t, nil
+	}
+	b, err := ioutil.ReadFile(g.TemplateFile)
+	if err != nil {
+		return nil, err
+	}
+	return t.New(g.TemplateFile).Parse(string(b))
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	if g.DocsFile != "" {
+		f, err := os.Open(g.DocsFile)
+		if err != nil {
+			return err
+		}
+		g.Docs, err = docs.Decode(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	t, err := g.Templates()
+	if err != nil {
+		return err
+	}
+
+	var classes []rbxapi.Class
+	subclasses := map[string][]string{}
+	for _, class := range root.GetClasses() {
+		if g.Class(class) {
+			classes = append(classes, class)
+			subclasses[class.GetSuperclass()] = append(subclasses[class.GetSuperclass()], class.GetName())
+		}
+	}
+	var enums []rbxapi.Enum
+	for _, enum := range root.GetEnums() {
+		if g.Enum(enum) {
+			enums = append(enums, enum)
+		}
+	}
+
+	var index IndexPage
+	for _, class := range classes {
+		index.Classes = append(index.Classes, Link{Name: class.GetName(), Path: classPath("", class.GetName())})
+		page := g.classPage(root, class, subclasses[class.GetName()])
+		if err := render(t, out, "class", "classes/"+class.GetName()+".md", page); err != nil {
+			return err
+		}
+	}
+	for _, enum := range enums {
+		index.Enums = append(index.Enums, Link{Name: enum.GetName(), Path: enumPath("", enum.GetName())})
+		if err := render(t, out, "enum", "enums/"+enum.GetName()+".md", g.enumPage(enum)); err != nil {
+			return err
+		}
+	}
+	sort.Slice(index.Classes, func(i, j int) bool { return index.Classes[i].Name < index.Classes[j].Name })
+	sort.Slice(index.Enums, func(i, j int) bool { return index.Enums[i].Name < index.Enums[j].Name })
+	return render(t, out, "index", "index.md", index)
+}
+
+// render executes the named template into a file of out.
+func render(t *template.Template, out gen.Output, name, file string, data interface{}) error {
+	f, err := out.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := t.ExecuteTemplate(f, name, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// classPath returns the path to the page of a class, relative to base.
+func classPath(base, class string) string {
+	return base + "classes/" + url.PathEscape(class) + ".md"
+}
+
+// enumPath returns the path to the page of an enum, relative to base.
+func enumPath(base, enum string) string {
+	return base + "enums/" + url.PathEscape(enum) + ".md"
+}
+
+// description returns the documentation of the entry of the given key.
+func (g *Generator) description(key string) string {
+	if entry := g.Docs.Get(key); entry != nil {
+		return strings.TrimSpace(entry.Documentation)
+	}
+	return ""
+}
+
+// typeString returns the name of a type, linked to the page of the class or
+// enum it refers to. Links are relative to a class or enum page.
+func (g *Generator) typeString(root rbxapi.Root, typ rbxapi.Type) string {
+	name := typ.GetName()
+	switch typ.GetCategory() {
+	case "Class":
+		if class := root.GetClass(name); class != nil && g.Class(class) {
+			return "[" + name + "](" + classPath("../", name) + ")"
+		}
+	case "Enum":
+		if enum := root.GetEnum(name); enum != nil && g.Enum(enum) {
+			return "[" + name + "](" + enumPath("../", name) + ")"
+		}
+	}
+	return name
+}
+
+// params returns the parameter list of a member.
+func params(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	ss := make([]string, len(list))
+	for i, param := range list {
+		ss[i] = param.GetName() + ": " + param.GetType().GetName()
+		if def, ok := param.GetDefault(); ok {
+			ss[i] += " = " + def
+		}
+	}
+	return "(" + strings.Join(ss, ", ") + ")"
+}
+
+// memberRow returns the row describing a member of class.
+func (g *Generator) memberRow(root rbxapi.Root, class string, member rbxapi.Member) MemberRow {
+	row := MemberRow{
+		Name:        member.GetName(),
+		MemberType:  member.GetMemberType(),
+		Signature:   member.GetName(),
+		Security:    rbxapi.MemberSecurity(member),
+		Tags:        member.GetTags(),
+		Description: g.description(docs.MemberKey(class, member.GetName())),
+	}
+	switch m := member.(type) {
+	case rbxapi.Property:
+		row.Type = g.typeString(root, m.GetValueType())
+		if read, write := m.GetSecurity(); read != write {
+			row.Security = read + " / " + write
+		}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		row.Signature += params(m.GetParameters())
+		row.Type = g.typeString(root, m.GetReturnType())
+	case rbxapi.Event:
+		row.Signature += params(m.GetParameters())
+	}
+	return row
+}
+
+// classPage returns the data of the page of a class.
+func (g *Generator) classPage(root rbxapi.Root, class rbxapi.Class, subclasses []string) ClassPage {
+	page := ClassPage{
+		Name:        class.GetName(),
+		Tags:        class.GetTags(),
+		Description: g.description(docs.ClassKey(class.GetName())),
+	}
+	for _, name := range gen.Superclasses(root, class) {
+		link := Link{Name: name, Path: url.PathEscape(name) + ".md"}
+		page.Superclasses = append(page.Superclasses, link)
+		super := root.GetClass(name)
+		if !g.Class(super) {
+			continue
+		}
+		inherited := InheritedMembers{Class: link}
+		for _, member := range super.GetMembers() {
+			if g.Member(member) {
+				inherited.Members = append(inherited.Members, member.GetName())
+			}
+		}
+		if len(inherited.Members) > 0 {
+			page.Inherited = append(page.Inherited, inherited)
+		}
+	}
+	sort.Strings(subclasses)
+	for _, name := range subclasses {
+		page.Subclasses = append(page.Subclasses, Link{Name: name, Path: url.PathEscape(name) + ".md"})
+	}
+	for _, member := range class.GetMembers() {
+		if g.Member(member) {
+			page.Members = append(page.Members, g.memberRow(root, class.GetName(), member))
+		}
+	}
+	return page
+}
+
+// enumPage returns the data of the page of an enum.
+func (g *Generator) enumPage(enum rbxapi.Enum) EnumPage {
+	page := EnumPage{
+		Name:        enum.GetName(),
+		Tags:        enum.GetTags(),
+		Description: g.description(docs.EnumKey(enum.GetName())),
+	}
+	for _, item := range enum.GetEnumItems() {
+		if !g.EnumItem(item) {
+			continue
+		}
+		page.Items = append(page.Items, ItemRow{
+			Name:        item.GetName(),
+			Value:       item.GetValue(),
+			Tags:        item.GetTags(),
+			Description: g.description(docs.EnumItemKey(enum.GetName(), item.GetName())),
+		})
+	}
+	return page
+}
@@ -0,0 +1,274 @@
+// The rbxdom package exports an API structure as a reflection database in
+// the JSON form used by the rbx-dom family of tools, such as Rojo.
+//
+// The database has the layout of the Database structure of rbx_reflection:
+//
+//	{
+//		"Version": [0, 600, 1, 6000000],
+//		"Classes": {
+//			"Instance": {
+//				"Name": "Instance",
+//				"Tags": ["NotCreatable"],
+//				"Superclass": null,
+//				"Properties": {
+//					"Name": {
+//						"Name": "Name",
+//						"Scriptability": "ReadWrite",
+//						"DataType": {"Value": "String"},
+//						"Tags": [],
+//						"Kind": {"Canonical": {"Serialization": "Serializes"}}
+//					}
+//				},
+//				"DefaultProperties": {}
+//			}
+//		},
+//		"Enums": {
+//			"Material": {"Name": "Material", "Items": {"Plastic": 256}}
+//		}
+//	}
+//
+// Properties with a value type that has no counterpart in rbx-dom are
+// omitted. API dumps do not contain the default values of properties, so
+// DefaultProperties is always empty; rbx-dom tooling fills it in separately.
+//
+// The serialization of a property is derived from the CanLoad and CanSave
+// fields of JSON dumps. Properties of other structures are assumed to
+// serialize.
+//
+// The package registers the "rbxdom" target with the gen package.
+package rbxdom
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "database.json"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "rbxdom",
+		Summary: "rbx-dom reflection database",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates a reflection database.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Version is the version of Roblox described by the database.
+	Version fetch.Number
+	// Filter selects the included descriptors.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	fs.TextVar(&g.Version, "version", g.Version, "version `number` described by the database")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Database is a reflection database.
+type Database struct {
+	Version [4]int
+	Classes map[string]*Class
+	Enums   map[string]*Enum
+}
+
+// Class describes a class.
+type Class struct {
+	Name       string
+	Tags       []string
+	Superclass *string
+	Properties map[string]*Property
+	// DefaultProperties maps the names of properties to their default
+	// values, which are not known from API dumps.
+	DefaultProperties map[string]interface{}
+}
+
+// Property describes a property of a class.
+type Property struct {
+	Name string
+	// Scriptability is one of None, Read, Write, ReadWrite, or Custom.
+	Scriptability string
+	// DataType is either {"Value": variantType} or {"Enum": enumName}.
+	DataType map[string]string
+	Tags     []string
+	// Kind is either {"Canonical": {"Serialization": serialization}} or
+	// {"Alias": {"AliasFor": name}}.
+	Kind map[string]map[string]string
+}
+
+// Enum describes an enum.
+type Enum struct {
+	Name  string
+	Items map[string]int
+}
+
+// variantTypes maps the names of value types to the rbx-dom variant types
+// that represent them.
+var variantTypes = map[string]string{
+	"Axes":                    "Axes",
+	"BinaryString":            "BinaryString",
+	"BrickColor":              "BrickColor",
+	"CFrame":                  "CFrame",
+	"Color3":                  "Color3",
+	"Color3uint8":             "Color3uint8",
+	"ColorSequence":           "ColorSequence",
+	"Content":                 "ContentId",
+	"Faces":                   "Faces",
+	"Font":                    "Font",
+	"NumberRange":             "NumberRange",
+	"NumberSequence":          "NumberSequence",
+	"OptionalCoordinateFrame": "OptionalCFrame",
+	"PhysicalProperties":      "PhysicalProperties",
+	"Ray":                     "Ray",
+	"Rect":                    "Rect",
+	"Region3":                 "Region3",
+	"Region3int16":            "Region3int16",
+	"SecurityCapabilities":    "SecurityCapabilities",
+	"SharedString":            "SharedString",
+	"Tags":                    "Tags",
+	"UDim":                    "UDim",
+	"UDim2":                   "UDim2",
+	"UniqueId":                "UniqueId",
+	"Vector2":                 "Vector2",
+	"Vector2int16":            "Vector2int16",
+	"Vector3":                 "Vector3",
+	"Vector3int16":            "Vector3int16",
+	"bool":                    "Bool",
+	"double":                  "Float64",
+	"float":                   "Float32",
+	"int":                     "Int32",
+	"int64":                   "Int64",
+	"string":                  "String",
+}
+
+// dataType returns the data type of a value type, or nil if the type has no
+// counterpart.
+func dataType(typ rbxapi.Type) map[string]string {
+	switch typ.GetCategory() {
+	case "Class":
+		return map[string]string{"Value": "Ref"}
+	case "Enum":
+		return map[string]string{"Enum": typ.GetName()}
+	}
+	if v, ok := variantTypes[typ.GetName()]; ok {
+		return map[string]string{"Value": v}
+	}
+	return nil
+}
+
+// tags returns the tags of a descriptor, which are never null.
+func tags(t rbxapi.Taggable) []string {
+	if tags := t.GetTags(); tags != nil {
+		return tags
+	}
+	return []string{}
+}
+
+// property returns the descriptor of a property, or nil if its type has no
+// counterpart.
+func property(prop rbxapi.Property) *Property {
+	typ := dataType(prop.GetValueType())
+	if typ == nil {
+		return nil
+	}
+	p := &Property{
+		Name:          prop.GetName(),
+		Scriptability: "ReadWrite",
+		DataType:      typ,
+		Tags:          tags(prop),
+	}
+	switch {
+	case prop.GetTag("NotScriptable"):
+		p.Scriptability = "None"
+	case prop.GetTag("ReadOnly"):
+		p.Scriptability = "Read"
+	}
+	serialization := "Serializes"
+	if jp, ok := prop.(*rbxapijson.Property); ok && !(jp.CanLoad && jp.CanSave) {
+		serialization = "DoesNotSerialize"
+	}
+	p.Kind = map[string]map[string]string{"Canonical": {"Serialization": serialization}}
+	return p
+}
+
+// Database returns the reflection database of root.
+func (g *Generator) Database(root rbxapi.Root) *Database {
+	db := &Database{
+		Version: g.Version,
+		Classes: map[string]*Class{},
+		Enums:   map[string]*Enum{},
+	}
+	for _, class := range root.GetClasses() {
+		if !g.Class(class) {
+			continue
+		}
+		c := &Class{
+			Name:              class.GetName(),
+			Tags:              tags(class),
+			Properties:        map[string]*Property{},
+			DefaultProperties: map[string]interface{}{},
+		}
+		if super := class.GetSuperclass(); root.GetClass(super) != nil {
+			c.Superclass = &super
+		}
+		for _, member := range class.GetMembers() {
+			if prop, ok := member.(rbxapi.Property); ok && g.Member(member) {
+				if p := property(prop); p != nil {
+					c.Properties[p.Name] = p
+				}
+			}
+		}
+		db.Classes[c.Name] = c
+	}
+	for _, enum := range root.GetEnums() {
+		if !g.Enum(enum) {
+			continue
+		}
+		e := &Enum{Name: enum.GetName(), Items: map[string]int{}}
+		for _, item := range enum.GetEnumItems() {
+			if g.EnumItem(item) {
+				e.Items[item.GetName()] = item.GetValue()
+			}
+		}
+		db.Enums[e.Name] = e
+	}
+	return db
+}
+
+// Write writes the reflection database of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	return je.Encode(g.Database(root))
+}
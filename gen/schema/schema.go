@@ -0,0 +1,288 @@
+// The schema package generates a JSON Schema describing instance trees.
+//
+// The schema validates the instance descriptions of Rojo-style files against
+// the API: the class name of each instance must be a known class, and each
+// property must be a scriptable, writable property of the class or one of its
+// superclasses, with a value of the property's type. Property values may be
+// given in either the implicit form, such as [1, 2, 3] for a Vector3, or the
+// explicit form, such as {"Vector3": [1, 2, 3]}.
+//
+// Two kinds of file are supported. A model file describes an instance with
+// the ClassName, Name, Properties, and Children fields. A project file
+// describes a tree of nodes with the $className and $properties fields, in
+// which other fields are child nodes.
+//
+// The package registers the "schema" target with the gen package.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/gen"
+	"io"
+	"sort"
+)
+
+const (
+	Model   = "model"   // Kind of schema that describes model files.
+	Project = "project" // Kind of schema that describes project files.
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "instance.schema.json"
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "schema",
+		Summary: "JSON Schema for instance trees",
+		New:     func() gen.Generator { return &Generator{Kind: Model} },
+	})
+}
+
+// Generator generates a JSON Schema.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+	// Kind is the kind of file described by the schema, either Model or
+	// Project. Defaults to Model.
+	Kind string
+	// Filter selects the included classes and properties.
+	gen.Filter
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+	fs.StringVar(&g.Kind, "kind", Model, "`kind` of file described by the schema: model or project")
+	g.Filter.Flags(fs)
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	if err := g.Filter.Check(); err != nil {
+		return err
+	}
+	switch g.Kind {
+	case "", Model, Project:
+	default:
+		return errors.New("unknown schema kind \"" + g.Kind + "\"")
+	}
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// object is a JSON Schema object. Keys are sorted when encoded.
+type object map[string]interface{}
+
+// ref returns a schema that refers to a definition.
+func ref(name string) object {
+	return object{"$ref": "#/definitions/" + name}
+}
+
+// numbers returns a schema of an array of n numbers.
+func numbers(n int) object {
+	return object{"type": "array", "items": object{"type": "number"}, "minItems": n, "maxItems": n}
+}
+
+// implicit contains the implicit forms of data types.
+var implicit = map[string]object{
+	"Vector2":        numbers(2),
+	"Vector2int16":   numbers(2),
+	"Vector3":        numbers(3),
+	"Vector3int16":   numbers(3),
+	"Color3":         numbers(3),
+	"Color3uint8":    numbers(3),
+	"UDim":           numbers(2),
+	"UDim2":          {"type": "array", "items": numbers(2), "minItems": 2, "maxItems": 2},
+	"Rect":           {"type": "array", "items": numbers(2), "minItems": 2, "maxItems": 2},
+	"NumberRange":    numbers(2),
+	"BrickColor":     {"type": []string{"integer", "string"}},
+	"Font":           {"type": "object", "required": []string{"family"}},
+	"Faces":          {"type": "array", "items": object{"enum": []string{"Right", "Top", "Back", "Left", "Bottom", "Front"}}},
+	"Axes":           {"type": "array", "items": object{"enum": []string{"X", "Y", "Z"}}},
+	"NumberSequence": {"type": "object", "required": []string{"keypoints"}},
+	"ColorSequence":  {"type": "object", "required": []string{"keypoints"}},
+}
+
+// writer accumulates the definitions of the schema.
+type writer struct {
+	g           *Generator
+	root        rbxapi.Root
+	definitions object
+}
+
+// valueType returns the schema of the values of a property type, or nil if
+// values of the type cannot be given.
+func (w *writer) valueType(typ rbxapi.Type) object {
+	name := typ.GetName()
+	switch typ.GetCategory() {
+	case "Class":
+		// References to instances cannot be expressed by value.
+		return nil
+	case "Enum":
+		enum := w.root.GetEnum(name)
+		if enum == nil {
+			return object{"type": []string{"integer", "string"}}
+		}
+		def := "Enum." + name
+		if _, ok := w.definitions[def]; !ok {
+			var names []string
+			var values []int
+			for _, item := range enum.GetEnumItems() {
+				names = append(names, item.GetName())
+				values = append(values, item.GetValue())
+			}
+			w.definitions[def] = object{"anyOf": []object{
+				{"type": "string", "enum": names},
+				{"type": "integer", "enum": values},
+				{"type": "object", "required": []string{"Enum"}, "properties": object{"Enum": object{"type": []string{"integer", "string"}}}},
+			}}
+		}
+		return ref(def)
+	}
+	switch name {
+	case "bool":
+		return object{"type": "boolean"}
+	case "int", "int64":
+		return object{"type": "integer"}
+	case "float", "double", "number":
+		return object{"type": "number"}
+	case "string", "Content", "BinaryString", "ProtectedString":
+		return object{"type": "string"}
+	case "":
+		return object{}
+	}
+	def := "Type." + name
+	if _, ok := w.definitions[def]; !ok {
+		explicit := object{"type": "object", "required": []string{name}, "maxProperties": 1}
+		if form, ok := implicit[name]; ok {
+			w.definitions[def] = object{"anyOf": []object{form, explicit}}
+		} else {
+			w.definitions[def] = object{"anyOf": []object{explicit, object{"not": object{"type": "null"}}}}
+		}
+	}
+	return ref(def)
+}
+
+// settable returns whether a property can be set by a file.
+func settable(member rbxapi.Member) bool {
+	return member.GetMemberType() == "Property" &&
+		!member.GetTag("ReadOnly") &&
+		!member.GetTag("NotScriptable")
+}
+
+// properties returns the schema of the properties of a class, including
+// inherited properties.
+func (w *writer) properties(class rbxapi.Class) object {
+	props := object{}
+	classes := []rbxapi.Class{class}
+	for _, name := range gen.Superclasses(w.root, class) {
+		classes = append(classes, w.root.GetClass(name))
+	}
+	for _, c := range classes {
+		for _, member := range c.GetMembers() {
+			prop, ok := member.(rbxapi.Property)
+			if !ok || !settable(member) || !w.g.Member(member) {
+				continue
+			}
+			if _, ok := props[member.GetName()]; ok {
+				continue
+			}
+			schema := w.valueType(prop.GetValueType())
+			if schema == nil {
+				continue
+			}
+			if member.GetTag("Deprecated") {
+				schema = object{"allOf": []object{schema}, "deprecated": true}
+			}
+			props[member.GetName()] = schema
+		}
+	}
+	return object{"type": "object", "properties": props, "additionalProperties": false}
+}
+
+// Write writes the schema of root to w.
+func (g *Generator) Write(w io.Writer, root rbxapi.Root) error {
+	sw := &writer{g: g, root: root, definitions: object{}}
+
+	var names []string
+	for _, class := range root.GetClasses() {
+		if g.Class(class) {
+			names = append(names, class.GetName())
+		}
+	}
+	sort.Strings(names)
+
+	classField, propsField := "ClassName", "Properties"
+	if g.Kind == Project {
+		classField, propsField = "$className", "$properties"
+	}
+	var cases []object
+	for _, name := range names {
+		def := "Class." + name
+		sw.definitions[def] = sw.properties(root.GetClass(name))
+		cases = append(cases, object{
+			"if":   object{"required": []string{classField}, "properties": object{classField: object{"const": name}}},
+			"then": object{"properties": object{propsField: ref(def)}},
+		})
+	}
+
+	var schema object
+	switch g.Kind {
+	case Project:
+		sw.definitions["Node"] = object{
+			"type": "object",
+			"properties": object{
+				"$className":              object{"enum": names},
+				"$properties":             object{"type": "object"},
+				"$path":                   object{"type": "string"},
+				"$ignoreUnknownInstances": object{"type": "boolean"},
+			},
+			"patternProperties": object{"^[^$]": ref("Node")},
+			"allOf":             cases,
+		}
+		schema = object{
+			"title":    "Roblox project",
+			"type":     "object",
+			"required": []string{"name", "tree"},
+			"properties": object{
+				"name": object{"type": "string"},
+				"tree": ref("Node"),
+			},
+		}
+	default:
+		sw.definitions["Instance"] = object{
+			"type":     "object",
+			"required": []string{"ClassName"},
+			"properties": object{
+				"ClassName":  object{"enum": names},
+				"Name":       object{"type": "string"},
+				"Properties": object{"type": "object"},
+				"Children":   object{"type": "array", "items": ref("Instance")},
+			},
+			"additionalProperties": false,
+			"allOf":                cases,
+		}
+		schema = object{"title": "Roblox model", "allOf": []object{ref("Instance")}}
+	}
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["definitions"] = sw.definitions
+
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	je.SetEscapeHTML(false)
+	return je.Encode(schema)
+}
@@ -0,0 +1,566 @@
+// The site package builds a static HTML reference of the API from the
+// versions stored in an archive.
+//
+// The reference describes the latest version of the API, and the history of
+// changes across every archived version. It is laid out as follows:
+//
+//	index.html             classes and enums of the latest version
+//	versions.html          archived versions, newest first
+//	class/<class>.html     members, inheritance, and history of a class
+//	enum/<enum>.html       items and history of an enum
+//	version/<guid>.html    changes made by a version
+//	style.css              stylesheet of every page
+//
+// Pages are rendered with the html/template package. The default templates,
+// defined in DefaultTemplates, may be replaced by a template file that
+// defines any of the templates named "index", "versions", "class", "enum",
+// "version", "style", "header", and "footer".
+package site
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/docs"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/patch"
+	"html/template"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Site builds a reference from an archive.
+type Site struct {
+	// Archive contains the versions described by the reference. Versions
+	// without a JSON API dump are ignored.
+	Archive *archive.Archive
+	// Docs provides descriptions of the documented descriptors.
+	Docs docs.Docs
+	// TemplateFile is the path to a file defining templates that replace the
+	// default templates.
+	TemplateFile string
+	// Filter selects the descriptors included in the reference.
+	gen.Filter
+}
+
+// Link refers to a page.
+type Link struct {
+	Name string
+	// Path is the location of the page, relative to the referring page. It
+	// is empty if the page does not exist.
+	Path string
+}
+
+// Page contains the fields common to the data of every page.
+type Page struct {
+	// Title is the title of the page.
+	Title string
+	// Base is the path to the root of the site, relative to the page.
+	Base string
+	// Latest is the latest version.
+	Latest fetch.Version
+}
+
+// IndexPage is the data of the index page.
+type IndexPage struct {
+	Page
+	// Classes contains the classes of the latest version in inheritance
+	// order, with the depth of each class in the tree.
+	Classes []TreeItem
+	Enums   []Link
+}
+
+// TreeItem is a class within the inheritance tree.
+type TreeItem struct {
+	Link
+	Depth int
+}
+
+// VersionsPage is the data of the list of versions.
+type VersionsPage struct {
+	Page
+	// Versions contains every version, newest first.
+	Versions []VersionItem
+}
+
+// VersionItem summarizes a version.
+type VersionItem struct {
+	Link
+	Version fetch.Version
+	// Changes is the number of changes made by the version.
+	Changes int
+}
+
+// ClassPage is the data of a class page.
+type ClassPage struct {
+	Page
+	Name        string
+	Tags        []string
+	Description string
+	// Superclasses contains the classes from which the class inherits,
+	// nearest first.
+	Superclasses []Link
+	// Subclasses contains the classes that directly inherit from the class.
+	Subclasses []Link
+	Members    []MemberRow
+	// Inherited contains the members inherited from each superclass.
+	Inherited []InheritedMembers
+	// History contains the changes made to the class and its members, newest
+	// first.
+	History []HistoryItem
+}
+
+// MemberRow describes a member of a class.
+type MemberRow struct {
+	Name       string
+	MemberType string
+	// Parameters is the parameter list of a function, event, or callback.
+	Parameters string
+	// Type is the value type of a property, or the return type of a
+	// function or callback.
+	Type Link
+	// Security is the security context of the member. For properties with
+	// different read and write contexts, both are included.
+	Security    string
+	Tags        []string
+	Description string
+}
+
+// InheritedMembers lists the names of the members inherited from a class.
+type InheritedMembers struct {
+	Class   Link
+	Members []string
+}
+
+// EnumPage is the data of an enum page.
+type EnumPage struct {
+	Page
+	Name        string
+	Tags        []string
+	Description string
+	Items       []ItemRow
+	// History contains the changes made to the enum and its items, newest
+	// first.
+	History []HistoryItem
+}
+
+// ItemRow describes an item of an enum.
+type ItemRow struct {
+	Name        string
+	Value       int
+	Tags        []string
+	Description string
+}
+
+// HistoryItem is a change made to a class or enum by a version.
+type HistoryItem struct {
+	Version Link
+	Change  string
+}
+
+// VersionPage is the data of the page of a version.
+type VersionPage struct {
+	Page
+	Version fetch.Version
+	// Prev is the preceding version, if any.
+	Prev *Link
+	// Next is the following version, if any.
+	Next    *Link
+	Changes []Change
+}
+
+// Change is a change made by a version.
+type Change struct {
+	// Type is "Add", "Remove", or "Change".
+	Type string
+	// Subject links to the class or enum containing the changed descriptor.
+	Subject Link
+	// Text describes the change.
+	Text string
+}
+
+// Templates returns the templates used to render pages.
+func (s *Site) Templates() (*template.Template, error) {
+	t, err := template.New("site").Parse(DefaultTemplates)
+	if err != nil {
+		return nil, err
+	}
+	if s.TemplateFile == "" {
+		return t, nil
+	}
+	b, err := ioutil.ReadFile(s.TemplateFile)
+	if err != nil {
+		return nil, err
+	}
+	return t.New(s.TemplateFile).Parse(string(b))
+}
+
+// classPath returns the path to the page of a class, relative to base.
+func classPath(base, class string) string {
+	return base + "class/" + url.PathEscape(class) + ".html"
+}
+
+// enumPath returns the path to the page of an enum, relative to base.
+func enumPath(base, enum string) string {
+	return base + "enum/" + url.PathEscape(enum) + ".html"
+}
+
+// versionPath returns the path to the page of a version, relative to base.
+func versionPath(base, guid string) string {
+	return base + "version/" + url.PathEscape(guid) + ".html"
+}
+
+// builder holds the state of a build.
+type builder struct {
+	*Site
+	t   *template.Template
+	out gen.Output
+	// root is the API of the latest version.
+	root   rbxapi.Root
+	latest fetch.Version
+	// history maps the names of classes and enums to their changes, oldest
+	// first.
+	classHistory map[string][]HistoryItem
+	enumHistory  map[string][]HistoryItem
+}
+
+// Build writes the pages of the reference to out.
+func (s *Site) Build(ctx context.Context, out gen.Output) error {
+	if err := s.Filter.Check(); err != nil {
+		return err
+	}
+	t, err := s.Templates()
+	if err != nil {
+		return err
+	}
+	metas, err := s.Archive.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	var versions []fetch.Version
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; ok {
+			versions = append(versions, meta.Version)
+		}
+	}
+	if len(versions) == 0 {
+		return errors.New("archive contains no API dumps")
+	}
+
+	latest := versions[len(versions)-1]
+	root, err := s.Archive.JSONDump(ctx, latest.GUID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", latest.GUID, err)
+	}
+	b := &builder{
+		Site:         s,
+		t:            t,
+		out:          out,
+		root:         root,
+		latest:       latest,
+		classHistory: map[string][]HistoryItem{},
+		enumHistory:  map[string][]HistoryItem{},
+	}
+	// Only the dumps of adjacent versions are held at once.
+	items := make([]VersionItem, len(versions))
+	var prev rbxapi.Root
+	for i, v := range versions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var next rbxapi.Root = b.root
+		if i < len(versions)-1 {
+			root, err := s.Archive.JSONDump(ctx, v.GUID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", v.GUID, err)
+			}
+			next = root
+		}
+		page := VersionPage{
+			Page:    b.page("Version "+v.Number.String(), "../"),
+			Version: v,
+			Changes: b.changes(v, prev, next),
+		}
+		if i > 0 {
+			page.Prev = &Link{Name: versions[i-1].Number.String(), Path: versionPath("../", versions[i-1].GUID)}
+		}
+		if i < len(versions)-1 {
+			page.Next = &Link{Name: versions[i+1].Number.String(), Path: versionPath("../", versions[i+1].GUID)}
+		}
+		if err := b.render("version", versionPath("", v.GUID), page); err != nil {
+			return err
+		}
+		items[len(versions)-1-i] = VersionItem{
+			Link:    Link{Name: v.Number.String(), Path: versionPath("", v.GUID)},
+			Version: v,
+			Changes: len(page.Changes),
+		}
+		prev = next
+	}
+	if err := b.render("versions", "versions.html", VersionsPage{Page: b.page("Versions", ""), Versions: items}); err != nil {
+		return err
+	}
+	if err := b.render("style", "style.css", nil); err != nil {
+		return err
+	}
+	return b.pages()
+}
+
+// page returns the common data of a page.
+func (b *builder) page(title, base string) Page {
+	return Page{Title: title, Base: base, Latest: b.latest}
+}
+
+// render executes the named template into a file of the output.
+func (b *builder) render(name, file string, data interface{}) error {
+	f, err := b.out.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := b.t.ExecuteTemplate(f, name, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// changes returns the changes between two versions, and records them in the
+// history of each class and enum. The first version has no changes.
+func (b *builder) changes(v fetch.Version, prev, next rbxapi.Root) []Change {
+	if prev == nil {
+		return []Change{}
+	}
+	link := Link{Name: v.Number.String(), Path: versionPath("../", v.GUID)}
+	changes := []Change{}
+	for _, action := range (&diff.Diff{Prev: prev, Next: next}).Diff() {
+		change := Change{Type: action.GetType().String()}
+		if s, ok := action.(fmt.Stringer); ok {
+			change.Text = s.String()
+		}
+		switch a := action.(type) {
+		case patch.Member:
+			name := a.GetClass().GetName()
+			change.Subject = b.classLink("../", name)
+			b.classHistory[name] = append(b.classHistory[name], HistoryItem{Version: link, Change: change.Text})
+		case patch.Class:
+			name := a.GetClass().GetName()
+			change.Subject = b.classLink("../", name)
+			b.classHistory[name] = append(b.classHistory[name], HistoryItem{Version: link, Change: change.Text})
+		case patch.EnumItem:
+			name := a.GetEnum().GetName()
+			change.Subject = b.enumLink("../", name)
+			b.enumHistory[name] = append(b.enumHistory[name], HistoryItem{Version: link, Change: change.Text})
+		case patch.Enum:
+			name := a.GetEnum().GetName()
+			change.Subject = b.enumLink("../", name)
+			b.enumHistory[name] = append(b.enumHistory[name], HistoryItem{Version: link, Change: change.Text})
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// classLink returns a link to the page of a class. The link has no path if
+// the class is not present in the latest version, or is excluded.
+func (b *builder) classLink(base, name string) Link {
+	if class := b.root.GetClass(name); class != nil && b.Class(class) {
+		return Link{Name: name, Path: classPath(base, name)}
+	}
+	return Link{Name: name}
+}
+
+// enumLink returns a link to the page of an enum. The link has no path if
+// the enum is not present in the latest version, or is excluded.
+func (b *builder) enumLink(base, name string) Link {
+	if enum := b.root.GetEnum(name); enum != nil && b.Enum(enum) {
+		return Link{Name: name, Path: enumPath(base, name)}
+	}
+	return Link{Name: name}
+}
+
+// typeLink returns the name of a type, linked to the page of the class or
+// enum it refers to.
+func (b *builder) typeLink(typ rbxapi.Type) Link {
+	switch typ.GetCategory() {
+	case "Class":
+		return b.classLink("../", typ.GetName())
+	case "Enum":
+		return b.enumLink("../", typ.GetName())
+	}
+	return Link{Name: typ.GetName()}
+}
+
+// description returns the documentation of the entry of the given key.
+func (b *builder) description(key string) string {
+	if entry := b.Docs.Get(key); entry != nil {
+		return strings.TrimSpace(entry.Documentation)
+	}
+	return ""
+}
+
+// history returns a list of changes, newest first.
+func history(list []HistoryItem) []HistoryItem {
+	r := make([]HistoryItem, len(list))
+	for i, item := range list {
+		r[len(list)-1-i] = item
+	}
+	return r
+}
+
+// params returns the parameter list of a member.
+func params(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	ss := make([]string, len(list))
+	for i, param := range list {
+		ss[i] = param.GetName() + ": " + param.GetType().GetName()
+		if def, ok := param.GetDefault(); ok {
+			ss[i] += " = " + def
+		}
+	}
+	return "(" + strings.Join(ss, ", ") + ")"
+}
+
+// pages writes the index, class, and enum pages of the latest version.
+func (b *builder) pages() error {
+	var classes []rbxapi.Class
+	subclasses := map[string][]string{}
+	for _, class := range b.root.GetClasses() {
+		if b.Class(class) {
+			classes = append(classes, class)
+			subclasses[class.GetSuperclass()] = append(subclasses[class.GetSuperclass()], class.GetName())
+		}
+	}
+	for _, list := range subclasses {
+		sort.Strings(list)
+	}
+
+	index := IndexPage{Page: b.page("API Reference", "")}
+	// Classes are listed depth-first from the roots of the inheritance tree.
+	listed := map[string]bool{}
+	var walk func(name string, depth int)
+	walk = func(name string, depth int) {
+		if listed[name] {
+			return
+		}
+		listed[name] = true
+		index.Classes = append(index.Classes, TreeItem{Link: Link{Name: name, Path: classPath("", name)}, Depth: depth})
+		for _, sub := range subclasses[name] {
+			walk(sub, depth+1)
+		}
+	}
+	var roots []string
+	for _, class := range classes {
+		if super := b.root.GetClass(class.GetSuperclass()); super == nil || !b.Class(super) {
+			roots = append(roots, class.GetName())
+		}
+	}
+	sort.Strings(roots)
+	for _, name := range roots {
+		walk(name, 0)
+	}
+
+	for _, class := range classes {
+		if err := b.render("class", classPath("", class.GetName()), b.classPage(class, subclasses[class.GetName()])); err != nil {
+			return err
+		}
+	}
+	for _, enum := range b.root.GetEnums() {
+		if !b.Enum(enum) {
+			continue
+		}
+		index.Enums = append(index.Enums, Link{Name: enum.GetName(), Path: enumPath("", enum.GetName())})
+		if err := b.render("enum", enumPath("", enum.GetName()), b.enumPage(enum)); err != nil {
+			return err
+		}
+	}
+	sort.Slice(index.Enums, func(i, j int) bool { return index.Enums[i].Name < index.Enums[j].Name })
+	return b.render("index", "index.html", index)
+}
+
+// classPage returns the data of the page of a class.
+func (b *builder) classPage(class rbxapi.Class, subclasses []string) ClassPage {
+	name := class.GetName()
+	page := ClassPage{
+		Page:        b.page(name, "../"),
+		Name:        name,
+		Tags:        class.GetTags(),
+		Description: b.description(docs.ClassKey(name)),
+		History:     history(b.classHistory[name]),
+	}
+	for _, super := range gen.Superclasses(b.root, class) {
+		link := b.classLink("../", super)
+		page.Superclasses = append(page.Superclasses, link)
+		if link.Path == "" {
+			continue
+		}
+		inherited := InheritedMembers{Class: link}
+		for _, member := range b.root.GetClass(super).GetMembers() {
+			if b.Member(member) {
+				inherited.Members = append(inherited.Members, member.GetName())
+			}
+		}
+		if len(inherited.Members) > 0 {
+			page.Inherited = append(page.Inherited, inherited)
+		}
+	}
+	for _, sub := range subclasses {
+		page.Subclasses = append(page.Subclasses, b.classLink("../", sub))
+	}
+	for _, member := range class.GetMembers() {
+		if !b.Member(member) {
+			continue
+		}
+		row := MemberRow{
+			Name:        member.GetName(),
+			MemberType:  member.GetMemberType(),
+			Security:    rbxapi.MemberSecurity(member),
+			Tags:        member.GetTags(),
+			Description: b.description(docs.MemberKey(name, member.GetName())),
+		}
+		switch m := member.(type) {
+		case rbxapi.Property:
+			row.Type = b.typeLink(m.GetValueType())
+			if read, write := m.GetSecurity(); read != write {
+				row.Security = read + " / " + write
+			}
+		case rbxapi.Function:
+			// Also matches callbacks.
+			row.Parameters = params(m.GetParameters())
+			row.Type = b.typeLink(m.GetReturnType())
+		case rbxapi.Event:
+			row.Parameters = params(m.GetParameters())
+		}
+		page.Members = append(page.Members, row)
+	}
+	return page
+}
+
+// enumPage returns the data of the page of an enum.
+func (b *builder) enumPage(enum rbxapi.Enum) EnumPage {
+	name := enum.GetName()
+	page := EnumPage{
+		Page:        b.page(name, "../"),
+		Name:        name,
+		Tags:        enum.GetTags(),
+		Description: b.description(docs.EnumKey(name)),
+		History:     history(b.enumHistory[name]),
+	}
+	for _, item := range enum.GetEnumItems() {
+		if !b.EnumItem(item) {
+			continue
+		}
+		page.Items = append(page.Items, ItemRow{
+			Name:        item.GetName(),
+			Value:       item.GetValue(),
+			Tags:        item.GetTags(),
+			Description: b.description(docs.EnumItemKey(name, item.GetName())),
+		})
+	}
+	return page
+}
@@ -0,0 +1,176 @@
+package site
+
+// DefaultTemplates defines the default templates of the pages. Each page
+// template receives the data of its page: "index" an IndexPage, "versions" a
+// VersionsPage, "class" a ClassPage, "enum" an EnumPage, and "version" a
+// VersionPage. The "header" and "footer" templates receive the data of the
+// page that includes them, and "style" renders the stylesheet.
+const DefaultTemplates = `
+{{- define "header" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Base}}style.css">
+</head>
+<body>
+<header>
+<nav>
+<a href="{{.Base}}index.html">API Reference</a>
+<a href="{{.Base}}versions.html">Versions</a>
+</nav>
+</header>
+<main>
+{{end -}}
+
+{{- define "footer" -}}
+</main>
+<footer>Version {{.Latest.Number}}{{if not .Latest.Date.IsZero}}, deployed {{.Latest.Date.Format "2006-01-02"}}{{end}}</footer>
+</body>
+</html>
+{{end -}}
+
+{{- define "link" -}}
+{{if .Path}}<a href="{{.Path}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}
+{{- end -}}
+
+{{- define "tags" -}}
+{{range .}} <span class="tag">{{.}}</span>{{end}}
+{{- end -}}
+
+{{- define "history" -}}
+{{if .}}
+<h2>History</h2>
+<ul class="history">
+{{- range .}}
+<li>{{template "link" .Version}}: {{.Change}}</li>
+{{- end}}
+</ul>
+{{end}}
+{{- end -}}
+
+{{- define "index" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+<section>
+<h2>Classes</h2>
+<ul class="tree">
+{{- range .Classes}}
+<li style="--depth: {{.Depth}}">{{template "link" .Link}}</li>
+{{- end}}
+</ul>
+</section>
+<section>
+<h2>Enums</h2>
+<ul>
+{{- range .Enums}}
+<li>{{template "link" .}}</li>
+{{- end}}
+</ul>
+</section>
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "versions" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+<table>
+<thead><tr><th>Version</th><th>GUID</th><th>Date</th><th>Changes</th></tr></thead>
+<tbody>
+{{- range .Versions}}
+<tr><td>{{template "link" .Link}}</td><td><code>{{.Version.GUID}}</code></td><td>{{if not .Version.Date.IsZero}}{{.Version.Date.Format "2006-01-02"}}{{end}}</td><td>{{.Changes}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "class" -}}
+{{template "header" .}}
+<h1>{{.Name}}{{template "tags" .Tags}}</h1>
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+{{- if .Superclasses}}
+<p>Inherits: {{range $i, $c := .Superclasses}}{{if $i}} &rarr; {{end}}{{template "link" $c}}{{end}}</p>
+{{- end}}
+{{- if .Subclasses}}
+<p>Inherited by: {{range $i, $c := .Subclasses}}{{if $i}}, {{end}}{{template "link" $c}}{{end}}</p>
+{{- end}}
+{{- if .Members}}
+<h2>Members</h2>
+<table>
+<thead><tr><th>Type</th><th>Member</th><th>Kind</th><th>Security</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Members}}
+<tr id="{{.Name}}"><td>{{template "link" .Type}}</td><td><code>{{.Name}}{{.Parameters}}</code>{{template "tags" .Tags}}</td><td>{{.MemberType}}</td><td>{{.Security}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- if .Inherited}}
+<h2>Inherited members</h2>
+{{- range .Inherited}}
+{{- $c := .Class}}
+<p>From {{template "link" $c}}: {{range $i, $m := .Members}}{{if $i}}, {{end}}<a href="{{$c.Path}}#{{$m}}">{{$m}}</a>{{end}}</p>
+{{- end}}
+{{- end}}
+{{template "history" .History}}
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "enum" -}}
+{{template "header" .}}
+<h1>{{.Name}}{{template "tags" .Tags}}</h1>
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+{{- if .Items}}
+<h2>Items</h2>
+<table>
+<thead><tr><th>Name</th><th>Value</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Items}}
+<tr id="{{.Name}}"><td><code>{{.Name}}</code>{{template "tags" .Tags}}</td><td>{{.Value}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{template "history" .History}}
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "version" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+<p><code>{{.Version.GUID}}</code>{{if not .Version.Date.IsZero}}, deployed {{.Version.Date.Format "2006-01-02 15:04 MST"}}{{end}}</p>
+<p>
+{{- with .Prev}}<a href="{{.Path}}">&larr; {{.Name}}</a>{{end}}
+{{- with .Next}} <a href="{{.Path}}">{{.Name}} &rarr;</a>{{end -}}
+</p>
+{{- if .Changes}}
+<ul class="changes">
+{{- range .Changes}}
+<li class="{{.Type}}">{{template "link" .Subject}}: {{.Text}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No changes.</p>
+{{- end}}
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "style" -}}
+body { font-family: sans-serif; margin: 0 auto; max-width: 60em; padding: 0 1em; line-height: 1.4; }
+nav a { margin-right: 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; vertical-align: top; }
+.tag { font-size: 0.75em; background: #eee; border-radius: 0.25em; padding: 0 0.25em; }
+.tree li { margin-left: calc(var(--depth) * 1.5em); list-style: none; }
+.changes .Add { color: #070; }
+.changes .Remove { color: #a00; }
+footer { margin: 2em 0; color: #666; font-size: 0.875em; }
+{{end -}}
+`
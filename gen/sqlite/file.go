@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// pageSize is the size of each page of the database file.
+const pageSize = 4096
+
+// The following constants determine how much of a payload is stored within a
+// table leaf page, as described by the file format.
+const (
+	maxLocal = pageSize - 35
+	minLocal = (pageSize-12)*32/255 - 23
+)
+
+// Page types.
+const (
+	tableInterior = 0x05
+	tableLeaf     = 0x0D
+)
+
+// pager holds the pages of a database file, which are numbered from 1.
+type pager struct {
+	pages [][]byte
+}
+
+// alloc adds an empty page, returning its number.
+func (p *pager) alloc() uint32 {
+	p.pages = append(p.pages, make([]byte, pageSize))
+	return uint32(len(p.pages))
+}
+
+// page returns the content of the page of the given number.
+func (p *pager) page(n uint32) []byte {
+	return p.pages[n-1]
+}
+
+// appendVarint appends the variable-length encoding of v to b.
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [9]byte
+	if v > 1<<56-1 {
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7F) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+	n := 0
+	for {
+		buf[n] = byte(v&0x7F) | 0x80
+		n++
+		if v >>= 7; v == 0 {
+			break
+		}
+	}
+	buf[0] &= 0x7F
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return append(b, buf[:n]...)
+}
+
+// appendRecord appends a record containing the given values to b. A value is
+// nil, an int64, a bool, or a string.
+func appendRecord(b []byte, values []interface{}) []byte {
+	var header, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			header = appendVarint(header, 0)
+		case bool:
+			if v {
+				header = appendVarint(header, 9)
+			} else {
+				header = appendVarint(header, 8)
+			}
+		case int64:
+			switch {
+			case v == 0:
+				header = appendVarint(header, 8)
+			case v == 1:
+				header = appendVarint(header, 9)
+			case v >= math.MinInt8 && v <= math.MaxInt8:
+				header = appendVarint(header, 1)
+				body = append(body, byte(v))
+			case v >= math.MinInt16 && v <= math.MaxInt16:
+				header = appendVarint(header, 2)
+				body = append(body, byte(v>>8), byte(v))
+			case v >= -1<<23 && v < 1<<23:
+				header = appendVarint(header, 3)
+				body = append(body, byte(v>>16), byte(v>>8), byte(v))
+			case v >= math.MinInt32 && v <= math.MaxInt32:
+				header = appendVarint(header, 4)
+				body = append(body, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+			case v >= -1<<47 && v < 1<<47:
+				header = appendVarint(header, 5)
+				body = append(body, byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+			default:
+				header = appendVarint(header, 6)
+				body = binary.BigEndian.AppendUint64(body, uint64(v))
+			}
+		case string:
+			header = appendVarint(header, uint64(len(v))*2+13)
+			body = append(body, v...)
+		default:
+			panic("sqlite: unsupported value type")
+		}
+	}
+	// The size of the header includes the varint encoding the size.
+	size := len(header) + 1
+	for len(appendVarint(nil, uint64(size)))+len(header) != size {
+		size++
+	}
+	b = appendVarint(b, uint64(size))
+	b = append(b, header...)
+	return append(b, body...)
+}
+
+// table builds the B-tree of a table, whose rows are added in increasing
+// order of rowid.
+type table struct {
+	p *pager
+	// cells contains the cells of the leaf being filled, which are written to
+	// a page when the leaf is full, and used is their total size.
+	cells [][]byte
+	used  int
+	// offset is the offset of the B-tree header within leaf pages.
+	offset int
+	// fixed, if not zero, is the page number of the only leaf.
+	fixed uint32
+	// children contains the completed leaves.
+	children []child
+	lastRow  int64
+}
+
+// child refers to a page of a B-tree, and the largest rowid it contains.
+type child struct {
+	page uint32
+	key  int64
+}
+
+// newTable returns a builder of a table.
+func newTable(p *pager) *table {
+	return &table{p: p}
+}
+
+// free returns the number of bytes available in the current leaf.
+func (t *table) free() int {
+	return pageSize - t.offset - 8 - t.used - 2*len(t.cells)
+}
+
+// insert adds a row to the table.
+func (t *table) insert(rowid int64, values []interface{}) error {
+	payload := appendRecord(nil, values)
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(rowid))
+	local := len(payload)
+	if local > maxLocal {
+		local = minLocal + (len(payload)-minLocal)%(pageSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	cell = append(cell, payload[:local]...)
+	if rest := payload[local:]; len(rest) > 0 {
+		cell = binary.BigEndian.AppendUint32(cell, t.overflow(rest))
+	}
+	if len(t.cells) > 0 && t.free() < len(cell)+2 {
+		if t.fixed != 0 {
+			return errors.New("sqlite: table does not fit within a page")
+		}
+		t.flush()
+	}
+	t.cells = append(t.cells, cell)
+	t.used += len(cell)
+	t.lastRow = rowid
+	return nil
+}
+
+// overflow writes the part of a payload that does not fit within a leaf to
+// a chain of overflow pages, returning the number of the first.
+func (t *table) overflow(rest []byte) uint32 {
+	first := t.p.alloc()
+	n := first
+	for {
+		page := t.p.page(n)
+		k := copy(page[4:], rest)
+		rest = rest[k:]
+		if len(rest) == 0 {
+			return first
+		}
+		next := t.p.alloc()
+		binary.BigEndian.PutUint32(page, next)
+		n = next
+	}
+}
+
+// flush writes the cells collected for the current leaf to a page.
+func (t *table) flush() {
+	n := t.fixed
+	if n == 0 {
+		n = t.p.alloc()
+	}
+	page := t.p.page(n)
+	h := page[t.offset:]
+	h[0] = tableLeaf
+	binary.BigEndian.PutUint16(h[3:], uint16(len(t.cells)))
+	end := pageSize
+	for i, cell := range t.cells {
+		end -= len(cell)
+		copy(page[end:], cell)
+		binary.BigEndian.PutUint16(h[8+2*i:], uint16(end))
+	}
+	// A content offset of zero is interpreted as 65536.
+	binary.BigEndian.PutUint16(h[5:], uint16(end))
+	t.children = append(t.children, child{page: n, key: t.lastRow})
+	t.cells = t.cells[:0]
+	t.used = 0
+}
+
+// finish completes the B-tree, returning the number of its root page.
+func (t *table) finish() uint32 {
+	if len(t.cells) > 0 || len(t.children) == 0 {
+		t.flush()
+	}
+	level := t.children
+	for len(level) > 1 {
+		var next []child
+		for len(level) > 0 {
+			n := t.p.alloc()
+			page := t.p.page(n)
+			page[0] = tableInterior
+			end := pageSize
+			count := 0
+			// The last child of the page is its right-most pointer.
+			for len(level) > 1 && end-(12+2*(count+1)) >= 4+9 {
+				cell := binary.BigEndian.AppendUint32(nil, level[0].page)
+				cell = appendVarint(cell, uint64(level[0].key))
+				end -= len(cell)
+				copy(page[end:], cell)
+				binary.BigEndian.PutUint16(page[12+2*count:], uint16(end))
+				count++
+				level = level[1:]
+			}
+			binary.BigEndian.PutUint16(page[3:], uint16(count))
+			binary.BigEndian.PutUint16(page[5:], uint16(end))
+			binary.BigEndian.PutUint32(page[8:], level[0].page)
+			next = append(next, child{page: n, key: level[0].key})
+			level = level[1:]
+		}
+		level = next
+	}
+	return level[0].page
+}
+
+// file is a database file under construction.
+type file struct {
+	pager
+	schema  *table
+	objects int64
+}
+
+// newFile returns an empty database file.
+func newFile() *file {
+	f := &file{}
+	f.alloc()
+	// The schema table is rooted at the first page, after the file header.
+	f.schema = &table{p: &f.pager, offset: 100, fixed: 1}
+	return f
+}
+
+// create adds the schema entry of a table whose B-tree is rooted at root.
+func (f *file) create(name, sql string, root uint32) error {
+	f.objects++
+	return f.schema.insert(f.objects, []interface{}{"table", name, name, int64(root), sql})
+}
+
+// WriteTo writes the completed file to w.
+func (f *file) WriteTo(w io.Writer) (n int64, err error) {
+	f.schema.finish()
+	h := f.page(1)
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], pageSize)
+	h[18] = 1 // File format write version.
+	h[19] = 1 // File format read version.
+	h[21] = 64
+	h[22] = 32
+	h[23] = 32
+	binary.BigEndian.PutUint32(h[24:], 1) // File change counter.
+	binary.BigEndian.PutUint32(h[28:], uint32(len(f.pages)))
+	binary.BigEndian.PutUint32(h[40:], 1) // Schema cookie.
+	binary.BigEndian.PutUint32(h[44:], 4) // Schema format number.
+	binary.BigEndian.PutUint32(h[56:], 1) // Text encoding, UTF-8.
+	binary.BigEndian.PutUint32(h[92:], 1) // Version-valid-for number.
+	binary.BigEndian.PutUint32(h[96:], 3008000)
+	for _, page := range f.pages {
+		k, err := w.Write(page)
+		n += int64(k)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,309 @@
+// The sqlite package exports API structures to a SQLite database.
+//
+// A Database accumulates any number of versions of the API, and is written as
+// a SQLite database file with the following normalized tables:
+//
+//	versions        id, guid, number, channel, date
+//	classes         id, version_id, name, superclass, memory_category
+//	members         id, class_id, member_type, name, type_category, type_name,
+//	                security, write_security, category, can_load, can_save
+//	parameters      id, member_id, position, name, type_category, type_name,
+//	                default_value
+//	enums           id, version_id, name
+//	enum_items      id, enum_id, name, value
+//	class_tags      class_id, tag
+//	member_tags     member_id, tag
+//	enum_tags       enum_id, tag
+//	enum_item_tags  enum_item_id, tag
+//
+// The type of a member is the value type of a property, or the return type
+// of a function or callback. Columns that do not apply to a descriptor, or
+// that are specific to JSON dumps and absent from other structures, are NULL.
+//
+// The file is written directly, without depending on a SQLite library. The
+// tables have no indexes; queries over large histories may benefit from
+// creating indexes after the file is written.
+//
+// The package registers the "sqlite" target with the gen package, which
+// writes a database containing a single version.
+package sqlite
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/archive"
+	"github.com/karl-police/rbxapi/fetch"
+	"github.com/karl-police/rbxapi/gen"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"time"
+)
+
+// DefaultFile is the name of the file produced when Generator.File is empty.
+const DefaultFile = "api.db"
+
+// schema contains the name and definition of each table, in the order they
+// are created.
+var schema = []struct{ name, sql string }{
+	{"versions", `CREATE TABLE versions (
+	id INTEGER PRIMARY KEY,
+	guid TEXT,
+	number TEXT,
+	channel TEXT,
+	date TEXT
+)`},
+	{"classes", `CREATE TABLE classes (
+	id INTEGER PRIMARY KEY,
+	version_id INTEGER NOT NULL REFERENCES versions(id),
+	name TEXT NOT NULL,
+	superclass TEXT,
+	memory_category TEXT
+)`},
+	{"members", `CREATE TABLE members (
+	id INTEGER PRIMARY KEY,
+	class_id INTEGER NOT NULL REFERENCES classes(id),
+	member_type TEXT NOT NULL,
+	name TEXT NOT NULL,
+	type_category TEXT,
+	type_name TEXT,
+	security TEXT,
+	write_security TEXT,
+	category TEXT,
+	can_load INTEGER,
+	can_save INTEGER
+)`},
+	{"parameters", `CREATE TABLE parameters (
+	id INTEGER PRIMARY KEY,
+	member_id INTEGER NOT NULL REFERENCES members(id),
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	type_category TEXT,
+	type_name TEXT,
+	default_value TEXT
+)`},
+	{"enums", `CREATE TABLE enums (
+	id INTEGER PRIMARY KEY,
+	version_id INTEGER NOT NULL REFERENCES versions(id),
+	name TEXT NOT NULL
+)`},
+	{"enum_items", `CREATE TABLE enum_items (
+	id INTEGER PRIMARY KEY,
+	enum_id INTEGER NOT NULL REFERENCES enums(id),
+	name TEXT NOT NULL,
+	value INTEGER NOT NULL
+)`},
+	{"class_tags", `CREATE TABLE class_tags (
+	class_id INTEGER NOT NULL REFERENCES classes(id),
+	tag TEXT NOT NULL
+)`},
+	{"member_tags", `CREATE TABLE member_tags (
+	member_id INTEGER NOT NULL REFERENCES members(id),
+	tag TEXT NOT NULL
+)`},
+	{"enum_tags", `CREATE TABLE enum_tags (
+	enum_id INTEGER NOT NULL REFERENCES enums(id),
+	tag TEXT NOT NULL
+)`},
+	{"enum_item_tags", `CREATE TABLE enum_item_tags (
+	enum_item_id INTEGER NOT NULL REFERENCES enum_items(id),
+	tag TEXT NOT NULL
+)`},
+}
+
+// Database is a SQLite database under construction. Rows are encoded as they
+// are added, so a Database holds roughly the size of the resulting file in
+// memory.
+type Database struct {
+	f      *file
+	tables map[string]*table
+	// rows contains the number of rows of each table, which is also the
+	// rowid of the last row.
+	rows map[string]int64
+}
+
+// New returns an empty database.
+func New() *Database {
+	db := &Database{
+		f:      newFile(),
+		tables: map[string]*table{},
+		rows:   map[string]int64{},
+	}
+	for _, t := range schema {
+		db.tables[t.name] = newTable(&db.f.pager)
+	}
+	return db
+}
+
+// insert adds a row to a table, returning its rowid. An INTEGER PRIMARY KEY
+// column is an alias of the rowid, and is stored as NULL.
+func (db *Database) insert(table string, values ...interface{}) int64 {
+	db.rows[table]++
+	id := db.rows[table]
+	// Rows are small enough that insert fails only for the schema table.
+	db.tables[table].insert(id, values)
+	return id
+}
+
+// text returns s, or nil if s is empty.
+func text(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// tags adds a row for each tag of a descriptor.
+func (db *Database) tags(table string, id int64, t rbxapi.Taggable) {
+	for _, tag := range t.GetTags() {
+		db.insert(table, id, tag)
+	}
+}
+
+// parameters adds the parameters of a member.
+func (db *Database) parameters(member int64, params rbxapi.Parameters) {
+	for i, param := range params.GetParameters() {
+		var def interface{}
+		if v, ok := param.GetDefault(); ok {
+			def = v
+		}
+		typ := param.GetType()
+		db.insert("parameters", nil, member, int64(i), param.GetName(), text(typ.GetCategory()), typ.GetName(), def)
+	}
+}
+
+// Add adds a version of the API. The fields of v may be zero if the version
+// is unknown.
+func (db *Database) Add(v fetch.Version, root rbxapi.Root) {
+	var number, date interface{}
+	if v.Number != (fetch.Number{}) {
+		number = v.Number.String()
+	}
+	if !v.Date.IsZero() {
+		date = v.Date.UTC().Format(time.RFC3339)
+	}
+	version := db.insert("versions", nil, text(v.GUID), number, text(v.Channel), date)
+	for _, class := range root.GetClasses() {
+		var memcat interface{}
+		if c, ok := class.(*rbxapijson.Class); ok {
+			memcat = text(c.MemoryCategory)
+		}
+		cid := db.insert("classes", nil, version, class.GetName(), text(class.GetSuperclass()), memcat)
+		db.tags("class_tags", cid, class)
+		for _, member := range class.GetMembers() {
+			db.member(cid, member)
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		eid := db.insert("enums", nil, version, enum.GetName())
+		db.tags("enum_tags", eid, enum)
+		for _, item := range enum.GetEnumItems() {
+			iid := db.insert("enum_items", nil, eid, item.GetName(), int64(item.GetValue()))
+			db.tags("enum_item_tags", iid, item)
+		}
+	}
+}
+
+// member adds a member of a class.
+func (db *Database) member(class int64, member rbxapi.Member) {
+	var typ rbxapi.Type
+	var security, write, category, canLoad, canSave interface{}
+	switch m := member.(type) {
+	case rbxapi.Property:
+		typ = m.GetValueType()
+		read, w := m.GetSecurity()
+		security, write = text(read), text(w)
+		if p, ok := m.(*rbxapijson.Property); ok {
+			category = text(p.Category)
+			canLoad, canSave = p.CanLoad, p.CanSave
+		}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		typ = m.GetReturnType()
+		security = text(m.GetSecurity())
+	case rbxapi.Event:
+		security = text(m.GetSecurity())
+	}
+	var typeCategory, typeName interface{}
+	if typ != nil {
+		typeCategory, typeName = text(typ.GetCategory()), typ.GetName()
+	}
+	id := db.insert("members", nil, class, member.GetMemberType(), member.GetName(), typeCategory, typeName, security, write, category, canLoad, canSave)
+	db.tags("member_tags", id, member)
+	if m, ok := member.(interface{ GetParameters() rbxapi.Parameters }); ok {
+		db.parameters(id, m.GetParameters())
+	}
+}
+
+// AddArchive adds every version of an archive that has a JSON API dump, in
+// order.
+func (db *Database) AddArchive(ctx context.Context, a *archive.Archive) error {
+	metas, err := a.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		if _, ok := meta.Files[fetch.JSONDumpFile]; !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		root, err := a.JSONDump(ctx, meta.Version.GUID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", meta.Version.GUID, err)
+		}
+		db.Add(meta.Version, root)
+	}
+	return nil
+}
+
+// WriteTo writes the database file to w. The database must not be modified
+// afterwards.
+func (db *Database) WriteTo(w io.Writer) (n int64, err error) {
+	for _, t := range schema {
+		if err := db.f.create(t.name, t.sql, db.tables[t.name].finish()); err != nil {
+			return 0, err
+		}
+	}
+	return db.f.WriteTo(w)
+}
+
+func init() {
+	gen.Register(gen.Target{
+		Name:    "sqlite",
+		Summary: "SQLite database",
+		New:     func() gen.Generator { return &Generator{} },
+	})
+}
+
+// Generator generates a SQLite database containing a single version.
+type Generator struct {
+	// File is the name of the produced file.
+	File string
+}
+
+// Flags implements the gen.Flagger interface.
+func (g *Generator) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&g.File, "file", DefaultFile, "`name` of the produced file")
+}
+
+// Generate implements the gen.Generator interface.
+func (g *Generator) Generate(root rbxapi.Root, out gen.Output) error {
+	name := g.File
+	if name == "" {
+		name = DefaultFile
+	}
+	db := New()
+	db.Add(fetch.Version{}, root)
+	f, err := out.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := db.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
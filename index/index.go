@@ -0,0 +1,180 @@
+// The index package wraps an API structure with indexes of its descriptors,
+// providing constant-time lookups by name where the structure itself may
+// only provide linear scans.
+package index
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/internal/index"
+	"github.com/karl-police/rbxapi/patch"
+)
+
+type classEntry struct {
+	class   rbxapi.Class
+	members map[string]rbxapi.Member
+}
+
+type enumEntry struct {
+	enum   rbxapi.Enum
+	items  map[string]rbxapi.EnumItem
+	values map[int]rbxapi.EnumItem
+}
+
+// Index wraps a Root with indexes of its descriptors. Where several
+// descriptors have the same name, the first is returned, matching the
+// behavior of lookups such as rbxapi.Root.GetClass.
+//
+// An Index reflects the Root at the time the Index was built. If the Root is
+// modified through some other means, Rebuild must be called before the Index
+// is used again. Modifying the Root with Index.Patch rebuilds the Index
+// automatically.
+//
+// Index implements the rbxapi.Root interface, as well as the patch.Patcher
+// interface.
+type Index struct {
+	root    rbxapi.Root
+	classes map[string]*classEntry
+	enums   map[string]*enumEntry
+}
+
+// New returns an Index of root.
+func New(root rbxapi.Root) *Index {
+	idx := &Index{root: root}
+	idx.Rebuild()
+	return idx
+}
+
+// Root returns the Root wrapped by the Index.
+func (idx *Index) Root() rbxapi.Root {
+	return idx.root
+}
+
+// Rebuild updates the indexes to reflect the current content of the Root.
+func (idx *Index) Rebuild() {
+	idx.classes = map[string]*classEntry{}
+	rbxapi.RangeClasses(idx.root, func(class rbxapi.Class) bool {
+		if _, ok := idx.classes[class.GetName()]; !ok {
+			idx.classes[class.GetName()] = &classEntry{
+				class:   class,
+				members: index.Members(class),
+			}
+		}
+		return true
+	})
+	idx.enums = map[string]*enumEntry{}
+	rbxapi.RangeEnums(idx.root, func(enum rbxapi.Enum) bool {
+		if _, ok := idx.enums[enum.GetName()]; ok {
+			return true
+		}
+		e := &enumEntry{
+			enum:   enum,
+			items:  index.EnumItems(enum),
+			values: map[int]rbxapi.EnumItem{},
+		}
+		rbxapi.RangeEnumItems(enum, func(item rbxapi.EnumItem) bool {
+			if _, ok := e.values[item.GetValue()]; !ok {
+				e.values[item.GetValue()] = item
+			}
+			return true
+		})
+		idx.enums[enum.GetName()] = e
+		return true
+	})
+}
+
+// Patch applies actions to the Root, then rebuilds the Index. The actions are
+// ignored if the Root does not implement patch.Patcher.
+//
+// Patch implements the patch.Patcher interface.
+func (idx *Index) Patch(actions []patch.Action) {
+	p, ok := idx.root.(patch.Patcher)
+	if !ok {
+		return
+	}
+	p.Patch(actions)
+	idx.Rebuild()
+}
+
+// GetClasses returns the list of class descriptors of the Root.
+//
+// GetClasses implements the rbxapi.Root interface.
+func (idx *Index) GetClasses() []rbxapi.Class {
+	return idx.root.GetClasses()
+}
+
+// RangeClasses calls fn for each class descriptor of the Root.
+//
+// RangeClasses implements the rbxapi.ClassRanger interface.
+func (idx *Index) RangeClasses(fn func(class rbxapi.Class) bool) {
+	rbxapi.RangeClasses(idx.root, fn)
+}
+
+// GetClass returns the first class descriptor of the given name, or nil if
+// no class of the given name is present.
+//
+// GetClass implements the rbxapi.Root interface.
+func (idx *Index) GetClass(name string) rbxapi.Class {
+	if c, ok := idx.classes[name]; ok {
+		return c.class
+	}
+	return nil
+}
+
+// GetMember returns the first member descriptor of the given name within the
+// class of the given name, or nil if no such member is present.
+func (idx *Index) GetMember(class, member string) rbxapi.Member {
+	if c, ok := idx.classes[class]; ok {
+		return c.members[member]
+	}
+	return nil
+}
+
+// GetEnums returns the list of enum descriptors of the Root.
+//
+// GetEnums implements the rbxapi.Root interface.
+func (idx *Index) GetEnums() []rbxapi.Enum {
+	return idx.root.GetEnums()
+}
+
+// RangeEnums calls fn for each enum descriptor of the Root.
+//
+// RangeEnums implements the rbxapi.EnumRanger interface.
+func (idx *Index) RangeEnums(fn func(enum rbxapi.Enum) bool) {
+	rbxapi.RangeEnums(idx.root, fn)
+}
+
+// GetEnum returns the first enum descriptor of the given name, or nil if no
+// enum of the given name is present.
+//
+// GetEnum implements the rbxapi.Root interface.
+func (idx *Index) GetEnum(name string) rbxapi.Enum {
+	if e, ok := idx.enums[name]; ok {
+		return e.enum
+	}
+	return nil
+}
+
+// GetEnumItem returns the first item of the given name within the enum of
+// the given name, or nil if no such item is present.
+func (idx *Index) GetEnumItem(enum, item string) rbxapi.EnumItem {
+	if e, ok := idx.enums[enum]; ok {
+		return e.items[item]
+	}
+	return nil
+}
+
+// GetEnumItemByValue returns the first item with the given value within the
+// enum of the given name, or nil if no such item is present.
+func (idx *Index) GetEnumItemByValue(enum string, value int) rbxapi.EnumItem {
+	if e, ok := idx.enums[enum]; ok {
+		return e.values[value]
+	}
+	return nil
+}
+
+// Copy returns an Index of a deep copy of the Root.
+//
+// Copy implements the rbxapi.Root interface.
+func (idx *Index) Copy() rbxapi.Root {
+	return New(idx.root.Copy())
+}
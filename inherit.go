@@ -0,0 +1,94 @@
+package rbxapi
+
+// OverridePolicy determines how GetMembersInherited handles a member whose
+// name is also used by a member of a superclass.
+type OverridePolicy int
+
+const (
+	// OverrideNearest includes only the member of the class nearest to the
+	// class being inspected. Members of superclasses with the same name are
+	// hidden. This reflects how members are resolved at runtime.
+	OverrideNearest OverridePolicy = iota
+
+	// OverrideFarthest includes only the member of the most distant
+	// superclass. Members of subclasses with the same name are hidden.
+	OverrideFarthest
+
+	// OverrideAll includes every member, regardless of name.
+	OverrideAll
+)
+
+// GetSuperclasses returns the chain of class descriptors that begins with the
+// class of the given name, followed by its superclass, the superclass of that
+// class, and so on. The chain stops at the first superclass that is not
+// present in root. Returns nil if the class is not present. A chain that
+// loops back on itself is stopped before a class is repeated.
+func GetSuperclasses(root Root, class string) []Class {
+	var chain []Class
+	visited := map[string]bool{}
+	for c := root.GetClass(class); c != nil; c = root.GetClass(c.GetSuperclass()) {
+		if visited[c.GetName()] {
+			break
+		}
+		visited[c.GetName()] = true
+		chain = append(chain, c)
+	}
+	return chain
+}
+
+// GetMemberInherited returns the first member descriptor of the given name
+// found in the class of the given name or any of its superclasses, searching
+// the nearest class first. Returns nil if no such member is present.
+func GetMemberInherited(root Root, class, member string) Member {
+	for _, c := range GetSuperclasses(root, class) {
+		if m := c.GetMember(member); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// GetMembersInherited returns a list of the member descriptors of the class
+// of the given name, followed by the members of each of its superclasses,
+// nearest first. policy determines which members are included when several
+// members have the same name. Returns nil if the class is not present.
+func GetMembersInherited(root Root, class string, policy OverridePolicy) []Member {
+	chain := GetSuperclasses(root, class)
+	if chain == nil {
+		return nil
+	}
+	var members []Member
+	for _, c := range chain {
+		RangeMembers(c, func(member Member) bool {
+			members = append(members, member)
+			return true
+		})
+	}
+	switch policy {
+	case OverrideNearest:
+		seen := make(map[string]bool, len(members))
+		n := 0
+		for _, member := range members {
+			if name := member.GetName(); !seen[name] {
+				seen[name] = true
+				members[n] = member
+				n++
+			}
+		}
+		members = members[:n]
+	case OverrideFarthest:
+		last := make(map[string]int, len(members))
+		for i, member := range members {
+			last[member.GetName()] = i
+		}
+		n := 0
+		for i, member := range members {
+			if last[member.GetName()] == i {
+				members[n] = member
+				n++
+			}
+		}
+		members = members[:n]
+	}
+	return members
+}
@@ -0,0 +1,448 @@
+// The apitest package provides API structures to the tests and benchmarks of
+// other packages.
+//
+// By default, structures are generated to resemble the JSON API dump of a
+// real build, in size and in the variety of descriptors. Benchmarks may be run
+// against real dumps instead by setting the environment variables named by
+// DumpEnv and NextEnv to the paths of JSON API dumps.
+package apitest
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+)
+
+const (
+	// DumpEnv names an environment variable that may be set to the path of
+	// a JSON API dump, which is then used in place of a generated
+	// structure.
+	DumpEnv = "RBXAPI_TEST_DUMP"
+	// NextEnv names an environment variable that may be set to the path of
+	// a JSON API dump of a build following the dump named by DumpEnv.
+	NextEnv = "RBXAPI_TEST_NEXT"
+)
+
+const (
+	// Classes is the number of classes of a generated build, comparable to
+	// that of a real build.
+	Classes = 900
+	// Enums is the number of enums of a generated build, comparable to that
+	// of a real build.
+	Enums = 450
+	// referenced is the number of classes and enums that may be referred to
+	// by the types of members. Such descriptors are never removed by
+	// Evolve, so that the category of each type remains stable.
+	referenced = 64
+)
+
+var primitives = []string{"bool", "int", "int64", "float", "double", "string"}
+
+var dataTypes = []string{"Vector3", "Vector2", "CFrame", "Color3", "UDim2", "BrickColor", "Rect", "NumberRange", "Content", "Instances"}
+
+var groups = []string{"Array", "Dictionary", "Tuple", "Variant"}
+
+var classTags = []string{"NotCreatable", "NotReplicated", "Service", "Settings", "Deprecated", "NotBrowsable"}
+
+var memberTags = []string{"Deprecated", "Hidden", "NotBrowsable", "NotReplicated", "NotScriptable", "CanYield"}
+
+var threadSafety = []string{"Unsafe", "ReadSafe", "Safe"}
+
+var capabilities = []string{"Basic", "Network", "UI", "Assets", "Physics"}
+
+// generator holds the state of a generated structure.
+type generator struct {
+	r       *rand.Rand
+	classes []string
+	enums   []string
+	// prefix is prepended to generated names.
+	prefix string
+	n      int
+}
+
+// chance returns true with a probability of p.
+func (g *generator) chance(p float64) bool {
+	return g.r.Float64() < p
+}
+
+// name returns a name that is unique within the structure.
+func (g *generator) name(prefix string) string {
+	g.n++
+	return g.prefix + prefix + strconv.Itoa(g.n)
+}
+
+func (g *generator) tags(list []string, p float64) rbxapijson.Tags {
+	tags := rbxapijson.Tags{}
+	for _, tag := range list {
+		if g.chance(p) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// capabilities returns a list of capabilities, or nil if none are chosen.
+func (g *generator) capabilities(p float64) []string {
+	var list []string
+	for _, c := range capabilities {
+		if g.chance(p) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// preferred returns the name of a descriptor to be used in place of a
+// descriptor with the given tags, or an empty string if there is none. Only
+// deprecated descriptors have a preferred descriptor.
+func (g *generator) preferred(tags rbxapijson.Tags, prefix string) string {
+	if !tags.GetTag("Deprecated") || !g.chance(0.5) {
+		return ""
+	}
+	return g.name(prefix)
+}
+
+func (g *generator) typ() rbxapijson.Type {
+	refs := func(names []string) []string {
+		if len(names) > referenced {
+			return names[:referenced]
+		}
+		return names
+	}
+	switch n := g.r.Intn(100); {
+	case n < 40:
+		return rbxapijson.Type{Category: "Primitive", Name: primitives[g.r.Intn(len(primitives))]}
+	case n < 65:
+		return rbxapijson.Type{Category: "DataType", Name: dataTypes[g.r.Intn(len(dataTypes))]}
+	case n < 80:
+		classes := refs(g.classes)
+		return rbxapijson.Type{Category: "Class", Name: classes[g.r.Intn(len(classes))]}
+	case n < 92:
+		enums := refs(g.enums)
+		return rbxapijson.Type{Category: "Enum", Name: enums[g.r.Intn(len(enums))]}
+	}
+	return rbxapijson.Type{Category: "Group", Name: groups[g.r.Intn(len(groups))]}
+}
+
+// security returns a security context no less restrictive than min.
+func (g *generator) security(min rbxapi.Security) string {
+	var s rbxapi.Security
+	switch n := g.r.Intn(100); {
+	case n < 70:
+		s = rbxapi.SecurityNone
+	case n < 80:
+		s = rbxapi.SecurityPlugin
+	case n < 85:
+		s = rbxapi.SecurityLocalUser
+	case n < 95:
+		s = rbxapi.SecurityRobloxScript
+	case n < 98:
+		s = rbxapi.SecurityRoblox
+	default:
+		s = rbxapi.SecurityNotAccessible
+	}
+	if s.Less(min) {
+		s = min
+	}
+	return s.String()
+}
+
+func (g *generator) params(defaults bool) []rbxapijson.Parameter {
+	params := make([]rbxapijson.Parameter, g.r.Intn(4))
+	for i := range params {
+		params[i] = rbxapijson.Parameter{Type: g.typ(), Name: "arg" + strconv.Itoa(i)}
+		if defaults && g.chance(0.2) {
+			params[i].HasDefault = true
+			params[i].Default = strconv.Itoa(g.r.Intn(10))
+		}
+	}
+	return params
+}
+
+func (g *generator) member() rbxapi.Member {
+	switch n := g.r.Intn(100); {
+	case n < 55:
+		read := g.security(rbxapi.SecurityNone)
+		readOnly := g.chance(0.15)
+		tags := g.tags(memberTags, 0.05)
+		if readOnly {
+			tags = append(tags, "ReadOnly")
+		}
+		prop := &rbxapijson.Property{
+			Name:                g.name("Property"),
+			ValueType:           g.typ(),
+			Category:            "Data",
+			ReadSecurity:        read,
+			WriteSecurity:       g.security(rbxapi.ParseSecurity(read)),
+			CanLoad:             !readOnly,
+			CanSave:             !readOnly,
+			ThreadSafety:        threadSafety[g.r.Intn(len(threadSafety))],
+			Capabilities:        g.capabilities(0.02),
+			Tags:                tags,
+			PreferredDescriptor: g.preferred(tags, "Property"),
+		}
+		if g.chance(0.3) {
+			prop.HasDefault = true
+			prop.Default = strconv.Itoa(g.r.Intn(10))
+		}
+		return prop
+	case n < 85:
+		tags := g.tags(memberTags, 0.05)
+		return &rbxapijson.Function{
+			Name:                g.name("Function"),
+			Parameters:          g.params(true),
+			ReturnType:          g.typ(),
+			Security:            g.security(rbxapi.SecurityNone),
+			ThreadSafety:        threadSafety[g.r.Intn(len(threadSafety))],
+			Capabilities:        g.capabilities(0.02),
+			Tags:                tags,
+			PreferredDescriptor: g.preferred(tags, "Function"),
+		}
+	case n < 97:
+		tags := g.tags(memberTags, 0.05)
+		return &rbxapijson.Event{
+			Name:                g.name("Event"),
+			Parameters:          g.params(false),
+			Security:            g.security(rbxapi.SecurityNone),
+			ThreadSafety:        threadSafety[g.r.Intn(len(threadSafety))],
+			Capabilities:        g.capabilities(0.02),
+			Tags:                tags,
+			PreferredDescriptor: g.preferred(tags, "Event"),
+		}
+	}
+	tags := g.tags(memberTags, 0.05)
+	return &rbxapijson.Callback{
+		Name:                g.name("Callback"),
+		Parameters:          g.params(false),
+		ReturnType:          g.typ(),
+		Security:            g.security(rbxapi.SecurityNone),
+		ThreadSafety:        threadSafety[g.r.Intn(len(threadSafety))],
+		Capabilities:        g.capabilities(0.02),
+		Tags:                tags,
+		PreferredDescriptor: g.preferred(tags, "Callback"),
+	}
+}
+
+func (g *generator) class(superclass string) *rbxapijson.Class {
+	class := &rbxapijson.Class{
+		Name:           g.name("Class"),
+		Superclass:     superclass,
+		MemoryCategory: "Instances",
+		Capabilities:   g.capabilities(0.05),
+		Members:        []rbxapi.Member{},
+		Tags:           g.tags(classTags, 0.05),
+	}
+	class.PreferredDescriptor = g.preferred(class.Tags, "Class")
+	for n := g.r.Intn(60); n > 0; n-- {
+		class.Members = append(class.Members, g.member())
+	}
+	return class
+}
+
+func (g *generator) enumItem(value int) *rbxapijson.EnumItem {
+	item := &rbxapijson.EnumItem{
+		Name:  g.name("Item"),
+		Value: value,
+		Tags:  g.tags(memberTags[:1], 0.02),
+	}
+	item.PreferredDescriptor = g.preferred(item.Tags, "Item")
+	return item
+}
+
+func (g *generator) enum() *rbxapijson.Enum {
+	enum := &rbxapijson.Enum{
+		Name:  g.name("Enum"),
+		Items: []*rbxapijson.EnumItem{},
+		Tags:  g.tags(memberTags[:1], 0.02),
+	}
+	enum.PreferredDescriptor = g.preferred(enum.Tags, "Enum")
+	for i, n := 0, 1+g.r.Intn(16); i < n; i++ {
+		enum.Items = append(enum.Items, g.enumItem(i))
+	}
+	return enum
+}
+
+// Generate returns a generated structure with the given number of classes and
+// enums. The same seed always produces the same structure.
+func Generate(seed int64, classes, enums int) *rbxapijson.Root {
+	g := &generator{r: rand.New(rand.NewSource(seed))}
+	// Names are assigned before descriptors are generated, so that types may
+	// refer to any class or enum.
+	for i := 0; i < classes; i++ {
+		g.classes = append(g.classes, "Class"+strconv.Itoa(i))
+	}
+	for i := 0; i < enums; i++ {
+		g.enums = append(g.enums, "Enum"+strconv.Itoa(i))
+	}
+	root := &rbxapijson.Root{Classes: []*rbxapijson.Class{}, Enums: []*rbxapijson.Enum{}}
+	for i := range g.classes {
+		superclass := "<<<ROOT>>>"
+		if i > 0 {
+			superclass = g.classes[g.r.Intn(i)]
+		}
+		class := g.class(superclass)
+		class.Name = g.classes[i]
+		root.Classes = append(root.Classes, class)
+	}
+	for i := range g.enums {
+		enum := g.enum()
+		enum.Name = g.enums[i]
+		root.Enums = append(root.Enums, enum)
+	}
+	return root
+}
+
+// Evolve returns a copy of root with changes resembling those made between
+// consecutive builds. Members, classes, enums, and enum items are added and
+// removed, the types, security, parameters, and tags of members are changed,
+// and descriptors are deprecated in favor of others. The same seed always
+// produces the same changes.
+func Evolve(root *rbxapijson.Root, seed int64) *rbxapijson.Root {
+	g := &generator{r: rand.New(rand.NewSource(seed)), prefix: "Added"}
+	for _, class := range root.Classes {
+		g.classes = append(g.classes, class.Name)
+	}
+	for _, enum := range root.Enums {
+		g.enums = append(g.enums, enum.Name)
+	}
+	next := root.Copy().(*rbxapijson.Root)
+	classes := next.Classes[:0]
+	for i, class := range next.Classes {
+		if i >= referenced && g.chance(0.005) {
+			continue
+		}
+		classes = append(classes, class)
+		if !g.chance(0.1) {
+			continue
+		}
+		if g.chance(0.5) {
+			class.Members = append(class.Members, g.member())
+		}
+		if len(class.Members) > 0 && g.chance(0.3) {
+			j := g.r.Intn(len(class.Members))
+			class.Members = append(class.Members[:j], class.Members[j+1:]...)
+		}
+		if len(class.Members) > 0 {
+			evolveMember(g, class.Members[g.r.Intn(len(class.Members))])
+		}
+		if g.chance(0.1) {
+			class.Tags = g.tags(classTags, 0.1)
+			class.PreferredDescriptor = g.preferred(class.Tags, "Class")
+		}
+	}
+	next.Classes = classes
+	for n := len(root.Classes) / 100; n > 0; n-- {
+		next.Classes = append(next.Classes, g.class(next.Classes[0].Name))
+	}
+	enums := next.Enums[:0]
+	for i, enum := range next.Enums {
+		if i >= referenced && g.chance(0.005) {
+			continue
+		}
+		enums = append(enums, enum)
+		if g.chance(0.05) {
+			enum.Items = append(enum.Items, g.enumItem(len(enum.Items)))
+		}
+		if len(enum.Items) > 1 && g.chance(0.02) {
+			enum.Items = enum.Items[:len(enum.Items)-1]
+		}
+		if g.chance(0.02) {
+			enum.Tags = g.tags(memberTags[:1], 0.5)
+			enum.PreferredDescriptor = g.preferred(enum.Tags, "Enum")
+		}
+		if g.chance(0.02) {
+			item := enum.Items[g.r.Intn(len(enum.Items))]
+			item.Tags = g.tags(memberTags[:1], 0.5)
+			item.PreferredDescriptor = g.preferred(item.Tags, "Item")
+		}
+	}
+	next.Enums = enums
+	for n := len(root.Enums) / 100; n > 0; n-- {
+		next.Enums = append(next.Enums, g.enum())
+	}
+	return next
+}
+
+// evolveMember changes a field of member.
+func evolveMember(g *generator, member rbxapi.Member) {
+	switch member := member.(type) {
+	case *rbxapijson.Property:
+		switch g.r.Intn(3) {
+		case 0:
+			member.ValueType = g.typ()
+		case 1:
+			member.ReadSecurity = g.security(rbxapi.SecurityNone)
+			member.WriteSecurity = g.security(rbxapi.ParseSecurity(member.ReadSecurity))
+		default:
+			member.Tags = g.tags(memberTags, 0.2)
+			member.PreferredDescriptor = g.preferred(member.Tags, "Property")
+		}
+	case *rbxapijson.Function:
+		switch g.r.Intn(3) {
+		case 0:
+			member.ReturnType = g.typ()
+		case 1:
+			member.Parameters = g.params(true)
+		default:
+			member.Security = g.security(rbxapi.SecurityNone)
+		}
+	case *rbxapijson.Event:
+		switch g.r.Intn(2) {
+		case 0:
+			member.Parameters = g.params(false)
+		default:
+			member.Security = g.security(rbxapi.SecurityNone)
+		}
+	case *rbxapijson.Callback:
+		switch g.r.Intn(2) {
+		case 0:
+			member.ReturnType = g.typ()
+		default:
+			member.Tags = g.tags(memberTags, 0.2)
+			member.PreferredDescriptor = g.preferred(member.Tags, "Callback")
+		}
+	}
+}
+
+// load decodes the JSON API dump at the path in the given environment
+// variable. Returns nil if the variable is not set.
+func load(tb testing.TB, env string) *rbxapijson.Root {
+	path := os.Getenv(env)
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	root, err := rbxapijson.Decode(f)
+	if err != nil {
+		tb.Fatalf("%s: %s", path, err)
+	}
+	return root
+}
+
+// Root returns the structure of a build. This is the dump named by DumpEnv
+// if it is set, and is otherwise generated.
+func Root(tb testing.TB) *rbxapijson.Root {
+	if root := load(tb, DumpEnv); root != nil {
+		return root
+	}
+	return Generate(1, Classes, Enums)
+}
+
+// Builds returns the structures of two consecutive builds. These are the
+// dumps named by DumpEnv and NextEnv if they are set. If only DumpEnv is
+// set, the following build is evolved from its dump. Otherwise, both builds
+// are generated.
+func Builds(tb testing.TB) (prev, next *rbxapijson.Root) {
+	prev = Root(tb)
+	if next = load(tb, NextEnv); next == nil {
+		next = Evolve(prev, 2)
+	}
+	return prev, next
+}
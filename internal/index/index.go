@@ -0,0 +1,96 @@
+// The index package provides indexes of descriptors by name, allowing
+// descriptors to be matched without repeated linear scans.
+package index
+
+import (
+	"github.com/karl-police/rbxapi"
+)
+
+// MinLen is the length of a list below which scanning the list for each
+// name is faster than building an index of it.
+const MinLen = 48
+
+// Names maps names to positions within a list. Only the first position of
+// each name is retained, matching the behavior of lookups such as
+// rbxapi.Root.GetClass.
+type Names map[string]int
+
+// New returns an index of a list of n items, where name returns the name of
+// the item at position i.
+func New(n int, name func(i int) string) Names {
+	idx := make(Names, n)
+	for i := 0; i < n; i++ {
+		idx.Add(name(i), i)
+	}
+	return idx
+}
+
+// Get returns the position of the given name, or -1 if the name is not
+// present.
+func (idx Names) Get(name string) int {
+	if i, ok := idx[name]; ok {
+		return i
+	}
+	return -1
+}
+
+// Add sets the position of the given name, unless the name is already
+// present.
+func (idx Names) Add(name string, i int) {
+	if _, ok := idx[name]; !ok {
+		idx[name] = i
+	}
+}
+
+// Remove removes the given name.
+func (idx Names) Remove(name string) {
+	delete(idx, name)
+}
+
+// Classes returns the class descriptors of root, keyed by name.
+func Classes(root rbxapi.Root) map[string]rbxapi.Class {
+	m := map[string]rbxapi.Class{}
+	rbxapi.RangeClasses(root, func(class rbxapi.Class) bool {
+		if _, ok := m[class.GetName()]; !ok {
+			m[class.GetName()] = class
+		}
+		return true
+	})
+	return m
+}
+
+// Enums returns the enum descriptors of root, keyed by name.
+func Enums(root rbxapi.Root) map[string]rbxapi.Enum {
+	m := map[string]rbxapi.Enum{}
+	rbxapi.RangeEnums(root, func(enum rbxapi.Enum) bool {
+		if _, ok := m[enum.GetName()]; !ok {
+			m[enum.GetName()] = enum
+		}
+		return true
+	})
+	return m
+}
+
+// Members returns the member descriptors of class, keyed by name.
+func Members(class rbxapi.Class) map[string]rbxapi.Member {
+	m := map[string]rbxapi.Member{}
+	rbxapi.RangeMembers(class, func(member rbxapi.Member) bool {
+		if _, ok := m[member.GetName()]; !ok {
+			m[member.GetName()] = member
+		}
+		return true
+	})
+	return m
+}
+
+// EnumItems returns the items of enum, keyed by name.
+func EnumItems(enum rbxapi.Enum) map[string]rbxapi.EnumItem {
+	m := map[string]rbxapi.EnumItem{}
+	rbxapi.RangeEnumItems(enum, func(item rbxapi.EnumItem) bool {
+		if _, ok := m[item.GetName()]; !ok {
+			m[item.GetName()] = item
+		}
+		return true
+	})
+	return m
+}
@@ -0,0 +1,46 @@
+// The luaname package provides the lexical rules of names in Luau, shared by
+// the generators that produce Lua and Luau source code.
+package luaname
+
+// Keywords lists the reserved words of Luau, ordered by name. Luau also has
+// contextual keywords, such as continue, export, and type, which remain
+// valid names and are not listed. Neither is goto, which is reserved only
+// by later versions of Lua.
+var Keywords = []string{
+	"and", "break", "do", "else", "elseif", "end", "false", "for", "function",
+	"if", "in", "local", "nil", "not", "or", "repeat", "return", "then", "true",
+	"until", "while",
+}
+
+var keywords = map[string]bool{}
+
+func init() {
+	for _, k := range Keywords {
+		keywords[k] = true
+	}
+}
+
+// IsKeyword returns whether s is a reserved word, which cannot be used as a
+// name.
+func IsKeyword(s string) bool {
+	return keywords[s]
+}
+
+// IsName returns whether s can be used as a name, such as that of a variable
+// or of a field accessed with a dot.
+func IsName(s string) bool {
+	if s == "" || keywords[s] {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_',
+			'A' <= r && r <= 'Z',
+			'a' <= r && r <= 'z',
+			i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
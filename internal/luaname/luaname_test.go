@@ -0,0 +1,28 @@
+package luaname_test
+
+import (
+	"github.com/karl-police/rbxapi/internal/luaname"
+	"testing"
+)
+
+func TestIsName(t *testing.T) {
+	for s, want := range map[string]bool{
+		"":         false,
+		"Part":     true,
+		"_G":       true,
+		"Vector3":  true,
+		"3D":       false,
+		"Two Word": false,
+		"end":      false,
+		"function": false,
+		"continue": true,
+		"export":   true,
+		"goto":     true,
+		"self":     true,
+		"type":     true,
+	} {
+		if got := luaname.IsName(s); got != want {
+			t.Errorf("IsName(%q) = %t, expected %t", s, got, want)
+		}
+	}
+}
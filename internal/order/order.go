@@ -0,0 +1,112 @@
+// The order package provides the canonical ordering of descriptors shared by
+// the normalization of each implementation.
+package order
+
+import (
+	"sort"
+)
+
+// Classes returns the canonical order of a list of n classes, where name and
+// superclass return the name and superclass of the class at position i. The
+// result lists positions such that each class follows its superclass, with
+// the subclasses of a class visited depth-first in order of name. Classes
+// whose superclass is not present start a hierarchy of their own. Classes
+// within an inheritance cycle follow all other classes, ordered by name.
+func Classes(n int, name, superclass func(i int) string) []int {
+	byName := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		if _, ok := byName[name(i)]; !ok {
+			byName[name(i)] = i
+		}
+	}
+	children := map[string][]int{}
+	var roots []int
+	for i := 0; i < n; i++ {
+		if _, ok := byName[superclass(i)]; ok && superclass(i) != name(i) {
+			children[superclass(i)] = append(children[superclass(i)], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	byNameOrder := func(list []int) {
+		sort.SliceStable(list, func(a, b int) bool { return name(list[a]) < name(list[b]) })
+	}
+
+	list := make([]int, 0, n)
+	visited := make([]bool, n)
+	var walk func(i int)
+	walk = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		list = append(list, i)
+		sub := children[name(i)]
+		byNameOrder(sub)
+		for _, j := range sub {
+			walk(j)
+		}
+	}
+	byNameOrder(roots)
+	for _, i := range roots {
+		walk(i)
+	}
+	if len(list) < n {
+		var rest []int
+		for i := 0; i < n; i++ {
+			if !visited[i] {
+				rest = append(rest, i)
+			}
+		}
+		byNameOrder(rest)
+		list = append(list, rest...)
+	}
+	return list
+}
+
+// memberTypes is the canonical order of member types.
+var memberTypes = map[string]int{
+	"Property": 0,
+	"Function": 1,
+	"Event":    2,
+	"Callback": 3,
+}
+
+// MemberLess returns whether a member with type typeA and name nameA precedes
+// a member with type typeB and name nameB. Members are ordered by type, in
+// the order Property, Function, Event, Callback, then by name. Unknown types
+// follow known types.
+func MemberLess(typeA, nameA, typeB, nameB string) bool {
+	ra, ok := memberTypes[typeA]
+	if !ok {
+		ra = len(memberTypes)
+	}
+	rb, ok := memberTypes[typeB]
+	if !ok {
+		rb = len(memberTypes)
+	}
+	if ra != rb {
+		return ra < rb
+	}
+	if typeA != typeB {
+		return typeA < typeB
+	}
+	return nameA < nameB
+}
+
+// Tags returns tags sorted, with duplicates removed. The result shares the
+// memory of tags.
+func Tags(tags []string) []string {
+	if len(tags) == 0 {
+		return tags
+	}
+	sort.Strings(tags)
+	n := 1
+	for _, tag := range tags[1:] {
+		if tag != tags[n-1] {
+			tags[n] = tag
+			n++
+		}
+	}
+	return tags[:n]
+}
@@ -0,0 +1,48 @@
+// The parallel package distributes work over multiple goroutines. It is
+// shared by the CopyParallel methods of each implementation.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Range calls fn for each position in [0, n). Positions are distributed in
+// contiguous chunks of at least minChunk positions across at most workers
+// goroutines, so that each goroutine handles a distinct range. If workers is
+// less than 1, then runtime.GOMAXPROCS(0) is used. If only one goroutine
+// would be used, fn is called serially. Range returns after every call has
+// returned.
+func Range(n, workers, minChunk int, fn func(i int)) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if minChunk < 1 {
+		minChunk = 1
+	}
+	if workers > n/minChunk {
+		workers = n / minChunk
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	size := (n + workers - 1) / workers
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				fn(i)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
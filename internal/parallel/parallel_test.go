@@ -0,0 +1,22 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 64, 1000} {
+		for _, workers := range []int{0, 1, 3, 16} {
+			counts := make([]int32, n)
+			Range(n, workers, 4, func(i int) {
+				atomic.AddInt32(&counts[i], 1)
+			})
+			for i, c := range counts {
+				if c != 1 {
+					t.Errorf("n=%d workers=%d: position %d visited %d times", n, workers, i, c)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// The merge package combines API structures.
+package merge
+
+import (
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"strconv"
+)
+
+// Policy determines how conflicts are resolved.
+type Policy int
+
+const (
+	PreferOverlay Policy = iota // Conflicting fields take the overlay value.
+	PreferBase                  // Conflicting fields keep the base value.
+	Fail                        // Conflicts cause the merge to fail.
+)
+
+// String returns a string representation of the policy.
+func (p Policy) String() string {
+	switch p {
+	case PreferOverlay:
+		return "prefer-overlay"
+	case PreferBase:
+		return "prefer-base"
+	case Fail:
+		return "fail"
+	}
+	return ""
+}
+
+// ParsePolicy returns the policy represented by s, as returned by
+// Policy.String.
+func ParsePolicy(s string) (Policy, bool) {
+	for _, p := range []Policy{PreferOverlay, PreferBase, Fail} {
+		if p.String() == s {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// Conflict is a field that has different values in the base and overlay.
+type Conflict struct {
+	// Action is a Change action from the base value to the overlay value.
+	Action patch.Action
+}
+
+func (c Conflict) String() string {
+	return c.Action.String()
+}
+
+// ConflictError is returned by Overlay under the Fail policy when there are
+// conflicts.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (err *ConflictError) Error() string {
+	return strconv.Itoa(len(err.Conflicts)) + " conflicts"
+}
+
+// union returns the tags of a followed by the tags of b not in a.
+func union(a, b []string) []string {
+	list := append([]string{}, a...)
+loop:
+	for _, t := range b {
+		for _, s := range a {
+			if s == t {
+				continue loop
+			}
+		}
+		list = append(list, t)
+	}
+	return list
+}
+
+// withNext returns a copy of a Change action with a different next value.
+func withNext(action patch.Action, next interface{}) patch.Action {
+	switch a := action.(type) {
+	case patch.Member:
+		return &diff.MemberAction{Type: patch.Change, Class: a.GetClass(), Member: a.GetMember(), Field: a.GetField(), Prev: a.GetPrev(), Next: next}
+	case patch.Class:
+		return &diff.ClassAction{Type: patch.Change, Class: a.GetClass(), Field: a.GetField(), Prev: a.GetPrev(), Next: next}
+	case patch.EnumItem:
+		return &diff.EnumItemAction{Type: patch.Change, Enum: a.GetEnum(), EnumItem: a.GetEnumItem(), Field: a.GetField(), Prev: a.GetPrev(), Next: next}
+	case patch.Enum:
+		return &diff.EnumAction{Type: patch.Change, Enum: a.GetEnum(), Field: a.GetField(), Prev: a.GetPrev(), Next: next}
+	}
+	return action
+}
+
+// Overlay returns a copy of base with the descriptors of overlay merged into
+// it. Descriptors present only in overlay are added, and descriptors present
+// only in base are kept. The tags of descriptors present in both are
+// combined. Other fields that differ are conflicts, which are resolved
+// according to policy, and are returned.
+//
+// The result has the same underlying type as base, which must implement
+// patch.Patcher. Information in overlay that cannot be represented by this
+// type is lost.
+func Overlay(base, overlay rbxapi.Root, policy Policy) (rbxapi.Root, []Conflict, error) {
+	root := base.Copy()
+	patcher, ok := root.(patch.Patcher)
+	if !ok {
+		return nil, nil, errors.New("base cannot be patched")
+	}
+	var actions []patch.Action
+	var conflicts []Conflict
+	for _, action := range (&diff.Diff{Prev: base, Next: overlay}).Diff() {
+		switch action.GetType() {
+		case patch.Add:
+			actions = append(actions, action)
+		case patch.Change:
+			if action.GetField() == "Tags" {
+				prev, _ := action.GetPrev().([]string)
+				next, _ := action.GetNext().([]string)
+				actions = append(actions, withNext(action, union(prev, next)))
+				continue
+			}
+			conflicts = append(conflicts, Conflict{Action: action})
+			if policy == PreferOverlay {
+				actions = append(actions, action)
+			}
+		}
+	}
+	if policy == Fail && len(conflicts) > 0 {
+		return nil, conflicts, &ConflictError{Conflicts: conflicts}
+	}
+	patcher.Patch(actions)
+	return root, conflicts, nil
+}
@@ -0,0 +1,322 @@
+package merge
+
+import (
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"reflect"
+)
+
+// Level indicates the kind of descriptor to which a MergeConflict applies.
+type Level int
+
+const (
+	ClassLevel    Level = iota // The conflict applies to a class.
+	MemberLevel                // The conflict applies to a member of a class.
+	EnumLevel                  // The conflict applies to an enum.
+	EnumItemLevel              // The conflict applies to an item of an enum.
+)
+
+// String returns a string representation of the level.
+func (l Level) String() string {
+	switch l {
+	case ClassLevel:
+		return "Class"
+	case MemberLevel:
+		return "Member"
+	case EnumLevel:
+		return "Enum"
+	case EnumItemLevel:
+		return "EnumItem"
+	}
+	return ""
+}
+
+// MergeConflict is a change made by both sides of a three-way merge that
+// could not be reconciled. Neither change is applied to the merged structure,
+// which retains the base value. A conflict can be resolved by passing either
+// Mine or Theirs to the Patch method of the merged structure.
+type MergeConflict struct {
+	// Level is the kind of descriptor to which the conflict applies.
+	Level Level
+	// Parent is the name of the class or enum of the descriptor.
+	Parent string
+	// Name is the name of the member or enum item of the descriptor. Empty
+	// for conflicts at the class or enum level.
+	Name string
+	// Field is the name of the conflicting field. Empty when the descriptor
+	// itself was added or removed.
+	Field string
+
+	// Mine is the action made by the "mine" side.
+	Mine patch.Action
+	// Theirs is the action made by the "theirs" side.
+	Theirs patch.Action
+}
+
+func (c MergeConflict) String() string {
+	return "mine: " + c.Mine.String() + "; theirs: " + c.Theirs.String()
+}
+
+// actionKey identifies the descriptor and field affected by an action.
+type actionKey struct {
+	level  Level
+	parent string
+	name   string
+	field  string
+	typ    patch.Type
+}
+
+// keyOf returns the key of an action.
+func keyOf(action patch.Action) actionKey {
+	k := actionKey{field: action.GetField(), typ: action.GetType()}
+	switch a := action.(type) {
+	case patch.Member:
+		k.level = MemberLevel
+		k.parent = a.GetClass().GetName()
+		k.name = a.GetMember().GetName()
+	case patch.Class:
+		k.level = ClassLevel
+		k.parent = a.GetClass().GetName()
+	case patch.EnumItem:
+		k.level = EnumItemLevel
+		k.parent = a.GetEnum().GetName()
+		k.name = a.GetEnumItem().GetName()
+	case patch.Enum:
+		k.level = EnumLevel
+		k.parent = a.GetEnum().GetName()
+	}
+	return k
+}
+
+// removals returns the keys of the removal actions that would conflict with
+// an action of the given key, which are those that remove the descriptor
+// itself, or the class or enum containing it.
+func removals(k actionKey) []actionKey {
+	keys := []actionKey{{level: k.level, parent: k.parent, name: k.name, typ: patch.Remove}}
+	switch k.level {
+	case MemberLevel:
+		keys = append(keys, actionKey{level: ClassLevel, parent: k.parent, typ: patch.Remove})
+	case EnumItemLevel:
+		keys = append(keys, actionKey{level: EnumLevel, parent: k.parent, typ: patch.Remove})
+	}
+	return keys
+}
+
+// equalValues returns whether two field values of a Change action are equal.
+func equalValues(a, b interface{}) bool {
+	switch a := a.(type) {
+	case rbxapi.Type:
+		b, ok := b.(rbxapi.Type)
+		return ok && a.GetCategory() == b.GetCategory() && a.GetName() == b.GetName()
+	case rbxapi.Parameters:
+		b, ok := b.(rbxapi.Parameters)
+		if !ok || a.GetLength() != b.GetLength() {
+			return false
+		}
+		for i := 0; i < a.GetLength(); i++ {
+			p, q := a.GetParameter(i), b.GetParameter(i)
+			pd, pk := p.GetDefault()
+			qd, qk := q.GetDefault()
+			if !equalValues(p.GetType(), q.GetType()) || p.GetName() != q.GetName() || pk != qk || pd != qd {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// equalMembers returns whether two member descriptors are equal.
+func equalMembers(class rbxapi.Class, a, b rbxapi.Member) bool {
+	if a.GetMemberType() != b.GetMemberType() {
+		return false
+	}
+	var d patch.Differ
+	switch a := a.(type) {
+	case rbxapi.Property:
+		b, _ := b.(rbxapi.Property)
+		d = &diff.DiffProperty{Class: class, Prev: a, Next: b}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		if a.GetMemberType() == "Callback" {
+			a, _ := a.(rbxapi.Callback)
+			b, _ := b.(rbxapi.Callback)
+			d = &diff.DiffCallback{Class: class, Prev: a, Next: b}
+			break
+		}
+		b, _ := b.(rbxapi.Function)
+		d = &diff.DiffFunction{Class: class, Prev: a, Next: b}
+	case rbxapi.Event:
+		b, _ := b.(rbxapi.Event)
+		d = &diff.DiffEvent{Class: class, Prev: a, Next: b}
+	default:
+		return false
+	}
+	return len(d.Diff()) == 0
+}
+
+// sameAction returns whether two actions of the same key make the same
+// change.
+func sameAction(a, b patch.Action) bool {
+	switch a.GetType() {
+	case patch.Remove:
+		return true
+	case patch.Change:
+		return equalValues(a.GetNext(), b.GetNext())
+	}
+	switch a := a.(type) {
+	case patch.Member:
+		b := b.(patch.Member)
+		return equalMembers(a.GetClass(), a.GetMember(), b.GetMember())
+	case patch.Class:
+		return len((&diff.DiffClass{Prev: a.GetClass(), Next: b.(patch.Class).GetClass()}).Diff()) == 0
+	case patch.EnumItem:
+		b := b.(patch.EnumItem)
+		return len((&diff.DiffEnumItem{Enum: a.GetEnum(), Prev: a.GetEnumItem(), Next: b.GetEnumItem()}).Diff()) == 0
+	case patch.Enum:
+		return len((&diff.DiffEnum{Prev: a.GetEnum(), Next: b.(patch.Enum).GetEnum()}).Diff()) == 0
+	}
+	return false
+}
+
+// mergeTags returns the tags of base that were removed by neither mine nor
+// theirs, followed by the tags added by mine, then the tags added by theirs.
+func mergeTags(base, mine, theirs []string) []string {
+	has := func(list []string, tag string) bool {
+		for _, t := range list {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+	list := []string{}
+	for _, t := range base {
+		if has(mine, t) && has(theirs, t) {
+			list = append(list, t)
+		}
+	}
+	for _, t := range mine {
+		if !has(base, t) && !has(list, t) {
+			list = append(list, t)
+		}
+	}
+	for _, t := range theirs {
+		if !has(base, t) && !has(list, t) {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// ThreeWay merges the changes made from base to mine and from base to theirs,
+// returning a copy of base with both sets of changes applied.
+//
+// Changes made by only one side are applied. Changes made identically by both
+// sides are applied once. Tags changed by both sides are combined, keeping
+// tags added by either side and dropping tags removed by either side. Other
+// changes made differently by both sides are conflicts, as are changes made
+// by one side to a descriptor removed by the other. Conflicting changes are
+// not applied, and are returned so that they can be resolved by the caller.
+//
+// The result has the same underlying type as base, which must implement
+// patch.Patcher. Information in mine or theirs that cannot be represented by
+// this type is lost.
+func ThreeWay(base, mine, theirs rbxapi.Root) (rbxapi.Root, []MergeConflict, error) {
+	root := base.Copy()
+	patcher, ok := root.(patch.Patcher)
+	if !ok {
+		return nil, nil, errors.New("base cannot be patched")
+	}
+	mineActions := (&diff.Diff{Prev: base, Next: mine}).Diff()
+	theirActions := (&diff.Diff{Prev: base, Next: theirs}).Diff()
+	mineKeys := make([]actionKey, len(mineActions))
+	mineIndex := make(map[actionKey]int, len(mineActions))
+	for i, action := range mineActions {
+		mineKeys[i] = keyOf(action)
+		mineIndex[mineKeys[i]] = i
+	}
+	theirKeys := make([]actionKey, len(theirActions))
+	theirIndex := make(map[actionKey]int, len(theirActions))
+	for i, action := range theirActions {
+		theirKeys[i] = keyOf(action)
+		theirIndex[theirKeys[i]] = i
+	}
+
+	var conflicts []MergeConflict
+	mineDone := make([]bool, len(mineActions))
+	theirDone := make([]bool, len(theirActions))
+	conflict := func(k actionKey, i, j int) {
+		conflicts = append(conflicts, MergeConflict{
+			Level:  k.level,
+			Parent: k.parent,
+			Name:   k.name,
+			Field:  k.field,
+			Mine:   mineActions[i],
+			Theirs: theirActions[j],
+		})
+		mineDone[i] = true
+		theirDone[j] = true
+	}
+
+	// Changes to descriptors removed by the other side. Removals within a
+	// removed descriptor are compatible.
+	for i, k := range mineKeys {
+		if k.typ == patch.Remove {
+			continue
+		}
+		for _, r := range removals(k) {
+			if j, ok := theirIndex[r]; ok {
+				conflict(r, i, j)
+			}
+		}
+	}
+	for j, k := range theirKeys {
+		if k.typ == patch.Remove {
+			continue
+		}
+		for _, r := range removals(k) {
+			if i, ok := mineIndex[r]; ok {
+				conflict(r, i, j)
+			}
+		}
+	}
+
+	var actions []patch.Action
+	for i, action := range mineActions {
+		if mineDone[i] {
+			continue
+		}
+		k := mineKeys[i]
+		j, ok := theirIndex[k]
+		if !ok {
+			actions = append(actions, action)
+			continue
+		}
+		if theirDone[j] {
+			mineDone[i] = true
+			continue
+		}
+		theirDone[j] = true
+		switch {
+		case sameAction(action, theirActions[j]):
+			actions = append(actions, action)
+		case k.typ == patch.Change && k.field == "Tags":
+			prev, _ := action.GetPrev().([]string)
+			next, _ := action.GetNext().([]string)
+			other, _ := theirActions[j].GetNext().([]string)
+			actions = append(actions, withNext(action, mergeTags(prev, next, other)))
+		default:
+			conflict(k, i, j)
+		}
+	}
+	for j, action := range theirActions {
+		if !theirDone[j] {
+			actions = append(actions, action)
+		}
+	}
+	patcher.Patch(actions)
+	return root, conflicts, nil
+}
@@ -0,0 +1,57 @@
+// The mmap package provides read-only memory-mapped access to files.
+//
+// Mapping a file allows large API dumps to be decoded without first reading
+// them into memory. On systems without memory mapping support, the content of
+// a file is instead read into memory.
+package mmap
+
+import (
+	"errors"
+)
+
+// ErrClosed is returned when accessing a Mapping that has been closed.
+var ErrClosed = errors.New("mapping is closed")
+
+// Mapping is a read-only view of the content of a file.
+type Mapping struct {
+	data   []byte
+	mapped bool
+	closed bool
+}
+
+// New returns a Mapping of content that already resides in memory. Closing
+// the Mapping does not affect b.
+func New(b []byte) *Mapping {
+	return &Mapping{data: b}
+}
+
+// Open maps the file at the given path into memory.
+func Open(path string) (*Mapping, error) {
+	return open(path)
+}
+
+// Bytes returns the content of the mapping. The returned slice must not be
+// modified, and must not be used after the Mapping is closed.
+func (m *Mapping) Bytes() []byte {
+	return m.data
+}
+
+// Len returns the length of the content of the mapping.
+func (m *Mapping) Len() int {
+	return len(m.data)
+}
+
+// Close releases the mapping. Any slice returned by Bytes, along with any
+// value referring to its memory, must not be used afterwards.
+func (m *Mapping) Close() error {
+	if m.closed {
+		return ErrClosed
+	}
+	m.closed = true
+	data := m.data
+	m.data = nil
+	if !m.mapped {
+		return nil
+	}
+	return unmap(data)
+}
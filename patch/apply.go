@@ -0,0 +1,330 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"strings"
+)
+
+// valueString converts an action value to a string.
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	case rbxapi.Type:
+		return v.String()
+	case rbxapi.Parameters:
+		params := v.GetParameters()
+		ss := make([]string, len(params))
+		for i, p := range params {
+			ss[i] = p.GetType().String() + " " + p.GetName()
+			if d, ok := p.GetDefault(); ok {
+				ss[i] += " = " + d
+			}
+		}
+		return "(" + strings.Join(ss, ", ") + ")"
+	}
+	return fmt.Sprint(v)
+}
+
+// ValuesEqual returns whether two action values are equal. Types without a
+// category match any type of the same name.
+func ValuesEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case rbxapi.Type:
+		b, ok := b.(rbxapi.Type)
+		if !ok || a.GetName() != b.GetName() {
+			return false
+		}
+		return a.GetCategory() == "" || b.GetCategory() == "" || a.GetCategory() == b.GetCategory()
+	case rbxapi.Parameters:
+		b, ok := b.(rbxapi.Parameters)
+		if !ok || a.GetLength() != b.GetLength() {
+			return false
+		}
+		for i := 0; i < a.GetLength(); i++ {
+			p, q := a.GetParameter(i), b.GetParameter(i)
+			pd, pok := p.GetDefault()
+			qd, qok := q.GetDefault()
+			if p.GetName() != q.GetName() || !ValuesEqual(p.GetType(), q.GetType()) || pok != qok || pd != qd {
+				return false
+			}
+		}
+		return true
+	}
+	return valueString(a) == valueString(b)
+}
+
+// FieldValue returns the value of a field of a descriptor. Returns false if
+// the field cannot be determined.
+func FieldValue(desc interface{}, field string) (interface{}, bool) {
+	if field == "Tags" {
+		if t, ok := desc.(rbxapi.Taggable); ok {
+			return t.GetTags(), true
+		}
+	}
+	switch d := desc.(type) {
+	case rbxapi.Class:
+		switch field {
+		case "Name":
+			return d.GetName(), true
+		case "Superclass":
+			return d.GetSuperclass(), true
+		}
+	case rbxapi.Member:
+		if field == "Name" {
+			return d.GetName(), true
+		}
+		switch d := d.(type) {
+		case rbxapi.Property:
+			read, write := d.GetSecurity()
+			switch field {
+			case "ValueType":
+				return d.GetValueType(), true
+			case "ReadSecurity":
+				return read, true
+			case "WriteSecurity":
+				return write, true
+			}
+		case rbxapi.Function:
+			switch field {
+			case "ReturnType":
+				return d.GetReturnType(), true
+			case "Parameters":
+				return d.GetParameters(), true
+			case "Security":
+				return d.GetSecurity(), true
+			}
+		case rbxapi.Event:
+			switch field {
+			case "Parameters":
+				return d.GetParameters(), true
+			case "Security":
+				return d.GetSecurity(), true
+			}
+		}
+	case rbxapi.Enum:
+		if field == "Name" {
+			return d.GetName(), true
+		}
+	case rbxapi.EnumItem:
+		switch field {
+		case "Name":
+			return d.GetName(), true
+		case "Value":
+			return d.GetValue(), true
+		}
+	}
+	return nil, false
+}
+
+// findMember returns the member of class with the same name and member type
+// as m.
+func findMember(class rbxapi.Class, m rbxapi.Member) rbxapi.Member {
+	for _, member := range class.GetMembers() {
+		if member.GetName() == m.GetName() && member.GetMemberType() == m.GetMemberType() {
+			return member
+		}
+	}
+	return nil
+}
+
+// target returns the descriptor within root to which an action applies, or
+// nil if it is not present. ok is false if the action has no descriptor.
+func target(root rbxapi.Root, action Action) (desc interface{}, ok bool) {
+	switch a := action.(type) {
+	case Member:
+		if a.GetClass() == nil || a.GetMember() == nil {
+			return nil, false
+		}
+		if class := root.GetClass(a.GetClass().GetName()); class != nil {
+			if member := findMember(class, a.GetMember()); member != nil {
+				return member, true
+			}
+		}
+		return nil, true
+	case Class:
+		if a.GetClass() == nil {
+			return nil, false
+		}
+		if class := root.GetClass(a.GetClass().GetName()); class != nil {
+			return class, true
+		}
+		return nil, true
+	case EnumItem:
+		if a.GetEnum() == nil || a.GetEnumItem() == nil {
+			return nil, false
+		}
+		if enum := root.GetEnum(a.GetEnum().GetName()); enum != nil {
+			if item := enum.GetEnumItem(a.GetEnumItem().GetName()); item != nil {
+				return item, true
+			}
+		}
+		return nil, true
+	case Enum:
+		if a.GetEnum() == nil {
+			return nil, false
+		}
+		if enum := root.GetEnum(a.GetEnum().GetName()); enum != nil {
+			return enum, true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// parentExists returns whether the class or enum containing the subject of a
+// Member or EnumItem action is present in root.
+func parentExists(root rbxapi.Root, action Action) bool {
+	switch a := action.(type) {
+	case Member:
+		return root.GetClass(a.GetClass().GetName()) != nil
+	case EnumItem:
+		return root.GetEnum(a.GetEnum().GetName()) != nil
+	}
+	return true
+}
+
+// Outcome indicates the result of applying an action.
+type Outcome int
+
+const (
+	Applied  Outcome = iota // The action was applied.
+	NoOp                    // The target already has the result of the action.
+	Conflict                // The action does not match the target.
+)
+
+// String returns a string representation of the outcome.
+func (o Outcome) String() string {
+	switch o {
+	case Applied:
+		return "Applied"
+	case NoOp:
+		return "NoOp"
+	case Conflict:
+		return "Conflict"
+	}
+	return ""
+}
+
+// Result describes the outcome of applying a single action.
+type Result struct {
+	// Index is the position of the action within the applied list.
+	Index int
+	// Action is the applied action.
+	Action Action
+	// Outcome is the result of applying the action.
+	Outcome Outcome
+	// Reason describes why the action was a no-op or a conflict. Empty if
+	// the action was applied.
+	Reason string
+}
+
+func (r Result) String() string {
+	if r.Reason == "" {
+		return fmt.Sprintf("action %d: %s", r.Index, r.Action)
+	}
+	return fmt.Sprintf("action %d: %s: %s", r.Index, r.Action, r.Reason)
+}
+
+// Report describes the outcome of applying a list of actions.
+type Report struct {
+	// Results contains the result of each action, in order.
+	Results []Result
+}
+
+// filter returns the results with the given outcome.
+func (r *Report) filter(outcome Outcome) []Result {
+	var list []Result
+	for _, result := range r.Results {
+		if result.Outcome == outcome {
+			list = append(list, result)
+		}
+	}
+	return list
+}
+
+// Applied returns the results of the actions that were applied.
+func (r *Report) Applied() []Result {
+	return r.filter(Applied)
+}
+
+// NoOps returns the results of the actions that had no effect, because the
+// target already had the result of the action.
+func (r *Report) NoOps() []Result {
+	return r.filter(NoOp)
+}
+
+// Conflicts returns the results of the actions that did not match the
+// target.
+func (r *Report) Conflicts() []Result {
+	return r.filter(Conflict)
+}
+
+// check returns the outcome of applying an action to root.
+func check(root rbxapi.Root, action Action) (Outcome, string) {
+	desc, ok := target(root, action)
+	switch {
+	case !ok:
+		return Conflict, "action has no descriptor"
+	case !parentExists(root, action):
+		return Conflict, "parent does not exist"
+	case action.GetType() == Add:
+		if desc != nil {
+			return Conflict, "already exists"
+		}
+	case desc == nil:
+		return Conflict, "does not exist"
+	case action.GetType() == Change:
+		v, ok := FieldValue(desc, action.GetField())
+		if !ok || ValuesEqual(v, action.GetPrev()) {
+			break
+		}
+		if ValuesEqual(v, action.GetNext()) {
+			return NoOp, "already " + valueString(action.GetNext())
+		}
+		return Conflict, "expected " + valueString(action.GetPrev()) + ", found " + valueString(v)
+	}
+	return Applied, ""
+}
+
+// Apply applies actions one at a time to a copy of root, returning the copy
+// along with a report of the outcome of each action. Each action is checked
+// against the state left by the previous actions.
+//
+// An action is a conflict if its descriptor or the parent of its descriptor
+// is not present, if it adds a descriptor that is already present, or if it
+// changes a field whose current value is not the previous value of the
+// action. A Change action is a no-op if the field already has the next value.
+// Conflicting and no-op actions are skipped, so that they cannot leave the
+// result in an inconsistent state, such as with two classes of the same name.
+//
+// root must implement Patcher.
+func Apply(root rbxapi.Root, actions []Action) (rbxapi.Root, *Report, error) {
+	root = root.Copy()
+	patcher, ok := root.(Patcher)
+	if !ok {
+		return nil, nil, errors.New("API structure cannot be patched")
+	}
+	report := &Report{Results: make([]Result, len(actions))}
+	for i, action := range actions {
+		outcome, reason := check(root, action)
+		report.Results[i] = Result{Index: i, Action: action, Outcome: outcome, Reason: reason}
+		if outcome == Applied {
+			patcher.Patch(actions[i : i+1])
+		}
+	}
+	return root, report, nil
+}
+
+// DryRun reports the outcome of applying actions to root, as Apply does,
+// without producing the patched structure. root is not modified.
+func DryRun(root rbxapi.Root, actions []Action) (*Report, error) {
+	_, report, err := Apply(root, actions)
+	return report, err
+}
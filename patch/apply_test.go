@@ -0,0 +1,65 @@
+package patch_test
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"testing"
+)
+
+func testRoot() *rbxapijson.Root {
+	return &rbxapijson.Root{
+		Classes: []*rbxapijson.Class{
+			{Name: "Part", Superclass: "<<<ROOT>>>", Members: []rbxapi.Member{
+				&rbxapijson.Property{Name: "Anchored", ValueType: rbxapijson.Type{Category: "Primitive", Name: "bool"}},
+			}},
+		},
+		Enums: []*rbxapijson.Enum{},
+	}
+}
+
+func TestApplyConflict(t *testing.T) {
+	root := testRoot()
+	part := &rbxapijson.Class{Name: "Part", Superclass: "Instance", Members: []rbxapi.Member{}}
+	missing := &rbxapijson.Class{Name: "Missing", Superclass: "<<<ROOT>>>", Members: []rbxapi.Member{}}
+	anchored := &rbxapijson.Property{Name: "Anchored", ValueType: rbxapijson.Type{Category: "Primitive", Name: "bool"}}
+	actions := []patch.Action{
+		&diff.ClassAction{Type: patch.Add, Class: part},
+		&diff.ClassAction{Type: patch.Remove, Class: missing},
+		&diff.MemberAction{Type: patch.Add, Class: part, Member: anchored},
+		&diff.MemberAction{Type: patch.Remove, Class: missing, Member: anchored},
+		&diff.ClassAction{Type: patch.Change, Class: part, Field: "Superclass", Prev: "Instance", Next: "BasePart"},
+	}
+	result, report, err := patch.Apply(root, actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(report.Conflicts()); n != len(actions) {
+		t.Errorf("got %d conflicts, expected %d", n, len(actions))
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: testRoot(), Next: result.(*rbxapijson.Root)}).Diff() {
+		t.Errorf("root changed: %s", action)
+	}
+}
+
+func TestApplyNoOp(t *testing.T) {
+	part := &rbxapijson.Class{Name: "Part"}
+	actions := []patch.Action{
+		&diff.ClassAction{Type: patch.Change, Class: part, Field: "Superclass", Prev: "Instance", Next: "<<<ROOT>>>"},
+		&diff.ClassAction{Type: patch.Change, Class: part, Field: "Name", Prev: "Part", Next: "BasePart"},
+	}
+	result, report, err := patch.Apply(testRoot(), actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(report.NoOps()); n != 1 {
+		t.Errorf("got %d no-ops, expected 1", n)
+	}
+	if n := len(report.Applied()); n != 1 {
+		t.Errorf("got %d applied actions, expected 1", n)
+	}
+	if result.GetClass("BasePart") == nil || result.GetClass("Part") != nil {
+		t.Error("Part was not renamed to BasePart")
+	}
+}
@@ -0,0 +1,249 @@
+// The patchfile package encodes lists of patch actions as JSON documents,
+// allowing patches to be stored and applied later.
+//
+// A patch file is an array of objects, one for each action, in order. Each
+// object has the following fields:
+//
+//	Type   "Add", "Remove", or "Change".
+//	Kind   "Class", "Member", "Enum", or "EnumItem".
+//	Class  The class of a Class or Member action, in the JSON dump format.
+//	Enum   The enum of an Enum or EnumItem action, in the JSON dump format.
+//	Field  The name of the changed field of a Change action.
+//	Prev   The previous value of the field of a Change action.
+//	Next   The next value of the field of a Change action.
+//
+// Values of type fields such as ValueType are encoded as a JSON dump type,
+// and values of Parameters fields are encoded as a list of JSON dump
+// parameters. Other values are encoded as plain JSON values.
+package patchfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+)
+
+// patchAction is the form of a patch.Action within a patch file.
+//
+// Descriptors are encoded in the JSON dump format. The descriptor of a Member
+// action is a class containing only the member, and the descriptor of an
+// EnumItem action is an enum containing only the item. The descriptor of a
+// Change action omits members and items.
+type patchAction struct {
+	Type  string
+	Kind  string
+	Class *rbxapijson.Class `json:",omitempty"`
+	Enum  *rbxapijson.Enum  `json:",omitempty"`
+	Field string            `json:",omitempty"`
+	Prev  json.RawMessage   `json:",omitempty"`
+	Next  json.RawMessage   `json:",omitempty"`
+}
+
+// Descriptors returns copies of the descriptors of an action, in the
+// rbxapijson representation. The descriptor of a Member action is a class
+// containing only the member, and the descriptor of an EnumItem action is an
+// enum containing only the item. The descriptor of a Change action omits
+// members and items. Returns nil for both if the action has no descriptor.
+func Descriptors(action patch.Action) (class *rbxapijson.Class, enum *rbxapijson.Enum) {
+	root := &rbxapijson.Root{}
+	switch a := action.(type) {
+	case patch.Member:
+		c, m := a.GetClass(), a.GetMember()
+		if c == nil || m == nil {
+			return nil, nil
+		}
+		class = &rbxapijson.Class{Name: c.GetName(), Members: []rbxapi.Member{}}
+		class.Patch([]patch.Action{&diff.MemberAction{Type: patch.Add, Class: c, Member: m}})
+		if len(class.Members) == 0 {
+			return nil, nil
+		}
+		return class, nil
+	case patch.Class:
+		c := a.GetClass()
+		if c == nil {
+			return nil, nil
+		}
+		root.Patch([]patch.Action{&diff.ClassAction{Type: patch.Add, Class: c}})
+		class = root.Classes[0]
+		if a.GetType() == patch.Change {
+			class.Members = []rbxapi.Member{}
+		}
+		return class, nil
+	case patch.EnumItem:
+		e, i := a.GetEnum(), a.GetEnumItem()
+		if e == nil || i == nil {
+			return nil, nil
+		}
+		enum = &rbxapijson.Enum{Name: e.GetName(), Items: []*rbxapijson.EnumItem{}}
+		enum.Patch([]patch.Action{&diff.EnumItemAction{Type: patch.Add, Enum: e, EnumItem: i}})
+		return nil, enum
+	case patch.Enum:
+		e := a.GetEnum()
+		if e == nil {
+			return nil, nil
+		}
+		root.Patch([]patch.Action{&diff.EnumAction{Type: patch.Add, Enum: e}})
+		enum = root.Enums[0]
+		if a.GetType() == patch.Change {
+			enum.Items = []*rbxapijson.EnumItem{}
+		}
+		return nil, enum
+	}
+	return nil, nil
+}
+
+// encodeValue encodes the value of a Change action.
+func encodeValue(v interface{}) (json.RawMessage, error) {
+	switch w := v.(type) {
+	case nil:
+		return nil, nil
+	case rbxapi.Type:
+		v = rbxapijson.Type{Category: w.GetCategory(), Name: w.GetName()}
+	case rbxapi.Parameters:
+		params := w.GetParameters()
+		list := make([]*rbxapijson.Parameter, len(params))
+		for i, param := range params {
+			list[i] = &rbxapijson.Parameter{Type: rbxapijson.Type{Category: param.GetType().GetCategory(), Name: param.GetType().GetName()}, Name: param.GetName()}
+			list[i].Default, list[i].HasDefault = param.GetDefault()
+		}
+		v = list
+	}
+	return json.Marshal(v)
+}
+
+// kind returns the kind of descriptor to which an action applies.
+func kind(action patch.Action) string {
+	switch action.(type) {
+	case patch.Member:
+		return "Member"
+	case patch.Class:
+		return "Class"
+	case patch.EnumItem:
+		return "EnumItem"
+	case patch.Enum:
+		return "Enum"
+	}
+	return ""
+}
+
+// decodeValue decodes the value of a Change action to the field.
+func decodeValue(field string, b json.RawMessage) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	switch field {
+	case "ValueType", "ReturnType":
+		var v rbxapijson.Type
+		err := json.Unmarshal(b, &v)
+		return v, err
+	case "Parameters":
+		var v []rbxapijson.Parameter
+		err := json.Unmarshal(b, &v)
+		return rbxapijson.Parameters{List: &v}, err
+	case "Tags", "Capabilities":
+		var v []string
+		err := json.Unmarshal(b, &v)
+		return v, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	switch w := v.(type) {
+	case json.Number:
+		i, err := w.Int64()
+		return int(i), err
+	case []interface{}:
+		list := make([]string, len(w))
+		for i, s := range w {
+			if list[i], _ = s.(string); list[i] == "" {
+				return nil, fmt.Errorf("unexpected value in field %s", field)
+			}
+		}
+		return list, nil
+	}
+	return v, nil
+}
+
+// Encode writes actions to w as a patch file. Returns an error if an action
+// has no descriptor.
+func Encode(w io.Writer, actions []patch.Action) error {
+	list := make([]patchAction, 0, len(actions))
+	for _, action := range actions {
+		class, enum := Descriptors(action)
+		if class == nil && enum == nil {
+			return errors.New("cannot encode action: " + action.String())
+		}
+		a := patchAction{
+			Type:  action.GetType().String(),
+			Kind:  kind(action),
+			Class: class,
+			Enum:  enum,
+			Field: action.GetField(),
+		}
+		var err error
+		if a.Prev, err = encodeValue(action.GetPrev()); err != nil {
+			return err
+		}
+		if a.Next, err = encodeValue(action.GetNext()); err != nil {
+			return err
+		}
+		list = append(list, a)
+	}
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	je.SetEscapeHTML(false)
+	return je.Encode(list)
+}
+
+// Decode reads a patch file from r. Descriptors of the returned actions are
+// of the rbxapijson package, and actions are of the diff package.
+func Decode(r io.Reader) ([]patch.Action, error) {
+	var list []patchAction
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+	actions := make([]patch.Action, len(list))
+	for i, a := range list {
+		var typ patch.Type
+		switch a.Type {
+		case "Add":
+			typ = patch.Add
+		case "Remove":
+			typ = patch.Remove
+		case "Change":
+			typ = patch.Change
+		default:
+			return nil, fmt.Errorf("action %d: unknown type %q", i, a.Type)
+		}
+		prev, err := decodeValue(a.Field, a.Prev)
+		if err != nil {
+			return nil, fmt.Errorf("action %d: %w", i, err)
+		}
+		next, err := decodeValue(a.Field, a.Next)
+		if err != nil {
+			return nil, fmt.Errorf("action %d: %w", i, err)
+		}
+		switch {
+		case a.Kind == "Class" && a.Class != nil:
+			actions[i] = &diff.ClassAction{Type: typ, Class: a.Class, Field: a.Field, Prev: prev, Next: next}
+		case a.Kind == "Member" && a.Class != nil && len(a.Class.Members) == 1:
+			actions[i] = &diff.MemberAction{Type: typ, Class: a.Class, Member: a.Class.Members[0], Field: a.Field, Prev: prev, Next: next}
+		case a.Kind == "Enum" && a.Enum != nil:
+			actions[i] = &diff.EnumAction{Type: typ, Enum: a.Enum, Field: a.Field, Prev: prev, Next: next}
+		case a.Kind == "EnumItem" && a.Enum != nil && len(a.Enum.Items) == 1:
+			actions[i] = &diff.EnumItemAction{Type: typ, Enum: a.Enum, EnumItem: a.Enum.Items[0], Field: a.Field, Prev: prev, Next: next}
+		default:
+			return nil, fmt.Errorf("action %d: malformed %s action", i, a.Kind)
+		}
+	}
+	return actions, nil
+}
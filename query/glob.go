@@ -0,0 +1,74 @@
+package query
+
+import (
+	"github.com/karl-police/rbxapi"
+	"path"
+	"strings"
+)
+
+// globName converts a qualified name or pattern to the form matched by
+// path.Match, in which "/" separates the elements of a name.
+func globName(s string) string {
+	return strings.Replace(s, ".", "/", -1)
+}
+
+// Glob returns the descriptors in root whose qualified names match pattern,
+// in the order they appear in root. The pattern has the syntax of path.Match,
+// with "." in place of "/" as the separator, so a "*" does not match across
+// a ".". A pattern without a "." matches classes and enums, while a pattern
+// with one "." matches members and enum items:
+//
+//	*Service
+//	*Service.Get*
+//	Enum?.*
+//
+// Returns an error if the pattern is malformed.
+func Glob(root rbxapi.Root, pattern string) ([]Match, error) {
+	pattern = globName(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	var matches []Match
+	switch strings.Count(pattern, "/") {
+	case 0:
+		rbxapi.RangeClasses(root, func(class rbxapi.Class) bool {
+			if ok, _ := path.Match(pattern, globName(class.GetName())); ok {
+				matches = append(matches, Match{Class: class})
+			}
+			return true
+		})
+		rbxapi.RangeEnums(root, func(enum rbxapi.Enum) bool {
+			if ok, _ := path.Match(pattern, globName(enum.GetName())); ok {
+				matches = append(matches, Match{Enum: enum})
+			}
+			return true
+		})
+	case 1:
+		outer := pattern[:strings.Index(pattern, "/")]
+		rbxapi.RangeClasses(root, func(class rbxapi.Class) bool {
+			if ok, _ := path.Match(outer, globName(class.GetName())); !ok {
+				return true
+			}
+			rbxapi.RangeMembers(class, func(member rbxapi.Member) bool {
+				if ok, _ := path.Match(pattern, globName(class.GetName()+"."+member.GetName())); ok {
+					matches = append(matches, Match{Class: class, Member: member})
+				}
+				return true
+			})
+			return true
+		})
+		rbxapi.RangeEnums(root, func(enum rbxapi.Enum) bool {
+			if ok, _ := path.Match(outer, globName(enum.GetName())); !ok {
+				return true
+			}
+			rbxapi.RangeEnumItems(enum, func(item rbxapi.EnumItem) bool {
+				if ok, _ := path.Match(pattern, globName(enum.GetName()+"."+item.GetName())); ok {
+					matches = append(matches, Match{Enum: enum, EnumItem: item})
+				}
+				return true
+			})
+			return true
+		})
+	}
+	return matches, nil
+}
@@ -0,0 +1,447 @@
+// The query package selects descriptors from an API structure using a
+// selector language.
+//
+// A selector is a sequence of steps separated by spaces. Each step names a
+// kind of descriptor, followed by any number of conditions in brackets:
+//
+//	Class[Tag=Service] Member[MemberType=Event]
+//
+// The kinds are Class, Member, Property, Function, Event, Callback, Enum, and
+// EnumItem. A Member step following a Class step selects members of the
+// matched classes, and an EnumItem step following an Enum step selects items
+// of the matched enums. A Member or EnumItem step on its own selects from
+// every class or enum.
+//
+// A condition has the form [Attr=Value] or [Attr!=Value]. Values may be
+// quoted with double quotes. The Tag attribute matches if the descriptor has
+// the tag. The other attributes are Name, Superclass, MemberType, ValueType,
+// ReturnType, Security, ReadSecurity, WriteSecurity, and Value. A condition
+// on an attribute that a descriptor does not have never matches, unless the
+// operator is "!=".
+//
+// Alternatively, Glob selects descriptors whose qualified names match a
+// pattern, such as "*Service.Get*".
+package query
+
+import (
+	"github.com/karl-police/rbxapi"
+	"strconv"
+	"strings"
+)
+
+// Kinds of descriptors.
+const (
+	KindClass    = "Class"
+	KindMember   = "Member"
+	KindEnum     = "Enum"
+	KindEnumItem = "EnumItem"
+)
+
+// attributes lists the valid condition attributes.
+var attributes = map[string]bool{
+	"Name":          true,
+	"Tag":           true,
+	"Superclass":    true,
+	"MemberType":    true,
+	"ValueType":     true,
+	"ReturnType":    true,
+	"Security":      true,
+	"ReadSecurity":  true,
+	"WriteSecurity": true,
+	"Value":         true,
+}
+
+// SyntaxError is returned when a selector cannot be parsed.
+type SyntaxError struct {
+	Offset int // Byte offset within the selector.
+	Msg    string
+}
+
+func (err *SyntaxError) Error() string {
+	return "offset " + strconv.Itoa(err.Offset) + ": " + err.Msg
+}
+
+// condition is a single bracketed condition of a step.
+type condition struct {
+	Attr   string
+	Negate bool
+	Value  string
+}
+
+// step is a single step of a selector.
+type step struct {
+	Kind       string // Class, Member, Enum, or EnumItem.
+	MemberType string // Member type required by a Member step, if any.
+	Conditions []condition
+}
+
+// Selector is a parsed selector.
+type Selector struct {
+	steps []step
+	text  string
+}
+
+// String returns the text of the selector.
+func (s *Selector) String() string {
+	return s.text
+}
+
+// parser parses a selector.
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) errorf(msg string) error {
+	return &SyntaxError{Offset: p.i, Msg: msg}
+}
+
+func (p *parser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t' || p.s[p.i] == '\n') {
+		p.i++
+	}
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z'
+}
+
+func (p *parser) ident() string {
+	j := p.i
+	for p.i < len(p.s) && isIdent(p.s[p.i]) {
+		p.i++
+	}
+	return p.s[j:p.i]
+}
+
+// value parses a quoted or unquoted value.
+func (p *parser) value() (string, error) {
+	if p.i < len(p.s) && p.s[p.i] == '"' {
+		j := p.i
+		for p.i++; p.i < len(p.s); p.i++ {
+			switch p.s[p.i] {
+			case '\\':
+				p.i++
+			case '"':
+				p.i++
+				v, err := strconv.Unquote(p.s[j:p.i])
+				if err != nil {
+					p.i = j
+					return "", p.errorf("malformed string")
+				}
+				return v, nil
+			}
+		}
+		p.i = j
+		return "", p.errorf("unterminated string")
+	}
+	j := p.i
+	for p.i < len(p.s) && p.s[p.i] != ']' {
+		p.i++
+	}
+	return strings.TrimSpace(p.s[j:p.i]), nil
+}
+
+func (p *parser) condition() (c condition, err error) {
+	p.i++ // '['
+	p.skipSpace()
+	if c.Attr = p.ident(); c.Attr == "" {
+		return c, p.errorf("expected attribute")
+	}
+	if !attributes[c.Attr] {
+		p.i -= len(c.Attr)
+		return c, p.errorf("unknown attribute \"" + c.Attr + "\"")
+	}
+	p.skipSpace()
+	switch {
+	case strings.HasPrefix(p.s[p.i:], "!="):
+		c.Negate = true
+		p.i += 2
+	case strings.HasPrefix(p.s[p.i:], "="):
+		p.i++
+	default:
+		return c, p.errorf("expected \"=\" or \"!=\"")
+	}
+	p.skipSpace()
+	if c.Value, err = p.value(); err != nil {
+		return c, err
+	}
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != ']' {
+		return c, p.errorf("expected \"]\"")
+	}
+	p.i++
+	return c, nil
+}
+
+func (p *parser) step() (s step, err error) {
+	start := p.i
+	switch kind := p.ident(); kind {
+	case KindClass, KindMember, KindEnum, KindEnumItem:
+		s.Kind = kind
+	case "Property", "Function", "Event", "Callback":
+		s.Kind = KindMember
+		s.MemberType = kind
+	case "":
+		return s, p.errorf("expected descriptor kind")
+	default:
+		p.i = start
+		return s, p.errorf("unknown descriptor kind \"" + kind + "\"")
+	}
+	for p.i < len(p.s) && p.s[p.i] == '[' {
+		c, err := p.condition()
+		if err != nil {
+			return s, err
+		}
+		s.Conditions = append(s.Conditions, c)
+	}
+	return s, nil
+}
+
+// Parse parses a selector.
+func Parse(selector string) (*Selector, error) {
+	p := &parser{s: selector}
+	sel := &Selector{text: selector}
+	for {
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			break
+		}
+		start := p.i
+		s, err := p.step()
+		if err != nil {
+			return nil, err
+		}
+		if n := len(sel.steps); n > 0 {
+			prev := sel.steps[n-1].Kind
+			if !(prev == KindClass && s.Kind == KindMember || prev == KindEnum && s.Kind == KindEnumItem) {
+				p.i = start
+				return nil, p.errorf("a " + s.Kind + " step cannot follow a " + prev + " step")
+			}
+		}
+		sel.steps = append(sel.steps, s)
+		if p.i < len(p.s) && p.s[p.i] != ' ' && p.s[p.i] != '\t' && p.s[p.i] != '\n' {
+			return nil, p.errorf("unexpected character")
+		}
+	}
+	if len(sel.steps) == 0 {
+		return nil, p.errorf("empty selector")
+	}
+	return sel, nil
+}
+
+// MustParse is like Parse, but panics if the selector cannot be parsed.
+func MustParse(selector string) *Selector {
+	sel, err := Parse(selector)
+	if err != nil {
+		panic("query: Parse(" + strconv.Quote(selector) + "): " + err.Error())
+	}
+	return sel
+}
+
+// Match is a descriptor matched by a selector. Fields that do not apply to
+// the kind of descriptor are nil. The Class of a Member match and the Enum of
+// an EnumItem match are the containing descriptors.
+type Match struct {
+	Class    rbxapi.Class
+	Member   rbxapi.Member
+	Enum     rbxapi.Enum
+	EnumItem rbxapi.EnumItem
+}
+
+// Kind returns the kind of the matched descriptor.
+func (m Match) Kind() string {
+	switch {
+	case m.Member != nil:
+		return KindMember
+	case m.Class != nil:
+		return KindClass
+	case m.EnumItem != nil:
+		return KindEnumItem
+	}
+	return KindEnum
+}
+
+// Name returns the qualified name of the matched descriptor, such as
+// "Workspace.Gravity".
+func (m Match) Name() string {
+	switch {
+	case m.Member != nil:
+		return m.Class.GetName() + "." + m.Member.GetName()
+	case m.Class != nil:
+		return m.Class.GetName()
+	case m.EnumItem != nil:
+		return m.Enum.GetName() + "." + m.EnumItem.GetName()
+	case m.Enum != nil:
+		return m.Enum.GetName()
+	}
+	return ""
+}
+
+// Descriptor returns the matched descriptor.
+func (m Match) Descriptor() interface{} {
+	switch {
+	case m.Member != nil:
+		return m.Member
+	case m.Class != nil:
+		return m.Class
+	case m.EnumItem != nil:
+		return m.EnumItem
+	}
+	return m.Enum
+}
+
+// String returns the kind and qualified name of the match, using the member
+// type for members.
+func (m Match) String() string {
+	if m.Member != nil {
+		return m.Member.GetMemberType() + " " + m.Name()
+	}
+	return m.Kind() + " " + m.Name()
+}
+
+// attribute returns the value of an attribute of a descriptor, and whether
+// the descriptor has the attribute.
+func attribute(desc interface{}, attr string) (string, bool) {
+	switch d := desc.(type) {
+	case rbxapi.Class:
+		switch attr {
+		case "Name":
+			return d.GetName(), true
+		case "Superclass":
+			return d.GetSuperclass(), true
+		}
+	case rbxapi.Member:
+		switch attr {
+		case "Name":
+			return d.GetName(), true
+		case "MemberType":
+			return d.GetMemberType(), true
+		}
+		switch d := d.(type) {
+		case rbxapi.Property:
+			read, write := d.GetSecurity()
+			switch attr {
+			case "ValueType":
+				return d.GetValueType().GetName(), true
+			case "ReadSecurity":
+				return read, true
+			case "WriteSecurity":
+				return write, true
+			}
+		case rbxapi.Function:
+			switch attr {
+			case "ReturnType":
+				return d.GetReturnType().GetName(), true
+			case "Security":
+				return d.GetSecurity(), true
+			}
+		case rbxapi.Event:
+			if attr == "Security" {
+				return d.GetSecurity(), true
+			}
+		}
+	case rbxapi.Enum:
+		if attr == "Name" {
+			return d.GetName(), true
+		}
+	case rbxapi.EnumItem:
+		switch attr {
+		case "Name":
+			return d.GetName(), true
+		case "Value":
+			return strconv.Itoa(d.GetValue()), true
+		}
+	}
+	return "", false
+}
+
+// matches returns whether a descriptor satisfies the conditions of a step.
+func (s *step) matches(desc interface{}) bool {
+	if s.MemberType != "" && desc.(rbxapi.Member).GetMemberType() != s.MemberType {
+		return false
+	}
+	for _, c := range s.Conditions {
+		var ok bool
+		if c.Attr == "Tag" {
+			ok = desc.(rbxapi.Taggable).GetTag(c.Value)
+		} else {
+			var v string
+			v, ok = attribute(desc, c.Attr)
+			ok = ok && v == c.Value
+		}
+		if ok == c.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the descriptors in root that match the selector, in the
+// order they appear in root.
+func (s *Selector) Select(root rbxapi.Root) []Match {
+	var matches []Match
+	first := &s.steps[0]
+	var second *step
+	if len(s.steps) > 1 {
+		second = &s.steps[1]
+	}
+	switch first.Kind {
+	case KindClass, KindMember:
+		for _, class := range root.GetClasses() {
+			if first.Kind == KindMember {
+				for _, member := range class.GetMembers() {
+					if first.matches(member) {
+						matches = append(matches, Match{Class: class, Member: member})
+					}
+				}
+				continue
+			}
+			if !first.matches(class) {
+				continue
+			}
+			if second == nil {
+				matches = append(matches, Match{Class: class})
+				continue
+			}
+			for _, member := range class.GetMembers() {
+				if second.matches(member) {
+					matches = append(matches, Match{Class: class, Member: member})
+				}
+			}
+		}
+	case KindEnum, KindEnumItem:
+		for _, enum := range root.GetEnums() {
+			if first.Kind == KindEnumItem {
+				for _, item := range enum.GetEnumItems() {
+					if first.matches(item) {
+						matches = append(matches, Match{Enum: enum, EnumItem: item})
+					}
+				}
+				continue
+			}
+			if !first.matches(enum) {
+				continue
+			}
+			if second == nil {
+				matches = append(matches, Match{Enum: enum})
+				continue
+			}
+			for _, item := range enum.GetEnumItems() {
+				if second.matches(item) {
+					matches = append(matches, Match{Enum: enum, EnumItem: item})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// Select parses selector and returns the descriptors in root that match it.
+func Select(root rbxapi.Root, selector string) ([]Match, error) {
+	sel, err := Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(root), nil
+}
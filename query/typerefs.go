@@ -0,0 +1,72 @@
+package query
+
+import (
+	"github.com/karl-police/rbxapi"
+)
+
+// Kinds of locations at which a type is referred to.
+const (
+	RefValueType  = "ValueType"  // The value type of a property.
+	RefReturnType = "ReturnType" // The return type of a function or callback.
+	RefParameter  = "Parameter"  // The type of a parameter.
+)
+
+// Ref is a location at which a type is referred to by a member.
+type Ref struct {
+	// Kind is the kind of location: RefValueType, RefReturnType, or
+	// RefParameter.
+	Kind string
+	// Class is the class of the member.
+	Class rbxapi.Class
+	// Member is the member referring to the type.
+	Member rbxapi.Member
+	// Parameter is the parameter of the member having the type. Nil unless
+	// Kind is RefParameter.
+	Parameter rbxapi.Parameter
+	// Type is the referred type, including its category.
+	Type rbxapi.Type
+}
+
+// String returns a description of the location, such as
+// "Parameter Workspace.Raycast(origin)".
+func (r Ref) String() string {
+	s := r.Kind + " " + r.Class.GetName() + "." + r.Member.GetName()
+	if r.Parameter != nil {
+		s += "(" + r.Parameter.GetName() + ")"
+	}
+	return s
+}
+
+// TypeRefs returns an index mapping the name of each type referred to by the
+// members of root to the locations at which it is referred to, in the order
+// of root. Types of different categories that have the same name, such as a
+// class and an enum, share an entry, and are distinguished by the category of
+// Ref.Type.
+func TypeRefs(root rbxapi.Root) map[string][]Ref {
+	refs := map[string][]Ref{}
+	var param rbxapi.Parameter
+	rbxapi.Walk(root, &rbxapi.Visitor{
+		Member: func(class rbxapi.Class, member rbxapi.Member) error {
+			param = nil
+			return nil
+		},
+		Parameter: func(class rbxapi.Class, member rbxapi.Member, p rbxapi.Parameter) error {
+			param = p
+			return nil
+		},
+		Type: func(class rbxapi.Class, member rbxapi.Member, typ rbxapi.Type) error {
+			ref := Ref{Class: class, Member: member, Parameter: param, Type: typ}
+			switch {
+			case param != nil:
+				ref.Kind = RefParameter
+			case member.GetMemberType() == "Property":
+				ref.Kind = RefValueType
+			default:
+				ref.Kind = RefReturnType
+			}
+			refs[typ.GetName()] = append(refs[typ.GetName()], ref)
+			return nil
+		},
+	})
+	return refs
+}
@@ -0,0 +1,115 @@
+package rbxapi
+
+// ClassRanger is implemented by a Root that is able to iterate over its class
+// descriptors without allocating a list.
+type ClassRanger interface {
+	// RangeClasses calls fn for each class descriptor, in the same order as
+	// GetClasses. Iteration stops if fn returns false.
+	RangeClasses(fn func(class Class) bool)
+}
+
+// EnumRanger is implemented by a Root that is able to iterate over its enum
+// descriptors without allocating a list.
+type EnumRanger interface {
+	// RangeEnums calls fn for each enum descriptor, in the same order as
+	// GetEnums. Iteration stops if fn returns false.
+	RangeEnums(fn func(enum Enum) bool)
+}
+
+// MemberRanger is implemented by a Class that is able to iterate over its
+// member descriptors without allocating a list.
+type MemberRanger interface {
+	// RangeMembers calls fn for each member descriptor, in the same order as
+	// GetMembers. Iteration stops if fn returns false.
+	RangeMembers(fn func(member Member) bool)
+}
+
+// EnumItemRanger is implemented by an Enum that is able to iterate over its
+// items without allocating a list.
+type EnumItemRanger interface {
+	// RangeEnumItems calls fn for each enum item descriptor, in the same
+	// order as GetEnumItems. Iteration stops if fn returns false.
+	RangeEnumItems(fn func(item EnumItem) bool)
+}
+
+// TagRanger is implemented by a Taggable that is able to iterate over its
+// tags without allocating a list.
+type TagRanger interface {
+	// RangeTags calls fn for each tag, in the same order as GetTags.
+	// Iteration stops if fn returns false.
+	RangeTags(fn func(tag string) bool)
+}
+
+// RangeClasses calls fn for each class descriptor of root. Iteration stops if
+// fn returns false. If root implements ClassRanger, then no list of classes
+// is allocated.
+func RangeClasses(root Root, fn func(class Class) bool) {
+	if r, ok := root.(ClassRanger); ok {
+		r.RangeClasses(fn)
+		return
+	}
+	for _, class := range root.GetClasses() {
+		if !fn(class) {
+			return
+		}
+	}
+}
+
+// RangeEnums calls fn for each enum descriptor of root. Iteration stops if fn
+// returns false. If root implements EnumRanger, then no list of enums is
+// allocated.
+func RangeEnums(root Root, fn func(enum Enum) bool) {
+	if r, ok := root.(EnumRanger); ok {
+		r.RangeEnums(fn)
+		return
+	}
+	for _, enum := range root.GetEnums() {
+		if !fn(enum) {
+			return
+		}
+	}
+}
+
+// RangeMembers calls fn for each member descriptor of class. Iteration stops
+// if fn returns false. If class implements MemberRanger, then no list of
+// members is allocated.
+func RangeMembers(class Class, fn func(member Member) bool) {
+	if r, ok := class.(MemberRanger); ok {
+		r.RangeMembers(fn)
+		return
+	}
+	for _, member := range class.GetMembers() {
+		if !fn(member) {
+			return
+		}
+	}
+}
+
+// RangeEnumItems calls fn for each item of enum. Iteration stops if fn
+// returns false. If enum implements EnumItemRanger, then no list of items is
+// allocated.
+func RangeEnumItems(enum Enum, fn func(item EnumItem) bool) {
+	if r, ok := enum.(EnumItemRanger); ok {
+		r.RangeEnumItems(fn)
+		return
+	}
+	for _, item := range enum.GetEnumItems() {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// RangeTags calls fn for each tag of t. Iteration stops if fn returns false.
+// If t implements TagRanger, then no list of tags is allocated.
+func RangeTags(t Taggable, fn func(tag string) bool) {
+	if r, ok := t.(TagRanger); ok {
+		r.RangeTags(fn)
+		return
+	}
+	for _, tag := range t.GetTags() {
+		if !fn(tag) {
+			return
+		}
+	}
+}
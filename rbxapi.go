@@ -82,6 +82,16 @@ type Property interface {
 	GetValueType() Type
 }
 
+// DefaultProperty is implemented by a Property that is able to indicate the
+// default value of the property.
+type DefaultProperty interface {
+	Property
+
+	// GetDefault returns a string representing the default value of the
+	// property, and whether a default value is present.
+	GetDefault() (value string, ok bool)
+}
+
 // Function represents a class member of the Function member type.
 type Function interface {
 	Member
@@ -0,0 +1,411 @@
+// The rbxapicache package implements a binary snapshot format of API
+// structures, intended for caching a decoded structure between runs of a
+// program.
+//
+// A snapshot begins with a header that identifies the format and its
+// version, followed by the structure encoded with encoding/gob. Reading a
+// snapshot is considerably faster than decoding a JSON file. However, reading
+// a snapshot is slower than decoding a dump file, so caching a structure of
+// the rbxapidump package has no benefit over keeping the dump file itself.
+// The format is specific to this package and may change between versions. A
+// snapshot of an unsupported version is rejected with a VersionError, in
+// which case the cache should be rebuilt from the original source. Snapshots
+// of the previous version, 4, are still read.
+//
+// ReadCache produces a structure of the rbxapijson package, which also retains
+// the fields specific to JSON dumps. The header also records whether the
+// snapshot was written from a structure of the rbxapidump package, in which
+// case ReadRoot produces a structure of that package instead. The package
+// registers the "cache" format with the codec package.
+package rbxapicache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/codec"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"strconv"
+)
+
+// Version is the version of the snapshot format written by WriteCache.
+const Version = 5
+
+// version4 is the previous version of the snapshot format, which is still
+// read. It lacks the kind of structure, and is read as kindJSON.
+const version4 = 4
+
+// magic identifies the start of a snapshot.
+const magic = "RBXAPI\x00C"
+
+// Kinds of structures from which a snapshot may be written.
+const (
+	kindJSON byte = iota
+	kindDump
+)
+
+// VersionError is an error indicating that the version of a snapshot is
+// unsupported.
+type VersionError interface {
+	error
+	// VersionError returns the unsupported version.
+	VersionError() int
+}
+
+// errVersion implements the VersionError interface.
+type errVersion int
+
+func (err errVersion) Error() string {
+	return "cache version " + strconv.FormatInt(int64(err), 10) + " is unsupported"
+}
+
+func (err errVersion) VersionError() int {
+	return int(err)
+}
+
+// ErrFormat is returned by ReadCache when the input is not a snapshot.
+var ErrFormat = errors.New("not an API cache")
+
+// cacheRoot is the encoded form of a root.
+type cacheRoot struct {
+	Classes []cacheClass
+	Enums   []cacheEnum
+}
+
+type cacheClass struct {
+	Name                string
+	Superclass          string
+	MemoryCategory      string
+	Members             []cacheMember
+	Capabilities        []string
+	Tags                []string
+	PreferredDescriptor string
+}
+
+// cacheMember is the encoded form of a member of any member type.
+type cacheMember struct {
+	MemberType          string
+	Name                string
+	ValueType           rbxapijson.Type
+	Category            string
+	ReadSecurity        string
+	WriteSecurity       string
+	CanLoad             bool
+	CanSave             bool
+	HasDefault          bool
+	Default             string
+	Parameters          []rbxapijson.Parameter
+	ReturnType          rbxapijson.Type
+	Security            string
+	ThreadSafety        string
+	Capabilities        []string
+	Tags                []string
+	PreferredDescriptor string
+}
+
+type cacheEnum struct {
+	Name                string
+	Items               []cacheEnumItem
+	Tags                []string
+	PreferredDescriptor string
+}
+
+type cacheEnumItem struct {
+	Name                string
+	Value               int
+	Tags                []string
+	PreferredDescriptor string
+}
+
+func encodeMember(member rbxapi.Member) (m cacheMember) {
+	switch member := member.(type) {
+	case *rbxapijson.Property:
+		m.ValueType = member.ValueType
+		m.Category = member.Category
+		m.ReadSecurity = member.ReadSecurity
+		m.WriteSecurity = member.WriteSecurity
+		m.CanLoad = member.CanLoad
+		m.CanSave = member.CanSave
+		m.HasDefault = member.HasDefault
+		m.Default = member.Default
+		m.ThreadSafety = member.ThreadSafety
+		m.Capabilities = member.Capabilities
+		m.Tags = member.Tags
+		m.PreferredDescriptor = member.PreferredDescriptor
+	case *rbxapijson.Function:
+		m.Parameters = member.Parameters
+		m.ReturnType = member.ReturnType
+		m.Security = member.Security
+		m.ThreadSafety = member.ThreadSafety
+		m.Capabilities = member.Capabilities
+		m.Tags = member.Tags
+		m.PreferredDescriptor = member.PreferredDescriptor
+	case *rbxapijson.Event:
+		m.Parameters = member.Parameters
+		m.Security = member.Security
+		m.ThreadSafety = member.ThreadSafety
+		m.Capabilities = member.Capabilities
+		m.Tags = member.Tags
+		m.PreferredDescriptor = member.PreferredDescriptor
+	case *rbxapijson.Callback:
+		m.Parameters = member.Parameters
+		m.ReturnType = member.ReturnType
+		m.Security = member.Security
+		m.ThreadSafety = member.ThreadSafety
+		m.Capabilities = member.Capabilities
+		m.Tags = member.Tags
+		m.PreferredDescriptor = member.PreferredDescriptor
+	}
+	m.MemberType = member.GetMemberType()
+	m.Name = member.GetName()
+	return m
+}
+
+func decodeMember(m cacheMember) (rbxapi.Member, error) {
+	switch m.MemberType {
+	case "Property":
+		return &rbxapijson.Property{
+			Name:                m.Name,
+			ValueType:           m.ValueType,
+			Category:            m.Category,
+			ReadSecurity:        m.ReadSecurity,
+			WriteSecurity:       m.WriteSecurity,
+			CanLoad:             m.CanLoad,
+			CanSave:             m.CanSave,
+			HasDefault:          m.HasDefault,
+			Default:             m.Default,
+			ThreadSafety:        m.ThreadSafety,
+			Capabilities:        m.Capabilities,
+			Tags:                m.Tags,
+			PreferredDescriptor: m.PreferredDescriptor,
+		}, nil
+	case "Function":
+		return &rbxapijson.Function{
+			Name:                m.Name,
+			Parameters:          m.Parameters,
+			ReturnType:          m.ReturnType,
+			Security:            m.Security,
+			ThreadSafety:        m.ThreadSafety,
+			Capabilities:        m.Capabilities,
+			Tags:                m.Tags,
+			PreferredDescriptor: m.PreferredDescriptor,
+		}, nil
+	case "Event":
+		return &rbxapijson.Event{
+			Name:                m.Name,
+			Parameters:          m.Parameters,
+			Security:            m.Security,
+			ThreadSafety:        m.ThreadSafety,
+			Capabilities:        m.Capabilities,
+			Tags:                m.Tags,
+			PreferredDescriptor: m.PreferredDescriptor,
+		}, nil
+	case "Callback":
+		return &rbxapijson.Callback{
+			Name:                m.Name,
+			Parameters:          m.Parameters,
+			ReturnType:          m.ReturnType,
+			Security:            m.Security,
+			ThreadSafety:        m.ThreadSafety,
+			Capabilities:        m.Capabilities,
+			Tags:                m.Tags,
+			PreferredDescriptor: m.PreferredDescriptor,
+		}, nil
+	}
+	return nil, errors.New("invalid member type \"" + m.MemberType + "\"")
+}
+
+// WriteCache writes a snapshot of root to w. If root is not a structure of
+// the rbxapijson package, it is first converted to one. Whether root is a
+// structure of the rbxapidump package is recorded in the snapshot.
+func WriteCache(w io.Writer, root rbxapi.Root) error {
+	kind := kindJSON
+	if _, ok := root.(*rbxapidump.Root); ok {
+		kind = kindDump
+	}
+	r := codec.JSON.Convert(root).(*rbxapijson.Root)
+	c := cacheRoot{
+		Classes: make([]cacheClass, len(r.Classes)),
+		Enums:   make([]cacheEnum, len(r.Enums)),
+	}
+	for i, class := range r.Classes {
+		cc := cacheClass{
+			Name:                class.Name,
+			Superclass:          class.Superclass,
+			MemoryCategory:      class.MemoryCategory,
+			Members:             make([]cacheMember, len(class.Members)),
+			Capabilities:        class.Capabilities,
+			Tags:                class.Tags,
+			PreferredDescriptor: class.PreferredDescriptor,
+		}
+		for j, member := range class.Members {
+			cc.Members[j] = encodeMember(member)
+		}
+		c.Classes[i] = cc
+	}
+	for i, enum := range r.Enums {
+		ce := cacheEnum{
+			Name:                enum.Name,
+			Items:               make([]cacheEnumItem, len(enum.Items)),
+			Tags:                enum.Tags,
+			PreferredDescriptor: enum.PreferredDescriptor,
+		}
+		for j, item := range enum.Items {
+			ce.Items[j] = cacheEnumItem{
+				Name:                item.Name,
+				Value:               item.Value,
+				Tags:                item.Tags,
+				PreferredDescriptor: item.PreferredDescriptor,
+			}
+		}
+		c.Enums[i] = ce
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(magic)
+	var version [binary.MaxVarintLen64]byte
+	bw.Write(version[:binary.PutUvarint(version[:], Version)])
+	bw.WriteByte(kind)
+	if err := gob.NewEncoder(bw).Encode(&c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadCache reads a snapshot from r. Returns ErrFormat if r does not contain
+// a snapshot, or a VersionError if the snapshot has an unsupported version.
+func ReadCache(r io.Reader) (root *rbxapijson.Root, err error) {
+	root, _, err = readCache(r)
+	return root, err
+}
+
+// ReadRoot reads a snapshot from r, like ReadCache. If the snapshot was
+// written from a structure of the rbxapidump package, the result is a
+// structure of that package. Otherwise, the result is a structure of the
+// rbxapijson package.
+//
+// A structure of the rbxapidump package is stored in the JSON form, and
+// converted after being read, which makes reading it slower than decoding
+// the original dump file.
+func ReadRoot(r io.Reader) (rbxapi.Root, error) {
+	root, kind, err := readCache(r)
+	if err != nil {
+		return nil, err
+	}
+	if kind == kindDump {
+		return codec.Dump.Convert(root), nil
+	}
+	return root, nil
+}
+
+// readCache reads a snapshot from r, returning the kind of structure from
+// which it was written.
+func readCache(r io.Reader) (root *rbxapijson.Root, kind byte, err error) {
+	br := bufio.NewReader(r)
+	var header [len(magic)]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, 0, ErrFormat
+		}
+		return nil, 0, err
+	}
+	if string(header[:]) != magic {
+		return nil, 0, ErrFormat
+	}
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch version {
+	case Version:
+		if kind, err = br.ReadByte(); err != nil {
+			return nil, 0, err
+		}
+	case version4:
+		kind = kindJSON
+	default:
+		return nil, 0, errVersion(version)
+	}
+
+	var c cacheRoot
+	if err := gob.NewDecoder(br).Decode(&c); err != nil {
+		return nil, 0, err
+	}
+	root = &rbxapijson.Root{
+		Classes: make([]*rbxapijson.Class, len(c.Classes)),
+		Enums:   make([]*rbxapijson.Enum, len(c.Enums)),
+	}
+	for i, cc := range c.Classes {
+		class := &rbxapijson.Class{
+			Name:                cc.Name,
+			Superclass:          cc.Superclass,
+			MemoryCategory:      cc.MemoryCategory,
+			Members:             make([]rbxapi.Member, len(cc.Members)),
+			Capabilities:        cc.Capabilities,
+			Tags:                cc.Tags,
+			PreferredDescriptor: cc.PreferredDescriptor,
+		}
+		for j, m := range cc.Members {
+			if class.Members[j], err = decodeMember(m); err != nil {
+				return nil, 0, err
+			}
+		}
+		root.Classes[i] = class
+	}
+	for i, ce := range c.Enums {
+		enum := &rbxapijson.Enum{
+			Name:                ce.Name,
+			Items:               make([]*rbxapijson.EnumItem, len(ce.Items)),
+			Tags:                ce.Tags,
+			PreferredDescriptor: ce.PreferredDescriptor,
+		}
+		for j, ci := range ce.Items {
+			enum.Items[j] = &rbxapijson.EnumItem{
+				Name:                ci.Name,
+				Value:               ci.Value,
+				Tags:                ci.Tags,
+				PreferredDescriptor: ci.PreferredDescriptor,
+			}
+		}
+		root.Enums[i] = enum
+	}
+	return root, kind, nil
+}
+
+type cacheCodec struct{}
+
+func (cacheCodec) Decode(r io.Reader) (rbxapi.Root, error) {
+	root, err := ReadCache(r)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (cacheCodec) Encode(w io.Writer, root rbxapi.Root) error {
+	return WriteCache(w, root)
+}
+
+// Convert returns root as a structure of the rbxapijson package.
+func (cacheCodec) Convert(root rbxapi.Root) rbxapi.Root {
+	return codec.JSON.Convert(root)
+}
+
+// Codec is the codec of the "cache" format.
+var Codec codec.Codec = cacheCodec{}
+
+func init() {
+	codec.Register(codec.Format{
+		Name:       "cache",
+		Extensions: []string{".rbxcache"},
+		Sniff: func(prefix []byte) bool {
+			return bytes.HasPrefix(prefix, []byte(magic))
+		},
+		Codec: Codec,
+	})
+}
@@ -0,0 +1,137 @@
+package rbxapicache
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"testing"
+)
+
+func TestRoundTripJSON(t *testing.T) {
+	root := apitest.Generate(1, 100, 50)
+	var buf bytes.Buffer
+	if err := WriteCache(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadRoot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := read.(*rbxapijson.Root); !ok {
+		t.Fatalf("read %T, expected *rbxapijson.Root", read)
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: root, Next: read.(*rbxapijson.Root)}).Diff() {
+		t.Errorf("lost: %s", action)
+	}
+}
+
+func TestRoundTripDump(t *testing.T) {
+	root := rbxapiconv.ToDump(apitest.Generate(1, 100, 50))
+	var buf bytes.Buffer
+	if err := WriteCache(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadRoot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := read.(*rbxapidump.Root); !ok {
+		t.Fatalf("read %T, expected *rbxapidump.Root", read)
+	}
+	for _, action := range (&diff.Diff{Prev: root, Next: read}).Diff() {
+		t.Errorf("lost: %s", action)
+	}
+}
+
+func TestVersion4(t *testing.T) {
+	root := rbxapiconv.ToDump(apitest.Generate(1, 20, 10))
+	var buf bytes.Buffer
+	if err := WriteCache(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	// A version 4 snapshot has the same layout, without the kind following
+	// the version.
+	b := buf.Bytes()
+	v4 := append([]byte(magic), version4)
+	v4 = append(v4, b[len(magic)+2:]...)
+
+	read, err := ReadRoot(bytes.NewReader(v4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := read.(*rbxapijson.Root); !ok {
+		t.Fatalf("read %T, expected *rbxapijson.Root", read)
+	}
+	if _, err := ReadCache(bytes.NewReader(append([]byte(magic), 3))); err == nil {
+		t.Error("expected error for version 3")
+	} else if verr, ok := err.(VersionError); !ok || verr.VersionError() != 3 {
+		t.Errorf("expected VersionError for version 3, got %v", err)
+	}
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	var buf bytes.Buffer
+	if err := rbxapijson.Encode(&buf, apitest.Root(b)); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rbxapijson.Decode(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeDump(b *testing.B) {
+	var buf bytes.Buffer
+	if err := rbxapidump.Encode(&buf, rbxapiconv.ToDump(apitest.Root(b))); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rbxapidump.Decode(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadCache(b *testing.B) {
+	var buf bytes.Buffer
+	if err := WriteCache(&buf, apitest.Root(b)); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadCache(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadRootDump(b *testing.B) {
+	var buf bytes.Buffer
+	if err := WriteCache(&buf, rbxapiconv.ToDump(apitest.Root(b))); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadRoot(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
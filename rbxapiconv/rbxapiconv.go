@@ -0,0 +1,390 @@
+// The rbxapiconv package converts API structures between the rbxapidump and
+// rbxapijson packages.
+//
+// Unlike a conversion through the generic rbxapi interfaces, which copies
+// tags verbatim, the conversion maps information between the
+// representations used by each format. Security contexts, which the dump
+// format represents as tags, are mapped to the security fields of JSON
+// descriptors. Tags of the dump format, which are conventionally lowercase,
+// are mapped to the capitalized tags of the JSON format. The categories of
+// types, which the dump format omits, are inferred from the structure.
+//
+// Some information has no representation in the dump format, such as the
+// serialization, category, and default value of properties, the memory
+// category of classes, and the thread safety and capabilities of members.
+// Such information is dropped when converting to the dump format. When
+// converting from it, properties that are not read-only are assumed to be
+// serialized, and other information is left empty.
+package rbxapiconv
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+)
+
+// DumpTags maps tags of the dump format to the equivalent tags of the JSON
+// format. Tags not present are the same in both formats.
+var DumpTags = map[string]string{
+	"readonly":      "ReadOnly",
+	"notbrowsable":  "NotBrowsable",
+	"deprecated":    "Deprecated",
+	"hidden":        "Hidden",
+	"notCreatable":  "NotCreatable",
+	"notreplicated": "NotReplicated",
+	"notScriptable": "NotScriptable",
+	"preliminary":   "Preliminary",
+	"service":       "Service",
+	"settings":      "Settings",
+}
+
+// jsonTags maps tags of the JSON format to the equivalent tags of the dump
+// format.
+var jsonTags = map[string]string{}
+
+func init() {
+	for d, j := range DumpTags {
+		jsonTags[j] = d
+	}
+}
+
+// HasTag returns whether t has the given tag, in the spelling of either the
+// JSON format or the dump format, so that descriptors of both formats may be
+// checked for a tag such as "Service". The given tag may itself be spelled
+// in either format.
+func HasTag(t rbxapi.Taggable, tag string) bool {
+	if t.GetTag(tag) {
+		return true
+	}
+	if d, ok := jsonTags[tag]; ok {
+		return t.GetTag(d)
+	}
+	if j, ok := DumpTags[tag]; ok {
+		return t.GetTag(j)
+	}
+	return false
+}
+
+// primitives lists the names of types that are in the Primitive category.
+var primitives = map[string]bool{
+	"bool":   true,
+	"int":    true,
+	"int64":  true,
+	"float":  true,
+	"double": true,
+	"string": true,
+	"void":   true,
+	"null":   true,
+}
+
+// groups lists the names of types that are in the Group category.
+var groups = map[string]bool{
+	"Array":      true,
+	"Dictionary": true,
+	"Map":        true,
+	"Tuple":      true,
+	"Variant":    true,
+}
+
+// toJSON holds the state of a conversion to the JSON format.
+type toJSON struct {
+	classes map[string]bool
+	enums   map[string]bool
+}
+
+// tags converts tags of the dump format, excluding security contexts, which
+// are returned separately. Security contexts default to "None".
+func (c *toJSON) tags(tags rbxapidump.Tags) (t rbxapijson.Tags, read, write string) {
+	t = rbxapijson.Tags{}
+	for _, tag := range tags {
+		security, isWrite := rbxapidump.ParseSecurityTag(tag)
+		switch {
+		case isWrite:
+			if write == "" {
+				write = security
+			}
+		case security != "":
+			if read == "" {
+				read = security
+			}
+		default:
+			if j, ok := DumpTags[tag]; ok {
+				tag = j
+			}
+			t.SetTag(tag)
+		}
+	}
+	if read == "" {
+		read = "None"
+	}
+	if write == "" {
+		write = read
+	}
+	return t, read, write
+}
+
+// typ converts a type of the dump format, inferring its category if absent.
+func (c *toJSON) typ(typ rbxapidump.Type) rbxapijson.Type {
+	t := rbxapijson.Type{Category: typ.GetCategory(), Name: typ.GetName()}
+	if t.Category == "" {
+		t.Category = category(t.Name, c.classes[t.Name], c.enums[t.Name])
+	}
+	return t
+}
+
+// category returns the inferred category of a type.
+func category(name string, class, enum bool) string {
+	switch {
+	case primitives[name]:
+		return "Primitive"
+	case groups[name]:
+		return "Group"
+	case enum:
+		return "Enum"
+	case class:
+		return "Class"
+	}
+	return "DataType"
+}
+
+// InferCategory returns the category of the type of the given name, as
+// inferred by ToJSON for types that have no category. Names of classes and
+// enums are looked up in root.
+func InferCategory(root rbxapi.Root, name string) string {
+	return category(name, root.GetClass(name) != nil, root.GetEnum(name) != nil)
+}
+
+func (c *toJSON) parameters(params []rbxapidump.Parameter) []rbxapijson.Parameter {
+	list := make([]rbxapijson.Parameter, len(params))
+	for i, p := range params {
+		list[i] = rbxapijson.Parameter{
+			Type:       c.typ(p.Type),
+			Name:       p.Name,
+			HasDefault: p.HasDefault,
+			Default:    p.Default,
+		}
+	}
+	return list
+}
+
+func (c *toJSON) member(member rbxapi.Member) rbxapi.Member {
+	switch member := member.(type) {
+	case *rbxapidump.Property:
+		tags, read, write := c.tags(member.Tags)
+		serialized := !member.GetTag("readonly")
+		return &rbxapijson.Property{
+			Name:          member.Name,
+			ValueType:     c.typ(member.ValueType),
+			ReadSecurity:  read,
+			WriteSecurity: write,
+			CanLoad:       serialized,
+			CanSave:       serialized,
+			Tags:          tags,
+		}
+	case *rbxapidump.Function:
+		tags, security, _ := c.tags(member.Tags)
+		return &rbxapijson.Function{
+			Name:       member.Name,
+			Parameters: c.parameters(member.Parameters),
+			ReturnType: c.typ(member.ReturnType),
+			Security:   security,
+			Tags:       tags,
+		}
+	case *rbxapidump.Event:
+		tags, security, _ := c.tags(member.Tags)
+		return &rbxapijson.Event{
+			Name:       member.Name,
+			Parameters: c.parameters(member.Parameters),
+			Security:   security,
+			Tags:       tags,
+		}
+	case *rbxapidump.Callback:
+		tags, security, _ := c.tags(member.Tags)
+		return &rbxapijson.Callback{
+			Name:       member.Name,
+			Parameters: c.parameters(member.Parameters),
+			ReturnType: c.typ(member.ReturnType),
+			Security:   security,
+			Tags:       tags,
+		}
+	}
+	return nil
+}
+
+// ToJSON returns root converted to the JSON format.
+func ToJSON(root *rbxapidump.Root) *rbxapijson.Root {
+	c := &toJSON{classes: map[string]bool{}, enums: map[string]bool{}}
+	for _, class := range root.Classes {
+		c.classes[class.Name] = true
+	}
+	for _, enum := range root.Enums {
+		c.enums[enum.Name] = true
+	}
+	r := &rbxapijson.Root{
+		Classes: make([]*rbxapijson.Class, len(root.Classes)),
+		Enums:   make([]*rbxapijson.Enum, len(root.Enums)),
+	}
+	for i, class := range root.Classes {
+		tags, _, _ := c.tags(class.Tags)
+		jclass := &rbxapijson.Class{
+			Name:       class.Name,
+			Superclass: class.Superclass,
+			Members:    make([]rbxapi.Member, 0, len(class.Members)),
+			Tags:       tags,
+		}
+		if jclass.Superclass == "" {
+			jclass.Superclass = "<<<ROOT>>>"
+		}
+		for _, member := range class.Members {
+			if member := c.member(member); member != nil {
+				jclass.Members = append(jclass.Members, member)
+			}
+		}
+		r.Classes[i] = jclass
+	}
+	for i, enum := range root.Enums {
+		tags, _, _ := c.tags(enum.Tags)
+		jenum := &rbxapijson.Enum{
+			Name:  enum.Name,
+			Items: make([]*rbxapijson.EnumItem, len(enum.Items)),
+			Tags:  tags,
+		}
+		for j, item := range enum.Items {
+			tags, _, _ := c.tags(item.Tags)
+			jenum.Items[j] = &rbxapijson.EnumItem{
+				Name:  item.Name,
+				Value: item.Value,
+				Tags:  tags,
+			}
+		}
+		r.Enums[i] = jenum
+	}
+	return r
+}
+
+// dumpTagsOf converts tags of the JSON format to the dump format, preceded
+// by the given security contexts, which are omitted if "None" or empty.
+func dumpTagsOf(tags rbxapijson.Tags, read, write string) rbxapidump.Tags {
+	t := rbxapidump.Tags{}
+	if read != "" && read != "None" {
+		t.SetTag(read)
+	}
+	if write != "" && write != read && write != "None" {
+		t.SetTag(rbxapidump.WriteSecurityTag(write))
+	}
+	for _, tag := range tags {
+		if d, ok := jsonTags[tag]; ok {
+			tag = d
+		}
+		t.SetTag(tag)
+	}
+	return t
+}
+
+// inferred lists the type categories that are inferred by ToJSON, and so are
+// omitted by ToDump.
+var inferred = map[string]bool{
+	"Primitive": true,
+	"Group":     true,
+	"Enum":      true,
+	"Class":     true,
+	"DataType":  true,
+}
+
+func dumpType(typ rbxapijson.Type) rbxapidump.Type {
+	if typ.Category == "" || inferred[typ.Category] {
+		return rbxapidump.Type(typ.Name)
+	}
+	return rbxapidump.Type(typ.Category + ":" + typ.Name)
+}
+
+func dumpParameters(params []rbxapijson.Parameter) []rbxapidump.Parameter {
+	list := make([]rbxapidump.Parameter, len(params))
+	for i, p := range params {
+		list[i] = rbxapidump.Parameter{
+			Type:       dumpType(p.Type),
+			Name:       p.Name,
+			HasDefault: p.HasDefault,
+			Default:    p.Default,
+		}
+	}
+	return list
+}
+
+func dumpMember(class string, member rbxapi.Member) rbxapi.Member {
+	switch member := member.(type) {
+	case *rbxapijson.Property:
+		return &rbxapidump.Property{
+			Name:      member.Name,
+			Class:     class,
+			ValueType: dumpType(member.ValueType),
+			Tags:      dumpTagsOf(member.Tags, member.ReadSecurity, member.WriteSecurity),
+		}
+	case *rbxapijson.Function:
+		return &rbxapidump.Function{
+			Name:       member.Name,
+			Class:      class,
+			ReturnType: dumpType(member.ReturnType),
+			Parameters: dumpParameters(member.Parameters),
+			Tags:       dumpTagsOf(member.Tags, member.Security, ""),
+		}
+	case *rbxapijson.Event:
+		return &rbxapidump.Event{
+			Name:       member.Name,
+			Class:      class,
+			Parameters: dumpParameters(member.Parameters),
+			Tags:       dumpTagsOf(member.Tags, member.Security, ""),
+		}
+	case *rbxapijson.Callback:
+		return &rbxapidump.Callback{
+			Name:       member.Name,
+			Class:      class,
+			ReturnType: dumpType(member.ReturnType),
+			Parameters: dumpParameters(member.Parameters),
+			Tags:       dumpTagsOf(member.Tags, member.Security, ""),
+		}
+	}
+	return nil
+}
+
+// ToDump returns root converted to the dump format.
+func ToDump(root *rbxapijson.Root) *rbxapidump.Root {
+	r := &rbxapidump.Root{
+		Classes: make([]*rbxapidump.Class, len(root.Classes)),
+		Enums:   make([]*rbxapidump.Enum, len(root.Enums)),
+	}
+	for i, class := range root.Classes {
+		dclass := &rbxapidump.Class{
+			Name:       class.Name,
+			Superclass: class.Superclass,
+			Members:    make([]rbxapi.Member, 0, len(class.Members)),
+			Tags:       dumpTagsOf(class.Tags, "", ""),
+		}
+		if dclass.Superclass == "<<<ROOT>>>" {
+			dclass.Superclass = ""
+		}
+		for _, member := range class.Members {
+			if member := dumpMember(class.Name, member); member != nil {
+				dclass.Members = append(dclass.Members, member)
+			}
+		}
+		r.Classes[i] = dclass
+	}
+	for i, enum := range root.Enums {
+		denum := &rbxapidump.Enum{
+			Name:  enum.Name,
+			Items: make([]*rbxapidump.EnumItem, len(enum.Items)),
+			Tags:  dumpTagsOf(enum.Tags, "", ""),
+		}
+		for j, item := range enum.Items {
+			denum.Items[j] = &rbxapidump.EnumItem{
+				Enum:  enum.Name,
+				Name:  item.Name,
+				Value: item.Value,
+				Tags:  dumpTagsOf(item.Tags, "", ""),
+			}
+		}
+		r.Enums[i] = denum
+	}
+	return r
+}
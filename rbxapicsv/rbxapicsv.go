@@ -0,0 +1,447 @@
+// The rbxapicsv package implements a flat, tabular representation of API
+// structures as comma- or tab-separated values.
+//
+// Each class, member, enum, and enum item occupies one row, making the format
+// suitable for spreadsheets and other tools that consume tabular data. The
+// first row names the columns:
+//
+//	Kind            Class, Property, Function, Event, Callback, Enum, or EnumItem
+//	Class           name of the class of a class or member row
+//	Enum            name of the enum of an enum or enum item row
+//	Name            name of a member or enum item
+//	Superclass      superclass of a class
+//	MemoryCategory  memory category of a class
+//	Category        category of a property
+//	Type            value type of a property, or return type of a function or callback
+//	Parameters      parameters of a function, event, or callback
+//	Security        security of a function, event, or callback, or read security of a property
+//	WriteSecurity   write security of a property
+//	CanLoad         whether a property can be loaded
+//	CanSave         whether a property can be saved
+//	Value           value of an enum item
+//	Tags            tags of the descriptor, separated by commas
+//
+// A type is written as "Category:Name". Parameters are separated by commas,
+// and each is written as a type followed by a name, and by "=" and a quoted
+// default value if the parameter has a default:
+//
+//	Class:Instance parent, Primitive:bool recursive="false"
+//
+// When decoding, columns may appear in any order, and columns other than Kind
+// may be omitted. Members and items follow the row of their class or enum,
+// if present.
+//
+// Decoding produces a structure of the rbxapijson package, which also retains
+// the fields specific to JSON dumps. The package registers the "csv" and "tsv"
+// formats with the codec package.
+package rbxapicsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/codec"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Columns contains the names of the columns, in the order they are written.
+var Columns = []string{
+	"Kind",
+	"Class",
+	"Enum",
+	"Name",
+	"Superclass",
+	"MemoryCategory",
+	"Category",
+	"Type",
+	"Parameters",
+	"Security",
+	"WriteSecurity",
+	"CanLoad",
+	"CanSave",
+	"Value",
+	"Tags",
+}
+
+// row maps column names to values.
+type row map[string]string
+
+// record returns the values of the row in the order of Columns.
+func (r row) record() []string {
+	record := make([]string, len(Columns))
+	for i, col := range Columns {
+		record[i] = r[col]
+	}
+	return record
+}
+
+// typeString returns the string representation of a type.
+func typeString(typ rbxapi.Type) string {
+	if typ.GetCategory() == "" {
+		return typ.GetName()
+	}
+	return typ.GetCategory() + ":" + typ.GetName()
+}
+
+// parseType parses a type from the form produced by typeString.
+func parseType(s string) rbxapijson.Type {
+	if i := strings.Index(s, ":"); i >= 0 {
+		return rbxapijson.Type{Category: s[:i], Name: s[i+1:]}
+	}
+	return rbxapijson.Type{Name: s}
+}
+
+// paramsString returns the string representation of a parameter list.
+func paramsString(params rbxapi.Parameters) string {
+	list := params.GetParameters()
+	ss := make([]string, len(list))
+	for i, param := range list {
+		ss[i] = typeString(param.GetType()) + " " + param.GetName()
+		if def, ok := param.GetDefault(); ok {
+			ss[i] += "=" + strconv.Quote(def)
+		}
+	}
+	return strings.Join(ss, ", ")
+}
+
+// parseParams parses a parameter list from the form produced by
+// paramsString.
+func parseParams(s string) ([]rbxapijson.Parameter, error) {
+	params := []rbxapijson.Parameter{}
+	for s = strings.TrimSpace(s); s != ""; {
+		var param rbxapijson.Parameter
+		i := strings.IndexByte(s, ' ')
+		if i < 0 {
+			return nil, errors.New("parameter missing name")
+		}
+		param.Type = parseType(s[:i])
+		s = s[i+1:]
+		i = strings.IndexAny(s, ",=")
+		if i < 0 {
+			i = len(s)
+		}
+		param.Name = strings.TrimSpace(s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "=") {
+			quoted, err := strconv.QuotedPrefix(s[1:])
+			if err != nil {
+				return nil, errors.New("parameter " + param.Name + ": malformed default value")
+			}
+			param.HasDefault = true
+			param.Default, _ = strconv.Unquote(quoted)
+			s = s[1+len(quoted):]
+		}
+		params = append(params, param)
+		s = strings.TrimSpace(s)
+		if s == "" {
+			break
+		}
+		if s[0] != ',' {
+			return nil, errors.New("parameter " + param.Name + ": expected comma")
+		}
+		s = strings.TrimSpace(s[1:])
+	}
+	return params, nil
+}
+
+// parseTags parses a list of tags separated by commas.
+func parseTags(s string) rbxapijson.Tags {
+	var tags rbxapijson.Tags
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// memberRow returns the row of a member.
+func memberRow(class string, member rbxapi.Member) row {
+	r := row{
+		"Kind":  member.GetMemberType(),
+		"Class": class,
+		"Name":  member.GetName(),
+		"Tags":  strings.Join(member.GetTags(), ", "),
+	}
+	switch m := member.(type) {
+	case rbxapi.Property:
+		r["Type"] = typeString(m.GetValueType())
+		r["Security"], r["WriteSecurity"] = m.GetSecurity()
+		if p, ok := m.(*rbxapijson.Property); ok {
+			r["Category"] = p.Category
+			r["CanLoad"] = strconv.FormatBool(p.CanLoad)
+			r["CanSave"] = strconv.FormatBool(p.CanSave)
+		}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		r["Type"] = typeString(m.GetReturnType())
+		r["Parameters"] = paramsString(m.GetParameters())
+		r["Security"] = m.GetSecurity()
+	case rbxapi.Event:
+		r["Parameters"] = paramsString(m.GetParameters())
+		r["Security"] = m.GetSecurity()
+	}
+	return r
+}
+
+// Encode writes root to w, with values separated by comma. Fields specific
+// to JSON dumps are written if root contains descriptors of the rbxapijson
+// package.
+func Encode(w io.Writer, root rbxapi.Root, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.Write(Columns)
+	for _, class := range root.GetClasses() {
+		r := row{
+			"Kind":       "Class",
+			"Class":      class.GetName(),
+			"Superclass": class.GetSuperclass(),
+			"Tags":       strings.Join(class.GetTags(), ", "),
+		}
+		if c, ok := class.(*rbxapijson.Class); ok {
+			r["MemoryCategory"] = c.MemoryCategory
+		}
+		cw.Write(r.record())
+		for _, member := range class.GetMembers() {
+			cw.Write(memberRow(class.GetName(), member).record())
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		cw.Write(row{
+			"Kind": "Enum",
+			"Enum": enum.GetName(),
+			"Tags": strings.Join(enum.GetTags(), ", "),
+		}.record())
+		for _, item := range enum.GetEnumItems() {
+			cw.Write(row{
+				"Kind":  "EnumItem",
+				"Enum":  enum.GetName(),
+				"Name":  item.GetName(),
+				"Value": strconv.Itoa(item.GetValue()),
+				"Tags":  strings.Join(item.GetTags(), ", "),
+			}.record())
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// DecodeError is an error that occurred while decoding a row.
+type DecodeError struct {
+	// Line is the line on which the row starts.
+	Line int
+	Err  error
+}
+
+func (err *DecodeError) Error() string {
+	return "line " + strconv.Itoa(err.Line) + ": " + err.Err.Error()
+}
+
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+// Decode parses an API structure from r, with values separated by comma.
+func Decode(r io.Reader, comma rune) (root *rbxapijson.Root, err error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, col := range header {
+		index[strings.TrimPrefix(strings.TrimSpace(col), "\uFEFF")] = i
+	}
+	if _, ok := index["Kind"]; !ok {
+		return nil, errors.New("missing Kind column")
+	}
+
+	root = &rbxapijson.Root{}
+	classes := map[string]*rbxapijson.Class{}
+	enums := map[string]*rbxapijson.Enum{}
+	getClass := func(name string) *rbxapijson.Class {
+		class, ok := classes[name]
+		if !ok {
+			class = &rbxapijson.Class{Name: name}
+			classes[name] = class
+			root.Classes = append(root.Classes, class)
+		}
+		return class
+	}
+	getEnum := func(name string) *rbxapijson.Enum {
+		enum, ok := enums[name]
+		if !ok {
+			enum = &rbxapijson.Enum{Name: name}
+			enums[name] = enum
+			root.Enums = append(root.Enums, enum)
+		}
+		return enum
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(col string) string {
+			if i, ok := index[col]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		fail := func(msg string) error {
+			return &DecodeError{Line: line, Err: errors.New(msg)}
+		}
+		kind := field("Kind")
+		switch kind {
+		case "":
+			// Blank rows are skipped.
+			continue
+		case "Class":
+			class := getClass(field("Class"))
+			class.Superclass = field("Superclass")
+			class.MemoryCategory = field("MemoryCategory")
+			class.Tags = parseTags(field("Tags"))
+		case "Property", "Function", "Event", "Callback":
+			if field("Class") == "" {
+				return nil, fail("member without class")
+			}
+			var params []rbxapijson.Parameter
+			if kind != "Property" {
+				if params, err = parseParams(field("Parameters")); err != nil {
+					return nil, &DecodeError{Line: line, Err: err}
+				}
+			}
+			var member rbxapi.Member
+			switch kind {
+			case "Property":
+				p := &rbxapijson.Property{
+					Name:          field("Name"),
+					ValueType:     parseType(field("Type")),
+					Category:      field("Category"),
+					ReadSecurity:  field("Security"),
+					WriteSecurity: field("WriteSecurity"),
+					Tags:          parseTags(field("Tags")),
+				}
+				if p.CanLoad, err = parseBool(field("CanLoad")); err != nil {
+					return nil, fail("CanLoad: " + err.Error())
+				}
+				if p.CanSave, err = parseBool(field("CanSave")); err != nil {
+					return nil, fail("CanSave: " + err.Error())
+				}
+				member = p
+			case "Function":
+				member = &rbxapijson.Function{
+					Name:       field("Name"),
+					Parameters: params,
+					ReturnType: parseType(field("Type")),
+					Security:   field("Security"),
+					Tags:       parseTags(field("Tags")),
+				}
+			case "Event":
+				member = &rbxapijson.Event{
+					Name:       field("Name"),
+					Parameters: params,
+					Security:   field("Security"),
+					Tags:       parseTags(field("Tags")),
+				}
+			case "Callback":
+				member = &rbxapijson.Callback{
+					Name:       field("Name"),
+					Parameters: params,
+					ReturnType: parseType(field("Type")),
+					Security:   field("Security"),
+					Tags:       parseTags(field("Tags")),
+				}
+			}
+			class := getClass(field("Class"))
+			class.Members = append(class.Members, member)
+		case "Enum":
+			enum := getEnum(field("Enum"))
+			enum.Tags = parseTags(field("Tags"))
+		case "EnumItem":
+			if field("Enum") == "" {
+				return nil, fail("enum item without enum")
+			}
+			value, err := strconv.Atoi(strings.TrimSpace(field("Value")))
+			if err != nil {
+				return nil, fail("malformed Value " + strconv.Quote(field("Value")))
+			}
+			enum := getEnum(field("Enum"))
+			enum.Items = append(enum.Items, &rbxapijson.EnumItem{
+				Name:  field("Name"),
+				Value: value,
+				Tags:  parseTags(field("Tags")),
+			})
+		default:
+			return nil, fail("unknown kind " + strconv.Quote(kind))
+		}
+	}
+	return root, nil
+}
+
+// parseBool parses a boolean value, where an empty string is false.
+func parseBool(s string) (bool, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// Codec implements the codec.Codec interface for values separated by Comma.
+type Codec struct {
+	Comma rune
+}
+
+// Decode implements the codec.Codec interface.
+func (c Codec) Decode(r io.Reader) (rbxapi.Root, error) {
+	root, err := Decode(r, c.Comma)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Encode implements the codec.Codec interface.
+func (c Codec) Encode(w io.Writer, root rbxapi.Root) error {
+	return Encode(w, root, c.Comma)
+}
+
+// Convert implements the codec.Codec interface. The native type of the codec
+// is that of the rbxapijson package.
+func (c Codec) Convert(root rbxapi.Root) rbxapi.Root {
+	return codec.JSON.Convert(root)
+}
+
+// sniff returns whether prefix begins with a header of columns separated by
+// comma.
+func sniff(prefix []byte, comma string) bool {
+	s := strings.TrimPrefix(string(prefix), "\uFEFF")
+	return strings.HasPrefix(s, "Kind"+comma) || strings.HasPrefix(s, "\"Kind\""+comma)
+}
+
+func init() {
+	codec.Register(codec.Format{
+		Name:       "csv",
+		Extensions: []string{".csv"},
+		Sniff:      func(prefix []byte) bool { return sniff(prefix, ",") },
+		Codec:      Codec{Comma: ','},
+	})
+	codec.Register(codec.Format{
+		Name:       "tsv",
+		Extensions: []string{".tsv"},
+		Sniff:      func(prefix []byte) bool { return sniff(prefix, "\t") },
+		Codec:      Codec{Comma: '\t'},
+	})
+}
@@ -0,0 +1,277 @@
+package rbxapidump
+
+import (
+	"io"
+	"sync"
+)
+
+// arenaBlockSize is the minimum number of descriptors allocated at once by an
+// Arena.
+const arenaBlockSize = 256
+
+// Arena allocates the descriptors of decoded API structures in blocks, which
+// are reused after the Arena is reset. Decoding many API dumps into the same
+// Arena greatly reduces the number of allocations made by the decoder.
+//
+// Descriptors allocated from an Arena are owned by the Arena. A Root decoded
+// with an Arena, along with any descriptor retrieved from it, must not be used
+// after the Arena is reset. Use Root.Copy to retain a structure beyond the
+// lifetime of the Arena.
+//
+// An Arena is not safe for concurrent use.
+type Arena struct {
+	classes   []Class
+	nclasses  int
+	props     []Property
+	nprops    int
+	funcs     []Function
+	nfuncs    int
+	events    []Event
+	nevents   int
+	callbacks []Callback
+	ncallback int
+	enums     []Enum
+	nenums    int
+	items     []EnumItem
+	nitems    int
+
+	classList []*Class
+	enumList  []*Enum
+}
+
+// NewArena returns a new, empty Arena.
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+// blockSize returns the capacity of a new block, given the number of
+// descriptors allocated so far.
+func blockSize(n int) int {
+	if n < arenaBlockSize {
+		return arenaBlockSize
+	}
+	return n
+}
+
+// Reset releases all descriptors allocated by the Arena, allowing their
+// memory to be reused. Blocks are consolidated so that a subsequent decode of
+// a similar size requires a single block per descriptor type.
+func (a *Arena) Reset() {
+	if a.nclasses > cap(a.classes) {
+		a.classes = make([]Class, 0, a.nclasses)
+	} else {
+		for i := range a.classes {
+			members := a.classes[i].Members
+			for j := range members {
+				members[j] = nil
+			}
+			a.classes[i] = Class{Members: members[:0]}
+		}
+		a.classes = a.classes[:0]
+	}
+	if a.nprops > cap(a.props) {
+		a.props = make([]Property, 0, a.nprops)
+	} else {
+		for i := range a.props {
+			a.props[i] = Property{}
+		}
+		a.props = a.props[:0]
+	}
+	if a.nfuncs > cap(a.funcs) {
+		a.funcs = make([]Function, 0, a.nfuncs)
+	} else {
+		for i := range a.funcs {
+			a.funcs[i] = Function{}
+		}
+		a.funcs = a.funcs[:0]
+	}
+	if a.nevents > cap(a.events) {
+		a.events = make([]Event, 0, a.nevents)
+	} else {
+		for i := range a.events {
+			a.events[i] = Event{}
+		}
+		a.events = a.events[:0]
+	}
+	if a.ncallback > cap(a.callbacks) {
+		a.callbacks = make([]Callback, 0, a.ncallback)
+	} else {
+		for i := range a.callbacks {
+			a.callbacks[i] = Callback{}
+		}
+		a.callbacks = a.callbacks[:0]
+	}
+	if a.nenums > cap(a.enums) {
+		a.enums = make([]Enum, 0, a.nenums)
+	} else {
+		for i := range a.enums {
+			items := a.enums[i].Items
+			for j := range items {
+				items[j] = nil
+			}
+			a.enums[i] = Enum{Items: items[:0]}
+		}
+		a.enums = a.enums[:0]
+	}
+	if a.nitems > cap(a.items) {
+		a.items = make([]EnumItem, 0, a.nitems)
+	} else {
+		for i := range a.items {
+			a.items[i] = EnumItem{}
+		}
+		a.items = a.items[:0]
+	}
+	for i := range a.classList {
+		a.classList[i] = nil
+	}
+	a.classList = a.classList[:0]
+	for i := range a.enumList {
+		a.enumList[i] = nil
+	}
+	a.enumList = a.enumList[:0]
+
+	a.nclasses = 0
+	a.nprops = 0
+	a.nfuncs = 0
+	a.nevents = 0
+	a.ncallback = 0
+	a.nenums = 0
+	a.nitems = 0
+}
+
+func (a *Arena) newClass() *Class {
+	if a == nil {
+		return &Class{}
+	}
+	if len(a.classes) == cap(a.classes) {
+		a.classes = make([]Class, 0, blockSize(a.nclasses))
+	}
+	a.classes = a.classes[:len(a.classes)+1]
+	a.nclasses++
+	return &a.classes[len(a.classes)-1]
+}
+
+func (a *Arena) newProperty() *Property {
+	if a == nil {
+		return &Property{}
+	}
+	if len(a.props) == cap(a.props) {
+		a.props = make([]Property, 0, blockSize(a.nprops))
+	}
+	a.props = a.props[:len(a.props)+1]
+	a.nprops++
+	return &a.props[len(a.props)-1]
+}
+
+func (a *Arena) newFunction() *Function {
+	if a == nil {
+		return &Function{}
+	}
+	if len(a.funcs) == cap(a.funcs) {
+		a.funcs = make([]Function, 0, blockSize(a.nfuncs))
+	}
+	a.funcs = a.funcs[:len(a.funcs)+1]
+	a.nfuncs++
+	return &a.funcs[len(a.funcs)-1]
+}
+
+func (a *Arena) newEvent() *Event {
+	if a == nil {
+		return &Event{}
+	}
+	if len(a.events) == cap(a.events) {
+		a.events = make([]Event, 0, blockSize(a.nevents))
+	}
+	a.events = a.events[:len(a.events)+1]
+	a.nevents++
+	return &a.events[len(a.events)-1]
+}
+
+func (a *Arena) newCallback() *Callback {
+	if a == nil {
+		return &Callback{}
+	}
+	if len(a.callbacks) == cap(a.callbacks) {
+		a.callbacks = make([]Callback, 0, blockSize(a.ncallback))
+	}
+	a.callbacks = a.callbacks[:len(a.callbacks)+1]
+	a.ncallback++
+	return &a.callbacks[len(a.callbacks)-1]
+}
+
+func (a *Arena) newEnum() *Enum {
+	if a == nil {
+		return &Enum{}
+	}
+	if len(a.enums) == cap(a.enums) {
+		a.enums = make([]Enum, 0, blockSize(a.nenums))
+	}
+	a.enums = a.enums[:len(a.enums)+1]
+	a.nenums++
+	return &a.enums[len(a.enums)-1]
+}
+
+func (a *Arena) newEnumItem() *EnumItem {
+	if a == nil {
+		return &EnumItem{}
+	}
+	if len(a.items) == cap(a.items) {
+		a.items = make([]EnumItem, 0, blockSize(a.nitems))
+	}
+	a.items = a.items[:len(a.items)+1]
+	a.nitems++
+	return &a.items[len(a.items)-1]
+}
+
+// newRoot returns a Root whose lists reuse the memory of the Arena.
+func (a *Arena) newRoot() *Root {
+	if a == nil {
+		return &Root{}
+	}
+	return &Root{Classes: a.classList[:0], Enums: a.enumList[:0]}
+}
+
+// DecodeArena parses an API dump from r, allocating descriptors from arena.
+// The returned Root is valid until the arena is reset.
+func DecodeArena(r io.Reader, arena *Arena) (root *Root, err error) {
+	data, err := readAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d := newDecoder(data, arena)
+	err = d.decode()
+	root = d.root
+	if arena != nil {
+		arena.classList = root.Classes
+		arena.enumList = root.Enums
+	}
+	return
+}
+
+// arenaPool holds arenas of released roots.
+var arenaPool = sync.Pool{New: func() interface{} { return NewArena() }}
+
+// DecodePooled parses an API dump from r, allocating descriptors from an
+// Arena retrieved from a shared pool. Calling Release on the returned Root
+// resets the Arena and returns it to the pool.
+func DecodePooled(r io.Reader) (root *Root, err error) {
+	arena := arenaPool.Get().(*Arena)
+	root, err = DecodeArena(r, arena)
+	root.arena = arena
+	return root, err
+}
+
+// Release recycles the memory of a Root returned by DecodePooled. The Root,
+// along with any descriptor retrieved from it, must not be used afterwards.
+// Release does nothing if the Root was not returned by DecodePooled.
+func (root *Root) Release() {
+	if root.arena == nil {
+		return
+	}
+	arena := root.arena
+	root.arena = nil
+	root.Classes = nil
+	root.Enums = nil
+	arena.Reset()
+	arenaPool.Put(arena)
+}
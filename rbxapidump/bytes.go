@@ -0,0 +1,103 @@
+package rbxapidump
+
+import (
+	"github.com/karl-police/rbxapi/mmap"
+)
+
+// DecodeBytes parses an API dump from b. The content of b is copied, so b may
+// be modified or discarded afterwards.
+func DecodeBytes(b []byte) (root *Root, err error) {
+	d := newDecoder(string(b), nil)
+	err = d.decode()
+	return d.root, err
+}
+
+// DecodeMapped parses an API dump from the content of m. Decoded strings
+// refer directly to the memory of m rather than being copied, which
+// considerably reduces the memory used by large dumps.
+//
+// The returned Root takes ownership of m. Calling Close on the Root closes m,
+// after which no string retrieved from the Root, or from a structure returned
+// by its CopyShared method, may be used. Copy and CopyParallel copy the
+// strings of the Root, so the returned structures remain valid after the Root
+// is closed. When the package is built with the purego tag, strings are
+// copied instead, and remain valid after the Root is closed.
+func DecodeMapped(m *mmap.Mapping) (root *Root, err error) {
+	d := newDecoder(bytesToString(m.Bytes()), nil)
+	err = d.decode()
+	root = d.root
+	root.mapping = m
+	return
+}
+
+// Close releases the mapping of a Root returned by DecodeMapped. Close does
+// nothing if the Root was not returned by DecodeMapped.
+func (root *Root) Close() error {
+	if root.mapping == nil {
+		return nil
+	}
+	m := root.mapping
+	root.mapping = nil
+	return m.Close()
+}
+
+// detachClass replaces each string of class with a copy that does not refer
+// to the memory of a mapping.
+func detachClass(class *Class) {
+	class.Name = cloneString(class.Name)
+	class.Superclass = cloneString(class.Superclass)
+	detachTags(class.Tags)
+	for _, member := range class.Members {
+		switch member := member.(type) {
+		case *Property:
+			member.Name = cloneString(member.Name)
+			member.Class = cloneString(member.Class)
+			member.ValueType = Type(cloneString(string(member.ValueType)))
+			detachTags(member.Tags)
+		case *Function:
+			member.Name = cloneString(member.Name)
+			member.Class = cloneString(member.Class)
+			member.ReturnType = Type(cloneString(string(member.ReturnType)))
+			detachParameters(member.Parameters)
+			detachTags(member.Tags)
+		case *Event:
+			member.Name = cloneString(member.Name)
+			member.Class = cloneString(member.Class)
+			detachParameters(member.Parameters)
+			detachTags(member.Tags)
+		case *Callback:
+			member.Name = cloneString(member.Name)
+			member.Class = cloneString(member.Class)
+			member.ReturnType = Type(cloneString(string(member.ReturnType)))
+			detachParameters(member.Parameters)
+			detachTags(member.Tags)
+		}
+	}
+}
+
+// detachEnum replaces each string of enum with a copy that does not refer to
+// the memory of a mapping.
+func detachEnum(enum *Enum) {
+	enum.Name = cloneString(enum.Name)
+	detachTags(enum.Tags)
+	for _, item := range enum.Items {
+		item.Enum = cloneString(item.Enum)
+		item.Name = cloneString(item.Name)
+		detachTags(item.Tags)
+	}
+}
+
+func detachParameters(params []Parameter) {
+	for i := range params {
+		p := &params[i]
+		p.Type = Type(cloneString(string(p.Type)))
+		p.Name = cloneString(p.Name)
+		p.Default = cloneString(p.Default)
+	}
+}
+
+func detachTags(tags Tags) {
+	for i, tag := range tags {
+		tags[i] = cloneString(tag)
+	}
+}
@@ -0,0 +1,43 @@
+package rbxapidump
+
+import (
+	"github.com/karl-police/rbxapi/internal/parallel"
+)
+
+// CopyParallel returns a deep copy of the API structure, like Copy, but
+// copies descriptors across multiple goroutines. workers is the number of
+// goroutines to use. If workers is less than 1, then runtime.GOMAXPROCS(0)
+// is used.
+//
+// As with Copy, the strings of a root returned by DecodeMapped are also
+// copied.
+//
+// Copying in parallel is only beneficial for large structures; a structure
+// with few descriptors is copied serially.
+func (root *Root) CopyParallel(workers int) *Root {
+	croot := &Root{
+		Classes: make([]*Class, len(root.Classes)),
+		Enums:   make([]*Enum, len(root.Enums)),
+	}
+	// Each position is either a class or an enum, so every call writes to a
+	// distinct element of the copied lists.
+	parallel.Range(len(root.Classes)+len(root.Enums), workers, minCopyChunk, func(i int) {
+		if i < len(root.Classes) {
+			croot.Classes[i] = root.Classes[i].Copy().(*Class)
+			if root.mapping != nil {
+				detachClass(croot.Classes[i])
+			}
+		} else {
+			j := i - len(root.Classes)
+			croot.Enums[j] = root.Enums[j].Copy().(*Enum)
+			if root.mapping != nil {
+				detachEnum(croot.Enums[j])
+			}
+		}
+	})
+	return croot
+}
+
+// minCopyChunk is the minimum number of descriptors copied by each goroutine
+// of CopyParallel.
+const minCopyChunk = 64
@@ -0,0 +1,74 @@
+package rbxapidump_test
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/mmap"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCopyMapped(t *testing.T) {
+	var buf bytes.Buffer
+	if err := rbxapidump.Encode(&buf, rbxapiconv.ToDump(apitest.Generate(1, 100, 50))); err != nil {
+		t.Fatal(err)
+	}
+	want, err := rbxapidump.DecodeBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ioutil.TempFile("", "rbxapidump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(buf.Bytes())
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mmap.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := rbxapidump.DecodeMapped(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Copies remain valid after the mapping is closed.
+	copied := root.Copy()
+	parallel := root.CopyParallel(0)
+	if err := root.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range (&diff.Diff{Prev: want, Next: copied}).Diff() {
+		t.Errorf("Copy: unexpected difference: %s", action)
+	}
+	for _, action := range (&diff.Diff{Prev: want, Next: parallel}).Diff() {
+		t.Errorf("CopyParallel: unexpected difference: %s", action)
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	root := rbxapiconv.ToDump(apitest.Root(b))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.Copy()
+	}
+}
+
+func BenchmarkCopyParallel(b *testing.B) {
+	root := rbxapiconv.ToDump(apitest.Root(b))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.CopyParallel(0)
+	}
+}
@@ -1,11 +1,11 @@
 package rbxapidump
 
 import (
-	"bufio"
-	"bytes"
 	"github.com/karl-police/rbxapi"
 	"io"
+	"os"
 	"strconv"
+	"strings"
 )
 
 // SyntaxError indicates that a syntax error occurred while decoding.
@@ -16,62 +16,113 @@ type SyntaxError interface {
 	SyntaxError() (msg string, line int)
 }
 
-// syntaxError implements the SyntaxError interface.
+// ColumnError is implemented by a SyntaxError that also reports the column
+// at which the error occurred. The errors returned by the decoder implement
+// it, which can be checked with a type assertion or errors.As.
+type ColumnError interface {
+	SyntaxError
+	// Column returns the column, in bytes starting at 1, at which the error
+	// occurred within its line.
+	Column() int
+}
+
+// syntaxError implements the SyntaxError and ColumnError interfaces.
 type syntaxError struct {
 	Msg  string
 	Line int
+	Col  int
 }
 
 func (e *syntaxError) Error() string {
-	return "error on line " + strconv.Itoa(e.Line) + ": " + e.Msg
+	return "error on line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Col) + ": " + e.Msg
+}
+
+func (e *syntaxError) SyntaxError() (msg string, line int) {
+	return e.Msg, e.Line
+}
+
+func (e *syntaxError) Column() int {
+	return e.Col
 }
 
+// decoder parses an API dump from a string. Decoded strings are sliced
+// directly from the input, so that they share its memory rather than being
+// allocated separately.
 type decoder struct {
+	arena *Arena
 	root  *Root
-	r     io.ByteReader
-	next  []byte
-	buf   bytes.Buffer
-	n     int64
+	data  string
+	pos   int
 	err   error
 	line  int
 	class *Class
 	enum  *Enum
+
+	// lenient is whether syntax errors are collected into warnings rather
+	// than stopping the decoder.
+	lenient  bool
+	warnings []SyntaxError
+}
+
+// readAll reads r until EOF. When the size of the content of r is known, as
+// with a bytes.Reader or a file, the content is read into a single
+// allocation of that size.
+func readAll(r io.Reader) (string, error) {
+	var b strings.Builder
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		b.Grow(r.Len())
+	case *os.File:
+		if info, err := r.Stat(); err == nil {
+			if pos, err := r.Seek(0, io.SeekCurrent); err == nil && info.Size() > pos {
+				b.Grow(int(info.Size() - pos))
+			}
+		}
+	}
+	_, err := io.Copy(&b, r)
+	return b.String(), err
 }
 
-// Creates a syntaxError with the current line number.
+// newDecoder returns a decoder that parses data.
+func newDecoder(data string, arena *Arena) *decoder {
+	return &decoder{
+		arena: arena,
+		root:  arena.newRoot(),
+		data:  data,
+		line:  1,
+	}
+}
+
+// Creates a syntaxError with the current line and column numbers.
 func (d *decoder) syntaxError(msg string) {
 	if d.err != nil && d.err != io.EOF {
 		return
 	}
-	d.err = &syntaxError{Msg: msg, Line: d.line}
+	d.err = &syntaxError{Msg: msg, Line: d.line, Col: d.pos - strings.LastIndexByte(d.data[:d.pos], '\n')}
 }
 
 func (d *decoder) getc() (b byte, ok bool) {
 	if d.err != nil {
 		return 0, false
 	}
-
-	if len(d.next) > 0 {
-		b, d.next = d.next[len(d.next)-1], d.next[:len(d.next)-1]
-	} else {
-		b, d.err = d.r.ReadByte()
-		if d.err != nil {
-			return 0, false
-		}
-		d.n++
+	if d.pos >= len(d.data) {
+		d.err = io.EOF
+		return 0, false
 	}
+	b = d.data[d.pos]
+	d.pos++
 	if b == '\n' {
 		d.line++
 	}
-
 	return b, true
 }
 
+// Unreads the last byte returned by getc.
 func (d *decoder) ungetc(b byte) {
 	if b == '\n' {
 		d.line--
 	}
-	d.next = append(d.next, b)
+	d.pos--
 }
 
 // Get the next character and compare it. Unget if the comparison fails.
@@ -93,6 +144,8 @@ func (d *decoder) expectChar(c byte) {
 		return
 	}
 	if b != c {
+		// Unread the byte so that the error refers to it.
+		d.ungetc(b)
 		d.syntaxError("expected '" + string(c) + "'")
 	}
 }
@@ -104,34 +157,29 @@ func (d *decoder) decodeChars(check charCheck) string {
 	if d.err != nil {
 		return ""
 	}
-	d.buf.Reset()
+	start := d.pos
 	width := 0
 	for {
 		b, ok := d.getc()
 		if !ok {
-			goto finish
+			break
 		}
 		if !check.isChar(b) {
 			d.ungetc(b)
-			goto finish
+			break
 		}
 		if b == ' ' {
 			width++
 		} else {
 			width = 0
 		}
-		d.buf.WriteByte(b)
 	}
-finish:
-	b := d.buf.Bytes()
 	if check.nofix {
-		// Remove trailing spaces.
-		for i := 0; i < width; i++ {
-			d.ungetc(b[len(b)-1-i])
-		}
-		b = b[:len(b)-width]
+		// Unread trailing spaces. Spaces never include a newline, so the
+		// line number is unaffected.
+		d.pos -= width
 	}
-	return string(b)
+	return d.data[start:d.pos]
 }
 
 // Decode characters from the given balanced brackets. Assumes the first
@@ -141,32 +189,32 @@ func (d *decoder) decodeNested(openChar, closeChar byte) string {
 	if d.err != nil {
 		return ""
 	}
-	d.buf.Reset()
+	start := d.pos
+	end := start
 	width := 0
 	for depth := 1; ; {
 		b, ok := d.getc()
 		if !ok {
-			goto finish
+			end = d.pos
+			break
 		}
 		switch b {
 		case openChar:
 			depth++
 		case closeChar:
 			depth--
-			if depth <= 0 {
-				goto finish
-			}
+		}
+		if depth <= 0 {
+			end = d.pos - 1
+			break
 		}
 		if b == ' ' {
 			width++
 		} else {
 			width = 0
 		}
-		d.buf.WriteByte(b)
 	}
-finish:
-	b := d.buf.Bytes()
-	return string(b[:len(b)-width])
+	return d.data[start : end-width]
 }
 
 func (d *decoder) expectChars(check charCheck, msg string) (s string) {
@@ -282,12 +330,16 @@ func (d *decoder) decode() error {
 	}
 
 	for d.err == nil {
+		line := d.line
 		d.decodeItem()
 		// Skip over whitespace between items. Expect at least one EOL, but
 		// only if we aren't at EOF.
 		if !d.decodeLine() && d.err != io.EOF {
 			d.syntaxError("expected end-of-line")
 		}
+		if d.lenient && d.err != nil && d.err != io.EOF {
+			d.skipItem(line)
+		}
 	}
 	if d.err != io.EOF {
 		return d.err
@@ -295,6 +347,20 @@ func (d *decoder) decode() error {
 	return nil
 }
 
+// Records the current error as a warning, then skips the remainder of the
+// item that began on the given line. The item is discarded if it had not yet
+// been added.
+func (d *decoder) skipItem(line int) {
+	d.warnings = append(d.warnings, d.err.(SyntaxError))
+	d.err = nil
+	for d.line == line {
+		if _, ok := d.getc(); !ok {
+			return
+		}
+	}
+	d.decodeLine()
+}
+
 // Skips any whitespace and lines. Returns whether at least one line was
 // decoded.
 func (d *decoder) decodeLine() (line bool) {
@@ -346,7 +412,7 @@ func (d *decoder) decodeItem() {
 
 func (d *decoder) decodeClass() {
 	d.clearParent()
-	var class Class
+	class := d.arena.newClass()
 	class.Name = d.expectChars(isClassName, "class name")
 	d.skipWhitespace()
 	if d.checkChar(':') {
@@ -355,11 +421,11 @@ func (d *decoder) decodeClass() {
 		d.skipWhitespace()
 	}
 	d.decodeTags(&class.Tags)
-	d.addClass(&class)
+	d.addClass(class)
 }
 
 func (d *decoder) decodeProperty() {
-	var member Property
+	member := d.arena.newProperty()
 	member.ValueType = Type(d.expectChars(isType, "value type"))
 	d.expectWhitespace()
 	member.Class = d.expectChars(isClassName, "member class")
@@ -370,11 +436,11 @@ func (d *decoder) decodeProperty() {
 	member.Name = d.expectChars(isMemberName, "member name")
 	d.skipWhitespace()
 	d.decodeTags(&member.Tags)
-	d.addMember(&member)
+	d.addMember(member)
 }
 
 func (d *decoder) decodeFunction(yields bool) {
-	var member Function
+	member := d.arena.newFunction()
 	member.ReturnType = Type(d.expectChars(isType, "return type"))
 	d.expectWhitespace()
 	member.Class = d.expectChars(isClassName, "member class")
@@ -392,11 +458,11 @@ func (d *decoder) decodeFunction(yields bool) {
 	} else {
 		member.Tags.UnsetTag("Yields")
 	}
-	d.addMember(&member)
+	d.addMember(member)
 }
 
 func (d *decoder) decodeEvent() {
-	var member Event
+	member := d.arena.newEvent()
 	member.Class = d.expectChars(isClassName, "member class")
 	d.expectClass(member.Class)
 	d.expectChar('.')
@@ -405,11 +471,11 @@ func (d *decoder) decodeEvent() {
 	member.Parameters = d.decodeParameters(false)
 	d.skipWhitespace()
 	d.decodeTags(&member.Tags)
-	d.addMember(&member)
+	d.addMember(member)
 }
 
 func (d *decoder) decodeCallback() {
-	var member Callback
+	member := d.arena.newCallback()
 	member.ReturnType = Type(d.expectChars(isType, "return type"))
 	d.expectWhitespace()
 	member.Class = d.expectChars(isClassName, "member class")
@@ -422,7 +488,7 @@ func (d *decoder) decodeCallback() {
 	member.Parameters = d.decodeParameters(false)
 	d.skipWhitespace()
 	d.decodeTags(&member.Tags)
-	d.addMember(&member)
+	d.addMember(member)
 }
 
 func (d *decoder) decodeParameters(canDefault bool) (params []Parameter) {
@@ -469,15 +535,15 @@ func (d *decoder) decodeDefault() string {
 }
 
 func (d *decoder) decodeEnum() {
-	var enum Enum
+	enum := d.arena.newEnum()
 	enum.Name = d.expectChars(isEnumName, "enum name")
 	d.skipWhitespace()
 	d.decodeTags(&enum.Tags)
-	d.addEnum(&enum)
+	d.addEnum(enum)
 }
 
 func (d *decoder) decodeEnumItem() {
-	var item EnumItem
+	item := d.arena.newEnumItem()
 	item.Enum = d.expectChars(isEnumName, "enum name")
 	d.expectEnum(item.Enum)
 	d.skipWhitespace()
@@ -490,7 +556,7 @@ func (d *decoder) decodeEnumItem() {
 	item.Value = d.expectInt()
 	d.skipWhitespace()
 	d.decodeTags(&item.Tags)
-	d.addEnumItem(&item)
+	d.addEnumItem(item)
 }
 
 func (d *decoder) decodeTags(tags *Tags) {
@@ -506,18 +572,34 @@ func (d *decoder) decodeTag() string {
 }
 
 // Decode parses an API dump from r.
+//
+// The content of r is read into a single string, of which every decoded
+// string is a part. The content is retained for as long as any decoded
+// string is in use.
 func Decode(r io.Reader) (root *Root, err error) {
-	br, ok := r.(io.ByteReader)
-	if !ok {
-		br = bufio.NewReader(r)
-	}
-	d := decoder{
-		root: &Root{},
-		r:    br,
-		next: make([]byte, 0, 9),
-		line: 1,
+	return DecodeArena(r, nil)
+}
+
+// DecodeOptions configures the handling of malformed input by
+// DecodeWithOptions.
+type DecodeOptions struct {
+	// Strict causes decoding to fail at the first syntax error. Otherwise,
+	// each line containing a syntax error is skipped, the error is collected
+	// as a warning, and decoding continues with the next line.
+	Strict bool
+}
+
+// DecodeWithOptions parses an API dump from r according to opts. A nil opts
+// decodes strictly, as Decode does. In lenient mode, the syntax errors of
+// skipped lines are returned as warnings, in the order they occurred, and err
+// is non-nil only if r could not be read.
+func DecodeWithOptions(r io.Reader, opts *DecodeOptions) (root *Root, warnings []SyntaxError, err error) {
+	data, err := readAll(r)
+	if err != nil {
+		return nil, nil, err
 	}
+	d := newDecoder(data, nil)
+	d.lenient = opts != nil && !opts.Strict
 	err = d.decode()
-	root = d.root
-	return
+	return d.root, d.warnings, err
 }
@@ -0,0 +1,70 @@
+package rbxapidump_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"testing"
+)
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	const text = "Class Part : BasePart\n\tProperty bool Part.Anchored\n\tBogus Part.Name\n"
+	_, err := rbxapidump.Decode(bytes.NewReader([]byte(text)))
+	var serr rbxapidump.ColumnError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected ColumnError, got %v", err)
+	}
+	// The error is detected after the unknown item type has been read.
+	if _, line := serr.SyntaxError(); line != 3 || serr.Column() != 8 {
+		t.Errorf("error at line %d, column %d; expected line 3, column 8: %s", line, serr.Column(), err)
+	}
+}
+
+// dumpText returns the text of a dump the size of a real build.
+func dumpText(b *testing.B) []byte {
+	var buf bytes.Buffer
+	if err := rbxapidump.Encode(&buf, rbxapiconv.ToDump(apitest.Root(b))); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkDecode(b *testing.B) {
+	text := dumpText(b)
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rbxapidump.Decode(bytes.NewReader(text)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	text := dumpText(b)
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rbxapidump.DecodeBytes(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodePooled(b *testing.B) {
+	text := dumpText(b)
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root, err := rbxapidump.DecodePooled(bytes.NewReader(text))
+		if err != nil {
+			b.Fatal(err)
+		}
+		root.Release()
+	}
+}
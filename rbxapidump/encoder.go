@@ -5,17 +5,20 @@ import (
 	"errors"
 	"github.com/karl-police/rbxapi"
 	"io"
+	"sort"
 	"strconv"
 )
 
 type encoder struct {
-	w      *bufio.Writer
-	root   *Root
-	n      int64
-	err    error
-	line   string
-	indent string
-	prefix string
+	w           *bufio.Writer
+	root        *Root
+	n           int64
+	err         error
+	line        string
+	indent      string
+	prefix      string
+	sortTags    bool
+	sortMembers bool
 }
 
 func (e *encoder) setError(msg string) {
@@ -129,7 +132,14 @@ func (e *encoder) encodeClass(class *Class) {
 	e.encodeTags(class.Tags)
 	e.writeString(e.line)
 
-	for _, member := range class.Members {
+	members := class.Members
+	if e.sortMembers {
+		members = append([]rbxapi.Member(nil), members...)
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].GetName() < members[j].GetName()
+		})
+	}
+	for _, member := range members {
 		e.encodeMember(class, member)
 		if e.err != nil {
 			return
@@ -251,6 +261,10 @@ func (e *encoder) encodeEnumItem(enum *Enum, item *EnumItem) {
 }
 
 func (e *encoder) encodeTags(tags Tags, exclude ...string) {
+	if e.sortTags {
+		tags = append(Tags(nil), tags...)
+		sort.Strings(tags)
+	}
 loop:
 	for _, tag := range tags {
 		for _, ex := range exclude {
@@ -288,14 +302,47 @@ func (e *encoder) encodeTag(tag string) {
 	e.writeString("]")
 }
 
+// EncodeOptions configures the formatting of an encoded API dump.
+type EncodeOptions struct {
+	// Indent is written before each member and enum item. If empty, a
+	// single tab is used.
+	Indent string
+	// LineEnding is written after each line. If empty, "\n" is used.
+	LineEnding string
+	// SortTags causes the tags of each descriptor to be written in sorted
+	// order, rather than in their original order.
+	SortTags bool
+	// SortMembers causes the members of each class to be written sorted by
+	// name, rather than in their original order.
+	SortMembers bool
+}
+
 // Encode encodes root, writing the results to w in the API dump format.
 func Encode(w io.Writer, root *Root) (err error) {
+	return EncodeWithOptions(w, root, nil)
+}
+
+// EncodeWithOptions encodes root, writing the results to w in the API dump
+// format, formatted according to opts. A nil opts is equivalent to an empty
+// EncodeOptions, which produces the same output as Encode.
+func EncodeWithOptions(w io.Writer, root *Root, opts *EncodeOptions) (err error) {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
 	e := &encoder{
-		w:      bufio.NewWriter(w),
-		root:   root,
-		prefix: "",
-		indent: "\t",
-		line:   "\n",
+		w:           bufio.NewWriter(w),
+		root:        root,
+		prefix:      "",
+		indent:      opts.Indent,
+		line:        opts.LineEnding,
+		sortTags:    opts.SortTags,
+		sortMembers: opts.SortMembers,
+	}
+	if e.indent == "" {
+		e.indent = "\t"
+	}
+	if e.line == "" {
+		e.line = "\n"
 	}
 	_, err = e.encode()
 	return err
@@ -0,0 +1,68 @@
+package rbxapidump
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/internal/order"
+	"sort"
+)
+
+// Normalize sorts the descriptors of the API structure into a canonical
+// order, producing deterministic output regardless of the original order.
+//
+// Classes are ordered so that each class follows its superclass, with the
+// subclasses of a class ordered by name. Members are ordered by member type,
+// then by name. Enums are ordered by name, and enum items are ordered by
+// value, then by name. Tags are sorted, and duplicate tags are removed.
+func (root *Root) Normalize() {
+	for i := range root.Classes {
+		class := root.ownClass(i)
+		class.Tags = Tags(order.Tags(class.Tags))
+		for _, member := range class.Members {
+			normalizeMember(member)
+		}
+		sort.SliceStable(class.Members, func(i, j int) bool {
+			a, b := class.Members[i], class.Members[j]
+			return order.MemberLess(a.GetMemberType(), a.GetName(), b.GetMemberType(), b.GetName())
+		})
+	}
+	classes := make([]*Class, 0, len(root.Classes))
+	for _, i := range order.Classes(len(root.Classes),
+		func(i int) string { return root.Classes[i].Name },
+		func(i int) string { return root.Classes[i].Superclass },
+	) {
+		classes = append(classes, root.Classes[i])
+	}
+	copy(root.Classes, classes)
+
+	for i := range root.Enums {
+		enum := root.ownEnum(i)
+		enum.Tags = Tags(order.Tags(enum.Tags))
+		for _, item := range enum.Items {
+			item.Tags = Tags(order.Tags(item.Tags))
+		}
+		sort.SliceStable(enum.Items, func(i, j int) bool {
+			a, b := enum.Items[i], enum.Items[j]
+			if a.Value != b.Value {
+				return a.Value < b.Value
+			}
+			return a.Name < b.Name
+		})
+	}
+	sort.SliceStable(root.Enums, func(i, j int) bool {
+		return root.Enums[i].Name < root.Enums[j].Name
+	})
+}
+
+// normalizeMember sorts the tags of a member.
+func normalizeMember(member rbxapi.Member) {
+	switch member := member.(type) {
+	case *Property:
+		member.Tags = Tags(order.Tags(member.Tags))
+	case *Function:
+		member.Tags = Tags(order.Tags(member.Tags))
+	case *Event:
+		member.Tags = Tags(order.Tags(member.Tags))
+	case *Callback:
+		member.Tags = Tags(order.Tags(member.Tags))
+	}
+}
@@ -2,9 +2,115 @@ package rbxapidump
 
 import (
 	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/internal/index"
 	"github.com/karl-police/rbxapi/patch"
 )
 
+// setTagWhere replaces the first tag for which match returns true with tag,
+// removing any other matching tags. If tag is empty, all matching tags are
+// removed. If no tag matches, tag is appended.
+func (tags *Tags) setTagWhere(tag string, match func(tag string) bool) {
+	list := (*tags)[:0]
+	set := tag == ""
+	for _, t := range *tags {
+		if !match(t) {
+			list = append(list, t)
+			continue
+		}
+		if !set {
+			list = append(list, tag)
+			set = true
+		}
+	}
+	if !set {
+		list = append(list, tag)
+	}
+	*tags = list
+}
+
+// isSecurity returns whether a tag names a security context, including the
+// write security of a property.
+func isSecurity(tag string) bool {
+	security, write := ParseSecurityTag(tag)
+	return security != "" || write
+}
+
+// isReadSecurity returns whether a tag names the security context of a
+// member, other than the write security of a property.
+func isReadSecurity(tag string) bool {
+	security, write := ParseSecurityTag(tag)
+	return security != "" && !write
+}
+
+// isWriteSecurity returns whether a tag names the write security of a
+// property.
+func isWriteSecurity(tag string) bool {
+	_, write := ParseSecurityTag(tag)
+	return write
+}
+
+// setSecurity sets the tag naming the security context of a member. The dump
+// format represents the absence of a security context by omitting the tag,
+// so the tag is removed if security is empty or "None".
+func (tags *Tags) setSecurity(security string) {
+	if security == "None" {
+		security = ""
+	}
+	tags.setTagWhere(security, isReadSecurity)
+}
+
+// setSecurities sets the tags naming the read and write security of a
+// property. The dump format omits the write security if it is the same as the
+// read security, so the write tag is removed in that case.
+func (tags *Tags) setSecurities(read, write string) {
+	if read == "None" {
+		read = ""
+	}
+	if write == "None" || write == read {
+		write = ""
+	}
+	tags.setSecurity(read)
+	if write != "" {
+		write = WriteSecurityTag(write)
+	}
+	tags.setTagWhere(write, isWriteSecurity)
+}
+
+// dumpMember returns whether a member action refers to a member of the dump
+// format, whose tags are the sole representation of its security contexts.
+func dumpMember(action patch.Action) bool {
+	if action, ok := action.(patch.Member); ok {
+		switch action.GetMember().(type) {
+		case *Property, *Function, *Event, *Callback:
+			return true
+		}
+	}
+	return false
+}
+
+// setMemberTags replaces the tags of a member. If dump is false, the tags
+// come from a format that represents security contexts separately, and name
+// no security context, in which case the current security tags are kept.
+func (tags *Tags) setMemberTags(next []string, dump bool) {
+	if dump {
+		*tags = Tags(Tags(next).GetTags())
+		return
+	}
+	for _, tag := range next {
+		if isSecurity(tag) {
+			*tags = Tags(Tags(next).GetTags())
+			return
+		}
+	}
+	list := Tags{}
+	for _, tag := range *tags {
+		if isSecurity(tag) {
+			list = append(list, tag)
+		}
+	}
+	*tags = append(list, next...)
+}
+
 // copyClass returns a deep copy of a generic rbxapi.Class.
 func copyClass(class rbxapi.Class) *Class {
 	members := class.GetMembers()
@@ -36,35 +142,43 @@ func copyClass(class rbxapi.Class) *Class {
 func copyMember(member rbxapi.Member) rbxapi.Member {
 	switch member := member.(type) {
 	case rbxapi.Property:
-		return &Property{
+		m := &Property{
 			Name:      member.GetName(),
 			ValueType: copyType(member.GetValueType()),
 			Tags:      Tags(member.GetTags()),
 		}
+		m.Tags.setSecurities(member.GetSecurity())
+		return m
 	case rbxapi.Function:
 		// Function and Callback have the same methods.
 		switch member.GetMemberType() {
 		case "Function":
-			return &Function{
+			m := &Function{
 				Name:       member.GetName(),
 				ReturnType: copyType(member.GetReturnType()),
 				Parameters: copyParameters(member.GetParameters()),
 				Tags:       Tags(member.GetTags()),
 			}
+			m.Tags.setSecurity(member.GetSecurity())
+			return m
 		case "Callback":
-			return &Callback{
+			m := &Callback{
 				Name:       member.GetName(),
 				ReturnType: copyType(member.GetReturnType()),
 				Parameters: copyParameters(member.GetParameters()),
 				Tags:       Tags(member.GetTags()),
 			}
+			m.Tags.setSecurity(member.GetSecurity())
+			return m
 		}
 	case rbxapi.Event:
-		return &Event{
+		m := &Event{
 			Name:       member.GetName(),
 			Parameters: copyParameters(member.GetParameters()),
 			Tags:       Tags(member.GetTags()),
 		}
+		m.Tags.setSecurity(member.GetSecurity())
+		return m
 	}
 	return nil
 }
@@ -117,42 +231,50 @@ func copyType(typ rbxapi.Type) Type {
 
 // Patch transforms the API structure by applying a list of patch actions.
 //
+// Changes to the security of a member are applied to its security tags.
+// Changes to fields that have no representation in the dump format, such as
+// the memory category of a class, or the category, serialization, and
+// default value of a property, are ignored.
+//
 // Patch implements the patch.Patcher interface.
 func (root *Root) Patch(actions []patch.Action) {
+	// Removed descriptors are set to nil, and the lists are compacted after
+	// all actions are applied, so that positions within the indexes remain
+	// valid.
+	classes := index.New(len(root.Classes), func(i int) string { return root.Classes[i].Name })
+	enums := index.New(len(root.Enums), func(i int) string { return root.Enums[i].Name })
+	removed := false
 	for i, action := range actions {
 		if action, ok := action.(patch.Member); ok {
 			if aclass, amember := (action.GetClass()), (action.GetMember()); aclass != nil && amember != nil {
-				name := aclass.GetName()
-				for _, class := range root.Classes {
-					if class.Name == name {
-						class.Patch(actions[i : i+1])
-						break
-					}
+				if j := classes.Get(aclass.GetName()); j >= 0 {
+					root.ownClass(j).Patch(actions[i : i+1])
 				}
 				continue
 			}
 		}
 		if action, ok := action.(patch.Class); ok {
 			if aclass := action.GetClass(); aclass != nil {
+				name := aclass.GetName()
 				switch action.GetType() {
 				case patch.Remove:
-					name := aclass.GetName()
-					for i, class := range root.Classes {
-						if class.Name == name {
-							copy(root.Classes[i:], root.Classes[i+1:])
-							root.Classes[len(root.Classes)-1] = nil
-							root.Classes = root.Classes[:len(root.Classes)-1]
-							break
-						}
+					if j := classes.Get(name); j >= 0 {
+						root.Classes[j] = nil
+						classes.Remove(name)
+						removed = true
 					}
 				case patch.Add:
-					root.Classes = append(root.Classes, copyClass(aclass))
+					class := copyClass(aclass)
+					classes.Add(class.Name, len(root.Classes))
+					root.Classes = append(root.Classes, class)
+					root.markOwned(class)
 				case patch.Change:
-					name := aclass.GetName()
-					for _, class := range root.Classes {
-						if class.Name == name {
-							class.Patch(actions[i : i+1])
-							break
+					if j := classes.Get(name); j >= 0 {
+						class := root.ownClass(j)
+						class.Patch(actions[i : i+1])
+						if class.Name != name {
+							classes.Remove(name)
+							classes.Add(class.Name, j)
 						}
 					}
 				}
@@ -161,37 +283,34 @@ func (root *Root) Patch(actions []patch.Action) {
 		}
 		if action, ok := action.(patch.EnumItem); ok {
 			if aenum, aitem := (action.GetEnum()), (action.GetEnumItem()); aenum != nil && aitem != nil {
-				name := aenum.GetName()
-				for _, enum := range root.Enums {
-					if enum.Name == name {
-						enum.Patch(actions[i : i+1])
-						break
-					}
+				if j := enums.Get(aenum.GetName()); j >= 0 {
+					root.ownEnum(j).Patch(actions[i : i+1])
 				}
 				continue
 			}
 		}
 		if action, ok := action.(patch.Enum); ok {
 			if aenum := action.GetEnum(); aenum != nil {
+				name := aenum.GetName()
 				switch action.GetType() {
 				case patch.Remove:
-					name := aenum.GetName()
-					for i, enum := range root.Enums {
-						if enum.Name == name {
-							copy(root.Enums[i:], root.Enums[i+1:])
-							root.Enums[len(root.Enums)-1] = nil
-							root.Enums = root.Enums[:len(root.Enums)-1]
-							break
-						}
+					if j := enums.Get(name); j >= 0 {
+						root.Enums[j] = nil
+						enums.Remove(name)
+						removed = true
 					}
 				case patch.Add:
-					root.Enums = append(root.Enums, copyEnum(aenum))
+					enum := copyEnum(aenum)
+					enums.Add(enum.Name, len(root.Enums))
+					root.Enums = append(root.Enums, enum)
+					root.markOwned(enum)
 				case patch.Change:
-					name := aenum.GetName()
-					for _, enum := range root.Enums {
-						if enum.Name == name {
-							enum.Patch(actions[i : i+1])
-							break
+					if j := enums.Get(name); j >= 0 {
+						enum := root.ownEnum(j)
+						enum.Patch(actions[i : i+1])
+						if enum.Name != name {
+							enums.Remove(name)
+							enums.Add(enum.Name, j)
 						}
 					}
 				}
@@ -199,6 +318,34 @@ func (root *Root) Patch(actions []patch.Action) {
 			}
 		}
 	}
+	if removed {
+		root.compact()
+	}
+}
+
+// compact removes nil descriptors from the lists of the root, preserving the
+// order of the remaining descriptors.
+func (root *Root) compact() {
+	classes := root.Classes[:0]
+	for _, class := range root.Classes {
+		if class != nil {
+			classes = append(classes, class)
+		}
+	}
+	for i := len(classes); i < len(root.Classes); i++ {
+		root.Classes[i] = nil
+	}
+	root.Classes = classes
+	enums := root.Enums[:0]
+	for _, enum := range root.Enums {
+		if enum != nil {
+			enums = append(enums, enum)
+		}
+	}
+	for i := len(enums); i < len(root.Enums); i++ {
+		root.Enums[i] = nil
+	}
+	root.Enums = enums
 }
 
 func (class *Class) Patch(actions []patch.Action) {
@@ -277,9 +424,26 @@ func (member *Property) Patch(actions []patch.Action) {
 			case string:
 				member.ValueType = Type(v)
 			}
+		case "ReadSecurity":
+			if v, ok := action.GetNext().(string); ok {
+				// An omitted write security is the same as the read
+				// security. It is kept, unless the action comes from a
+				// dump, where it remains omitted, following the read
+				// security.
+				read, write := member.GetSecurity()
+				if write == "" && !dumpMember(action) {
+					write = read
+				}
+				member.Tags.setSecurities(v, write)
+			}
+		case "WriteSecurity":
+			if v, ok := action.GetNext().(string); ok {
+				read, _ := member.GetSecurity()
+				member.Tags.setSecurities(read, v)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
-				member.Tags = Tags(Tags(v).GetTags())
+				member.Tags.setMemberTags(v, dumpMember(action))
 			}
 		}
 	}
@@ -306,9 +470,13 @@ func (member *Function) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(rbxapi.Parameters); ok {
 				member.Parameters = copyParameters(v)
 			}
+		case "Security":
+			if v, ok := action.GetNext().(string); ok {
+				member.Tags.setSecurity(v)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
-				member.Tags = Tags(Tags(v).GetTags())
+				member.Tags.setMemberTags(v, dumpMember(action))
 			}
 		}
 	}
@@ -328,9 +496,13 @@ func (member *Event) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(rbxapi.Parameters); ok {
 				member.Parameters = copyParameters(v)
 			}
+		case "Security":
+			if v, ok := action.GetNext().(string); ok {
+				member.Tags.setSecurity(v)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
-				member.Tags = Tags(Tags(v).GetTags())
+				member.Tags.setMemberTags(v, dumpMember(action))
 			}
 		}
 	}
@@ -357,9 +529,13 @@ func (member *Callback) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(rbxapi.Parameters); ok {
 				member.Parameters = copyParameters(v)
 			}
+		case "Security":
+			if v, ok := action.GetNext().(string); ok {
+				member.Tags.setSecurity(v)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
-				member.Tags = Tags(Tags(v).GetTags())
+				member.Tags.setMemberTags(v, dumpMember(action))
 			}
 		}
 	}
@@ -0,0 +1,101 @@
+package rbxapidump_test
+
+import (
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"testing"
+)
+
+// builds returns the structures of two consecutive builds in the dump
+// format.
+func builds(b *testing.B) (prev, next *rbxapidump.Root) {
+	jprev, jnext := apitest.Builds(b)
+	return rbxapiconv.ToDump(jprev), rbxapiconv.ToDump(jnext)
+}
+
+func TestPatch(t *testing.T) {
+	jprev := apitest.Generate(1, 100, 50)
+	jnext := apitest.Evolve(jprev, 2)
+	prev, next := rbxapiconv.ToDump(jprev), rbxapiconv.ToDump(jnext)
+	root := prev.Copy().(*rbxapidump.Root)
+	root.Patch((&diff.Diff{Prev: prev, Next: next}).Diff())
+	for _, action := range (&diff.Diff{Prev: root, Next: next}).Diff() {
+		t.Errorf("remaining: %s", action)
+	}
+}
+
+// TestPatchJSON applies actions between structures of the rbxapijson package
+// to a structure of the dump format. As the formats represent tags, types,
+// and security differently, the result is compared after conversion to the
+// JSON format.
+func TestPatchJSON(t *testing.T) {
+	jprev := apitest.Generate(1, 100, 50)
+	jnext := apitest.Evolve(jprev, 2)
+	root := rbxapiconv.ToDump(jprev)
+	root.Patch((&rbxapijson.Diff{Prev: jprev, Next: jnext}).Diff())
+	want := rbxapiconv.ToJSON(rbxapiconv.ToDump(jnext))
+	for _, action := range (&diff.Diff{Prev: rbxapiconv.ToJSON(root), Next: want}).Diff() {
+		t.Errorf("remaining: %s", action)
+	}
+}
+
+// TestPatchAddSecurity adds members from the JSON format, whose security
+// contexts become tags. The write security is omitted when it is the same as
+// the read security, as it is in a dump.
+func TestPatchAddSecurity(t *testing.T) {
+	class := &rbxapijson.Class{Name: "Part", Superclass: "<<<ROOT>>>", Members: []rbxapi.Member{
+		&rbxapijson.Property{Name: "Same", ValueType: rbxapijson.Type{Category: "Primitive", Name: "bool"}, ReadSecurity: "PluginSecurity", WriteSecurity: "PluginSecurity", Tags: rbxapijson.Tags{"ReadOnly"}},
+		&rbxapijson.Property{Name: "Differ", ValueType: rbxapijson.Type{Category: "Primitive", Name: "bool"}, ReadSecurity: "None", WriteSecurity: "PluginSecurity"},
+		&rbxapijson.Function{Name: "None", ReturnType: rbxapijson.Type{Category: "Primitive", Name: "void"}, Security: "None"},
+	}}
+	root := &rbxapidump.Root{}
+	root.Patch([]patch.Action{&diff.ClassAction{Type: patch.Add, Class: class}})
+	for name, want := range map[string]string{
+		"Same":   "[ReadOnly PluginSecurity]",
+		"Differ": "[ScriptWriteRestricted: [PluginSecurity]]",
+		"None":   "[]",
+	} {
+		if got := fmt.Sprint(root.GetClass("Part").GetMember(name).GetTags()); got != want {
+			t.Errorf("%s: got tags %s, expected %s", name, got, want)
+		}
+	}
+}
+
+func BenchmarkPatch(b *testing.B) {
+	prev, next := builds(b)
+	actions := (&diff.Diff{Prev: prev, Next: next}).Diff()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		root := prev.Copy().(*rbxapidump.Root)
+		b.StartTimer()
+		root.Patch(actions)
+	}
+}
+
+// BenchmarkPatchRemoveMembers removes every member, as when filtering a
+// build, which applies many more actions than a diff between builds.
+func BenchmarkPatchRemoveMembers(b *testing.B) {
+	root, _ := builds(b)
+	var actions []patch.Action
+	for _, class := range root.Classes {
+		for _, member := range class.Members {
+			actions = append(actions, &diff.MemberAction{Type: patch.Remove, Class: class, Member: member})
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		r := root.Copy().(*rbxapidump.Root)
+		b.StartTimer()
+		r.Patch(actions)
+	}
+}
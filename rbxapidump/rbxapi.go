@@ -7,7 +7,9 @@ package rbxapidump
 
 import (
 	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/mmap"
 	"strings"
+	"sync"
 )
 
 // Root represents the top-level structure of the API dump.
@@ -16,6 +18,16 @@ type Root struct {
 	Classes []*Class
 	// Enums is the list of enum descriptors present in the API.
 	Enums []*Enum
+
+	// arena is the pooled Arena from which the descriptors were allocated.
+	arena *Arena
+	// mapping is the mapped input to which decoded strings refer.
+	mapping *mmap.Mapping
+	// owned is the set of descriptors owned by the root, or nil if the root
+	// does not share descriptors with other roots.
+	owned map[interface{}]bool
+	// shareMu guards owned against concurrent calls to CopyShared.
+	shareMu sync.Mutex
 }
 
 // GetClasses returns a list of class descriptors present in the API.
@@ -29,6 +41,18 @@ func (root *Root) GetClasses() []rbxapi.Class {
 	return list
 }
 
+// RangeClasses calls fn for each class descriptor present in the API.
+// Iteration stops if fn returns false.
+//
+// RangeClasses implements the rbxapi.ClassRanger interface.
+func (root *Root) RangeClasses(fn func(class rbxapi.Class) bool) {
+	for _, class := range root.Classes {
+		if !fn(class) {
+			return
+		}
+	}
+}
+
 // GetClass returns the first class descriptor of the given name, or nil if no
 // class of the given name is present.
 //
@@ -53,6 +77,18 @@ func (root *Root) GetEnums() []rbxapi.Enum {
 	return list
 }
 
+// RangeEnums calls fn for each enum descriptor present in the API. Iteration
+// stops if fn returns false.
+//
+// RangeEnums implements the rbxapi.EnumRanger interface.
+func (root *Root) RangeEnums(fn func(enum rbxapi.Enum) bool) {
+	for _, enum := range root.Enums {
+		if !fn(enum) {
+			return
+		}
+	}
+}
+
 // GetEnum returns the first enum descriptor of the given name, or nil if no
 // enum of the given name is present.
 //
@@ -66,7 +102,9 @@ func (root *Root) GetEnum(name string) rbxapi.Enum {
 	return nil
 }
 
-// Copy returns a deep copy of the API structure.
+// Copy returns a deep copy of the API structure. If root was returned by
+// DecodeMapped, the strings of the copy are also copied, so that the copy
+// remains valid after root is closed.
 //
 // Copy implements the rbxapi.Root interface.
 func (root *Root) Copy() rbxapi.Root {
@@ -76,9 +114,15 @@ func (root *Root) Copy() rbxapi.Root {
 	}
 	for i, class := range root.Classes {
 		croot.Classes[i] = class.Copy().(*Class)
+		if root.mapping != nil {
+			detachClass(croot.Classes[i])
+		}
 	}
 	for i, enum := range root.Enums {
 		croot.Enums[i] = enum.Copy().(*Enum)
+		if root.mapping != nil {
+			detachEnum(croot.Enums[i])
+		}
 	}
 	return croot
 }
@@ -114,6 +158,18 @@ func (class *Class) GetMembers() []rbxapi.Member {
 	return list
 }
 
+// RangeMembers calls fn for each member descriptor belonging to the class.
+// Iteration stops if fn returns false.
+//
+// RangeMembers implements the rbxapi.MemberRanger interface.
+func (class *Class) RangeMembers(fn func(member rbxapi.Member) bool) {
+	for _, member := range class.Members {
+		if !fn(member) {
+			return
+		}
+	}
+}
+
 // GetMember returns the first member descriptor of the given name, or nil if
 // no member of the given name is present.
 //
@@ -140,11 +196,39 @@ func (class *Class) Copy() rbxapi.Class {
 	return &cclass
 }
 
-// getSecurity finds the first security-related tag.
+// writePrefix and writeSuffix enclose the write security of a property
+// within a tag.
+const (
+	writePrefix = "ScriptWriteRestricted: ["
+	writeSuffix = "]"
+)
+
+// ParseSecurityTag returns the security context named by a tag, or an empty
+// string if the tag does not name a security context. Write is true if the
+// tag names the write security of a property, which has the form
+// "ScriptWriteRestricted: [X]".
+func ParseSecurityTag(tag string) (security string, write bool) {
+	if strings.HasPrefix(tag, writePrefix) && strings.HasSuffix(tag, writeSuffix) {
+		return tag[len(writePrefix) : len(tag)-len(writeSuffix)], true
+	}
+	if strings.Contains(tag, "Security") || strings.Contains(tag, "security") {
+		return tag, false
+	}
+	return "", false
+}
+
+// WriteSecurityTag returns the tag naming the given write security of a
+// property.
+func WriteSecurityTag(security string) string {
+	return writePrefix + security + writeSuffix
+}
+
+// getSecurity finds the first tag naming a security context, other than the
+// write security of a property.
 func getSecurity(tags Tags) string {
 	for _, tag := range tags {
-		if strings.Contains(tag, "Security") || strings.Contains(tag, "security") {
-			return tag
+		if security, write := ParseSecurityTag(tag); security != "" && !write {
+			return security
 		}
 	}
 	return ""
@@ -186,19 +270,17 @@ func (member *Property) Copy() rbxapi.Member {
 //
 // GetSecurity implements the rbxapi.Property interface.
 func (member *Property) GetSecurity() (read, write string) {
-	const prefix = "ScriptWriteRestricted: ["
-	const suffix = "]"
 	for _, tag := range member.Tags {
-		if write == "" && strings.HasPrefix(tag, prefix) {
-			write = tag[len(prefix) : len(tag)-len(suffix)]
-			if read != "" {
-				break
-			}
-		} else if read == "" && (strings.Contains(tag, "Security") || strings.Contains(tag, "security")) {
-			read = tag
-			if write != "" {
-				break
+		security, w := ParseSecurityTag(tag)
+		if w {
+			if write == "" {
+				write = security
 			}
+		} else if read == "" {
+			read = security
+		}
+		if read != "" && write != "" {
+			break
 		}
 	}
 	return read, write
@@ -460,6 +542,18 @@ func (enum *Enum) GetEnumItems() []rbxapi.EnumItem {
 	return list
 }
 
+// RangeEnumItems calls fn for each item of the enum. Iteration stops if fn
+// returns false.
+//
+// RangeEnumItems implements the rbxapi.EnumItemRanger interface.
+func (enum *Enum) RangeEnumItems(fn func(item rbxapi.EnumItem) bool) {
+	for _, item := range enum.Items {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
 // GetEnumItem returns the first item of the given name, or nil if no item of
 // the given name is present.
 //
@@ -541,6 +635,18 @@ func (tags Tags) GetTags() []string {
 	return list
 }
 
+// RangeTags calls fn for each tag present in the descriptor. Iteration stops
+// if fn returns false.
+//
+// RangeTags implements the rbxapi.TagRanger interface.
+func (tags Tags) RangeTags(fn func(tag string) bool) {
+	for _, tag := range tags {
+		if !fn(tag) {
+			return
+		}
+	}
+}
+
 // SetTag adds one or more tags to the list. Duplicate tags are removed.
 func (tags *Tags) SetTag(tag ...string) {
 	*tags = append(*tags, tag...)
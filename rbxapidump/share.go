@@ -0,0 +1,87 @@
+package rbxapidump
+
+// CopyShared returns a copy of the API structure that shares its descriptors
+// with root, rather than copying them. Only the lists of descriptors are
+// copied, making CopyShared much cheaper than Copy.
+//
+// The descriptors become shared by both root and the copy, and are treated as
+// immutable by each. When a class or enum is modified by Patch, the descriptor
+// is first replaced with a copy owned by the modified structure, leaving the
+// other unaffected. To modify a descriptor directly, it must first be
+// retrieved with MutableClass or MutableEnum.
+//
+// CopyShared modifies root to mark its descriptors as shared. It is safe to
+// call concurrently on the same root, but not concurrently with modifications
+// to root.
+func (root *Root) CopyShared() *Root {
+	croot := &Root{
+		Classes: make([]*Class, len(root.Classes)),
+		Enums:   make([]*Enum, len(root.Enums)),
+		owned:   map[interface{}]bool{},
+	}
+	root.shareMu.Lock()
+	root.owned = map[interface{}]bool{}
+	copy(croot.Classes, root.Classes)
+	copy(croot.Enums, root.Enums)
+	root.shareMu.Unlock()
+	return croot
+}
+
+// markOwned marks a descriptor as owned by root, if root shares descriptors.
+func (root *Root) markOwned(v interface{}) {
+	if root.owned != nil {
+		root.owned[v] = true
+	}
+}
+
+// ownClass returns the class at index i of the class list, first replacing
+// it with a copy if it is shared with other structures.
+func (root *Root) ownClass(i int) *Class {
+	class := root.Classes[i]
+	if root.owned == nil || root.owned[class] {
+		return class
+	}
+	class = class.Copy().(*Class)
+	root.Classes[i] = class
+	root.owned[class] = true
+	return class
+}
+
+// ownEnum returns the enum at index i of the enum list, first replacing it
+// with a copy if it is shared with other structures.
+func (root *Root) ownEnum(i int) *Enum {
+	enum := root.Enums[i]
+	if root.owned == nil || root.owned[enum] {
+		return enum
+	}
+	enum = enum.Copy().(*Enum)
+	root.Enums[i] = enum
+	root.owned[enum] = true
+	return enum
+}
+
+// MutableClass returns the first class descriptor of the given name, or nil
+// if no class of the given name is present. If the class is shared with
+// another structure by CopyShared, it is first replaced with a copy, so that
+// the returned class can be modified without affecting other structures.
+func (root *Root) MutableClass(name string) *Class {
+	for i, class := range root.Classes {
+		if class.Name == name {
+			return root.ownClass(i)
+		}
+	}
+	return nil
+}
+
+// MutableEnum returns the first enum descriptor of the given name, or nil if
+// no enum of the given name is present. If the enum is shared with another
+// structure by CopyShared, it is first replaced with a copy, so that the
+// returned enum can be modified without affecting other structures.
+func (root *Root) MutableEnum(name string) *Enum {
+	for i, enum := range root.Enums {
+		if enum.Name == name {
+			return root.ownEnum(i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package rbxapidump_test
+
+import (
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"sync"
+	"testing"
+)
+
+func TestCopyShared(t *testing.T) {
+	jprev := apitest.Generate(1, 100, 50)
+	prev := rbxapiconv.ToDump(jprev)
+	jnext := apitest.Evolve(jprev, 2)
+	next := rbxapiconv.ToDump(jnext)
+	orig := prev.Copy()
+
+	// CopyShared may be called concurrently on the same root.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev.CopyShared()
+		}()
+	}
+	wg.Wait()
+
+	shared := prev.CopyShared()
+	shared.Patch((&diff.Diff{Prev: prev, Next: next}).Diff())
+	for _, action := range (&diff.Diff{Prev: shared, Next: next}).Diff() {
+		t.Errorf("copy not patched: %s", action)
+	}
+	for _, action := range (&diff.Diff{Prev: orig, Next: prev}).Diff() {
+		t.Errorf("source modified: %s", action)
+	}
+
+	// Patching the source leaves the copy unaffected.
+	later := rbxapiconv.ToDump(apitest.Evolve(jnext, 2))
+	prev.Patch((&diff.Diff{Prev: prev, Next: later}).Diff())
+	for _, action := range (&diff.Diff{Prev: prev, Next: later}).Diff() {
+		t.Errorf("source not patched: %s", action)
+	}
+	for _, action := range (&diff.Diff{Prev: next, Next: shared}).Diff() {
+		t.Errorf("copy modified: %s", action)
+	}
+}
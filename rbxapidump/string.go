@@ -0,0 +1,19 @@
+//go:build !purego
+// +build !purego
+
+package rbxapidump
+
+import (
+	"unsafe"
+)
+
+// bytesToString returns a string that shares the memory of b. The content of
+// b must not be modified afterwards.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// cloneString returns a copy of s that does not share its memory.
+func cloneString(s string) string {
+	return string([]byte(s))
+}
@@ -0,0 +1,15 @@
+//go:build purego
+// +build purego
+
+package rbxapidump
+
+// bytesToString returns a copy of b as a string.
+func bytesToString(b []byte) string {
+	return string(b)
+}
+
+// cloneString returns s. Decoded strings are already copied, so they need not
+// be copied again.
+func cloneString(s string) string {
+	return s
+}
@@ -0,0 +1,24 @@
+package rbxapijson_test
+
+import (
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"testing"
+)
+
+func BenchmarkCopy(b *testing.B) {
+	root := apitest.Root(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.Copy()
+	}
+}
+
+func BenchmarkCopyParallel(b *testing.B) {
+	root := apitest.Root(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.CopyParallel(0)
+	}
+}
@@ -23,13 +23,80 @@ func (err errVersion) Error() string {
 	return "version " + strconv.FormatInt(int64(err), 10) + " is unsupported"
 }
 
+// PathError is an error that occurred while decoding a descriptor within an
+// API dump in JSON format.
+type PathError struct {
+	// Path locates the descriptor within the dump, such as
+	// "Classes[12].Members[3]".
+	Path string
+	// Err is the error that occurred.
+	Err error
+}
+
+func (err *PathError) Error() string {
+	return err.Path + ": " + err.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (err *PathError) Unwrap() error {
+	return err.Err
+}
+
+// withPath returns err located at path, which is prepended to the path of err
+// if it is already a PathError.
+func withPath(path string, err error) error {
+	if p, ok := err.(*PathError); ok {
+		return &PathError{Path: path + "." + p.Path, Err: p.Err}
+	}
+	return &PathError{Path: path, Err: err}
+}
+
+// locate finds the first element of the list under key within the object b
+// that fails to be decoded by fn, and returns the error of the element located
+// by its path. Returns false if no element fails.
+func locate(b []byte, key string, fn func(b []byte) error) (error, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, false
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(obj[key], &list); err != nil {
+		return nil, false
+	}
+	for i, raw := range list {
+		if err := fn(raw); err != nil {
+			return withPath(key+"["+strconv.Itoa(i)+"]", err), true
+		}
+	}
+	return nil, false
+}
+
+func decodeClass(b []byte) error {
+	return json.Unmarshal(b, &Class{})
+}
+
+func decodeMember(b []byte) error {
+	return json.Unmarshal(b, &jsonMember{})
+}
+
+func decodeParameter(b []byte) error {
+	return json.Unmarshal(b, &Parameter{})
+}
+
+func decodeEnum(b []byte) error {
+	return json.Unmarshal(b, &Enum{})
+}
+
+func decodeEnumItem(b []byte) error {
+	return json.Unmarshal(b, &EnumItem{})
+}
+
 // UnmarshalJSON implements the json.Unmarshaller interface.
 func (root *Root) UnmarshalJSON(b []byte) (err error) {
 	var v struct{ Version int }
 	if err := json.Unmarshal(b, &v); err != nil {
 		return err
 	}
-	fmt.Println("Hello")e
 	switch v.Version {
 	case 1:
 		r := struct {
@@ -37,9 +104,16 @@ func (root *Root) UnmarshalJSON(b []byte) (err error) {
 			Enums   []*Enum
 		}{}
 		if err := json.Unmarshal(b, &r); err != nil {
+			if err, ok := locate(b, "Classes", decodeClass); ok {
+				return err
+			}
+			if err, ok := locate(b, "Enums", decodeEnum); ok {
+				return err
+			}
 			return err
 		}
-		*root = Root(r)
+		root.Classes = r.Classes
+		root.Enums = r.Enums
 	default:
 		return errVersion(v.Version)
 	}
@@ -62,23 +136,9 @@ func (jmember *jsonMember) UnmarshalJSON(b []byte) (err error) {
 	switch t.MemberType {
 	case "Property":
 		var member Property
-		// Unmarshal matching fields.
 		if err := json.Unmarshal(b, &member); err != nil {
 			return err
 		}
-		// Unmarshal fields where the JSON structure differs.
-		var extra struct {
-			Security      struct{ Read, Write string }
-			Serialization struct{ CanLoad, CanSave bool }
-		}
-		if err := json.Unmarshal(b, &extra); err != nil {
-			return err
-		}
-		member.ReadSecurity = extra.Security.Read
-		member.WriteSecurity = extra.Security.Write
-		member.CanLoad = extra.Serialization.CanLoad
-		member.CanSave = extra.Serialization.CanSave
-
 		jmember.Member = &member
 
 	case "Function":
@@ -115,16 +175,23 @@ func (class *Class) UnmarshalJSON(b []byte) (err error) {
 		Superclass     string
 		MemoryCategory string
 		Members        []jsonMember
-		Tags
+		Capabilities   []string
+		Tags           []json.RawMessage
 	}
 	if err := json.Unmarshal(b, &c); err != nil {
+		if err, ok := locate(b, "Members", decodeMember); ok {
+			return err
+		}
 		return err
 	}
 
 	class.Name = c.Name
 	class.Superclass = c.Superclass
 	class.MemoryCategory = c.MemoryCategory
-	class.Tags = c.Tags
+	class.Capabilities = c.Capabilities
+	if class.Tags, class.PreferredDescriptor, err = decodeTags(c.Tags); err != nil {
+		return err
+	}
 	class.Members = make([]rbxapi.Member, len(c.Members))
 	for i, m := range c.Members {
 		class.Members[i] = m.Member
@@ -132,6 +199,102 @@ func (class *Class) UnmarshalJSON(b []byte) (err error) {
 	return nil
 }
 
+// decodeTags decodes a list of tags, which contains strings and objects
+// naming a preferred descriptor.
+func decodeTags(list []json.RawMessage) (tags Tags, preferred string, err error) {
+	for _, raw := range list {
+		var tag string
+		if err := json.Unmarshal(raw, &tag); err == nil {
+			tags = append(tags, tag)
+			continue
+		}
+		var obj struct{ PreferredDescriptorName string }
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, "", errors.New("invalid tag " + string(raw))
+		}
+		preferred = obj.PreferredDescriptorName
+	}
+	return tags, preferred, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface.
+func (member *Property) UnmarshalJSON(b []byte) (err error) {
+	type property Property
+	// Tags and fields where the JSON structure differs shadow the matching
+	// fields.
+	m := struct {
+		*property
+		Security      struct{ Read, Write string }
+		Serialization struct{ CanLoad, CanSave bool }
+		Default       *string
+		Tags          []json.RawMessage
+	}{property: (*property)(member)}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	if m.Default != nil {
+		member.HasDefault = true
+		member.Default = *m.Default
+	}
+	member.ReadSecurity = m.Security.Read
+	member.WriteSecurity = m.Security.Write
+	member.CanLoad = m.Serialization.CanLoad
+	member.CanSave = m.Serialization.CanSave
+	member.Tags, member.PreferredDescriptor, err = decodeTags(m.Tags)
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface.
+func (member *Function) UnmarshalJSON(b []byte) (err error) {
+	type function Function
+	m := struct {
+		*function
+		Tags []json.RawMessage
+	}{function: (*function)(member)}
+	if err := json.Unmarshal(b, &m); err != nil {
+		if err, ok := locate(b, "Parameters", decodeParameter); ok {
+			return err
+		}
+		return err
+	}
+	member.Tags, member.PreferredDescriptor, err = decodeTags(m.Tags)
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface.
+func (member *Event) UnmarshalJSON(b []byte) (err error) {
+	type event Event
+	m := struct {
+		*event
+		Tags []json.RawMessage
+	}{event: (*event)(member)}
+	if err := json.Unmarshal(b, &m); err != nil {
+		if err, ok := locate(b, "Parameters", decodeParameter); ok {
+			return err
+		}
+		return err
+	}
+	member.Tags, member.PreferredDescriptor, err = decodeTags(m.Tags)
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface.
+func (member *Callback) UnmarshalJSON(b []byte) (err error) {
+	type callback Callback
+	m := struct {
+		*callback
+		Tags []json.RawMessage
+	}{callback: (*callback)(member)}
+	if err := json.Unmarshal(b, &m); err != nil {
+		if err, ok := locate(b, "Parameters", decodeParameter); ok {
+			return err
+		}
+		return err
+	}
+	member.Tags, member.PreferredDescriptor, err = decodeTags(m.Tags)
+	return err
+}
+
 // UnmarshalJSON implements the json.Unmarshaller interface.
 func (param *Parameter) UnmarshalJSON(b []byte) (err error) {
 	var p struct {
@@ -151,6 +314,37 @@ func (param *Parameter) UnmarshalJSON(b []byte) (err error) {
 	return nil
 }
 
+// UnmarshalJSON implements the json.Unmarshaller interface.
+func (enum *Enum) UnmarshalJSON(b []byte) (err error) {
+	type enumType Enum
+	e := struct {
+		*enumType
+		Tags []json.RawMessage
+	}{enumType: (*enumType)(enum)}
+	if err := json.Unmarshal(b, &e); err != nil {
+		if err, ok := locate(b, "Items", decodeEnumItem); ok {
+			return err
+		}
+		return err
+	}
+	enum.Tags, enum.PreferredDescriptor, err = decodeTags(e.Tags)
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface.
+func (item *EnumItem) UnmarshalJSON(b []byte) (err error) {
+	type enumItem EnumItem
+	i := struct {
+		*enumItem
+		Tags []json.RawMessage
+	}{enumItem: (*enumItem)(item)}
+	if err := json.Unmarshal(b, &i); err != nil {
+		return err
+	}
+	item.Tags, item.PreferredDescriptor, err = decodeTags(i.Tags)
+	return err
+}
+
 // Decode parses an API dump from r in JSON format.
 func Decode(r io.Reader) (root *Root, err error) {
 	jd := json.NewDecoder(r)
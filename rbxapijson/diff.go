@@ -141,9 +141,15 @@ func (d *DiffClass) Diff() (actions []patch.Action) {
 	if d.Prev.MemoryCategory != d.Next.MemoryCategory {
 		actions = append(actions, &diff.ClassAction{patch.Change, d.Prev, "MemoryCategory", d.Prev.MemoryCategory, d.Next.MemoryCategory})
 	}
+	if eq, p, n := compareAndCopyTags(d.Prev.Capabilities, d.Next.Capabilities); !eq {
+		actions = append(actions, &diff.ClassAction{patch.Change, d.Prev, "Capabilities", p, n})
+	}
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.ClassAction{patch.Change, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.ClassAction{patch.Change, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	if !d.ExcludeMembers {
 		names := make(map[string]struct{}, len(d.Prev.Members))
 		for _, p := range d.Prev.Members {
@@ -224,12 +230,33 @@ func (d *DiffProperty) Diff() (actions []patch.Action) {
 	if d.Prev.CanSave != d.Next.CanSave {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "CanSave", d.Prev.CanSave, d.Next.CanSave})
 	}
+	if d.Prev.HasDefault != d.Next.HasDefault || d.Prev.HasDefault && d.Prev.Default != d.Next.Default {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Default", defaultValue(d.Prev), defaultValue(d.Next)})
+	}
+	if d.Prev.ThreadSafety != d.Next.ThreadSafety {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "ThreadSafety", d.Prev.ThreadSafety, d.Next.ThreadSafety})
+	}
+	if eq, p, n := compareAndCopyTags(d.Prev.Capabilities, d.Next.Capabilities); !eq {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Capabilities", p, n})
+	}
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	return
 }
 
+// defaultValue returns the default value of a property as the value of a
+// patch action, which is nil if the property has no default value.
+func defaultValue(p *Property) interface{} {
+	if !p.HasDefault {
+		return nil
+	}
+	return p.Default
+}
+
 // Diff is a patch.Differ that finds differences between two Function values.
 type DiffFunction struct {
 	Class      *Class
@@ -258,9 +285,18 @@ func (d *DiffFunction) Diff() (actions []patch.Action) {
 	if d.Prev.Security != d.Next.Security {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Security", d.Prev.Security, d.Next.Security})
 	}
+	if d.Prev.ThreadSafety != d.Next.ThreadSafety {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "ThreadSafety", d.Prev.ThreadSafety, d.Next.ThreadSafety})
+	}
+	if eq, p, n := compareAndCopyTags(d.Prev.Capabilities, d.Next.Capabilities); !eq {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Capabilities", p, n})
+	}
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	return
 }
 
@@ -289,9 +325,18 @@ func (d *DiffEvent) Diff() (actions []patch.Action) {
 	if d.Prev.Security != d.Next.Security {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Security", d.Prev.Security, d.Next.Security})
 	}
+	if d.Prev.ThreadSafety != d.Next.ThreadSafety {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "ThreadSafety", d.Prev.ThreadSafety, d.Next.ThreadSafety})
+	}
+	if eq, p, n := compareAndCopyTags(d.Prev.Capabilities, d.Next.Capabilities); !eq {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Capabilities", p, n})
+	}
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	return
 }
 
@@ -323,9 +368,18 @@ func (d *DiffCallback) Diff() (actions []patch.Action) {
 	if d.Prev.Security != d.Next.Security {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Security", d.Prev.Security, d.Next.Security})
 	}
+	if d.Prev.ThreadSafety != d.Next.ThreadSafety {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "ThreadSafety", d.Prev.ThreadSafety, d.Next.ThreadSafety})
+	}
+	if eq, p, n := compareAndCopyTags(d.Prev.Capabilities, d.Next.Capabilities); !eq {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Capabilities", p, n})
+	}
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.MemberAction{patch.Change, d.Class, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	return
 }
 
@@ -352,6 +406,9 @@ func (d *DiffEnum) Diff() (actions []patch.Action) {
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.EnumAction{patch.Change, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.EnumAction{patch.Change, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	if !d.ExcludeEnumItems {
 		names := make(map[string]struct{}, len(d.Prev.Items))
 		for _, p := range d.Prev.Items {
@@ -397,5 +454,8 @@ func (d *DiffEnumItem) Diff() (actions []patch.Action) {
 	if eq, p, n := compareAndCopyTags(d.Prev.GetTags(), d.Next.GetTags()); !eq {
 		actions = append(actions, &diff.EnumItemAction{patch.Change, d.Enum, d.Prev, "Tags", p, n})
 	}
+	if d.Prev.PreferredDescriptor != d.Next.PreferredDescriptor {
+		actions = append(actions, &diff.EnumItemAction{patch.Change, d.Enum, d.Prev, "PreferredDescriptor", d.Prev.PreferredDescriptor, d.Next.PreferredDescriptor})
+	}
 	return
 }
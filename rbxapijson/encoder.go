@@ -15,6 +15,19 @@ func (root *Root) MarshalJSON() (b []byte, err error) {
 	return json.Marshal(&r)
 }
 
+// encodeTags returns the JSON representation of a list of tags, which
+// includes an object naming the preferred descriptor, if any.
+func encodeTags(tags Tags, preferred string) []interface{} {
+	list := make([]interface{}, 0, len(tags)+1)
+	for _, tag := range tags {
+		list = append(list, tag)
+	}
+	if preferred != "" {
+		list = append(list, struct{ PreferredDescriptorName string }{preferred})
+	}
+	return list
+}
+
 // MarshalJSON implements the json.Marshaller interface.
 func (class *Class) MarshalJSON() (b []byte, err error) {
 	var c struct {
@@ -22,12 +35,14 @@ func (class *Class) MarshalJSON() (b []byte, err error) {
 		Superclass     string
 		MemoryCategory string
 		Members        []interface{}
-		Tags           `json:",omitempty"`
+		Capabilities   []string      `json:",omitempty"`
+		Tags           []interface{} `json:",omitempty"`
 	}
 	c.Name = class.Name
 	c.Superclass = class.Superclass
 	c.MemoryCategory = class.MemoryCategory
-	c.Tags = class.Tags
+	c.Capabilities = class.Capabilities
+	c.Tags = encodeTags(class.Tags, class.PreferredDescriptor)
 	c.Members = make([]interface{}, len(class.Members))
 	for i, m := range class.Members {
 		switch m := m.(type) {
@@ -40,6 +55,10 @@ func (class *Class) MarshalJSON() (b []byte, err error) {
 				CanLoad bool
 				CanSave bool
 			}
+			var def *string
+			if m.HasDefault {
+				def = &m.Default
+			}
 			c.Members[i] = struct {
 				MemberType    string
 				Name          string
@@ -47,7 +66,10 @@ func (class *Class) MarshalJSON() (b []byte, err error) {
 				Category      string
 				Security      security
 				Serialization serialization
-				Tags          `json:",omitempty"`
+				Default       *string       `json:",omitempty"`
+				ThreadSafety  string        `json:",omitempty"`
+				Capabilities  []string      `json:",omitempty"`
+				Tags          []interface{} `json:",omitempty"`
 			}{
 				MemberType:    "Property",
 				Name:          m.Name,
@@ -55,28 +77,55 @@ func (class *Class) MarshalJSON() (b []byte, err error) {
 				Category:      m.Category,
 				Security:      security{Read: m.ReadSecurity, Write: m.WriteSecurity},
 				Serialization: serialization{CanLoad: m.CanLoad, CanSave: m.CanSave},
-				Tags:          m.Tags,
+				Default:       def,
+				ThreadSafety:  m.ThreadSafety,
+				Capabilities:  m.Capabilities,
+				Tags:          encodeTags(m.Tags, m.PreferredDescriptor),
 			}
 		case *Function:
+			type function Function
 			c.Members[i] = struct {
 				MemberType string
-				*Function
-			}{m.GetMemberType(), m}
+				*function
+				Tags []interface{} `json:",omitempty"`
+			}{m.GetMemberType(), (*function)(m), encodeTags(m.Tags, m.PreferredDescriptor)}
 		case *Event:
+			type event Event
 			c.Members[i] = struct {
 				MemberType string
-				*Event
-			}{m.GetMemberType(), m}
+				*event
+				Tags []interface{} `json:",omitempty"`
+			}{m.GetMemberType(), (*event)(m), encodeTags(m.Tags, m.PreferredDescriptor)}
 		case *Callback:
+			type callback Callback
 			c.Members[i] = struct {
 				MemberType string
-				*Callback
-			}{m.GetMemberType(), m}
+				*callback
+				Tags []interface{} `json:",omitempty"`
+			}{m.GetMemberType(), (*callback)(m), encodeTags(m.Tags, m.PreferredDescriptor)}
 		}
 	}
 	return json.Marshal(&c)
 }
 
+// MarshalJSON implements the json.Marshaller interface.
+func (enum *Enum) MarshalJSON() (b []byte, err error) {
+	type enumType Enum
+	return json.Marshal(&struct {
+		*enumType
+		Tags []interface{} `json:",omitempty"`
+	}{(*enumType)(enum), encodeTags(enum.Tags, enum.PreferredDescriptor)})
+}
+
+// MarshalJSON implements the json.Marshaller interface.
+func (item *EnumItem) MarshalJSON() (b []byte, err error) {
+	type enumItem EnumItem
+	return json.Marshal(&struct {
+		*enumItem
+		Tags []interface{} `json:",omitempty"`
+	}{(*enumItem)(item), encodeTags(item.Tags, item.PreferredDescriptor)})
+}
+
 // MarshalJSON implements the json.Marshaller interface.
 func (param *Parameter) MarshalJSON() (b []byte, err error) {
 	var p struct {
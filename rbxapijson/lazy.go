@@ -0,0 +1,332 @@
+package rbxapijson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/mmap"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// lazyEntry locates the encoded form of a single class or enum descriptor
+// within the input of a LazyRoot.
+type lazyEntry struct {
+	name       string
+	start, end int64
+	done       bool
+	value      interface{}
+}
+
+// LazyRoot is an API structure whose class and enum descriptors are parsed on
+// demand. Decoding a LazyRoot only indexes the location of each descriptor
+// within the input; a descriptor is fully parsed the first time it is
+// accessed. This is useful for tools that inspect only a few descriptors of a
+// large API dump.
+//
+// Because descriptors are parsed lazily, a malformed descriptor is not
+// detected until it is accessed. Such a descriptor is treated as absent, and
+// the error can be retrieved with Err.
+//
+// A LazyRoot is safe for concurrent use.
+type LazyRoot struct {
+	mutex   sync.Mutex
+	data    []byte
+	classes []*lazyEntry
+	enums   []*lazyEntry
+	err     error
+	mapping *mmap.Mapping
+}
+
+// DecodeLazy reads an API dump from r in JSON format, returning a LazyRoot
+// that defers parsing of each class and enum descriptor until it is
+// accessed.
+func DecodeLazy(r io.Reader) (root *LazyRoot, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeLazyBytes(data)
+}
+
+// DecodeLazyBytes parses an API dump from b in JSON format, returning a
+// LazyRoot that defers parsing of each class and enum descriptor until it is
+// accessed. The LazyRoot refers to b, which must not be modified afterwards.
+func DecodeLazyBytes(b []byte) (root *LazyRoot, err error) {
+	root = &LazyRoot{data: b}
+	jd := json.NewDecoder(bytes.NewReader(b))
+	if err := expectDelim(jd, '{'); err != nil {
+		return nil, err
+	}
+	version := 0
+	for jd.More() {
+		tok, err := jd.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key, _ := tok.(string); key {
+		case "Version":
+			if err := jd.Decode(&version); err != nil {
+				return nil, err
+			}
+		case "Classes":
+			if root.classes, err = indexEntries(jd, b); err != nil {
+				return nil, err
+			}
+		case "Enums":
+			if root.enums, err = indexEntries(jd, b); err != nil {
+				return nil, err
+			}
+		default:
+			var skip json.RawMessage
+			if err := jd.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := expectDelim(jd, '}'); err != nil {
+		return nil, err
+	}
+	if version != 1 {
+		return nil, errVersion(version)
+	}
+	return root, nil
+}
+
+// DecodeLazyMapped parses an API dump from the content of m in JSON format,
+// returning a LazyRoot that defers parsing of each class and enum descriptor
+// until it is accessed. The LazyRoot takes ownership of m, which is closed by
+// calling Close on the LazyRoot.
+func DecodeLazyMapped(m *mmap.Mapping) (root *LazyRoot, err error) {
+	if root, err = DecodeLazyBytes(m.Bytes()); err != nil {
+		return nil, err
+	}
+	root.mapping = m
+	return root, nil
+}
+
+// Close releases the mapping of a LazyRoot returned by DecodeLazyMapped.
+// Descriptors that have already been parsed remain valid, while descriptors
+// that have not are treated as absent. Close does nothing if the LazyRoot was
+// not returned by DecodeLazyMapped.
+func (root *LazyRoot) Close() error {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	if root.mapping == nil {
+		return nil
+	}
+	m := root.mapping
+	root.mapping = nil
+	root.data = nil
+	return m.Close()
+}
+
+// expectDelim reads the next token from jd, and returns an error if it is not
+// the given delimiter.
+func expectDelim(jd *json.Decoder, delim json.Delim) error {
+	tok, err := jd.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return errors.New("expected " + delim.String())
+	}
+	return nil
+}
+
+// indexEntries reads an array of descriptors from jd, recording the name and
+// byte range of each one without parsing the remainder of the descriptor.
+func indexEntries(jd *json.Decoder, data []byte) (entries []*lazyEntry, err error) {
+	if err := expectDelim(jd, '['); err != nil {
+		return nil, err
+	}
+	for jd.More() {
+		start := jd.InputOffset()
+		var v struct{ Name string }
+		if err := jd.Decode(&v); err != nil {
+			return nil, err
+		}
+		end := jd.InputOffset()
+		// The offset preceding a value may include the separator of the
+		// previous value.
+		start += int64(len(data[start:end]) - len(bytes.TrimLeft(data[start:end], " \t\r\n,")))
+		entries = append(entries, &lazyEntry{name: v.Name, start: start, end: end})
+	}
+	if err := expectDelim(jd, ']'); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// materialize parses the descriptor of an entry into v, if it has not
+// already been parsed. Returns nil if the descriptor could not be parsed.
+func (root *LazyRoot) materialize(entry *lazyEntry, v interface{}) interface{} {
+	if !entry.done {
+		if root.data == nil {
+			if root.err == nil {
+				root.err = mmap.ErrClosed
+			}
+			return nil
+		}
+		entry.done = true
+		if err := json.Unmarshal(root.data[entry.start:entry.end], v); err != nil {
+			if root.err == nil {
+				root.err = err
+			}
+			return nil
+		}
+		entry.value = v
+	}
+	return entry.value
+}
+
+// class returns the parsed class descriptor of an entry.
+func (root *LazyRoot) class(entry *lazyEntry) *Class {
+	class, _ := root.materialize(entry, &Class{}).(*Class)
+	return class
+}
+
+// enum returns the parsed enum descriptor of an entry.
+func (root *LazyRoot) enum(entry *lazyEntry) *Enum {
+	enum, _ := root.materialize(entry, &Enum{}).(*Enum)
+	return enum
+}
+
+// Err returns the first error that occurred while parsing a descriptor, or
+// nil if no error has occurred.
+func (root *LazyRoot) Err() error {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	return root.err
+}
+
+// Materialize parses all remaining descriptors, returning the result as a
+// Root. Returns an error if any descriptor could not be parsed.
+func (root *LazyRoot) Materialize() (*Root, error) {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	r := &Root{
+		Classes: make([]*Class, 0, len(root.classes)),
+		Enums:   make([]*Enum, 0, len(root.enums)),
+	}
+	for _, entry := range root.classes {
+		if class := root.class(entry); class != nil {
+			r.Classes = append(r.Classes, class)
+		}
+	}
+	for _, entry := range root.enums {
+		if enum := root.enum(entry); enum != nil {
+			r.Enums = append(r.Enums, enum)
+		}
+	}
+	return r, root.err
+}
+
+// GetClasses returns a list of class descriptors present in the API. All
+// class descriptors are parsed.
+//
+// GetClasses implements the rbxapi.Root interface.
+func (root *LazyRoot) GetClasses() []rbxapi.Class {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	list := make([]rbxapi.Class, 0, len(root.classes))
+	for _, entry := range root.classes {
+		if class := root.class(entry); class != nil {
+			list = append(list, class)
+		}
+	}
+	return list
+}
+
+// RangeClasses calls fn for each class descriptor present in the API, parsing
+// each descriptor as it is reached. Iteration stops if fn returns false.
+//
+// RangeClasses implements the rbxapi.ClassRanger interface.
+func (root *LazyRoot) RangeClasses(fn func(class rbxapi.Class) bool) {
+	for _, entry := range root.classes {
+		root.mutex.Lock()
+		class := root.class(entry)
+		root.mutex.Unlock()
+		if class != nil && !fn(class) {
+			return
+		}
+	}
+}
+
+// GetClass returns the first class descriptor of the given name, or nil if no
+// class of the given name is present. Only the matching descriptor is parsed.
+//
+// GetClass implements the rbxapi.Root interface.
+func (root *LazyRoot) GetClass(name string) rbxapi.Class {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	for _, entry := range root.classes {
+		if entry.name == name {
+			if class := root.class(entry); class != nil {
+				return class
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// GetEnums returns a list of enum descriptors present in the API. All enum
+// descriptors are parsed.
+//
+// GetEnums implements the rbxapi.Root interface.
+func (root *LazyRoot) GetEnums() []rbxapi.Enum {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	list := make([]rbxapi.Enum, 0, len(root.enums))
+	for _, entry := range root.enums {
+		if enum := root.enum(entry); enum != nil {
+			list = append(list, enum)
+		}
+	}
+	return list
+}
+
+// RangeEnums calls fn for each enum descriptor present in the API, parsing
+// each descriptor as it is reached. Iteration stops if fn returns false.
+//
+// RangeEnums implements the rbxapi.EnumRanger interface.
+func (root *LazyRoot) RangeEnums(fn func(enum rbxapi.Enum) bool) {
+	for _, entry := range root.enums {
+		root.mutex.Lock()
+		enum := root.enum(entry)
+		root.mutex.Unlock()
+		if enum != nil && !fn(enum) {
+			return
+		}
+	}
+}
+
+// GetEnum returns the first enum descriptor of the given name, or nil if no
+// enum of the given name is present. Only the matching descriptor is parsed.
+//
+// GetEnum implements the rbxapi.Root interface.
+func (root *LazyRoot) GetEnum(name string) rbxapi.Enum {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	for _, entry := range root.enums {
+		if entry.name == name {
+			if enum := root.enum(entry); enum != nil {
+				return enum
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Copy returns a deep copy of the API structure. The copy is a fully parsed
+// Root.
+//
+// Copy implements the rbxapi.Root interface.
+func (root *LazyRoot) Copy() rbxapi.Root {
+	r, _ := root.Materialize()
+	return r.Copy()
+}
@@ -2,6 +2,7 @@ package rbxapijson
 
 import (
 	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/internal/index"
 	"github.com/karl-police/rbxapi/patch"
 )
 
@@ -32,11 +33,16 @@ func copyMember(member rbxapi.Member) rbxapi.Member {
 		if member, ok := member.(*Property); ok {
 			return member.Copy().(*Property)
 		}
-		return &Property{
+		m := &Property{
 			Name:      member.GetName(),
 			ValueType: copyType(member.GetValueType()),
 			Tags:      Tags(member.GetTags()),
 		}
+		m.ReadSecurity, m.WriteSecurity = member.GetSecurity()
+		if member, ok := member.(rbxapi.DefaultProperty); ok {
+			m.Default, m.HasDefault = member.GetDefault()
+		}
+		return m
 	case rbxapi.Function:
 		// Function and Callback have the same methods.
 		switch member := member.(type) {
@@ -51,6 +57,7 @@ func copyMember(member rbxapi.Member) rbxapi.Member {
 				Name:       member.GetName(),
 				ReturnType: copyType(member.GetReturnType()),
 				Parameters: copyParameters(member.GetParameters()),
+				Security:   member.GetSecurity(),
 				Tags:       Tags(member.GetTags()),
 			}
 		case "Callback":
@@ -58,6 +65,7 @@ func copyMember(member rbxapi.Member) rbxapi.Member {
 				Name:       member.GetName(),
 				ReturnType: copyType(member.GetReturnType()),
 				Parameters: copyParameters(member.GetParameters()),
+				Security:   member.GetSecurity(),
 				Tags:       Tags(member.GetTags()),
 			}
 		}
@@ -68,6 +76,7 @@ func copyMember(member rbxapi.Member) rbxapi.Member {
 		return &Event{
 			Name:       member.GetName(),
 			Parameters: copyParameters(member.GetParameters()),
+			Security:   member.GetSecurity(),
 			Tags:       Tags(member.GetTags()),
 		}
 	}
@@ -132,40 +141,43 @@ func typeFromString(s string) Type {
 //
 // Patch implements the patch.Patcher interface.
 func (root *Root) Patch(actions []patch.Action) {
+	// Removed descriptors are set to nil, and the lists are compacted after
+	// all actions are applied, so that positions within the indexes remain
+	// valid.
+	classes := index.New(len(root.Classes), func(i int) string { return root.Classes[i].Name })
+	enums := index.New(len(root.Enums), func(i int) string { return root.Enums[i].Name })
+	removed := false
 	for i, action := range actions {
 		if action, ok := action.(patch.Member); ok {
 			if aclass, amember := (action.GetClass()), (action.GetMember()); aclass != nil && amember != nil {
-				name := aclass.GetName()
-				for _, class := range root.Classes {
-					if class.Name == name {
-						class.Patch(actions[i : i+1])
-						break
-					}
+				if j := classes.Get(aclass.GetName()); j >= 0 {
+					root.ownClass(j).Patch(actions[i : i+1])
 				}
 				continue
 			}
 		}
 		if action, ok := action.(patch.Class); ok {
 			if aclass := action.GetClass(); aclass != nil {
+				name := aclass.GetName()
 				switch action.GetType() {
 				case patch.Remove:
-					name := aclass.GetName()
-					for i, class := range root.Classes {
-						if class.Name == name {
-							copy(root.Classes[i:], root.Classes[i+1:])
-							root.Classes[len(root.Classes)-1] = nil
-							root.Classes = root.Classes[:len(root.Classes)-1]
-							break
-						}
+					if j := classes.Get(name); j >= 0 {
+						root.Classes[j] = nil
+						classes.Remove(name)
+						removed = true
 					}
 				case patch.Add:
-					root.Classes = append(root.Classes, copyClass(aclass))
+					class := copyClass(aclass)
+					classes.Add(class.Name, len(root.Classes))
+					root.Classes = append(root.Classes, class)
+					root.markOwned(class)
 				case patch.Change:
-					name := aclass.GetName()
-					for _, class := range root.Classes {
-						if class.Name == name {
-							class.Patch(actions[i : i+1])
-							break
+					if j := classes.Get(name); j >= 0 {
+						class := root.ownClass(j)
+						class.Patch(actions[i : i+1])
+						if class.Name != name {
+							classes.Remove(name)
+							classes.Add(class.Name, j)
 						}
 					}
 				}
@@ -174,37 +186,34 @@ func (root *Root) Patch(actions []patch.Action) {
 		}
 		if action, ok := action.(patch.EnumItem); ok {
 			if aenum, aitem := (action.GetEnum()), (action.GetEnumItem()); aenum != nil && aitem != nil {
-				name := aenum.GetName()
-				for _, enum := range root.Enums {
-					if enum.Name == name {
-						enum.Patch(actions[i : i+1])
-						break
-					}
+				if j := enums.Get(aenum.GetName()); j >= 0 {
+					root.ownEnum(j).Patch(actions[i : i+1])
 				}
 				continue
 			}
 		}
 		if action, ok := action.(patch.Enum); ok {
 			if aenum := action.GetEnum(); aenum != nil {
+				name := aenum.GetName()
 				switch action.GetType() {
 				case patch.Remove:
-					name := aenum.GetName()
-					for i, enum := range root.Enums {
-						if enum.Name == name {
-							copy(root.Enums[i:], root.Enums[i+1:])
-							root.Enums[len(root.Enums)-1] = nil
-							root.Enums = root.Enums[:len(root.Enums)-1]
-							break
-						}
+					if j := enums.Get(name); j >= 0 {
+						root.Enums[j] = nil
+						enums.Remove(name)
+						removed = true
 					}
 				case patch.Add:
-					root.Enums = append(root.Enums, copyEnum(aenum))
+					enum := copyEnum(aenum)
+					enums.Add(enum.Name, len(root.Enums))
+					root.Enums = append(root.Enums, enum)
+					root.markOwned(enum)
 				case patch.Change:
-					name := aenum.GetName()
-					for _, enum := range root.Enums {
-						if enum.Name == name {
-							enum.Patch(actions[i : i+1])
-							break
+					if j := enums.Get(name); j >= 0 {
+						enum := root.ownEnum(j)
+						enum.Patch(actions[i : i+1])
+						if enum.Name != name {
+							enums.Remove(name)
+							enums.Add(enum.Name, j)
 						}
 					}
 				}
@@ -212,6 +221,34 @@ func (root *Root) Patch(actions []patch.Action) {
 			}
 		}
 	}
+	if removed {
+		root.compact()
+	}
+}
+
+// compact removes nil descriptors from the lists of the root, preserving the
+// order of the remaining descriptors.
+func (root *Root) compact() {
+	classes := root.Classes[:0]
+	for _, class := range root.Classes {
+		if class != nil {
+			classes = append(classes, class)
+		}
+	}
+	for i := len(classes); i < len(root.Classes); i++ {
+		root.Classes[i] = nil
+	}
+	root.Classes = classes
+	enums := root.Enums[:0]
+	for _, enum := range root.Enums {
+		if enum != nil {
+			enums = append(enums, enum)
+		}
+	}
+	for i := len(enums); i < len(root.Enums); i++ {
+		root.Enums[i] = nil
+	}
+	root.Enums = enums
 }
 
 func (class *Class) Patch(actions []patch.Action) {
@@ -265,10 +302,18 @@ func (class *Class) Patch(actions []patch.Action) {
 				if v, ok := action.GetNext().(string); ok {
 					class.MemoryCategory = v
 				}
+			case "Capabilities":
+				if v, ok := action.GetNext().([]string); ok {
+					class.Capabilities = append([]string(nil), v...)
+				}
 			case "Tags":
 				if v, ok := action.GetNext().([]string); ok {
 					class.Tags = Tags(Tags(v).GetTags())
 				}
+			case "PreferredDescriptor":
+				if v, ok := action.GetNext().(string); ok {
+					class.PreferredDescriptor = v
+				}
 			}
 		}
 	}
@@ -311,10 +356,31 @@ func (member *Property) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(bool); ok {
 				member.CanSave = v
 			}
+		case "Default":
+			switch v := action.GetNext().(type) {
+			case string:
+				member.Default = v
+				member.HasDefault = true
+			case nil:
+				member.Default = ""
+				member.HasDefault = false
+			}
+		case "ThreadSafety":
+			if v, ok := action.GetNext().(string); ok {
+				member.ThreadSafety = v
+			}
+		case "Capabilities":
+			if v, ok := action.GetNext().([]string); ok {
+				member.Capabilities = append([]string(nil), v...)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
 				member.Tags = Tags(Tags(v).GetTags())
 			}
+		case "PreferredDescriptor":
+			if v, ok := action.GetNext().(string); ok {
+				member.PreferredDescriptor = v
+			}
 		}
 	}
 }
@@ -344,10 +410,22 @@ func (member *Function) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(string); ok {
 				member.Security = v
 			}
+		case "ThreadSafety":
+			if v, ok := action.GetNext().(string); ok {
+				member.ThreadSafety = v
+			}
+		case "Capabilities":
+			if v, ok := action.GetNext().([]string); ok {
+				member.Capabilities = append([]string(nil), v...)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
 				member.Tags = Tags(Tags(v).GetTags())
 			}
+		case "PreferredDescriptor":
+			if v, ok := action.GetNext().(string); ok {
+				member.PreferredDescriptor = v
+			}
 		}
 	}
 }
@@ -370,10 +448,22 @@ func (member *Event) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(string); ok {
 				member.Security = v
 			}
+		case "ThreadSafety":
+			if v, ok := action.GetNext().(string); ok {
+				member.ThreadSafety = v
+			}
+		case "Capabilities":
+			if v, ok := action.GetNext().([]string); ok {
+				member.Capabilities = append([]string(nil), v...)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
 				member.Tags = Tags(Tags(v).GetTags())
 			}
+		case "PreferredDescriptor":
+			if v, ok := action.GetNext().(string); ok {
+				member.PreferredDescriptor = v
+			}
 		}
 	}
 }
@@ -403,10 +493,22 @@ func (member *Callback) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().(string); ok {
 				member.Security = v
 			}
+		case "ThreadSafety":
+			if v, ok := action.GetNext().(string); ok {
+				member.ThreadSafety = v
+			}
+		case "Capabilities":
+			if v, ok := action.GetNext().([]string); ok {
+				member.Capabilities = append([]string(nil), v...)
+			}
 		case "Tags":
 			if v, ok := action.GetNext().([]string); ok {
 				member.Tags = Tags(Tags(v).GetTags())
 			}
+		case "PreferredDescriptor":
+			if v, ok := action.GetNext().(string); ok {
+				member.PreferredDescriptor = v
+			}
 		}
 	}
 }
@@ -453,6 +555,10 @@ func (enum *Enum) Patch(actions []patch.Action) {
 				if v, ok := action.GetNext().([]string); ok {
 					enum.Tags = Tags(Tags(v).GetTags())
 				}
+			case "PreferredDescriptor":
+				if v, ok := action.GetNext().(string); ok {
+					enum.PreferredDescriptor = v
+				}
 			}
 		}
 	}
@@ -476,6 +582,10 @@ func (item *EnumItem) Patch(actions []patch.Action) {
 			if v, ok := action.GetNext().([]string); ok {
 				item.Tags = Tags(Tags(v).GetTags())
 			}
+		case "PreferredDescriptor":
+			if v, ok := action.GetNext().(string); ok {
+				item.PreferredDescriptor = v
+			}
 		}
 	}
 }
@@ -0,0 +1,74 @@
+package rbxapijson_test
+
+import (
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/patch"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	prev := apitest.Generate(1, 100, 50)
+	next := apitest.Evolve(prev, 2)
+	// The generic differ compares only the fields exposed by the rbxapi
+	// interfaces, so the result is compared in the same way.
+	for _, test := range []struct {
+		name string
+		diff func(prev, next *rbxapijson.Root) patch.Differ
+	}{
+		{"json", func(prev, next *rbxapijson.Root) patch.Differ { return &rbxapijson.Diff{Prev: prev, Next: next} }},
+		{"generic", func(prev, next *rbxapijson.Root) patch.Differ { return &diff.Diff{Prev: prev, Next: next} }},
+	} {
+		root := prev.Copy().(*rbxapijson.Root)
+		root.Patch(test.diff(prev, next).Diff())
+		for _, action := range test.diff(root, next).Diff() {
+			t.Errorf("%s: remaining: %s", test.name, action)
+		}
+		for _, action := range (&rbxapijson.Diff{Prev: apitest.Generate(1, 100, 50), Next: prev}).Diff() {
+			t.Fatalf("%s: source modified: %s", test.name, action)
+		}
+	}
+}
+
+func BenchmarkDiff(b *testing.B) {
+	prev, next := apitest.Builds(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		(&rbxapijson.Diff{Prev: prev, Next: next}).Diff()
+	}
+}
+
+func BenchmarkPatch(b *testing.B) {
+	prev, next := apitest.Builds(b)
+	actions := (&rbxapijson.Diff{Prev: prev, Next: next}).Diff()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		root := prev.Copy().(*rbxapijson.Root)
+		b.StartTimer()
+		root.Patch(actions)
+	}
+}
+
+// BenchmarkPatchRemoveMembers removes every member, as when filtering a
+// build, which applies many more actions than a diff between builds.
+func BenchmarkPatchRemoveMembers(b *testing.B) {
+	root := apitest.Root(b)
+	var actions []patch.Action
+	for _, class := range root.Classes {
+		for _, member := range class.Members {
+			actions = append(actions, &diff.MemberAction{Type: patch.Remove, Class: class, Member: member})
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		r := root.Copy().(*rbxapijson.Root)
+		b.StartTimer()
+		r.Patch(actions)
+	}
+}
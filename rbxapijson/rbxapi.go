@@ -7,12 +7,19 @@ package rbxapijson
 
 import (
 	"github.com/karl-police/rbxapi"
+	"sync"
 )
-e
+
 // Root represents the top-level structure of an API.
 type Root struct {
 	Classes []*Class
 	Enums   []*Enum
+
+	// owned is the set of descriptors owned by the root, or nil if the root
+	// does not share descriptors with other roots.
+	owned map[interface{}]bool
+	// shareMu guards owned against concurrent calls to CopyShared.
+	shareMu sync.Mutex
 }
 
 // GetClasses returns a list of class descriptors present in the API.
@@ -26,6 +33,18 @@ func (root *Root) GetClasses() []rbxapi.Class {
 	return list
 }
 
+// RangeClasses calls fn for each class descriptor present in the API.
+// Iteration stops if fn returns false.
+//
+// RangeClasses implements the rbxapi.ClassRanger interface.
+func (root *Root) RangeClasses(fn func(class rbxapi.Class) bool) {
+	for _, class := range root.Classes {
+		if !fn(class) {
+			return
+		}
+	}
+}
+
 // GetClass returns the first class descriptor of the given name, or nil if no
 // class of the given name is present.
 //
@@ -50,6 +69,18 @@ func (root *Root) GetEnums() []rbxapi.Enum {
 	return list
 }
 
+// RangeEnums calls fn for each enum descriptor present in the API. Iteration
+// stops if fn returns false.
+//
+// RangeEnums implements the rbxapi.EnumRanger interface.
+func (root *Root) RangeEnums(fn func(enum rbxapi.Enum) bool) {
+	for _, enum := range root.Enums {
+		if !fn(enum) {
+			return
+		}
+	}
+}
+
 // GetEnum returns the first enum descriptor of the given name, or nil if no
 // enum of the given name is present.
 //
@@ -82,11 +113,13 @@ func (root *Root) Copy() rbxapi.Root {
 
 // Class represents a class descriptor.
 type Class struct {
-	Name           string
-	Superclass     string
-	MemoryCategory string
-	Members        []rbxapi.Member
-	Tags           `json:",omitempty"`
+	Name                string
+	Superclass          string
+	MemoryCategory      string
+	Members             []rbxapi.Member
+	Capabilities        []string `json:",omitempty"`
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetName returns the class name.
@@ -96,6 +129,17 @@ func (class *Class) GetName() string {
 	return class.Name
 }
 
+// GetCapabilities returns a copy of the list of capabilities required to
+// access the class.
+func (class *Class) GetCapabilities() []string {
+	if class.Capabilities == nil {
+		return nil
+	}
+	list := make([]string, len(class.Capabilities))
+	copy(list, class.Capabilities)
+	return list
+}
+
 // GetSuperclass returns the name of the class that this class inherits from.
 //
 // GetSuperclass implements the rbxapi.Class interface.
@@ -112,6 +156,18 @@ func (class *Class) GetMembers() []rbxapi.Member {
 	return list
 }
 
+// RangeMembers calls fn for each member descriptor belonging to the class.
+// Iteration stops if fn returns false.
+//
+// RangeMembers implements the rbxapi.MemberRanger interface.
+func (class *Class) RangeMembers(fn func(member rbxapi.Member) bool) {
+	for _, member := range class.Members {
+		if !fn(member) {
+			return
+		}
+	}
+}
+
 // GetMember returns the first member descriptor of the given name, or nil if
 // no member of the given name is present.
 //
@@ -135,19 +191,25 @@ func (class *Class) Copy() rbxapi.Class {
 		cclass.Members[i] = member.Copy()
 	}
 	cclass.Tags = Tags(class.GetTags())
+	cclass.Capabilities = class.GetCapabilities()
 	return &cclass
 }
 
 // Property represents a class member of the Property member type.
 type Property struct {
-	Name          string
-	ValueType     Type
-	Category      string
-	ReadSecurity  string
-	WriteSecurity string
-	CanLoad       bool
-	CanSave       bool
-	Tags          `json:",omitempty"`
+	Name                string
+	ValueType           Type
+	Category            string
+	ReadSecurity        string
+	WriteSecurity       string
+	CanLoad             bool
+	CanSave             bool
+	HasDefault          bool     `json:"-"`
+	Default             string   `json:",omitempty"`
+	ThreadSafety        string   `json:",omitempty"`
+	Capabilities        []string `json:",omitempty"`
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetMemberType returns a string indicating the the type of member.
@@ -164,12 +226,24 @@ func (member *Property) GetName() string {
 	return member.Name
 }
 
+// GetCapabilities returns a copy of the list of capabilities required to
+// access the member.
+func (member *Property) GetCapabilities() []string {
+	if member.Capabilities == nil {
+		return nil
+	}
+	list := make([]string, len(member.Capabilities))
+	copy(list, member.Capabilities)
+	return list
+}
+
 // Copy returns a deep copy of the member descriptor.
 //
 // Copy implements the rbxapi.Member interface.
 func (member *Property) Copy() rbxapi.Member {
 	cmember := *member
 	cmember.Tags = Tags(member.GetTags())
+	cmember.Capabilities = member.GetCapabilities()
 	return &cmember
 }
 
@@ -181,6 +255,12 @@ func (member *Property) GetSecurity() (read, write string) {
 	return member.ReadSecurity, member.WriteSecurity
 }
 
+// GetThreadSafety returns the safety of accessing the member from parallel
+// Luau threads. This may be empty if the safety is unknown.
+func (member *Property) GetThreadSafety() string {
+	return member.ThreadSafety
+}
+
 // GetValueType returns the type of value stored in the property.
 //
 // GetValueType implements the rbxapi.Property interface.
@@ -188,13 +268,24 @@ func (member *Property) GetValueType() rbxapi.Type {
 	return member.ValueType
 }
 
+// GetDefault returns a string representing the default value of the
+// property, and whether a default value is present.
+//
+// GetDefault implements the rbxapi.DefaultProperty interface.
+func (member *Property) GetDefault() (value string, ok bool) {
+	return member.Default, member.HasDefault
+}
+
 // Function represents a class member of the Function member type.
 type Function struct {
-	Name       string
-	Parameters []Parameter
-	ReturnType Type
-	Security   string
-	Tags       `json:",omitempty"`
+	Name                string
+	Parameters          []Parameter
+	ReturnType          Type
+	Security            string
+	ThreadSafety        string   `json:",omitempty"`
+	Capabilities        []string `json:",omitempty"`
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetMemberType returns a string indicating the the type of member.
@@ -211,6 +302,17 @@ func (member *Function) GetName() string {
 	return member.Name
 }
 
+// GetCapabilities returns a copy of the list of capabilities required to
+// access the member.
+func (member *Function) GetCapabilities() []string {
+	if member.Capabilities == nil {
+		return nil
+	}
+	list := make([]string, len(member.Capabilities))
+	copy(list, member.Capabilities)
+	return list
+}
+
 // Copy returns a deep copy of the member descriptor.
 //
 // Copy implements the rbxapi.Member interface.
@@ -219,6 +321,7 @@ func (member *Function) Copy() rbxapi.Member {
 	cmember.Parameters = make([]Parameter, len(member.Parameters))
 	copy(cmember.Parameters, member.Parameters)
 	cmember.Tags = Tags(member.GetTags())
+	cmember.Capabilities = member.GetCapabilities()
 	return &cmember
 }
 
@@ -229,6 +332,12 @@ func (member *Function) GetSecurity() string {
 	return member.Security
 }
 
+// GetThreadSafety returns the safety of accessing the member from parallel
+// Luau threads. This may be empty if the safety is unknown.
+func (member *Function) GetThreadSafety() string {
+	return member.ThreadSafety
+}
+
 // GetParameters returns the list of parameters describing the arguments
 // passed to the function. These parameters may have default values.
 //
@@ -246,10 +355,13 @@ func (member *Function) GetReturnType() rbxapi.Type {
 
 // Event represents a class member of the Event member type.
 type Event struct {
-	Name       string
-	Parameters []Parameter
-	Security   string
-	Tags       `json:",omitempty"`
+	Name                string
+	Parameters          []Parameter
+	Security            string
+	ThreadSafety        string   `json:",omitempty"`
+	Capabilities        []string `json:",omitempty"`
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetMemberType returns a string indicating the the type of member.
@@ -266,6 +378,17 @@ func (member *Event) GetName() string {
 	return member.Name
 }
 
+// GetCapabilities returns a copy of the list of capabilities required to
+// access the member.
+func (member *Event) GetCapabilities() []string {
+	if member.Capabilities == nil {
+		return nil
+	}
+	list := make([]string, len(member.Capabilities))
+	copy(list, member.Capabilities)
+	return list
+}
+
 // Copy returns a deep copy of the member descriptor.
 //
 // Copy implements the rbxapi.Member interface.
@@ -274,6 +397,7 @@ func (member *Event) Copy() rbxapi.Member {
 	cmember.Parameters = make([]Parameter, len(member.Parameters))
 	copy(cmember.Parameters, member.Parameters)
 	cmember.Tags = Tags(member.GetTags())
+	cmember.Capabilities = member.GetCapabilities()
 	return &cmember
 }
 
@@ -284,6 +408,12 @@ func (member *Event) GetSecurity() string {
 	return member.Security
 }
 
+// GetThreadSafety returns the safety of accessing the member from parallel
+// Luau threads. This may be empty if the safety is unknown.
+func (member *Event) GetThreadSafety() string {
+	return member.ThreadSafety
+}
+
 // GetParameters returns the list of parameters describing the arguments
 // received from the event. These parameters cannot have default values.
 //
@@ -294,11 +424,14 @@ func (member *Event) GetParameters() rbxapi.Parameters {
 
 // Callback represents a class member of the Callback member type.
 type Callback struct {
-	Name       string
-	Parameters []Parameter
-	ReturnType Type
-	Security   string
-	Tags       `json:",omitempty"`
+	Name                string
+	Parameters          []Parameter
+	ReturnType          Type
+	Security            string
+	ThreadSafety        string   `json:",omitempty"`
+	Capabilities        []string `json:",omitempty"`
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetMemberType returns a string indicating the the type of member.
@@ -315,6 +448,17 @@ func (member *Callback) GetName() string {
 	return member.Name
 }
 
+// GetCapabilities returns a copy of the list of capabilities required to
+// access the member.
+func (member *Callback) GetCapabilities() []string {
+	if member.Capabilities == nil {
+		return nil
+	}
+	list := make([]string, len(member.Capabilities))
+	copy(list, member.Capabilities)
+	return list
+}
+
 // Copy returns a deep copy of the member descriptor.
 //
 // Copy implements the rbxapi.Member interface.
@@ -323,6 +467,7 @@ func (member *Callback) Copy() rbxapi.Member {
 	cmember.Parameters = make([]Parameter, len(member.Parameters))
 	copy(cmember.Parameters, member.Parameters)
 	cmember.Tags = Tags(member.GetTags())
+	cmember.Capabilities = member.GetCapabilities()
 	return &cmember
 }
 
@@ -333,6 +478,12 @@ func (member *Callback) GetSecurity() string {
 	return member.Security
 }
 
+// GetThreadSafety returns the safety of accessing the member from parallel
+// Luau threads. This may be empty if the safety is unknown.
+func (member *Callback) GetThreadSafety() string {
+	return member.ThreadSafety
+}
+
 // GetParameters returns the list of parameters describing the arguments
 // passed to the callback. These parameters cannot have default values.
 //
@@ -416,9 +567,10 @@ func (param Parameter) Copy() rbxapi.Parameter {
 
 // Enum represents an enum descriptor.
 type Enum struct {
-	Name  string
-	Items []*EnumItem
-	Tags  `json:",omitempty"`
+	Name                string
+	Items               []*EnumItem
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetName returns the name of the enum.
@@ -439,6 +591,18 @@ func (enum *Enum) GetEnumItems() []rbxapi.EnumItem {
 	return list
 }
 
+// RangeEnumItems calls fn for each item of the enum. Iteration stops if fn
+// returns false.
+//
+// RangeEnumItems implements the rbxapi.EnumItemRanger interface.
+func (enum *Enum) RangeEnumItems(fn func(item rbxapi.EnumItem) bool) {
+	for _, item := range enum.Items {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
 // GetEnumItem returns the first item of the given name, or nil if no item of
 // the given name is present.
 //
@@ -467,9 +631,10 @@ func (enum *Enum) Copy() rbxapi.Enum {
 
 // EnumItem represents an enum item descriptor.
 type EnumItem struct {
-	Name  string
-	Value int
-	Tags  `json:",omitempty"`
+	Name                string
+	Value               int
+	Tags                `json:",omitempty"`
+	PreferredDescriptor string `json:"-"`
 }
 
 // GetName returns the name of the enum item.
@@ -496,6 +661,11 @@ func (item *EnumItem) Copy() rbxapi.EnumItem {
 }
 
 // Tags contains the list of tags of a descriptor.
+//
+// In the JSON format, the list of tags may also contain an object with a
+// PreferredDescriptorName field, which names the descriptor to use instead of
+// the tagged descriptor. This is decoded into the PreferredDescriptor field
+// of the descriptor rather than into Tags.
 type Tags []string
 
 // GetTag returns whether the given tag is present in the descriptor.
@@ -519,6 +689,18 @@ func (tags Tags) GetTags() []string {
 	return list
 }
 
+// RangeTags calls fn for each tag present in the descriptor. Iteration stops
+// if fn returns false.
+//
+// RangeTags implements the rbxapi.TagRanger interface.
+func (tags Tags) RangeTags(fn func(tag string) bool) {
+	for _, tag := range tags {
+		if !fn(tag) {
+			return
+		}
+	}
+}
+
 // SetTag adds one or more tags to the list. Duplicate tags are removed.
 func (tags *Tags) SetTag(tag ...string) {
 	*tags = append(*tags, tag...)
@@ -0,0 +1,44 @@
+package rbxapijson_test
+
+import (
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"sync"
+	"testing"
+)
+
+func TestCopyShared(t *testing.T) {
+	prev := apitest.Generate(1, 100, 50)
+	next := apitest.Evolve(prev, 2)
+	orig := prev.Copy().(*rbxapijson.Root)
+
+	// CopyShared may be called concurrently on the same root.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev.CopyShared()
+		}()
+	}
+	wg.Wait()
+
+	shared := prev.CopyShared()
+	shared.Patch((&rbxapijson.Diff{Prev: prev, Next: next}).Diff())
+	for _, action := range (&rbxapijson.Diff{Prev: shared, Next: next}).Diff() {
+		t.Errorf("copy not patched: %s", action)
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: orig, Next: prev}).Diff() {
+		t.Errorf("source modified: %s", action)
+	}
+
+	// Patching the source leaves the copy unaffected.
+	later := apitest.Evolve(next, 2)
+	prev.Patch((&rbxapijson.Diff{Prev: prev, Next: later}).Diff())
+	for _, action := range (&rbxapijson.Diff{Prev: prev, Next: later}).Diff() {
+		t.Errorf("source not patched: %s", action)
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: next, Next: shared}).Diff() {
+		t.Errorf("copy modified: %s", action)
+	}
+}
@@ -0,0 +1,111 @@
+package rbxapijson
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Decoder decodes the descriptors of an API dump in JSON format
+// incrementally, holding only a single descriptor in memory at a time. This
+// allows very large dumps to be processed with bounded memory.
+type Decoder struct {
+	jd *json.Decoder
+	// array is the key of the array currently being decoded, or empty if
+	// the decoder is not within an array.
+	array string
+	// index is the position of the next descriptor within the array.
+	index   int
+	started bool
+	version int
+	err     error
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{jd: json.NewDecoder(r)}
+}
+
+// Decode returns the next descriptor of the dump, which is either a *Class or
+// an *Enum. Descriptors are returned in the order in which they appear in the
+// input. Returns io.EOF after the last descriptor has been returned.
+//
+// The version of the dump is checked when it is encountered. Because the
+// version may appear after the descriptors, a VersionError may be returned
+// only after some descriptors have been decoded.
+func (d *Decoder) Decode() (descriptor interface{}, err error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if descriptor, d.err = d.next(); d.err != nil {
+		return nil, d.err
+	}
+	return descriptor, nil
+}
+
+func (d *Decoder) next() (descriptor interface{}, err error) {
+	if !d.started {
+		if err := expectDelim(d.jd, '{'); err != nil {
+			return nil, err
+		}
+		d.started = true
+	}
+	for {
+		if d.array != "" {
+			if d.jd.More() {
+				path := d.array + "[" + strconv.Itoa(d.index) + "]"
+				d.index++
+				if d.array == "Classes" {
+					class := &Class{}
+					if err := d.jd.Decode(class); err != nil {
+						return nil, withPath(path, err)
+					}
+					return class, nil
+				}
+				enum := &Enum{}
+				if err := d.jd.Decode(enum); err != nil {
+					return nil, withPath(path, err)
+				}
+				return enum, nil
+			}
+			if err := expectDelim(d.jd, ']'); err != nil {
+				return nil, err
+			}
+			d.array = ""
+			continue
+		}
+		if !d.jd.More() {
+			if err := expectDelim(d.jd, '}'); err != nil {
+				return nil, err
+			}
+			if d.version != 1 {
+				return nil, errVersion(d.version)
+			}
+			return nil, io.EOF
+		}
+		tok, err := d.jd.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key, _ := tok.(string); key {
+		case "Version":
+			if err := d.jd.Decode(&d.version); err != nil {
+				return nil, err
+			}
+			if d.version != 1 {
+				return nil, errVersion(d.version)
+			}
+		case "Classes", "Enums":
+			if err := expectDelim(d.jd, '['); err != nil {
+				return nil, err
+			}
+			d.array = key
+			d.index = 0
+		default:
+			var skip json.RawMessage
+			if err := d.jd.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
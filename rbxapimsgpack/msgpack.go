@@ -0,0 +1,271 @@
+package rbxapimsgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// appendString appends a string.
+func appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xA0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xD9, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, 0xDA, byte(n>>8), byte(n))
+	default:
+		b = append(b, 0xDB)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, s...)
+}
+
+// appendInt appends an integer in its shortest form.
+func appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v < 128, v >= -32 && v < 0:
+		return append(b, byte(v))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(b, 0xD0, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return append(b, 0xD1, byte(v>>8), byte(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		b = append(b, 0xD2)
+		return binary.BigEndian.AppendUint32(b, uint32(v))
+	}
+	b = append(b, 0xD3)
+	return binary.BigEndian.AppendUint64(b, uint64(v))
+}
+
+// appendBool appends a boolean.
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xC3)
+	}
+	return append(b, 0xC2)
+}
+
+// appendHeader appends the header of an array or map of n elements, given
+// the type bytes of the fixed, 16-bit, and 32-bit forms.
+func appendHeader(b []byte, n int, fix, b16, b32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return append(b, b16, byte(n>>8), byte(n))
+	}
+	b = append(b, b32)
+	return binary.BigEndian.AppendUint32(b, uint32(n))
+}
+
+// appendArray appends the header of an array of n elements.
+func appendArray(b []byte, n int) []byte {
+	return appendHeader(b, n, 0x90, 0xDC, 0xDD)
+}
+
+// object builds a map with string keys.
+type object struct {
+	n    int
+	body []byte
+}
+
+// key appends a key, to be followed by its value.
+func (o *object) key(k string) *object {
+	o.n++
+	o.body = appendString(o.body, k)
+	return o
+}
+
+func (o *object) string(k, v string) {
+	o.key(k).body = appendString(o.body, v)
+}
+
+func (o *object) int(k string, v int64) {
+	o.key(k).body = appendInt(o.body, v)
+}
+
+func (o *object) bool(k string, v bool) {
+	o.key(k).body = appendBool(o.body, v)
+}
+
+func (o *object) object(k string, v *object) {
+	o.key(k).body = v.appendTo(o.body)
+}
+
+// strings appends an array of strings, omitting an empty array.
+func (o *object) strings(k string, v []string) {
+	if len(v) == 0 {
+		return
+	}
+	o.key(k).body = appendArray(o.body, len(v))
+	for _, s := range v {
+		o.body = appendString(o.body, s)
+	}
+}
+
+// objects appends an array of objects.
+func (o *object) objects(k string, v []*object) {
+	o.key(k).body = appendArray(o.body, len(v))
+	for _, e := range v {
+		o.body = e.appendTo(o.body)
+	}
+}
+
+// appendTo appends the map to b.
+func (o *object) appendTo(b []byte) []byte {
+	b = appendHeader(b, o.n, 0x80, 0xDE, 0xDF)
+	return append(b, o.body...)
+}
+
+var errTruncated = errors.New("unexpected end of data")
+
+// maxDepth limits the nesting of decoded values.
+const maxDepth = 64
+
+// reader decodes values into maps of type map[string]interface{}, arrays of
+// type []interface{}, and values of type string, []byte, int64, uint64,
+// float64, bool, or nil.
+type reader struct {
+	b     []byte
+	depth int
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || n > len(r.b) {
+		return nil, errTruncated
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *reader) uint(size int) (uint64, error) {
+	b, err := r.bytes(size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// length reads a length of the given size in bytes.
+func (r *reader) length(size int) (int, error) {
+	n, err := r.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(r.b)) {
+		// Every element occupies at least one byte.
+		return 0, errTruncated
+	}
+	return int(n), nil
+}
+
+func (r *reader) array(n int) (interface{}, error) {
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func (r *reader) object(n int) (interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, errors.New("map key is not a string")
+		}
+		if m[key], err = r.value(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// value reads a value.
+func (r *reader) value() (v interface{}, err error) {
+	if r.depth++; r.depth > maxDepth {
+		return nil, errors.New("maximum depth exceeded")
+	}
+	defer func() { r.depth-- }()
+	if len(r.b) == 0 {
+		return nil, errTruncated
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	switch {
+	case c <= 0x7F:
+		return int64(c), nil
+	case c >= 0xE0:
+		return int64(int8(c)), nil
+	case c&0xF0 == 0x80:
+		return r.object(int(c & 0x0F))
+	case c&0xF0 == 0x90:
+		return r.array(int(c & 0x0F))
+	case c&0xE0 == 0xA0:
+		b, err := r.bytes(int(c & 0x1F))
+		return string(b), err
+	}
+	var n int
+	switch c {
+	case 0xC0:
+		return nil, nil
+	case 0xC2:
+		return false, nil
+	case 0xC3:
+		return true, nil
+	case 0xC4, 0xC5, 0xC6, 0xD9, 0xDA, 0xDB:
+		size := 1 << (c - 0xC4)
+		if c >= 0xD9 {
+			size = 1 << (c - 0xD9)
+		}
+		if n, err = r.length(size); err != nil {
+			return nil, err
+		}
+		b, err := r.bytes(n)
+		if c >= 0xD9 {
+			return string(b), err
+		}
+		return append([]byte(nil), b...), err
+	case 0xCA:
+		u, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xCB:
+		u, err := r.uint(8)
+		return math.Float64frombits(u), err
+	case 0xCC, 0xCD, 0xCE, 0xCF:
+		return r.uint(1 << (c - 0xCC))
+	case 0xD0, 0xD1, 0xD2, 0xD3:
+		size := 1 << (c - 0xD0)
+		u, err := r.uint(size)
+		// Sign-extend from the size of the value.
+		shift := 64 - 8*uint(size)
+		return int64(u<<shift) >> shift, err
+	case 0xDC, 0xDD:
+		if n, err = r.length(2 << (c - 0xDC)); err != nil {
+			return nil, err
+		}
+		return r.array(n)
+	case 0xDE, 0xDF:
+		if n, err = r.length(2 << (c - 0xDE)); err != nil {
+			return nil, err
+		}
+		return r.object(n)
+	}
+	return nil, errors.New("unsupported type byte 0x" + strconv.FormatUint(uint64(c), 16))
+}
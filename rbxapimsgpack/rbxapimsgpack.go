@@ -0,0 +1,432 @@
+// The rbxapimsgpack package implements a codec for API structures encoded as
+// MessagePack.
+//
+// The encoding has the same layout as a JSON dump, with maps keyed by the
+// same field names, but is more compact and faster to decode, which makes it
+// suitable for caches and for communication between processes.
+//
+// Decoding produces a structure of the rbxapijson package, which also retains
+// the fields specific to JSON dumps. Unknown keys are ignored. The package
+// registers the "msgpack" format with the codec package.
+package rbxapimsgpack
+
+import (
+	"bytes"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/codec"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// Version is the version of the layout written by Encode.
+const Version = 1
+
+func encodeType(typ rbxapi.Type) *object {
+	o := &object{}
+	o.string("Category", typ.GetCategory())
+	o.string("Name", typ.GetName())
+	return o
+}
+
+func encodeParameters(params rbxapi.Parameters) []*object {
+	list := params.GetParameters()
+	objs := make([]*object, len(list))
+	for i, param := range list {
+		p := &object{}
+		p.object("Type", encodeType(param.GetType()))
+		p.string("Name", param.GetName())
+		if def, ok := param.GetDefault(); ok {
+			p.string("Default", def)
+		}
+		objs[i] = p
+	}
+	return objs
+}
+
+func encodeMember(member rbxapi.Member) *object {
+	o := &object{}
+	o.string("MemberType", member.GetMemberType())
+	o.string("Name", member.GetName())
+	switch member := member.(type) {
+	case rbxapi.Property:
+		p, _ := member.(*rbxapijson.Property)
+		o.object("ValueType", encodeType(member.GetValueType()))
+		if p != nil {
+			o.string("Category", p.Category)
+		}
+		read, write := member.GetSecurity()
+		security := &object{}
+		security.string("Read", read)
+		security.string("Write", write)
+		o.object("Security", security)
+		if p != nil {
+			serial := &object{}
+			serial.bool("CanLoad", p.CanLoad)
+			serial.bool("CanSave", p.CanSave)
+			o.object("Serialization", serial)
+			if p.HasDefault {
+				o.string("Default", p.Default)
+			}
+		}
+	case rbxapi.Function:
+		// Also matches callbacks.
+		o.objects("Parameters", encodeParameters(member.GetParameters()))
+		o.object("ReturnType", encodeType(member.GetReturnType()))
+		o.string("Security", member.GetSecurity())
+	case rbxapi.Event:
+		o.objects("Parameters", encodeParameters(member.GetParameters()))
+		o.string("Security", member.GetSecurity())
+	}
+	var threadSafety, preferred string
+	var capabilities []string
+	switch member := member.(type) {
+	case *rbxapijson.Property:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	case *rbxapijson.Function:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	case *rbxapijson.Event:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	case *rbxapijson.Callback:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	}
+	if threadSafety != "" {
+		o.string("ThreadSafety", threadSafety)
+	}
+	o.strings("Capabilities", capabilities)
+	o.strings("Tags", member.GetTags())
+	o.preferred(preferred)
+	return o
+}
+
+// preferred appends the name of a preferred descriptor, omitting an empty
+// name. In a JSON dump, the name is held by an object within the list of
+// tags, which is kept separate here so that tags remain a list of strings.
+func (o *object) preferred(name string) {
+	if name != "" {
+		o.string("PreferredDescriptor", name)
+	}
+}
+
+// Encode writes root to w. Fields specific to JSON dumps are written if root
+// contains descriptors of the rbxapijson package.
+func Encode(w io.Writer, root rbxapi.Root) error {
+	classes := root.GetClasses()
+	cobjs := make([]*object, len(classes))
+	for i, class := range classes {
+		c := &object{}
+		c.string("Name", class.GetName())
+		c.string("Superclass", class.GetSuperclass())
+		if class, ok := class.(*rbxapijson.Class); ok {
+			c.string("MemoryCategory", class.MemoryCategory)
+			c.strings("Capabilities", class.Capabilities)
+		}
+		members := class.GetMembers()
+		mobjs := make([]*object, len(members))
+		for j, member := range members {
+			mobjs[j] = encodeMember(member)
+		}
+		c.objects("Members", mobjs)
+		c.strings("Tags", class.GetTags())
+		if class, ok := class.(*rbxapijson.Class); ok {
+			c.preferred(class.PreferredDescriptor)
+		}
+		cobjs[i] = c
+	}
+	enums := root.GetEnums()
+	eobjs := make([]*object, len(enums))
+	for i, enum := range enums {
+		e := &object{}
+		e.string("Name", enum.GetName())
+		items := enum.GetEnumItems()
+		iobjs := make([]*object, len(items))
+		for j, item := range items {
+			o := &object{}
+			o.string("Name", item.GetName())
+			o.int("Value", int64(item.GetValue()))
+			o.strings("Tags", item.GetTags())
+			if item, ok := item.(*rbxapijson.EnumItem); ok {
+				o.preferred(item.PreferredDescriptor)
+			}
+			iobjs[j] = o
+		}
+		e.objects("Items", iobjs)
+		e.strings("Tags", enum.GetTags())
+		if enum, ok := enum.(*rbxapijson.Enum); ok {
+			e.preferred(enum.PreferredDescriptor)
+		}
+		eobjs[i] = e
+	}
+	r := &object{}
+	// Version is written first, so that the format can be sniffed.
+	r.int("Version", Version)
+	r.objects("Classes", cobjs)
+	r.objects("Enums", eobjs)
+	_, err := w.Write(r.appendTo(nil))
+	return err
+}
+
+// decoder converts decoded values into descriptors, keeping the first error
+// that occurs.
+type decoder struct {
+	err error
+}
+
+func (d *decoder) fail(key string) {
+	if d.err == nil {
+		d.err = errors.New("field " + strconv.Quote(key) + " has wrong type")
+	}
+}
+
+func (d *decoder) object(v interface{}, key string) map[string]interface{} {
+	o, ok := v.(map[string]interface{})
+	if !ok && v != nil {
+		d.fail(key)
+	}
+	return o
+}
+
+func (d *decoder) array(o map[string]interface{}, key string) []interface{} {
+	a, ok := o[key].([]interface{})
+	if !ok && o[key] != nil {
+		d.fail(key)
+	}
+	return a
+}
+
+func (d *decoder) string(o map[string]interface{}, key string) string {
+	s, ok := o[key].(string)
+	if !ok && o[key] != nil {
+		d.fail(key)
+	}
+	return s
+}
+
+func (d *decoder) bool(o map[string]interface{}, key string) bool {
+	b, ok := o[key].(bool)
+	if !ok && o[key] != nil {
+		d.fail(key)
+	}
+	return b
+}
+
+func (d *decoder) int(o map[string]interface{}, key string) int64 {
+	switch v := o[key].(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case nil:
+	default:
+		d.fail(key)
+	}
+	return 0
+}
+
+func (d *decoder) tags(o map[string]interface{}) rbxapijson.Tags {
+	return rbxapijson.Tags(d.strings(o, "Tags"))
+}
+
+// strings returns the array of strings of o at key.
+func (d *decoder) strings(o map[string]interface{}, key string) (list []string) {
+	for _, v := range d.array(o, key) {
+		s, ok := v.(string)
+		if !ok {
+			d.fail(key)
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+func (d *decoder) typ(o map[string]interface{}, key string) rbxapijson.Type {
+	t := d.object(o[key], key)
+	return rbxapijson.Type{Category: d.string(t, "Category"), Name: d.string(t, "Name")}
+}
+
+func (d *decoder) parameters(o map[string]interface{}) []rbxapijson.Parameter {
+	list := d.array(o, "Parameters")
+	params := make([]rbxapijson.Parameter, len(list))
+	for i, v := range list {
+		p := d.object(v, "Parameters")
+		params[i] = rbxapijson.Parameter{Type: d.typ(p, "Type"), Name: d.string(p, "Name")}
+		if _, ok := p["Default"]; ok {
+			params[i].HasDefault = true
+			params[i].Default = d.string(p, "Default")
+		}
+	}
+	return params
+}
+
+func (d *decoder) member(o map[string]interface{}) rbxapi.Member {
+	name := d.string(o, "Name")
+	switch typ := d.string(o, "MemberType"); typ {
+	case "Property":
+		security := d.object(o["Security"], "Security")
+		serial := d.object(o["Serialization"], "Serialization")
+		prop := &rbxapijson.Property{
+			Name:                name,
+			ValueType:           d.typ(o, "ValueType"),
+			Category:            d.string(o, "Category"),
+			ReadSecurity:        d.string(security, "Read"),
+			WriteSecurity:       d.string(security, "Write"),
+			CanLoad:             d.bool(serial, "CanLoad"),
+			CanSave:             d.bool(serial, "CanSave"),
+			ThreadSafety:        d.string(o, "ThreadSafety"),
+			Capabilities:        d.strings(o, "Capabilities"),
+			Tags:                d.tags(o),
+			PreferredDescriptor: d.string(o, "PreferredDescriptor"),
+		}
+		if _, ok := o["Default"]; ok {
+			prop.HasDefault = true
+			prop.Default = d.string(o, "Default")
+		}
+		return prop
+	case "Function":
+		return &rbxapijson.Function{
+			Name:                name,
+			Parameters:          d.parameters(o),
+			ReturnType:          d.typ(o, "ReturnType"),
+			Security:            d.string(o, "Security"),
+			ThreadSafety:        d.string(o, "ThreadSafety"),
+			Capabilities:        d.strings(o, "Capabilities"),
+			Tags:                d.tags(o),
+			PreferredDescriptor: d.string(o, "PreferredDescriptor"),
+		}
+	case "Event":
+		return &rbxapijson.Event{
+			Name:                name,
+			Parameters:          d.parameters(o),
+			Security:            d.string(o, "Security"),
+			ThreadSafety:        d.string(o, "ThreadSafety"),
+			Capabilities:        d.strings(o, "Capabilities"),
+			Tags:                d.tags(o),
+			PreferredDescriptor: d.string(o, "PreferredDescriptor"),
+		}
+	case "Callback":
+		return &rbxapijson.Callback{
+			Name:                name,
+			Parameters:          d.parameters(o),
+			ReturnType:          d.typ(o, "ReturnType"),
+			Security:            d.string(o, "Security"),
+			ThreadSafety:        d.string(o, "ThreadSafety"),
+			Capabilities:        d.strings(o, "Capabilities"),
+			Tags:                d.tags(o),
+			PreferredDescriptor: d.string(o, "PreferredDescriptor"),
+		}
+	default:
+		if d.err == nil {
+			d.err = errors.New("member " + strconv.Quote(name) + " has unknown type " + strconv.Quote(typ))
+		}
+		return nil
+	}
+}
+
+func (d *decoder) class(o map[string]interface{}) *rbxapijson.Class {
+	class := &rbxapijson.Class{
+		Name:                d.string(o, "Name"),
+		Superclass:          d.string(o, "Superclass"),
+		MemoryCategory:      d.string(o, "MemoryCategory"),
+		Capabilities:        d.strings(o, "Capabilities"),
+		Members:             []rbxapi.Member{},
+		Tags:                d.tags(o),
+		PreferredDescriptor: d.string(o, "PreferredDescriptor"),
+	}
+	for _, v := range d.array(o, "Members") {
+		if member := d.member(d.object(v, "Members")); member != nil {
+			class.Members = append(class.Members, member)
+		}
+	}
+	return class
+}
+
+func (d *decoder) enum(o map[string]interface{}) *rbxapijson.Enum {
+	enum := &rbxapijson.Enum{
+		Name:                d.string(o, "Name"),
+		Items:               []*rbxapijson.EnumItem{},
+		Tags:                d.tags(o),
+		PreferredDescriptor: d.string(o, "PreferredDescriptor"),
+	}
+	for _, v := range d.array(o, "Items") {
+		item := d.object(v, "Items")
+		enum.Items = append(enum.Items, &rbxapijson.EnumItem{
+			Name:                d.string(item, "Name"),
+			Value:               int(d.int(item, "Value")),
+			Tags:                d.tags(item),
+			PreferredDescriptor: d.string(item, "PreferredDescriptor"),
+		})
+	}
+	return enum
+}
+
+// Decode parses an API structure from r.
+func Decode(r io.Reader) (root *rbxapijson.Root, err error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	rd := &reader{b: b}
+	v, err := rd.value()
+	if err != nil {
+		return nil, err
+	}
+	if len(rd.b) > 0 {
+		return nil, errors.New("unexpected data after root")
+	}
+	var d decoder
+	o := d.object(v, "root")
+	if d.err != nil {
+		return nil, d.err
+	}
+	if version := d.int(o, "Version"); d.err == nil && version != Version {
+		return nil, errors.New("version " + strconv.FormatInt(version, 10) + " is unsupported")
+	}
+	root = &rbxapijson.Root{Classes: []*rbxapijson.Class{}, Enums: []*rbxapijson.Enum{}}
+	for _, v := range d.array(o, "Classes") {
+		root.Classes = append(root.Classes, d.class(d.object(v, "Classes")))
+	}
+	for _, v := range d.array(o, "Enums") {
+		root.Enums = append(root.Enums, d.enum(d.object(v, "Enums")))
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return root, nil
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Decode(r io.Reader) (rbxapi.Root, error) {
+	root, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (msgpackCodec) Encode(w io.Writer, root rbxapi.Root) error {
+	return Encode(w, root)
+}
+
+// Convert returns root as a structure of the rbxapijson package.
+func (msgpackCodec) Convert(root rbxapi.Root) rbxapi.Root {
+	return codec.JSON.Convert(root)
+}
+
+// Codec is the codec of the "msgpack" format.
+var Codec codec.Codec = msgpackCodec{}
+
+func init() {
+	codec.Register(codec.Format{
+		Name:       "msgpack",
+		Extensions: []string{".msgpack", ".mpk"},
+		Sniff: func(prefix []byte) bool {
+			// A map whose first key is Version.
+			return len(prefix) > 0 && prefix[0]&0xF0 == 0x80 &&
+				bytes.HasPrefix(prefix[1:], []byte("\xA7Version"))
+		},
+		Codec: Codec,
+	})
+}
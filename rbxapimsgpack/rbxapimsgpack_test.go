@@ -0,0 +1,81 @@
+package rbxapimsgpack_test
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/codec"
+	"github.com/karl-police/rbxapi/diff"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"github.com/karl-police/rbxapi/rbxapimsgpack"
+	"testing"
+)
+
+func TestRoundTripJSON(t *testing.T) {
+	root := apitest.Generate(1, 100, 50)
+	var buf bytes.Buffer
+	if err := rbxapimsgpack.Encode(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := rbxapimsgpack.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: root, Next: decoded}).Diff() {
+		t.Errorf("lost: %s", action)
+	}
+}
+
+func TestRoundTripDump(t *testing.T) {
+	var text bytes.Buffer
+	if err := rbxapidump.Encode(&text, rbxapiconv.ToDump(apitest.Generate(1, 100, 50))); err != nil {
+		t.Fatal(err)
+	}
+	root, err := rbxapidump.Decode(bytes.NewReader(text.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converted, lost := codec.Convert(rbxapimsgpack.Codec, root)
+	for _, action := range lost {
+		t.Errorf("lost in conversion: %s", action)
+	}
+	var buf bytes.Buffer
+	if err := rbxapimsgpack.Codec.Encode(&buf, converted); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := rbxapimsgpack.Codec.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := codec.Dump.Convert(decoded).(*rbxapidump.Root)
+	for _, action := range (&diff.Diff{Prev: root, Next: back}).Diff() {
+		t.Errorf("lost: %s", action)
+	}
+	var out bytes.Buffer
+	if err := rbxapidump.Encode(&out, back); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), text.Bytes()) {
+		t.Error("dump encoded after round trip differs from original")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	root := apitest.Generate(1, 20, 10)
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, root, "msgpack", ""); err != nil {
+		t.Fatal(err)
+	}
+	decoded, format, err := codec.Decode(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format.Name != "msgpack" {
+		t.Fatalf("detected format %q, expected msgpack", format.Name)
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: root, Next: decoded.(*rbxapijson.Root)}).Diff() {
+		t.Errorf("lost: %s", action)
+	}
+}
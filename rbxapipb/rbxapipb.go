@@ -0,0 +1,427 @@
+// The rbxapipb package implements a codec for API structures encoded as
+// Protocol Buffers.
+//
+// The messages are described by the schema in api.proto, which other
+// languages may use to generate decoders. The binary encoding is compact and
+// considerably faster to decode than the JSON format.
+//
+// Decoding produces a structure of the rbxapijson package, which also retains
+// the fields specific to JSON dumps. Unknown fields are ignored, so that
+// fields added to later versions of the schema do not prevent decoding. The
+// package registers the "protobuf" format with the codec package.
+package rbxapipb
+
+import (
+	"bytes"
+	"errors"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/codec"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// Version is the version of the schema written by Encode.
+const Version = 1
+
+func encodeType(typ rbxapi.Type) []byte {
+	var b []byte
+	b = appendString(b, 1, typ.GetCategory())
+	b = appendString(b, 2, typ.GetName())
+	return b
+}
+
+func encodeParameters(b []byte, field int, params rbxapi.Parameters) []byte {
+	for _, param := range params.GetParameters() {
+		var p []byte
+		p = appendBytes(p, 1, encodeType(param.GetType()))
+		p = appendString(p, 2, param.GetName())
+		if def, ok := param.GetDefault(); ok {
+			p = appendBytes(p, 3, []byte(def))
+		}
+		b = appendBytes(b, field, p)
+	}
+	return b
+}
+
+func encodeMember(member rbxapi.Member) []byte {
+	var b []byte
+	b = appendString(b, 1, member.GetName())
+	b = appendStrings(b, 2, member.GetTags())
+	var threadSafety, preferred string
+	var capabilities []string
+	switch member := member.(type) {
+	case *rbxapijson.Property:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	case *rbxapijson.Function:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	case *rbxapijson.Event:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	case *rbxapijson.Callback:
+		threadSafety, capabilities, preferred = member.ThreadSafety, member.Capabilities, member.PreferredDescriptor
+	}
+	b = appendString(b, 7, threadSafety)
+	b = appendStrings(b, 8, capabilities)
+	b = appendString(b, 9, preferred)
+	var m []byte
+	switch member := member.(type) {
+	case rbxapi.Property:
+		p, _ := member.(*rbxapijson.Property)
+		read, write := member.GetSecurity()
+		m = appendBytes(m, 1, encodeType(member.GetValueType()))
+		if p != nil {
+			m = appendString(m, 2, p.Category)
+		}
+		m = appendString(m, 3, read)
+		m = appendString(m, 4, write)
+		if p != nil {
+			m = appendBool(m, 5, p.CanLoad)
+			m = appendBool(m, 6, p.CanSave)
+			if p.HasDefault {
+				m = appendBytes(m, 7, []byte(p.Default))
+			}
+		}
+		return appendBytes(b, 3, m)
+	case rbxapi.Function:
+		// Also matches callbacks, which have the same fields.
+		m = encodeParameters(m, 1, member.GetParameters())
+		m = appendBytes(m, 2, encodeType(member.GetReturnType()))
+		m = appendString(m, 3, member.GetSecurity())
+		if member.GetMemberType() == "Callback" {
+			return appendBytes(b, 6, m)
+		}
+		return appendBytes(b, 4, m)
+	case rbxapi.Event:
+		m = encodeParameters(m, 1, member.GetParameters())
+		m = appendString(m, 2, member.GetSecurity())
+		return appendBytes(b, 5, m)
+	}
+	return b
+}
+
+// Encode writes root to w. Fields specific to JSON dumps are written if root
+// contains descriptors of the rbxapijson package.
+func Encode(w io.Writer, root rbxapi.Root) error {
+	b := appendUint(nil, 1, Version)
+	for _, class := range root.GetClasses() {
+		var c []byte
+		c = appendString(c, 1, class.GetName())
+		c = appendString(c, 2, class.GetSuperclass())
+		if class, ok := class.(*rbxapijson.Class); ok {
+			c = appendString(c, 3, class.MemoryCategory)
+		}
+		for _, member := range class.GetMembers() {
+			c = appendBytes(c, 4, encodeMember(member))
+		}
+		c = appendStrings(c, 5, class.GetTags())
+		if class, ok := class.(*rbxapijson.Class); ok {
+			c = appendStrings(c, 6, class.Capabilities)
+			c = appendString(c, 7, class.PreferredDescriptor)
+		}
+		b = appendBytes(b, 2, c)
+	}
+	for _, enum := range root.GetEnums() {
+		var e []byte
+		e = appendString(e, 1, enum.GetName())
+		for _, item := range enum.GetEnumItems() {
+			var i []byte
+			i = appendString(i, 1, item.GetName())
+			i = appendUint(i, 2, uint64(int64(item.GetValue())))
+			i = appendStrings(i, 3, item.GetTags())
+			if item, ok := item.(*rbxapijson.EnumItem); ok {
+				i = appendString(i, 4, item.PreferredDescriptor)
+			}
+			e = appendBytes(e, 2, i)
+		}
+		e = appendStrings(e, 3, enum.GetTags())
+		if enum, ok := enum.(*rbxapijson.Enum); ok {
+			e = appendString(e, 4, enum.PreferredDescriptor)
+		}
+		b = appendBytes(b, 3, e)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// decoder decodes messages, keeping the first error that occurs.
+type decoder struct {
+	err error
+}
+
+// string returns the value of a string field.
+func (d *decoder) string(r *reader) string {
+	if err := r.expect(wireBytes); err != nil {
+		d.fail(err)
+		return ""
+	}
+	return string(r.data)
+}
+
+// message decodes an embedded message by calling fn for each of its fields.
+func (d *decoder) message(r *reader, fn func(r *reader)) {
+	if err := r.expect(wireBytes); err != nil {
+		d.fail(err)
+		return
+	}
+	d.fields(r.data, fn)
+}
+
+// fields calls fn for each field of the message encoded in b.
+func (d *decoder) fields(b []byte, fn func(r *reader)) {
+	r := &reader{b: b}
+	for d.err == nil {
+		ok, err := r.next()
+		if err != nil {
+			d.fail(err)
+		}
+		if !ok {
+			return
+		}
+		fn(r)
+	}
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) varint(r *reader) uint64 {
+	if err := r.expect(wireVarint); err != nil {
+		d.fail(err)
+	}
+	return r.value
+}
+
+func (d *decoder) typ(r *reader) (typ rbxapijson.Type) {
+	d.message(r, func(r *reader) {
+		switch r.field {
+		case 1:
+			typ.Category = d.string(r)
+		case 2:
+			typ.Name = d.string(r)
+		}
+	})
+	return typ
+}
+
+func (d *decoder) parameter(r *reader) (param rbxapijson.Parameter) {
+	d.message(r, func(r *reader) {
+		switch r.field {
+		case 1:
+			param.Type = d.typ(r)
+		case 2:
+			param.Name = d.string(r)
+		case 3:
+			param.HasDefault = true
+			param.Default = d.string(r)
+		}
+	})
+	return param
+}
+
+func (d *decoder) member(r *reader) (member rbxapi.Member) {
+	var name, threadSafety, preferred string
+	var tags rbxapijson.Tags
+	var capabilities []string
+	d.message(r, func(r *reader) {
+		switch r.field {
+		case 1:
+			name = d.string(r)
+		case 2:
+			tags = append(tags, d.string(r))
+		case 7:
+			threadSafety = d.string(r)
+		case 8:
+			capabilities = append(capabilities, d.string(r))
+		case 9:
+			preferred = d.string(r)
+		case 3:
+			p := &rbxapijson.Property{}
+			d.message(r, func(r *reader) {
+				switch r.field {
+				case 1:
+					p.ValueType = d.typ(r)
+				case 2:
+					p.Category = d.string(r)
+				case 3:
+					p.ReadSecurity = d.string(r)
+				case 4:
+					p.WriteSecurity = d.string(r)
+				case 5:
+					p.CanLoad = d.varint(r) != 0
+				case 6:
+					p.CanSave = d.varint(r) != 0
+				case 7:
+					p.HasDefault = true
+					p.Default = d.string(r)
+				}
+			})
+			member = p
+		case 4, 6:
+			f := &rbxapijson.Function{Parameters: []rbxapijson.Parameter{}}
+			d.message(r, func(r *reader) {
+				switch r.field {
+				case 1:
+					f.Parameters = append(f.Parameters, d.parameter(r))
+				case 2:
+					f.ReturnType = d.typ(r)
+				case 3:
+					f.Security = d.string(r)
+				}
+			})
+			if r.field == 6 {
+				member = &rbxapijson.Callback{Parameters: f.Parameters, ReturnType: f.ReturnType, Security: f.Security}
+			} else {
+				member = f
+			}
+		case 5:
+			e := &rbxapijson.Event{Parameters: []rbxapijson.Parameter{}}
+			d.message(r, func(r *reader) {
+				switch r.field {
+				case 1:
+					e.Parameters = append(e.Parameters, d.parameter(r))
+				case 2:
+					e.Security = d.string(r)
+				}
+			})
+			member = e
+		}
+	})
+	switch m := member.(type) {
+	case *rbxapijson.Property:
+		m.Name, m.Tags = name, tags
+		m.ThreadSafety, m.Capabilities, m.PreferredDescriptor = threadSafety, capabilities, preferred
+	case *rbxapijson.Function:
+		m.Name, m.Tags = name, tags
+		m.ThreadSafety, m.Capabilities, m.PreferredDescriptor = threadSafety, capabilities, preferred
+	case *rbxapijson.Event:
+		m.Name, m.Tags = name, tags
+		m.ThreadSafety, m.Capabilities, m.PreferredDescriptor = threadSafety, capabilities, preferred
+	case *rbxapijson.Callback:
+		m.Name, m.Tags = name, tags
+		m.ThreadSafety, m.Capabilities, m.PreferredDescriptor = threadSafety, capabilities, preferred
+	case nil:
+		d.fail(errors.New("member " + strconv.Quote(name) + " has unknown kind"))
+	}
+	return member
+}
+
+func (d *decoder) class(r *reader) *rbxapijson.Class {
+	class := &rbxapijson.Class{Members: []rbxapi.Member{}}
+	d.message(r, func(r *reader) {
+		switch r.field {
+		case 1:
+			class.Name = d.string(r)
+		case 2:
+			class.Superclass = d.string(r)
+		case 3:
+			class.MemoryCategory = d.string(r)
+		case 4:
+			if member := d.member(r); member != nil {
+				class.Members = append(class.Members, member)
+			}
+		case 5:
+			class.Tags = append(class.Tags, d.string(r))
+		case 6:
+			class.Capabilities = append(class.Capabilities, d.string(r))
+		case 7:
+			class.PreferredDescriptor = d.string(r)
+		}
+	})
+	return class
+}
+
+func (d *decoder) enum(r *reader) *rbxapijson.Enum {
+	enum := &rbxapijson.Enum{Items: []*rbxapijson.EnumItem{}}
+	d.message(r, func(r *reader) {
+		switch r.field {
+		case 1:
+			enum.Name = d.string(r)
+		case 2:
+			item := &rbxapijson.EnumItem{}
+			d.message(r, func(r *reader) {
+				switch r.field {
+				case 1:
+					item.Name = d.string(r)
+				case 2:
+					item.Value = int(int64(d.varint(r)))
+				case 3:
+					item.Tags = append(item.Tags, d.string(r))
+				case 4:
+					item.PreferredDescriptor = d.string(r)
+				}
+			})
+			enum.Items = append(enum.Items, item)
+		case 3:
+			enum.Tags = append(enum.Tags, d.string(r))
+		case 4:
+			enum.PreferredDescriptor = d.string(r)
+		}
+	})
+	return enum
+}
+
+// Decode parses an API structure from r.
+func Decode(r io.Reader) (root *rbxapijson.Root, err error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var d decoder
+	var version uint64
+	root = &rbxapijson.Root{Classes: []*rbxapijson.Class{}, Enums: []*rbxapijson.Enum{}}
+	d.fields(b, func(r *reader) {
+		switch r.field {
+		case 1:
+			version = d.varint(r)
+		case 2:
+			root.Classes = append(root.Classes, d.class(r))
+		case 3:
+			root.Enums = append(root.Enums, d.enum(r))
+		}
+	})
+	if d.err != nil {
+		return nil, d.err
+	}
+	if version != Version {
+		return nil, errors.New("version " + strconv.FormatUint(version, 10) + " is unsupported")
+	}
+	return root, nil
+}
+
+type pbCodec struct{}
+
+func (pbCodec) Decode(r io.Reader) (rbxapi.Root, error) {
+	root, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (pbCodec) Encode(w io.Writer, root rbxapi.Root) error {
+	return Encode(w, root)
+}
+
+// Convert returns root as a structure of the rbxapijson package.
+func (pbCodec) Convert(root rbxapi.Root) rbxapi.Root {
+	return codec.JSON.Convert(root)
+}
+
+// Codec is the codec of the "protobuf" format.
+var Codec codec.Codec = pbCodec{}
+
+func init() {
+	codec.Register(codec.Format{
+		Name:       "protobuf",
+		Extensions: []string{".pb", ".binpb"},
+		Sniff: func(prefix []byte) bool {
+			// The version field is always encoded first.
+			return bytes.HasPrefix(prefix, []byte{1<<3 | wireVarint, Version})
+		},
+		Codec: Codec,
+	})
+}
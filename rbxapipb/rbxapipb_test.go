@@ -0,0 +1,24 @@
+package rbxapipb_test
+
+import (
+	"bytes"
+	"github.com/karl-police/rbxapi/internal/apitest"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"github.com/karl-police/rbxapi/rbxapipb"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	root := apitest.Generate(1, 100, 50)
+	var buf bytes.Buffer
+	if err := rbxapipb.Encode(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := rbxapipb.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range (&rbxapijson.Diff{Prev: root, Next: decoded}).Diff() {
+		t.Errorf("lost: %s", action)
+	}
+}
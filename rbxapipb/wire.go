@@ -0,0 +1,154 @@
+package rbxapipb
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// appendVarint appends the base 128 encoding of v to b.
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// appendTag appends the key of a field.
+func appendTag(b []byte, field, wire int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wire))
+}
+
+// appendUint appends a varint field, omitting the zero value.
+func appendUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return appendVarint(appendTag(b, field, wireVarint), v)
+}
+
+// appendBool appends a boolean field, omitting false.
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendUint(b, field, 1)
+}
+
+// appendBytes appends a length-delimited field, regardless of its length.
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendString appends a string field, omitting the empty string.
+func appendString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendStrings appends a repeated string field.
+func appendStrings(b []byte, field int, v []string) []byte {
+	for _, s := range v {
+		b = appendBytes(b, field, []byte(s))
+	}
+	return b
+}
+
+var (
+	errTruncated = errors.New("unexpected end of message")
+	errOverflow  = errors.New("varint overflows 64 bits")
+)
+
+// reader decodes the fields of a message.
+type reader struct {
+	b []byte
+	// field and wire are the number and wire type of the current field.
+	field int
+	wire  int
+	// value holds the value of a varint field, and data that of a
+	// length-delimited field.
+	value uint64
+	data  []byte
+}
+
+// varint reads a varint.
+func (r *reader) varint() (uint64, error) {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 64 {
+			return 0, errOverflow
+		}
+		if len(r.b) == 0 {
+			return 0, errTruncated
+		}
+		c := r.b[0]
+		r.b = r.b[1:]
+		v |= uint64(c&0x7F) << shift
+		if c < 0x80 {
+			return v, nil
+		}
+	}
+}
+
+// next reads the next field, returning false at the end of the message. The
+// values of fixed-size fields are skipped, as the schema has no such fields.
+func (r *reader) next() (bool, error) {
+	if len(r.b) == 0 {
+		return false, nil
+	}
+	key, err := r.varint()
+	if err != nil {
+		return false, err
+	}
+	r.field, r.wire = int(key>>3), int(key&7)
+	r.value, r.data = 0, nil
+	switch r.wire {
+	case wireVarint:
+		r.value, err = r.varint()
+	case wireBytes:
+		var n uint64
+		if n, err = r.varint(); err != nil {
+			break
+		}
+		if n > uint64(len(r.b)) {
+			return false, errTruncated
+		}
+		r.data, r.b = r.b[:n], r.b[n:]
+	case wireFixed64:
+		if len(r.b) < 8 {
+			return false, errTruncated
+		}
+		r.b = r.b[8:]
+	case wireFixed32:
+		if len(r.b) < 4 {
+			return false, errTruncated
+		}
+		r.b = r.b[4:]
+	default:
+		return false, errors.New("unsupported wire type " + strconv.Itoa(r.wire))
+	}
+	return err == nil, err
+}
+
+// expect returns an error if the current field is not of the given wire
+// type.
+func (r *reader) expect(wire int) error {
+	if r.wire != wire {
+		return errors.New("field " + strconv.Itoa(r.field) + " has wrong wire type")
+	}
+	return nil
+}
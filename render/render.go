@@ -0,0 +1,225 @@
+// The render package produces human-readable changelogs from lists of patch
+// actions, such as those returned by the diff package.
+//
+// Actions are grouped by the class or enum to which they apply, in the order
+// in which each class or enum first appears. Within a group, additions are
+// listed first, then removals, then changes. Each action is described by a
+// sentence such as "Added Property Workspace.FluidForces" or "Changed
+// ReturnType of Function Instance.Destroy from void to bool".
+package render
+
+import (
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/patch"
+	"html"
+	"io"
+	"strings"
+)
+
+// Group contains the actions that apply to a single class or enum, including
+// its members or items.
+type Group struct {
+	// Kind is "Class" or "Enum".
+	Kind string
+	// Name is the name of the class or enum.
+	Name string
+	// Added contains the actions that add descriptors.
+	Added []patch.Action
+	// Removed contains the actions that remove descriptors.
+	Removed []patch.Action
+	// Changed contains the actions that change fields of descriptors.
+	Changed []patch.Action
+}
+
+// Title returns the heading of the group, such as "Class Workspace".
+func (g *Group) Title() string {
+	return g.Kind + " " + g.Name
+}
+
+// Actions returns the actions of the group, ordered by kind.
+func (g *Group) Actions() []patch.Action {
+	actions := make([]patch.Action, 0, len(g.Added)+len(g.Removed)+len(g.Changed))
+	actions = append(actions, g.Added...)
+	actions = append(actions, g.Removed...)
+	actions = append(actions, g.Changed...)
+	return actions
+}
+
+// subject returns the kind and name of the group to which an action belongs,
+// followed by the kind and qualified name of the descriptor to which the
+// action applies.
+func subject(action patch.Action) (groupKind, group, kind, name string) {
+	switch a := action.(type) {
+	case patch.Member:
+		if c := a.GetClass(); c != nil {
+			group = c.GetName()
+		}
+		kind, name = "Member", group+"."
+		if m := a.GetMember(); m != nil {
+			kind = m.GetMemberType()
+			name += m.GetName()
+		}
+		return "Class", group, kind, name
+	case patch.Class:
+		if c := a.GetClass(); c != nil {
+			group = c.GetName()
+		}
+		return "Class", group, "Class", group
+	case patch.EnumItem:
+		if e := a.GetEnum(); e != nil {
+			group = e.GetName()
+		}
+		name = group + "."
+		if i := a.GetEnumItem(); i != nil {
+			name += i.GetName()
+		}
+		return "Enum", group, "EnumItem", name
+	case patch.Enum:
+		if e := a.GetEnum(); e != nil {
+			group = e.GetName()
+		}
+		return "Enum", group, "Enum", group
+	}
+	return "", "", "", ""
+}
+
+// Groups returns actions grouped by the class or enum to which they apply.
+func Groups(actions []patch.Action) []*Group {
+	var groups []*Group
+	index := map[[2]string]*Group{}
+	for _, action := range actions {
+		groupKind, name, _, _ := subject(action)
+		k := [2]string{groupKind, name}
+		g := index[k]
+		if g == nil {
+			g = &Group{Kind: groupKind, Name: name}
+			index[k] = g
+			groups = append(groups, g)
+		}
+		switch action.GetType() {
+		case patch.Add:
+			g.Added = append(g.Added, action)
+		case patch.Remove:
+			g.Removed = append(g.Removed, action)
+		case patch.Change:
+			g.Changed = append(g.Changed, action)
+		}
+	}
+	return groups
+}
+
+// Value returns a human-readable representation of the value of a field, as
+// returned by the GetPrev and GetNext methods of an action.
+func Value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	case rbxapi.Type:
+		return v.String()
+	case rbxapi.Parameters:
+		params := v.GetParameters()
+		ss := make([]string, len(params))
+		for i, p := range params {
+			ss[i] = p.GetType().String() + " " + p.GetName()
+			if d, ok := p.GetDefault(); ok {
+				ss[i] += " = " + d
+			}
+		}
+		return "(" + strings.Join(ss, ", ") + ")"
+	}
+	return fmt.Sprint(v)
+}
+
+// verbs maps the type of an action to the verb that describes it.
+var verbs = map[patch.Type]string{
+	patch.Add:    "Added",
+	patch.Remove: "Removed",
+	patch.Change: "Changed",
+}
+
+// Describe returns a sentence describing an action.
+func Describe(action patch.Action) string {
+	_, _, kind, name := subject(action)
+	verb := verbs[action.GetType()]
+	if action.GetType() == patch.Change {
+		return verb + " " + action.GetField() + " of " + kind + " " + name +
+			" from " + Value(action.GetPrev()) + " to " + Value(action.GetNext())
+	}
+	return verb + " " + kind + " " + name
+}
+
+// Text writes a changelog of actions to w as plain text. Each group is headed
+// by its title, followed by an indented line per action.
+func Text(w io.Writer, actions []patch.Action) error {
+	for i, g := range Groups(actions) {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, g.Title()); err != nil {
+			return err
+		}
+		for _, action := range g.Actions() {
+			if _, err := fmt.Fprintf(w, "\t%s\n", Describe(action)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mdEscaper escapes characters that have meaning in Markdown.
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+	`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`,
+)
+
+// Markdown writes a changelog of actions to w as Markdown. Each group is a
+// section headed by its title, containing a list item per action.
+func Markdown(w io.Writer, actions []patch.Action) error {
+	for i, g := range Groups(actions) {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "## %s\n\n", mdEscaper.Replace(g.Title())); err != nil {
+			return err
+		}
+		for _, action := range g.Actions() {
+			if _, err := fmt.Fprintf(w, "- %s\n", mdEscaper.Replace(Describe(action))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HTML writes a changelog of actions to w as an HTML fragment. Each group is a
+// section element containing a heading and a list. Each list item has a class
+// of "add", "remove", or "change", according to the type of its action.
+func HTML(w io.Writer, actions []patch.Action) error {
+	for _, g := range Groups(actions) {
+		_, err := fmt.Fprintf(w, "<section class=\"%s\">\n\t<h2>%s</h2>\n\t<ul>\n",
+			strings.ToLower(g.Kind), html.EscapeString(g.Title()))
+		if err != nil {
+			return err
+		}
+		for _, action := range g.Actions() {
+			class := strings.ToLower(action.GetType().String())
+			if _, err := fmt.Fprintf(w, "\t\t<li class=\"%s\">%s</li>\n", class, html.EscapeString(Describe(action))); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, "\t</ul>\n</section>\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package rbxapi
+
+import (
+	"strings"
+)
+
+// MemberSecurity returns the security context required to access a member.
+// For properties, this is the read security. Returns an empty string if the
+// member does not have a security context.
+func MemberSecurity(member Member) string {
+	switch member := member.(type) {
+	case Property:
+		read, _ := member.GetSecurity()
+		return read
+	case Function:
+		return member.GetSecurity()
+	case Event:
+		return member.GetSecurity()
+	case Callback:
+		return member.GetSecurity()
+	}
+	return ""
+}
+
+// Security is a security context, which identifies the permissions required
+// to access a member. The zero value is equivalent to SecurityNone.
+//
+// Contexts are ordered by their position in Securities. Unknown contexts are
+// ordered after every known context, so that they are treated as the most
+// restrictive.
+type Security string
+
+// Known security contexts, ordered from least to most restrictive.
+const (
+	SecurityNone          Security = "None"
+	SecurityPlugin        Security = "PluginSecurity"
+	SecurityLocalUser     Security = "LocalUserSecurity"
+	SecurityRobloxScript  Security = "RobloxScriptSecurity"
+	SecurityRoblox        Security = "RobloxSecurity"
+	SecurityNotAccessible Security = "NotAccessibleSecurity"
+)
+
+// Securities lists the known security contexts, ordered from least to most
+// restrictive.
+var Securities = []Security{
+	SecurityNone,
+	SecurityPlugin,
+	SecurityLocalUser,
+	SecurityRobloxScript,
+	SecurityRoblox,
+	SecurityNotAccessible,
+}
+
+// writePrefix and writeSuffix enclose the write security of a property within
+// a tag, as used by the dump format.
+const (
+	writePrefix = "ScriptWriteRestricted: ["
+	writeSuffix = "]"
+)
+
+// ParseSecurity returns the security context represented by s, which may be
+// the name of a context, as used by JSON fields, or a tag naming a context,
+// as used by the dump format, such as "ScriptWriteRestricted: [X]". Known
+// contexts are matched case-insensitively, and are returned in their
+// canonical form. An empty string returns SecurityNone.
+func ParseSecurity(s string) Security {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, writePrefix) && strings.HasSuffix(s, writeSuffix) {
+		s = strings.TrimSpace(s[len(writePrefix) : len(s)-len(writeSuffix)])
+	}
+	if s == "" {
+		return SecurityNone
+	}
+	for _, level := range Securities {
+		if strings.EqualFold(s, string(level)) {
+			return level
+		}
+	}
+	return Security(s)
+}
+
+// SecurityFromTags returns the read and write security contexts indicated by
+// a list of tags, as used by the dump format. The read security is the first
+// tag naming a context, or SecurityNone if there is no such tag. The write
+// security is indicated by a "ScriptWriteRestricted: [X]" tag, and is
+// otherwise the same as the read security.
+func SecurityFromTags(tags []string) (read, write Security) {
+	for _, tag := range tags {
+		switch {
+		case strings.HasPrefix(tag, writePrefix) && strings.HasSuffix(tag, writeSuffix):
+			if write == "" {
+				write = ParseSecurity(tag)
+			}
+		case strings.Contains(tag, "Security") || strings.Contains(tag, "security"):
+			if read == "" {
+				read = ParseSecurity(tag)
+			}
+		}
+	}
+	if read == "" {
+		read = SecurityNone
+	}
+	if write == "" {
+		write = read
+	}
+	return read, write
+}
+
+// String returns the name of the context. The zero value returns "None".
+func (s Security) String() string {
+	if s == "" {
+		return string(SecurityNone)
+	}
+	return string(s)
+}
+
+// Rank returns the position of s within Securities, or -1 if s is unknown.
+// The zero value has the rank of SecurityNone.
+func (s Security) Rank() int {
+	if s == "" {
+		return 0
+	}
+	for i, level := range Securities {
+		if level == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Known returns whether s is present in Securities.
+func (s Security) Known() bool {
+	return s.Rank() >= 0
+}
+
+// Compare returns -1 if s is less restrictive than t, 1 if s is more
+// restrictive than t, and 0 if they are equally restrictive. Unknown contexts
+// are equally restrictive to each other.
+func (s Security) Compare(t Security) int {
+	rank := func(s Security) int {
+		if r := s.Rank(); r >= 0 {
+			return r
+		}
+		return len(Securities)
+	}
+	switch rs, rt := rank(s), rank(t); {
+	case rs < rt:
+		return -1
+	case rs > rt:
+		return 1
+	}
+	return 0
+}
+
+// Less returns whether s is less restrictive than t.
+func (s Security) Less(t Security) bool {
+	return s.Compare(t) < 0
+}
+
+// Accessible returns whether something requiring s can be accessed from the
+// given context. A context can access anything requiring the same or a less
+// restrictive context. Unknown contexts are never accessible, and can access
+// nothing.
+func (s Security) Accessible(context Security) bool {
+	rs, rc := s.Rank(), context.Rank()
+	return rs >= 0 && rc >= 0 && rs <= rc
+}
+
+// MemberSecurities returns the read and write security contexts of a member,
+// normalized so that members decoded by different codecs can be compared. For
+// members other than properties, both contexts are the security of the
+// member. An empty context is SecurityNone, and an empty write security of a
+// property is the same as its read security.
+func MemberSecurities(member Member) (read, write Security) {
+	var r, w string
+	if p, ok := member.(Property); ok {
+		r, w = p.GetSecurity()
+	} else {
+		r = MemberSecurity(member)
+	}
+	read = ParseSecurity(r)
+	if w == "" {
+		return read, read
+	}
+	return read, ParseSecurity(w)
+}
+
+// MemberAccessible returns whether a member can be read, or called, from the
+// given context.
+func MemberAccessible(member Member, context Security) bool {
+	read, _ := MemberSecurities(member)
+	return read.Accessible(context)
+}
@@ -0,0 +1,111 @@
+// The tree package provides the class hierarchy of an API structure, allowing
+// the superclasses and subclasses of a class to be found without repeated
+// scans of the structure.
+package tree
+
+import (
+	"github.com/karl-police/rbxapi"
+)
+
+// Tree is the class hierarchy of a Root. Where several classes have the same
+// name, only the first is included, following the order of the Root.
+//
+// A Tree reflects the Root at the time the tree was created, and must be
+// recreated if the Root is modified.
+type Tree struct {
+	classes    map[string]rbxapi.Class
+	subclasses map[string][]rbxapi.Class
+	roots      []rbxapi.Class
+}
+
+// New returns the class hierarchy of root.
+func New(root rbxapi.Root) *Tree {
+	t := &Tree{
+		classes:    map[string]rbxapi.Class{},
+		subclasses: map[string][]rbxapi.Class{},
+	}
+	var list []rbxapi.Class
+	rbxapi.RangeClasses(root, func(class rbxapi.Class) bool {
+		if _, ok := t.classes[class.GetName()]; !ok {
+			t.classes[class.GetName()] = class
+			list = append(list, class)
+		}
+		return true
+	})
+	for _, class := range list {
+		super := class.GetSuperclass()
+		if _, ok := t.classes[super]; ok && super != class.GetName() {
+			t.subclasses[super] = append(t.subclasses[super], class)
+		} else {
+			t.roots = append(t.roots, class)
+		}
+	}
+	return t
+}
+
+// Class returns the class of the given name, or nil if no such class is
+// present.
+func (t *Tree) Class(name string) rbxapi.Class {
+	return t.classes[name]
+}
+
+// Roots returns the classes whose superclass is not present, such as the
+// Instance class. Classes are in the same order as the Root.
+func (t *Tree) Roots() []rbxapi.Class {
+	return append([]rbxapi.Class(nil), t.roots...)
+}
+
+// SuperclassChain returns the class of the given name, followed by its
+// superclass, the superclass of that class, and so on. The chain stops at
+// the first superclass that is not present, or before a class would be
+// repeated. Returns nil if the class is not present.
+func (t *Tree) SuperclassChain(name string) []rbxapi.Class {
+	var chain []rbxapi.Class
+	visited := map[string]bool{}
+	for class := t.classes[name]; class != nil && !visited[class.GetName()]; class = t.classes[class.GetSuperclass()] {
+		visited[class.GetName()] = true
+		chain = append(chain, class)
+	}
+	return chain
+}
+
+// Subclasses returns the classes that inherit directly from the class of the
+// given name, in the same order as the Root. If recursive is true, then the
+// subclasses of each subclass are also included, each following its
+// superclass in depth-first order.
+func (t *Tree) Subclasses(name string, recursive bool) []rbxapi.Class {
+	if !recursive {
+		return append([]rbxapi.Class(nil), t.subclasses[name]...)
+	}
+	var list []rbxapi.Class
+	visited := map[string]bool{name: true}
+	var walk func(name string)
+	walk = func(name string) {
+		for _, class := range t.subclasses[name] {
+			if visited[class.GetName()] {
+				continue
+			}
+			visited[class.GetName()] = true
+			list = append(list, class)
+			walk(class.GetName())
+		}
+	}
+	walk(name)
+	return list
+}
+
+// IsSubclassOf returns whether the class named a inherits from the class
+// named b, either directly or through other superclasses. A class is not a
+// subclass of itself.
+func (t *Tree) IsSubclassOf(a, b string) bool {
+	chain := t.SuperclassChain(a)
+	if len(chain) == 0 {
+		return false
+	}
+	for _, class := range chain[1:] {
+		if class.GetName() == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"strconv"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "json-conformance",
+		severity: Error,
+		summary:  "JSON API dumps must conform to the structure of the format",
+		check:    checkJSONConformance,
+	})
+}
+
+// typeCategories contains the known categories of types.
+var typeCategories = map[string]bool{
+	"Primitive": true,
+	"Class":     true,
+	"Enum":      true,
+	"DataType":  true,
+	"Group":     true,
+}
+
+// conformance checks the descriptors of a JSON API dump.
+type conformance struct {
+	r *reporter
+}
+
+func (c conformance) security(path, field, security string) {
+	switch {
+	case security == "":
+		c.r.report(path, field+" is missing")
+	case !rbxapi.Security(security).Known():
+		c.r.report(path, field+" has unknown security context "+security)
+	}
+}
+
+func (c conformance) typ(path, field string, typ rbxapijson.Type) {
+	switch {
+	case typ.Category == "" && typ.Name == "":
+		c.r.report(path, field+" is missing")
+	case typ.Name == "":
+		c.r.report(path, field+" has no name")
+	case !typeCategories[typ.Category]:
+		c.r.report(path, field+" has unknown category "+strconv.Quote(typ.Category))
+	}
+}
+
+func (c conformance) params(path string, params []rbxapijson.Parameter) {
+	for i, param := range params {
+		c.typ(path, "type of parameter "+strconv.Itoa(i), param.Type)
+	}
+}
+
+func (c conformance) member(class *rbxapijson.Class, member rbxapi.Member) {
+	path := MemberPath(class, member)
+	switch m := member.(type) {
+	case *rbxapijson.Property:
+		c.typ(path, "ValueType", m.ValueType)
+		if m.Category == "" {
+			c.r.report(path, "Category is missing")
+		}
+		c.security(path, "read security", m.ReadSecurity)
+		c.security(path, "write security", m.WriteSecurity)
+		if m.CanSave && !m.CanLoad {
+			c.r.report(path, "CanSave is true, but CanLoad is false")
+		}
+	case *rbxapijson.Function:
+		c.params(path, m.Parameters)
+		c.typ(path, "ReturnType", m.ReturnType)
+		c.security(path, "Security", m.Security)
+	case *rbxapijson.Event:
+		c.params(path, m.Parameters)
+		c.security(path, "Security", m.Security)
+	case *rbxapijson.Callback:
+		c.params(path, m.Parameters)
+		c.typ(path, "ReturnType", m.ReturnType)
+		c.security(path, "Security", m.Security)
+	default:
+		c.r.report(path, fmt.Sprintf("member type %s is implemented by %T, which is not a JSON descriptor", member.GetMemberType(), member))
+	}
+}
+
+func checkJSONConformance(root rbxapi.Root, r *reporter) {
+	jroot, ok := root.(*rbxapijson.Root)
+	if !ok {
+		return
+	}
+	c := conformance{r: r}
+	for i, class := range jroot.Classes {
+		if class == nil {
+			r.report("", "class "+strconv.Itoa(i)+" is null")
+			continue
+		}
+		path := ClassPath(class)
+		if class.Superclass == "" {
+			r.report(path, "Superclass is missing")
+		}
+		if class.MemoryCategory == "" {
+			r.report(path, "MemoryCategory is missing")
+		}
+		for j, member := range class.Members {
+			if member == nil {
+				r.report(path, "member "+strconv.Itoa(j)+" is null")
+				continue
+			}
+			c.member(class, member)
+		}
+	}
+	for i, enum := range jroot.Enums {
+		if enum == nil {
+			r.report("", "enum "+strconv.Itoa(i)+" is null")
+			continue
+		}
+		for j, item := range enum.Items {
+			if item == nil {
+				r.report(EnumPath(enum), "item "+strconv.Itoa(j)+" is null")
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConsistencyRule is the rule of diagnostics returned by Compare.
+const ConsistencyRule = "consistency"
+
+// Dump is an API structure with a name that identifies it in diagnostics,
+// such as "text dump" or "JSON dump".
+type Dump struct {
+	Name string
+	Root rbxapi.Root
+}
+
+// comparer accumulates the disagreements between two dumps.
+type comparer struct {
+	a, b        Dump
+	diagnostics []Diagnostic
+}
+
+func (c *comparer) report(severity Severity, path, msg string) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Rule:     ConsistencyRule,
+		Severity: severity,
+		Path:     path,
+		Message:  msg,
+	})
+}
+
+// only reports a descriptor that is present in only one dump.
+func (c *comparer) only(path string, inA bool) {
+	name := c.b.Name
+	if inA {
+		name = c.a.Name
+	}
+	c.report(Error, path, "present only in "+name)
+}
+
+// differ reports a field with differing values.
+func (c *comparer) differ(severity Severity, path, field, a, b string) {
+	c.report(severity, path, field+" differs: "+c.a.Name+" has "+a+", "+c.b.Name+" has "+b)
+}
+
+// typesEqual returns whether two types are equal. Types without a category
+// match any type of the same name, since older dumps do not have categories.
+func typesEqual(a, b rbxapi.Type) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.GetName() != b.GetName() {
+		return false
+	}
+	return a.GetCategory() == "" || b.GetCategory() == "" || a.GetCategory() == b.GetCategory()
+}
+
+func typeString(t rbxapi.Type) string {
+	if t == nil {
+		return "no type"
+	}
+	return t.String()
+}
+
+func paramsString(params []rbxapi.Parameter) string {
+	ss := make([]string, len(params))
+	for i, p := range params {
+		ss[i] = typeString(p.GetType()) + " " + p.GetName()
+		if d, ok := p.GetDefault(); ok {
+			ss[i] += " = " + d
+		}
+	}
+	return "(" + strings.Join(ss, ", ") + ")"
+}
+
+func paramsEqual(a, b []rbxapi.Parameter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		ad, aok := a[i].GetDefault()
+		bd, bok := b[i].GetDefault()
+		if a[i].GetName() != b[i].GetName() || !typesEqual(a[i].GetType(), b[i].GetType()) || aok != bok || ad != bd {
+			return false
+		}
+	}
+	return true
+}
+
+// semanticTags returns the tags of t that carry meaning in both formats, in
+// lower case and sorted. Tags naming security contexts are excluded, since
+// the text format represents security as tags, and the JSON format as
+// fields.
+func semanticTags(t rbxapi.Taggable) []string {
+	var tags []string
+	for _, tag := range t.GetTags() {
+		if security, write := rbxapidump.ParseSecurityTag(tag); security != "" || write {
+			continue
+		}
+		tags = append(tags, strings.ToLower(tag))
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (c *comparer) tags(path string, a, b rbxapi.Taggable) {
+	at, bt := semanticTags(a), semanticTags(b)
+	if strings.Join(at, " ") != strings.Join(bt, " ") {
+		c.differ(Warning, path, "tags", "["+strings.Join(at, ", ")+"]", "["+strings.Join(bt, ", ")+"]")
+	}
+}
+
+// security reports differing security contexts. A missing context is
+// equivalent to None.
+func (c *comparer) security(path, field, a, b string) {
+	if a == "" {
+		a = "None"
+	}
+	if b == "" {
+		b = "None"
+	}
+	if a != b {
+		c.differ(Warning, path, field, a, b)
+	}
+}
+
+// memberKey identifies a member within a class.
+type memberKey struct{ memberType, name string }
+
+func (c *comparer) member(path string, a, b rbxapi.Member) {
+	switch a := a.(type) {
+	case rbxapi.Property:
+		b := b.(rbxapi.Property)
+		if !typesEqual(a.GetValueType(), b.GetValueType()) {
+			c.differ(Error, path, "value type", typeString(a.GetValueType()), typeString(b.GetValueType()))
+		}
+		ar, aw := a.GetSecurity()
+		br, bw := b.GetSecurity()
+		c.security(path, "read security", ar, br)
+		c.security(path, "write security", aw, bw)
+	case rbxapi.Function:
+		// Also matches callbacks.
+		b := b.(rbxapi.Function)
+		if ap, bp := a.GetParameters().GetParameters(), b.GetParameters().GetParameters(); !paramsEqual(ap, bp) {
+			c.differ(Error, path, "parameters", paramsString(ap), paramsString(bp))
+		}
+		if !typesEqual(a.GetReturnType(), b.GetReturnType()) {
+			c.differ(Error, path, "return type", typeString(a.GetReturnType()), typeString(b.GetReturnType()))
+		}
+	case rbxapi.Event:
+		b := b.(rbxapi.Event)
+		if ap, bp := a.GetParameters().GetParameters(), b.GetParameters().GetParameters(); !paramsEqual(ap, bp) {
+			c.differ(Error, path, "parameters", paramsString(ap), paramsString(bp))
+		}
+	}
+	if _, ok := a.(rbxapi.Property); !ok {
+		c.security(path, "security", rbxapi.MemberSecurity(a), rbxapi.MemberSecurity(b))
+	}
+	c.tags(path, a, b)
+}
+
+func (c *comparer) class(a, b rbxapi.Class) {
+	path := ClassPath(a)
+	if as, bs := a.GetSuperclass(), b.GetSuperclass(); as != bs && !(isRootSuperclass(as) && isRootSuperclass(bs)) {
+		c.differ(Error, path, "superclass", as, bs)
+	}
+	c.tags(path, a, b)
+	members := map[memberKey]rbxapi.Member{}
+	for _, m := range b.GetMembers() {
+		members[memberKey{m.GetMemberType(), m.GetName()}] = m
+	}
+	for _, am := range a.GetMembers() {
+		key := memberKey{am.GetMemberType(), am.GetName()}
+		bm, ok := members[key]
+		if !ok {
+			c.only(MemberPath(a, am), true)
+			continue
+		}
+		delete(members, key)
+		c.member(MemberPath(a, am), am, bm)
+	}
+	for _, bm := range b.GetMembers() {
+		if _, ok := members[memberKey{bm.GetMemberType(), bm.GetName()}]; ok {
+			c.only(MemberPath(b, bm), false)
+		}
+	}
+}
+
+func (c *comparer) enum(a, b rbxapi.Enum) {
+	c.tags(EnumPath(a), a, b)
+	for _, ai := range a.GetEnumItems() {
+		bi := b.GetEnumItem(ai.GetName())
+		if bi == nil {
+			c.only(EnumItemPath(a, ai), true)
+			continue
+		}
+		path := EnumItemPath(a, ai)
+		if ai.GetValue() != bi.GetValue() {
+			c.differ(Error, path, "value", strconv.Itoa(ai.GetValue()), strconv.Itoa(bi.GetValue()))
+		}
+		c.tags(path, ai, bi)
+	}
+	for _, bi := range b.GetEnumItems() {
+		if a.GetEnumItem(bi.GetName()) == nil {
+			c.only(EnumItemPath(b, bi), false)
+		}
+	}
+}
+
+// Compare reports the disagreements between two dumps of the same build,
+// such as the text dump and the JSON dump, which are expected to describe the
+// same API. Differences that are only due to the formats, such as the case
+// of tags and the representation of security contexts, are ignored.
+func Compare(a, b Dump) []Diagnostic {
+	c := &comparer{a: a, b: b}
+	for _, ac := range a.Root.GetClasses() {
+		if bc := b.Root.GetClass(ac.GetName()); bc != nil {
+			c.class(ac, bc)
+		} else {
+			c.only(ClassPath(ac), true)
+		}
+	}
+	for _, bc := range b.Root.GetClasses() {
+		if a.Root.GetClass(bc.GetName()) == nil {
+			c.only(ClassPath(bc), false)
+		}
+	}
+	for _, ae := range a.Root.GetEnums() {
+		if be := b.Root.GetEnum(ae.GetName()); be != nil {
+			c.enum(ae, be)
+		} else {
+			c.only(EnumPath(ae), true)
+		}
+	}
+	for _, be := range b.Root.GetEnums() {
+		if a.Root.GetEnum(be.GetName()) == nil {
+			c.only(EnumPath(be), false)
+		}
+	}
+	return c.diagnostics
+}
@@ -0,0 +1,147 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapijson"
+	"strings"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "deprecated-without-preferred",
+		severity: Info,
+		summary:  "deprecated descriptors should name a preferred descriptor",
+		check:    checkDeprecatedWithoutPreferred,
+	})
+	addRule(&rule{
+		name:     "undefined-preferred",
+		severity: Warning,
+		summary:  "preferred descriptors must be defined",
+		check:    checkUndefinedPreferred,
+	})
+	addRule(&rule{
+		name:     "deprecated-reference",
+		severity: Warning,
+		summary:  "members that are not deprecated should not refer to deprecated classes or enums",
+		check:    checkDeprecatedReferences,
+	})
+}
+
+// preferred returns the name of the preferred descriptor of v. ok is false
+// if the structure of v cannot name a preferred descriptor.
+func preferred(v interface{}) (name string, ok bool) {
+	switch v := v.(type) {
+	case *rbxapijson.Class:
+		return v.PreferredDescriptor, true
+	case *rbxapijson.Property:
+		return v.PreferredDescriptor, true
+	case *rbxapijson.Function:
+		return v.PreferredDescriptor, true
+	case *rbxapijson.Event:
+		return v.PreferredDescriptor, true
+	case *rbxapijson.Callback:
+		return v.PreferredDescriptor, true
+	case *rbxapijson.Enum:
+		return v.PreferredDescriptor, true
+	case *rbxapijson.EnumItem:
+		return v.PreferredDescriptor, true
+	}
+	return "", false
+}
+
+func checkDeprecatedWithoutPreferred(root rbxapi.Root, r *reporter) {
+	check := func(path string, t rbxapi.Taggable) {
+		if name, ok := preferred(t); ok && name == "" && t.GetTag("Deprecated") {
+			r.report(path, "deprecated without a preferred descriptor")
+		}
+	}
+	for _, class := range root.GetClasses() {
+		check(ClassPath(class), class)
+		for _, member := range class.GetMembers() {
+			check(MemberPath(class, member), member)
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		check(EnumPath(enum), enum)
+		for _, item := range enum.GetEnumItems() {
+			check(EnumItemPath(enum, item), item)
+		}
+	}
+}
+
+// findMember returns whether a member of the given name is defined by class
+// or one of its superclasses. The name may be qualified by the name of a
+// class, as in "Class.Member" or "Class:Member".
+func findMember(root rbxapi.Root, class rbxapi.Class, name string) bool {
+	if i := strings.IndexAny(name, ".:"); i >= 0 {
+		if class = root.GetClass(name[:i]); class == nil {
+			return false
+		}
+		name = name[i+1:]
+	}
+	seen := map[string]bool{}
+	for class != nil && !seen[class.GetName()] {
+		if class.GetMember(name) != nil {
+			return true
+		}
+		seen[class.GetName()] = true
+		class = root.GetClass(class.GetSuperclass())
+	}
+	return false
+}
+
+func checkUndefinedPreferred(root rbxapi.Root, r *reporter) {
+	report := func(path, name string) {
+		r.report(path, "preferred descriptor "+name+" is not defined")
+	}
+	for _, class := range root.GetClasses() {
+		if name, _ := preferred(class); name != "" && root.GetClass(name) == nil {
+			report(ClassPath(class), name)
+		}
+		for _, member := range class.GetMembers() {
+			if name, _ := preferred(member); name != "" && !findMember(root, class, name) {
+				report(MemberPath(class, member), name)
+			}
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		if name, _ := preferred(enum); name != "" && root.GetEnum(name) == nil {
+			report(EnumPath(enum), name)
+		}
+		for _, item := range enum.GetEnumItems() {
+			if name, _ := preferred(item); name != "" && enum.GetEnumItem(name) == nil {
+				report(EnumItemPath(enum, item), name)
+			}
+		}
+	}
+}
+
+func checkDeprecatedReferences(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		// Members of a deprecated class are implicitly deprecated.
+		if class.GetTag("Deprecated") {
+			continue
+		}
+		for _, member := range class.GetMembers() {
+			if member.GetTag("Deprecated") {
+				continue
+			}
+			for _, ref := range memberTypes(member) {
+				if ref.typ == nil {
+					continue
+				}
+				name := ref.typ.GetName()
+				switch ref.typ.GetCategory() {
+				case "Class":
+					if c := root.GetClass(name); c != nil && c.GetTag("Deprecated") {
+						r.report(MemberPath(class, member), ref.what+" refers to deprecated class "+name)
+					}
+				case "Enum":
+					if e := root.GetEnum(name); e != nil && e.GetTag("Deprecated") {
+						r.report(MemberPath(class, member), ref.what+" refers to deprecated enum "+name)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"strconv"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "duplicate-class",
+		severity: Error,
+		summary:  "class names must be unique",
+		check:    checkDuplicateClasses,
+	})
+	addRule(&rule{
+		name:     "duplicate-member",
+		severity: Error,
+		summary:  "members of a class must have unique names and member types",
+		check:    checkDuplicateMembers,
+	})
+	addRule(&rule{
+		name:     "duplicate-enum-item",
+		severity: Error,
+		summary:  "items of an enum must have unique names",
+		check:    checkDuplicateEnumItems,
+	})
+	// Enums legitimately contain deprecated aliases of other items, so
+	// duplicate values are only suspicious.
+	addRule(&rule{
+		name:     "duplicate-enum-value",
+		severity: Warning,
+		summary:  "items of an enum should have unique values",
+		check:    checkDuplicateEnumValues,
+	})
+}
+
+func checkDuplicateClasses(root rbxapi.Root, r *reporter) {
+	seen := map[string]int{}
+	for i, class := range root.GetClasses() {
+		name := class.GetName()
+		if j, ok := seen[name]; ok {
+			r.report(ClassPath(class), "class "+strconv.Itoa(i)+" has the same name as class "+strconv.Itoa(j))
+			continue
+		}
+		seen[name] = i
+	}
+}
+
+func checkDuplicateMembers(root rbxapi.Root, r *reporter) {
+	type key struct{ memberType, name string }
+	for _, class := range root.GetClasses() {
+		seen := map[key]int{}
+		for i, member := range class.GetMembers() {
+			k := key{member.GetMemberType(), member.GetName()}
+			if j, ok := seen[k]; ok {
+				r.report(MemberPath(class, member), "member "+strconv.Itoa(i)+" has the same name and member type as member "+strconv.Itoa(j))
+				continue
+			}
+			seen[k] = i
+		}
+	}
+}
+
+func checkDuplicateEnumItems(root rbxapi.Root, r *reporter) {
+	for _, enum := range root.GetEnums() {
+		seen := map[string]int{}
+		for i, item := range enum.GetEnumItems() {
+			name := item.GetName()
+			if j, ok := seen[name]; ok {
+				r.report(EnumItemPath(enum, item), "item "+strconv.Itoa(i)+" has the same name as item "+strconv.Itoa(j))
+				continue
+			}
+			seen[name] = i
+		}
+	}
+}
+
+func checkDuplicateEnumValues(root rbxapi.Root, r *reporter) {
+	for _, enum := range root.GetEnums() {
+		seen := map[int]rbxapi.EnumItem{}
+		for _, item := range enum.GetEnumItems() {
+			value := item.GetValue()
+			if first, ok := seen[value]; ok {
+				if first.GetName() == item.GetName() {
+					// Reported by duplicate-enum-item.
+					continue
+				}
+				r.report(EnumItemPath(enum, item), "value "+strconv.Itoa(value)+" is also the value of "+first.GetName())
+				continue
+			}
+			seen[value] = item
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"sort"
+)
+
+// EnumReport describes how the items of an enum are numbered, so that
+// serializers can determine whether the enum is safe to store by value.
+type EnumReport struct {
+	// Enum is the name of the enum.
+	Enum string
+	// Items is the number of items.
+	Items int
+	// Min and Max are the lowest and highest values.
+	Min, Max int
+	// Duplicates contains the groups of items that share a value, ordered by
+	// value.
+	Duplicates []ValueGroup `json:",omitempty"`
+	// Gaps contains the ranges of unused values that are larger than the
+	// requested gap size, ordered by value.
+	Gaps []Gap `json:",omitempty"`
+	// Decreasing contains the names of the items that have a lower value
+	// than the item listed before them.
+	Decreasing []string `json:",omitempty"`
+	// SafeByValue is whether each value identifies exactly one item, so that
+	// an item can be stored as its value and restored without ambiguity.
+	SafeByValue bool
+}
+
+// ValueGroup is a group of items that share a value.
+type ValueGroup struct {
+	Value int
+	Items []string
+}
+
+// Gap is a range of unused values between two values that are in use.
+type Gap struct {
+	// After is the used value below the gap.
+	After int
+	// Before is the used value above the gap.
+	Before int
+}
+
+// Size returns the number of unused values in the gap.
+func (g Gap) Size() int {
+	return g.Before - g.After - 1
+}
+
+// AnalyzeEnum reports how the items of enum are numbered. Gaps of more than
+// minGap unused values are reported.
+func AnalyzeEnum(enum rbxapi.Enum, minGap int) EnumReport {
+	report := EnumReport{Enum: enum.GetName(), SafeByValue: true}
+	items := enum.GetEnumItems()
+	report.Items = len(items)
+	byValue := map[int][]string{}
+	var values []int
+	for i, item := range items {
+		v := item.GetValue()
+		if i == 0 || v < report.Min {
+			report.Min = v
+		}
+		if i == 0 || v > report.Max {
+			report.Max = v
+		}
+		if i > 0 && v < items[i-1].GetValue() {
+			report.Decreasing = append(report.Decreasing, item.GetName())
+		}
+		if _, ok := byValue[v]; !ok {
+			values = append(values, v)
+		}
+		byValue[v] = append(byValue[v], item.GetName())
+	}
+	sort.Ints(values)
+	for i, v := range values {
+		if names := byValue[v]; len(names) > 1 {
+			report.Duplicates = append(report.Duplicates, ValueGroup{Value: v, Items: names})
+			report.SafeByValue = false
+		}
+		if i > 0 {
+			if gap := (Gap{After: values[i-1], Before: v}); gap.Size() > minGap {
+				report.Gaps = append(report.Gaps, gap)
+			}
+		}
+	}
+	return report
+}
+
+// AnalyzeEnums reports how the items of each enum of root are numbered.
+func AnalyzeEnums(root rbxapi.Root, minGap int) []EnumReport {
+	enums := root.GetEnums()
+	reports := make([]EnumReport, len(enums))
+	for i, enum := range enums {
+		reports[i] = AnalyzeEnum(enum, minGap)
+	}
+	return reports
+}
@@ -0,0 +1,58 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"strings"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "inheritance-cycle",
+		severity: Error,
+		summary:  "classes must not inherit from themselves",
+		check:    checkInheritanceCycles,
+	})
+	addRule(&rule{
+		name:     "orphaned-root",
+		severity: Warning,
+		summary:  "every class other than Instance should have a superclass",
+		check:    checkOrphanedRoots,
+	})
+}
+
+// rootClass is the name of the class from which all other classes are
+// expected to inherit.
+const rootClass = "Instance"
+
+func checkInheritanceCycles(root rbxapi.Root, r *reporter) {
+	// done contains classes known to be outside of any unreported cycle.
+	done := map[string]bool{}
+	for _, class := range root.GetClasses() {
+		var chain []rbxapi.Class
+		index := map[string]int{}
+		for c := class; c != nil && !done[c.GetName()]; c = root.GetClass(c.GetSuperclass()) {
+			if i, ok := index[c.GetName()]; ok {
+				cycle := make([]string, 0, len(chain)-i+1)
+				for _, c := range chain[i:] {
+					cycle = append(cycle, c.GetName())
+				}
+				cycle = append(cycle, c.GetName())
+				r.report(ClassPath(c), "inheritance cycle "+strings.Join(cycle, " -> "))
+				break
+			}
+			index[c.GetName()] = len(chain)
+			chain = append(chain, c)
+		}
+		for _, c := range chain {
+			done[c.GetName()] = true
+		}
+	}
+}
+
+func checkOrphanedRoots(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		if isRootSuperclass(class.GetSuperclass()) && class.GetName() != rootClass {
+			r.report(ClassPath(class), "class has no superclass")
+		}
+	}
+}
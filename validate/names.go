@@ -0,0 +1,38 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"strconv"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "empty-name",
+		severity: Error,
+		summary:  "descriptors must have a name",
+		check:    checkEmptyNames,
+	})
+}
+
+func checkEmptyNames(root rbxapi.Root, r *reporter) {
+	for i, class := range root.GetClasses() {
+		if class.GetName() == "" {
+			r.report(ClassPath(class), "class "+strconv.Itoa(i)+" has no name")
+		}
+		for j, member := range class.GetMembers() {
+			if member.GetName() == "" {
+				r.report(MemberPath(class, member), "member "+strconv.Itoa(j)+" has no name")
+			}
+		}
+	}
+	for i, enum := range root.GetEnums() {
+		if enum.GetName() == "" {
+			r.report(EnumPath(enum), "enum "+strconv.Itoa(i)+" has no name")
+		}
+		for j, item := range enum.GetEnumItems() {
+			if item.GetName() == "" {
+				r.report(EnumItemPath(enum, item), "item "+strconv.Itoa(j)+" has no name")
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "member-case",
+		severity: Warning,
+		summary:  "member names should be PascalCase",
+		options: map[string]string{
+			// Whether deprecated members are checked. Legacy members
+			// often have camelCase names, and are deprecated in favor of
+			// PascalCase members.
+			"deprecated": "false",
+			"exceptions": "",
+		},
+		check: checkMemberCase,
+	})
+	addRule(&rule{
+		name:     "event-name",
+		severity: Warning,
+		summary:  "callbacks should have the callback prefix, and events should not",
+		options: map[string]string{
+			"prefix": "On",
+		},
+		check: checkEventNames,
+	})
+	addRule(&rule{
+		name:     "hungarian",
+		severity: Warning,
+		summary:  "names should not have Hungarian notation prefixes",
+		options: map[string]string{
+			"prefixes": "b,by,ch,dw,f,fn,h,i,lp,m_,n,p,psz,str,sz,u,w",
+		},
+		check: checkHungarian,
+	})
+	addRule(&rule{
+		name:     "parameter-name",
+		severity: Error,
+		summary:  "parameters must have names that are unique within a signature",
+		check:    checkParameterNames,
+	})
+}
+
+// isPascalCase returns whether s begins with an upper-case letter and
+// contains only letters and digits.
+func isPascalCase(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	if !unicode.IsUpper(r) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// set returns a set of the items of an option list.
+func set(list []string) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, s := range list {
+		m[s] = true
+	}
+	return m
+}
+
+func checkMemberCase(root rbxapi.Root, r *reporter) {
+	deprecated, _ := strconv.ParseBool(r.option("deprecated"))
+	exceptions := set(r.list("exceptions"))
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			name := member.GetName()
+			if name == "" || isPascalCase(name) || exceptions[name] || exceptions[class.GetName()+"."+name] {
+				continue
+			}
+			if !deprecated && member.GetTag("Deprecated") {
+				continue
+			}
+			r.report(MemberPath(class, member), "name is not PascalCase")
+		}
+	}
+}
+
+// hasPrefix returns whether name begins with prefix, followed by the start
+// of another word.
+func hasPrefix(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return unicode.IsUpper(r)
+}
+
+func checkEventNames(root rbxapi.Root, r *reporter) {
+	prefix := r.option("prefix")
+	if prefix == "" {
+		return
+	}
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			switch member.GetMemberType() {
+			case "Event":
+				if hasPrefix(member.GetName(), prefix) {
+					r.report(MemberPath(class, member), "event name has callback prefix "+prefix)
+				}
+			case "Callback":
+				if !hasPrefix(member.GetName(), prefix) {
+					r.report(MemberPath(class, member), "callback name does not have prefix "+prefix)
+				}
+			}
+		}
+	}
+}
+
+// parameters returns the parameters of a member.
+func parameters(member rbxapi.Member) []rbxapi.Parameter {
+	switch m := member.(type) {
+	case rbxapi.Function:
+		// Also matches callbacks.
+		return m.GetParameters().GetParameters()
+	case rbxapi.Event:
+		return m.GetParameters().GetParameters()
+	}
+	return nil
+}
+
+// hungarianPrefix returns the first of prefixes that name has.
+func hungarianPrefix(name string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if hasPrefix(name, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+func checkHungarian(root rbxapi.Root, r *reporter) {
+	prefixes := r.list("prefixes")
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			if p := hungarianPrefix(member.GetName(), prefixes); p != "" {
+				r.report(MemberPath(class, member), "name has Hungarian prefix "+p)
+			}
+			for _, param := range parameters(member) {
+				if p := hungarianPrefix(param.GetName(), prefixes); p != "" {
+					r.report(MemberPath(class, member), "parameter "+param.GetName()+" has Hungarian prefix "+p)
+				}
+			}
+		}
+	}
+}
+
+func checkParameterNames(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			seen := map[string]bool{}
+			for i, param := range parameters(member) {
+				name := param.GetName()
+				switch {
+				case name == "":
+					r.report(MemberPath(class, member), "parameter "+strconv.Itoa(i)+" has no name")
+				case seen[name]:
+					r.report(MemberPath(class, member), "parameter "+name+" is given more than once")
+				}
+				seen[name] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+)
+
+// Parameters without names are reported by parameter-name, and parameters
+// typed as undefined enums are reported by undefined-enum.
+
+func init() {
+	addRule(&rule{
+		name:     "default-order",
+		severity: Error,
+		summary:  "only the trailing parameters of a function may have defaults",
+		check:    checkDefaultOrder,
+	})
+	addRule(&rule{
+		name:     "unexpected-default",
+		severity: Error,
+		summary:  "parameters of events and callbacks must not have defaults",
+		check:    checkUnexpectedDefaults,
+	})
+}
+
+func checkDefaultOrder(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			if member.GetMemberType() != "Function" {
+				continue
+			}
+			var first rbxapi.Parameter
+			for _, param := range parameters(member) {
+				if _, ok := param.GetDefault(); ok {
+					if first == nil {
+						first = param
+					}
+				} else if first != nil {
+					r.report(MemberPath(class, member), "parameter "+param.GetName()+" has no default, but follows parameter "+first.GetName()+", which has a default")
+				}
+			}
+		}
+	}
+}
+
+func checkUnexpectedDefaults(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			if t := member.GetMemberType(); t != "Event" && t != "Callback" {
+				continue
+			}
+			for _, param := range parameters(member) {
+				if d, ok := param.GetDefault(); ok {
+					r.report(MemberPath(class, member), "parameter "+param.GetName()+" of "+member.GetMemberType()+" has default "+d)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/patch"
+)
+
+// Policy describes the changes between two versions of an API structure
+// that are acceptable, such as when new builds are ingested automatically.
+// The zero Policy accepts every change.
+type Policy struct {
+	// ForbidRemovals rejects the removal of any descriptor.
+	ForbidRemovals bool
+	// ForbidSecurityTightening rejects changes of security contexts to more
+	// restrictive contexts. Unknown contexts are considered to be the most
+	// restrictive.
+	ForbidSecurityTightening bool
+	// ForbidTypeChanges rejects changes to value types, return types, and
+	// parameters, except for the descriptors named in AllowTypeChanges.
+	ForbidTypeChanges bool
+	// AllowTypeChanges contains the names of descriptors that are exempt
+	// from ForbidTypeChanges. A member is named as "Class.Member".
+	AllowTypeChanges []string
+}
+
+// Policy rule names, reported as the rule of diagnostics returned by
+// Policy.Check.
+const (
+	PolicyRemoval            = "policy-removal"
+	PolicySecurityTightening = "policy-security-tightening"
+	PolicyTypeChange         = "policy-type-change"
+)
+
+// actionPath returns the path and name of the descriptor of an action.
+func actionPath(action patch.Action) (path, name string) {
+	switch a := action.(type) {
+	case patch.Member:
+		class, member := a.GetClass(), a.GetMember()
+		if class == nil || member == nil {
+			break
+		}
+		return MemberPath(class, member), class.GetName() + "." + member.GetName()
+	case patch.Class:
+		if class := a.GetClass(); class != nil {
+			return ClassPath(class), class.GetName()
+		}
+	case patch.EnumItem:
+		enum, item := a.GetEnum(), a.GetEnumItem()
+		if enum == nil || item == nil {
+			break
+		}
+		return EnumItemPath(enum, item), enum.GetName() + "." + item.GetName()
+	case patch.Enum:
+		if enum := a.GetEnum(); enum != nil {
+			return EnumPath(enum), enum.GetName()
+		}
+	}
+	return "", ""
+}
+
+// changeSecurity returns the security context of a field value of a Change
+// action.
+func changeSecurity(v interface{}) rbxapi.Security {
+	s, _ := v.(string)
+	return rbxapi.ParseSecurity(s)
+}
+
+// Check returns a diagnostic for each action that violates the policy. The
+// actions pass the policy if no diagnostics are returned.
+func (p *Policy) Check(actions []patch.Action) []Diagnostic {
+	allowed := make(map[string]bool, len(p.AllowTypeChanges))
+	for _, name := range p.AllowTypeChanges {
+		allowed[name] = true
+	}
+	var diagnostics []Diagnostic
+	violate := func(rule, path string, action patch.Action, reason string) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Rule:     rule,
+			Severity: Error,
+			Path:     path,
+			Message:  reason + ": " + action.String(),
+		})
+	}
+	for _, action := range actions {
+		path, name := actionPath(action)
+		switch action.GetType() {
+		case patch.Remove:
+			if p.ForbidRemovals {
+				violate(PolicyRemoval, path, action, "removals are forbidden")
+			}
+		case patch.Change:
+			switch action.GetField() {
+			case "Security", "ReadSecurity", "WriteSecurity":
+				if p.ForbidSecurityTightening && changeSecurity(action.GetPrev()).Less(changeSecurity(action.GetNext())) {
+					violate(PolicySecurityTightening, path, action, "security tightening is forbidden")
+				}
+			case "ValueType", "ReturnType", "Parameters":
+				if p.ForbidTypeChanges && !allowed[name] {
+					violate(PolicyTypeChange, path, action, "type change is not allowed")
+				}
+			}
+		}
+	}
+	return diagnostics
+}
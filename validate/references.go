@@ -0,0 +1,94 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "undefined-superclass",
+		severity: Error,
+		summary:  "the superclass of a class must be defined",
+		check:    checkSuperclasses,
+	})
+	addRule(&rule{
+		name:     "undefined-enum",
+		severity: Error,
+		summary:  "enum types must refer to a defined enum",
+		check:    checkEnumTypes,
+	})
+	addRule(&rule{
+		name:     "undefined-class",
+		severity: Error,
+		summary:  "class types must refer to a defined class",
+		check:    checkClassTypes,
+	})
+}
+
+// isRootSuperclass returns whether name indicates that a class has no
+// superclass.
+func isRootSuperclass(name string) bool {
+	return name == "" || name == "<<<ROOT>>>"
+}
+
+func checkSuperclasses(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		super := class.GetSuperclass()
+		if !isRootSuperclass(super) && root.GetClass(super) == nil {
+			r.report(ClassPath(class), "superclass "+super+" is not defined")
+		}
+	}
+}
+
+// typeRef is a type referred to by a member.
+type typeRef struct {
+	// what describes where the type appears in the member.
+	what string
+	typ  rbxapi.Type
+}
+
+// memberTypes returns the types referred to by a member.
+func memberTypes(member rbxapi.Member) []typeRef {
+	var refs []typeRef
+	params := func(params rbxapi.Parameters) {
+		for _, param := range params.GetParameters() {
+			refs = append(refs, typeRef{"parameter " + param.GetName(), param.GetType()})
+		}
+	}
+	switch m := member.(type) {
+	case rbxapi.Property:
+		refs = append(refs, typeRef{"value type", m.GetValueType()})
+	case rbxapi.Function:
+		// Also matches callbacks.
+		params(m.GetParameters())
+		refs = append(refs, typeRef{"return type", m.GetReturnType()})
+	case rbxapi.Event:
+		params(m.GetParameters())
+	}
+	return refs
+}
+
+// checkTypes reports each type of the given category that does not refer to
+// a defined descriptor. noun names the kind of descriptor in messages.
+func checkTypes(root rbxapi.Root, r *reporter, category, noun string, defined func(name string) bool) {
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			for _, ref := range memberTypes(member) {
+				if ref.typ == nil || ref.typ.GetCategory() != category {
+					continue
+				}
+				if name := ref.typ.GetName(); !defined(name) {
+					r.report(MemberPath(class, member), ref.what+" refers to undefined "+noun+" "+name)
+				}
+			}
+		}
+	}
+}
+
+func checkEnumTypes(root rbxapi.Root, r *reporter) {
+	checkTypes(root, r, "Enum", "enum", func(name string) bool { return root.GetEnum(name) != nil })
+}
+
+func checkClassTypes(root rbxapi.Root, r *reporter) {
+	checkTypes(root, r, "Class", "class", func(name string) bool { return root.GetClass(name) != nil })
+}
@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// The SARIF types contain the subset of the SARIF 2.1.0 format used by
+// WriteSARIF.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string        `json:"id"`
+	ShortDescription     *sarifMessage `json:"shortDescription,omitempty"`
+	DefaultConfiguration sarifConfig   `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifLevel returns the SARIF level corresponding to a severity.
+func sarifLevel(s Severity) string {
+	switch s {
+	case Info:
+		return "note"
+	case Warning:
+		return "warning"
+	}
+	return "error"
+}
+
+// WriteSARIF writes diagnostics to w as a SARIF log, as consumed by code
+// scanning tools. uri locates the API dump that was checked.
+//
+// The log describes each registered rule, as well as any other rule
+// reported by the diagnostics, such as the rules of a Policy. Since a
+// descriptor has no position within a dump, the path of a diagnostic is
+// written as a logical location.
+func WriteSARIF(w io.Writer, uri string, diagnostics []Diagnostic) error {
+	driver := sarifDriver{
+		Name:           "rbxapi",
+		InformationURI: "https://github.com/karl-police/rbxapi",
+		Rules:          []sarifRule{},
+	}
+	indexes := map[string]int{}
+	for _, info := range Rules() {
+		indexes[info.Name] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   info.Name,
+			ShortDescription:     &sarifMessage{Text: info.Summary},
+			DefaultConfiguration: sarifConfig{Level: sarifLevel(info.Severity)},
+		})
+	}
+	results := make([]sarifResult, len(diagnostics))
+	for i, d := range diagnostics {
+		index, ok := indexes[d.Rule]
+		if !ok {
+			index = len(driver.Rules)
+			indexes[d.Rule] = index
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:                   d.Rule,
+				DefaultConfiguration: sarifConfig{Level: sarifLevel(d.Severity)},
+			})
+		}
+		loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: uri},
+		}}
+		if d.Path != "" {
+			loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.Path}}
+		}
+		results[i] = sarifResult{
+			RuleID:    d.Rule,
+			RuleIndex: index,
+			Level:     sarifLevel(d.Severity),
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{loc},
+		}
+	}
+	je := json.NewEncoder(w)
+	je.SetIndent("", "\t")
+	je.SetEscapeHTML(false)
+	return je.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+)
+
+func init() {
+	addRule(&rule{
+		name:     "read-only-write-security",
+		severity: Warning,
+		summary:  "read-only properties should not have a write security more permissive than their read security",
+		check:    checkReadOnlyWriteSecurity,
+	})
+	addRule(&rule{
+		name:     "security-tag",
+		severity: Warning,
+		summary:  "security tags should agree with the security of a member",
+		check:    checkSecurityTags,
+	})
+	addRule(&rule{
+		name:     "service-security",
+		severity: Info,
+		summary:  "members of services should have a known security context",
+		check:    checkServiceSecurity,
+	})
+}
+
+func checkReadOnlyWriteSecurity(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			prop, ok := member.(rbxapi.Property)
+			if !ok || !rbxapiconv.HasTag(prop, "ReadOnly") {
+				continue
+			}
+			read, write := rbxapi.MemberSecurities(prop)
+			if read.Known() && write.Known() && write.Less(read) {
+				r.report(MemberPath(class, member), "write security "+write.String()+" is more permissive than read security "+read.String())
+			}
+		}
+	}
+}
+
+// securityTag returns the first tag of t that names a security context other
+// than None.
+func securityTag(t rbxapi.Taggable) string {
+	for _, tag := range t.GetTags() {
+		if rbxapi.Security(tag).Rank() > 0 {
+			return tag
+		}
+	}
+	return ""
+}
+
+func checkSecurityTags(root rbxapi.Root, r *reporter) {
+	for _, class := range root.GetClasses() {
+		for _, member := range class.GetMembers() {
+			tag := securityTag(member)
+			if tag == "" {
+				continue
+			}
+			if security := rbxapi.MemberSecurity(member); rbxapi.Security(security).Rank() == 0 {
+				r.report(MemberPath(class, member), "tagged "+tag+", but the security is None")
+			}
+		}
+	}
+}
+
+func checkServiceSecurity(root rbxapi.Root, r *reporter) {
+	// The dump format omits the None context, so a missing context is
+	// expected of members decoded from a dump.
+	_, dump := root.(*rbxapidump.Root)
+	for _, class := range root.GetClasses() {
+		if !rbxapiconv.HasTag(class, "Service") {
+			continue
+		}
+		for _, member := range class.GetMembers() {
+			contexts := []string{rbxapi.MemberSecurity(member)}
+			if prop, ok := member.(rbxapi.Property); ok {
+				_, write := prop.GetSecurity()
+				contexts = append(contexts, write)
+			}
+			for _, security := range contexts {
+				if security == "" {
+					if dump {
+						continue
+					}
+					r.report(MemberPath(class, member), "missing security context")
+					break
+				}
+				if !rbxapi.Security(security).Known() {
+					r.report(MemberPath(class, member), "unknown security context "+security)
+					break
+				}
+			}
+		}
+	}
+}
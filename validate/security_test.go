@@ -0,0 +1,42 @@
+package validate_test
+
+import (
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"github.com/karl-police/rbxapi/validate"
+	"strings"
+	"testing"
+)
+
+func TestServiceSecurityDump(t *testing.T) {
+	root, err := rbxapidump.Decode(strings.NewReader(`Class Instance
+Class Workspace : Instance [notCreatable] [service]
+	Property bool Workspace.Gravity
+	Function void Workspace:Reset() [UnknownSecurity]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := validate.Validate(root, "service-security")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Path != "Function Workspace.Reset" {
+		t.Errorf("expected unknown security context of Workspace.Reset, got %v", diagnostics)
+	}
+}
+
+func TestReadOnlyWriteSecurityDump(t *testing.T) {
+	root, err := rbxapidump.Decode(strings.NewReader(`Class Instance
+	Property bool Instance.Archivable [readonly] [RobloxScriptSecurity] [ScriptWriteRestricted: [PluginSecurity]]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := validate.Validate(root, "read-only-write-security")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Path != "Property Instance.Archivable" {
+		t.Errorf("expected permissive write security of Instance.Archivable, got %v", diagnostics)
+	}
+}
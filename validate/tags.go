@@ -0,0 +1,149 @@
+package validate
+
+import (
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/rbxapiconv"
+	"github.com/karl-police/rbxapi/rbxapidump"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// KnownTags lists the tags known to be used by API dumps, in both the JSON
+// and the legacy text formats, ordered by name. Tags naming security
+// contexts, as used by the text format, are known through
+// rbxapi.Securities.
+//
+// Tags that are spelled differently in each format are known through
+// rbxapiconv.DumpTags, in both spellings. When Roblox introduces a new tag,
+// it should be added to one of the two once its meaning is understood.
+var KnownTags = knownTags([]string{
+	"CanYield",
+	"CustomLuaState",
+	"NoYield",
+	"PlayerReplicated",
+	"RobloxPlaceSecurity",
+	"UserSettings",
+	"WritePluginSecurity",
+	"WriteRobloxSecurity",
+	"Yields",
+	"backend",
+	"writeonly",
+})
+
+// knownTags returns tags combined with both spellings of each tag in
+// rbxapiconv.DumpTags, sorted by name.
+func knownTags(tags []string) []string {
+	for d, j := range rbxapiconv.DumpTags {
+		tags = append(tags, d, j)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func init() {
+	addRule(&rule{
+		name:     "unknown-tag",
+		severity: Warning,
+		summary:  "tags should be known",
+		options: map[string]string{
+			// Additional tags to consider known.
+			"known": "",
+		},
+		check: checkUnknownTags,
+	})
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d := prev + cost
+			if row[j]+1 < d {
+				d = row[j] + 1
+			}
+			if row[j-1]+1 < d {
+				d = row[j-1] + 1
+			}
+			prev, row[j] = row[j], d
+		}
+	}
+	return row[len(rb)]
+}
+
+// suggest returns the known tag nearest to tag, or an empty string if no
+// known tag is near enough to be a likely misspelling. Tags are compared
+// case-insensitively.
+func suggest(tag string, known []string) string {
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := distance(strings.ToLower(tag), strings.ToLower(k)); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	// Short tags are near too many other tags for a suggestion to be
+	// useful.
+	if 2*bestDist >= utf8.RuneCountInString(tag) {
+		return ""
+	}
+	return best
+}
+
+func checkUnknownTags(root rbxapi.Root, r *reporter) {
+	known := map[string]bool{}
+	var list []string
+	add := func(tag string) {
+		if !known[tag] {
+			known[tag] = true
+			list = append(list, tag)
+		}
+	}
+	for _, tag := range KnownTags {
+		add(tag)
+	}
+	for _, security := range rbxapi.Securities[1:] {
+		add(string(security))
+	}
+	for _, tag := range r.list("known") {
+		add(tag)
+	}
+	sort.Strings(list)
+	check := func(path string, t rbxapi.Taggable) {
+		for _, tag := range t.GetTags() {
+			if known[tag] {
+				continue
+			}
+			if _, write := rbxapidump.ParseSecurityTag(tag); write {
+				continue
+			}
+			msg := "unknown tag " + tag
+			if s := suggest(tag, list); s != "" {
+				msg += "; did you mean " + s + "?"
+			}
+			r.report(path, msg)
+		}
+	}
+	for _, class := range root.GetClasses() {
+		check(ClassPath(class), class)
+		for _, member := range class.GetMembers() {
+			check(MemberPath(class, member), member)
+		}
+	}
+	for _, enum := range root.GetEnums() {
+		check(EnumPath(enum), enum)
+		for _, item := range enum.GetEnumItems() {
+			check(EnumItemPath(enum, item), item)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+// The validate package checks API structures for problems.
+//
+// Checks are organized into named rules. Each rule reports problems as
+// diagnostics, which locate the offending descriptor with a path such as
+// "Property Workspace.Gravity".
+//
+// Additional rules may be added with Register, and run alongside the built-in
+// rules.
+//
+// A Policy checks the changes between two versions of an API structure,
+// reporting the changes it forbids as diagnostics.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"github.com/karl-police/rbxapi"
+	"github.com/karl-police/rbxapi/patch"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity indicates the seriousness of a diagnostic.
+type Severity int
+
+const (
+	Info    Severity = iota // The diagnostic is informational.
+	Warning                 // The diagnostic indicates a likely problem.
+	Error                   // The diagnostic indicates an invalid structure.
+)
+
+// String returns a string representation of the severity.
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return ""
+}
+
+// ParseSeverity returns the severity represented by s.
+func ParseSeverity(s string) (Severity, bool) {
+	switch strings.ToLower(s) {
+	case "info":
+		return Info, true
+	case "warning":
+		return Warning, true
+	case "error":
+		return Error, true
+	}
+	return 0, false
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *Severity) UnmarshalText(text []byte) error {
+	v, ok := ParseSeverity(string(text))
+	if !ok {
+		return errors.New("unknown severity \"" + string(text) + "\"")
+	}
+	*s = v
+	return nil
+}
+
+// Diagnostic describes a problem found by a rule.
+type Diagnostic struct {
+	// Rule is the name of the rule that reported the problem.
+	Rule string
+	// Severity is the seriousness of the problem.
+	Severity Severity
+	// Path locates the descriptor with the problem. It is empty if the
+	// problem applies to the structure as a whole.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// String returns a string representation of the diagnostic.
+func (d Diagnostic) String() string {
+	s := d.Severity.String() + ": "
+	if d.Path != "" {
+		s += d.Path + ": "
+	}
+	return s + d.Message + " [" + d.Rule + "]"
+}
+
+// ClassPath returns the path of a class.
+func ClassPath(class rbxapi.Class) string {
+	return "Class " + class.GetName()
+}
+
+// MemberPath returns the path of a member of a class.
+func MemberPath(class rbxapi.Class, member rbxapi.Member) string {
+	return member.GetMemberType() + " " + class.GetName() + "." + member.GetName()
+}
+
+// EnumPath returns the path of an enum.
+func EnumPath(enum rbxapi.Enum) string {
+	return "Enum " + enum.GetName()
+}
+
+// EnumItemPath returns the path of an item of an enum.
+func EnumItemPath(enum rbxapi.Enum, item rbxapi.EnumItem) string {
+	return "EnumItem " + enum.GetName() + "." + item.GetName()
+}
+
+// reporter collects the diagnostics of a rule.
+type reporter struct {
+	rule        *rule
+	severity    Severity
+	options     map[string]string
+	diagnostics []Diagnostic
+}
+
+// report adds a diagnostic with the severity of the rule.
+func (r *reporter) report(path, msg string) {
+	r.diagnostics = append(r.diagnostics, Diagnostic{
+		Rule:     r.rule.name,
+		Severity: r.severity,
+		Path:     path,
+		Message:  msg,
+	})
+}
+
+// run runs the check of the rule. A malformed structure, such as one with
+// nil descriptors, may cause the check to panic, which is reported as a
+// diagnostic rather than stopping the validation.
+func (r *reporter) run(root rbxapi.Root) {
+	defer func() {
+		if v := recover(); v != nil {
+			r.diagnostics = append(r.diagnostics, Diagnostic{
+				Rule:     r.rule.name,
+				Severity: Error,
+				Message:  fmt.Sprintf("rule failed: %v", v),
+			})
+		}
+	}()
+	r.rule.check(root, r)
+}
+
+// option returns the value of an option of the rule, or its default value if
+// the option is not configured.
+func (r *reporter) option(name string) string {
+	if v, ok := r.options[name]; ok {
+		return v
+	}
+	return r.rule.options[name]
+}
+
+// list returns the value of an option of the rule as a comma-separated list.
+func (r *reporter) list(name string) []string {
+	var list []string
+	for _, s := range strings.Split(r.option(name), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// rule is a named check.
+type rule struct {
+	name     string
+	severity Severity
+	summary  string
+	// options maps the names of the options of the rule to their default
+	// values.
+	options map[string]string
+	check   func(root rbxapi.Root, r *reporter)
+}
+
+// rules contains the built-in and registered rules, ordered by name.
+var rules []*rule
+
+// addRule adds a rule.
+func addRule(r *rule) {
+	i := sort.Search(len(rules), func(i int) bool { return rules[i].name >= r.name })
+	if i < len(rules) && rules[i].name == r.name {
+		panic("validate: rule " + r.name + " added twice")
+	}
+	rules = append(rules, nil)
+	copy(rules[i+1:], rules[i:])
+	rules[i] = r
+}
+
+// Rule is a check that may be added to the built-in rules with Register.
+type Rule interface {
+	// Name returns the name of the rule, which must be unique among rules.
+	Name() string
+	// Severity returns the default severity of the diagnostics of the rule.
+	Severity() Severity
+	// Check returns the problems found in root. The validator sets the Rule
+	// and Severity of each diagnostic, so they need not be set by Check.
+	Check(root rbxapi.Root) []Diagnostic
+}
+
+// Register adds a rule, such as a rule specific to an organization, to be
+// run alongside the built-in rules. If the rule has a Summary method
+// returning a string, it is used as the summary of the rule.
+//
+// Register is meant to be called from an init function, and panics if a
+// rule of the same name is already present.
+func Register(r Rule) {
+	var summary string
+	if s, ok := r.(interface{ Summary() string }); ok {
+		summary = s.Summary()
+	}
+	addRule(&rule{
+		name:     r.Name(),
+		severity: r.Severity(),
+		summary:  summary,
+		check: func(root rbxapi.Root, rep *reporter) {
+			for _, d := range r.Check(root) {
+				rep.report(d.Path, d.Message)
+			}
+		},
+	})
+}
+
+// RuleInfo describes a rule.
+type RuleInfo struct {
+	Name     string
+	Severity Severity
+	Summary  string
+	// Options maps the names of the options of the rule to their default
+	// values.
+	Options map[string]string `json:",omitempty"`
+}
+
+// Rules returns a description of each available rule, ordered by name.
+func Rules() []RuleInfo {
+	list := make([]RuleInfo, len(rules))
+	for i, r := range rules {
+		list[i] = RuleInfo{Name: r.name, Severity: r.severity, Summary: r.summary}
+		if len(r.options) > 0 {
+			list[i].Options = make(map[string]string, len(r.options))
+			for k, v := range r.options {
+				list[i].Options[k] = v
+			}
+		}
+	}
+	return list
+}
+
+// findRule returns the rule of the given name, or nil if there is no such
+// rule.
+func findRule(name string) *rule {
+	i := sort.Search(len(rules), func(i int) bool { return rules[i].name >= name })
+	if i < len(rules) && rules[i].name == name {
+		return rules[i]
+	}
+	return nil
+}
+
+// selectRules returns the rules of the given names. No names, or the name
+// "all", selects every rule.
+func selectRules(names []string) ([]*rule, error) {
+	if len(names) == 0 {
+		return rules, nil
+	}
+	var list []*rule
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name == "all" {
+			return rules, nil
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		r := findRule(name)
+		if r == nil {
+			return nil, errors.New("unknown rule \"" + name + "\"")
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+// Config configures a validation.
+type Config struct {
+	// Rules contains the names of the rules to run. No names, or the name
+	// "all", runs every rule.
+	Rules []string
+	// Severity overrides the severity of rules, by rule name.
+	Severity map[string]Severity
+	// Options sets the options of rules, by rule name and then option name.
+	// Options that are not set have their default values.
+	Options map[string]map[string]string
+}
+
+// check returns an error if the config refers to unknown rules or options.
+func (c *Config) check() error {
+	for name := range c.Severity {
+		if findRule(name) == nil {
+			return errors.New("unknown rule \"" + name + "\"")
+		}
+	}
+	for name, options := range c.Options {
+		r := findRule(name)
+		if r == nil {
+			return errors.New("unknown rule \"" + name + "\"")
+		}
+		for option := range options {
+			if _, ok := r.options[option]; !ok {
+				return errors.New("unknown option \"" + option + "\" of rule \"" + name + "\"")
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks root with the configured rules, returning the diagnostics
+// of each rule in the order the rules are named.
+func (c *Config) Validate(root rbxapi.Root) ([]Diagnostic, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	list, err := selectRules(c.Rules)
+	if err != nil {
+		return nil, err
+	}
+	var diagnostics []Diagnostic
+	for _, rule := range list {
+		r := reporter{rule: rule, severity: rule.severity, options: c.Options[rule.name]}
+		if s, ok := c.Severity[rule.name]; ok {
+			r.severity = s
+		}
+		r.run(root)
+		diagnostics = append(diagnostics, r.diagnostics...)
+	}
+	return diagnostics, nil
+}
+
+// Validate checks root with the rules of the given names, returning the
+// diagnostics of each rule in the order the rules are named. No names, or the
+// name "all", runs every rule. Rules have their default severities and
+// options.
+func Validate(root rbxapi.Root, names ...string) ([]Diagnostic, error) {
+	return (&Config{Rules: names}).Validate(root)
+}
+
+// Max returns the highest severity among diagnostics, or -1 if there are no
+// diagnostics.
+func Max(diagnostics []Diagnostic) Severity {
+	max := Severity(-1)
+	for _, d := range diagnostics {
+		if d.Severity > max {
+			max = d.Severity
+		}
+	}
+	return max
+}
+
+// PatchDiagnostic is a diagnostic introduced by an action of a patch.
+type PatchDiagnostic struct {
+	// Index is the position of the action within the patch.
+	Index int
+	// Action is the action that introduced the diagnostic.
+	Action patch.Action `json:"-"`
+	Diagnostic
+}
+
+// String returns a string representation of the diagnostic.
+func (d PatchDiagnostic) String() string {
+	return "action " + strconv.Itoa(d.Index) + ": " + d.Diagnostic.String()
+}
+
+// ValidatePatch checks the result of applying actions to root with the
+// configured rules, without modifying root. The actions are applied one at a
+// time to a copy of root, which must implement patch.Patcher. Each diagnostic
+// that is not reported for root is attributed to the action after which it
+// is first reported.
+func (c *Config) ValidatePatch(root rbxapi.Root, actions []patch.Action) ([]PatchDiagnostic, error) {
+	root = root.Copy()
+	patcher, ok := root.(patch.Patcher)
+	if !ok {
+		return nil, errors.New("API structure cannot be patched")
+	}
+	diagnostics, err := c.Validate(root)
+	if err != nil {
+		return nil, err
+	}
+	// Diagnostics are counted, so that additional occurrences of an existing
+	// diagnostic are also reported.
+	count := func(diagnostics []Diagnostic) map[Diagnostic]int {
+		counts := make(map[Diagnostic]int, len(diagnostics))
+		for _, d := range diagnostics {
+			counts[d]++
+		}
+		return counts
+	}
+	prev := count(diagnostics)
+	var introduced []PatchDiagnostic
+	for i, action := range actions {
+		patcher.Patch([]patch.Action{action})
+		diagnostics, err := c.Validate(root)
+		if err != nil {
+			return nil, err
+		}
+		next := count(diagnostics)
+		for _, d := range diagnostics {
+			if next[d] > prev[d] {
+				introduced = append(introduced, PatchDiagnostic{Index: i, Action: action, Diagnostic: d})
+				prev[d]++
+			}
+		}
+		prev = next
+	}
+	return introduced, nil
+}
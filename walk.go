@@ -0,0 +1,140 @@
+package rbxapi
+
+import (
+	"errors"
+)
+
+// SkipChildren is returned by a function of a Visitor to indicate that the
+// children of the visited descriptor are to be skipped. It is not returned as
+// an error by Walk.
+var SkipChildren = errors.New("skip children")
+
+// Visitor contains functions called by Walk for each kind of descriptor. A nil
+// function is not called, and the children of the corresponding descriptors
+// are visited.
+//
+// A function that returns SkipChildren causes the children of the visited
+// descriptor to be skipped. A function that returns any other non-nil error
+// stops the walk, and the error is returned by Walk.
+type Visitor struct {
+	// Class is called for each class. Its children are its members.
+	Class func(class Class) error
+	// Member is called for each member of a class. Its children are its
+	// parameters and types.
+	Member func(class Class, member Member) error
+	// Parameter is called for each parameter of a member. Its child is its
+	// type.
+	Parameter func(class Class, member Member, param Parameter) error
+	// Type is called for each type referred to by a member, being the value
+	// type of a property, the return type of a function or callback, or the
+	// type of a parameter.
+	Type func(class Class, member Member, typ Type) error
+	// Enum is called for each enum. Its children are its items.
+	Enum func(enum Enum) error
+	// EnumItem is called for each item of an enum.
+	EnumItem func(enum Enum, item EnumItem) error
+}
+
+// visit interprets the error returned by a function of a Visitor. It returns
+// whether the children of the descriptor are to be visited, and the error
+// that stops the walk, if any.
+func visit(err error) (children bool, stop error) {
+	switch err {
+	case nil:
+		return true, nil
+	case SkipChildren:
+		return false, nil
+	}
+	return false, err
+}
+
+// walkMember visits the parameters and types of a member.
+func walkMember(class Class, member Member, v *Visitor) error {
+	if p, ok := member.(Property); ok && v.Type != nil {
+		if _, err := visit(v.Type(class, member, p.GetValueType())); err != nil {
+			return err
+		}
+	}
+	if f, ok := member.(interface{ GetReturnType() Type }); ok && v.Type != nil {
+		if _, err := visit(v.Type(class, member, f.GetReturnType())); err != nil {
+			return err
+		}
+	}
+	f, ok := member.(interface{ GetParameters() Parameters })
+	if !ok || v.Parameter == nil && v.Type == nil {
+		return nil
+	}
+	params := f.GetParameters()
+	for i, n := 0, params.GetLength(); i < n; i++ {
+		param := params.GetParameter(i)
+		if v.Parameter != nil {
+			children, err := visit(v.Parameter(class, member, param))
+			if err != nil {
+				return err
+			}
+			if !children {
+				continue
+			}
+		}
+		if v.Type != nil {
+			if _, err := visit(v.Type(class, member, param.GetType())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Walk traverses the descriptors of root, calling the functions of v. Each
+// class is visited, followed by each of its members. Each member is followed
+// by its value type or return type, then each of its parameters, each
+// followed by its type. After all classes, each enum is visited, followed by
+// each of its items. Descriptors are visited in the order of root.
+//
+// Returns the first error returned by a function of v, other than
+// SkipChildren.
+func Walk(root Root, v *Visitor) (err error) {
+	memberLevel := v.Member != nil || v.Parameter != nil || v.Type != nil
+	RangeClasses(root, func(class Class) bool {
+		if v.Class != nil {
+			var children bool
+			if children, err = visit(v.Class(class)); err != nil || !children {
+				return err == nil
+			}
+		}
+		if !memberLevel {
+			return true
+		}
+		RangeMembers(class, func(member Member) bool {
+			if v.Member != nil {
+				var children bool
+				if children, err = visit(v.Member(class, member)); err != nil || !children {
+					return err == nil
+				}
+			}
+			err = walkMember(class, member, v)
+			return err == nil
+		})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	RangeEnums(root, func(enum Enum) bool {
+		if v.Enum != nil {
+			var children bool
+			if children, err = visit(v.Enum(enum)); err != nil || !children {
+				return err == nil
+			}
+		}
+		if v.EnumItem == nil {
+			return true
+		}
+		RangeEnumItems(enum, func(item EnumItem) bool {
+			_, err = visit(v.EnumItem(enum, item))
+			return err == nil
+		})
+		return err == nil
+	})
+	return err
+}